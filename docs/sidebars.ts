@@ -95,7 +95,10 @@ const sidebars: SidebarsConfig = {
             'reference/cli/agent',
             'reference/cli/exec',
             'reference/cli/attach',
+            'reference/cli/send',
             'reference/cli/ps',
+            'reference/cli/tui',
+            'reference/cli/peek',
             'reference/cli/logs',
             'reference/cli/stop',
             'reference/cli/kill',
@@ -3,9 +3,26 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
+// DefaultProfile is the profile name used when no profile is specified.
+// Credentials for the default profile are stored under the provider's base
+// keychain account so that existing credentials keep working.
+const DefaultProfile = "default"
+
+// Sentinel errors for credential handling.
+var (
+	ErrInvalidProfile        = errors.New("invalid profile name")
+	ErrInvalidCredentialType = errors.New("invalid credential type")
+)
+
+// profileNamePattern restricts profile names to characters that are safe in keychain account names.
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
 // CredentialType distinguishes between subscription-based and API key authentication.
 type CredentialType string
 
@@ -17,6 +34,19 @@ const (
 	CredentialTypeAPIKey CredentialType = "apikey"
 )
 
+// ParseCredentialType converts a user-supplied string to a CredentialType.
+// Accepts "subscription", "apikey", and "api-key".
+func ParseCredentialType(s string) (CredentialType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case string(CredentialTypeSubscription):
+		return CredentialTypeSubscription, nil
+	case string(CredentialTypeAPIKey), "api-key":
+		return CredentialTypeAPIKey, nil
+	default:
+		return "", fmt.Errorf("%w: %q (valid: subscription, apikey)", ErrInvalidCredentialType, s)
+	}
+}
+
 // Credential holds a provider's authentication credential with its type.
 type Credential struct {
 	Type  CredentialType `json:"type"`
@@ -51,7 +81,8 @@ type ProviderInfo struct {
 	// APIKeyEnvVar is the environment variable for API key credentials.
 	APIKeyEnvVar string
 
-	// KeychainAccount is the keychain account name for storing credentials.
+	// KeychainAccount is the keychain account name for storing credentials
+	// of the default profile. Use AccountForProfile for named profiles.
 	KeychainAccount string
 
 	// RequiresContainerSetup indicates whether subscription credentials need
@@ -59,6 +90,28 @@ type ProviderInfo struct {
 	RequiresContainerSetup bool
 }
 
+// AccountForProfile returns the keychain account name for the given profile.
+// The default profile (or an empty name) maps to KeychainAccount; named
+// profiles are stored as "<KeychainAccount>:<profile>".
+func (i ProviderInfo) AccountForProfile(profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return i.KeychainAccount
+	}
+	return i.KeychainAccount + ":" + profile
+}
+
+// ValidateProfile checks that a profile name is usable as part of a keychain account.
+// An empty name is valid and refers to the default profile.
+func ValidateProfile(profile string) error {
+	if profile == "" {
+		return nil
+	}
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("%w: %q (use letters, digits, '-' or '_')", ErrInvalidProfile, profile)
+	}
+	return nil
+}
+
 // Storage abstracts credential storage backends.
 //
 //go:generate go run github.com/matryer/moq@latest -pkg mocks -out mocks/storage.go . Storage
@@ -92,11 +145,26 @@ type Provider interface {
 	// ValidateAPIKey validates an API key credential value.
 	ValidateAPIKey(value string) error
 
-	// Store saves a credential to storage.
-	Store(storage Storage, cred Credential) error
+	// Store saves a credential to storage under the given profile.
+	// An empty profile refers to DefaultProfile.
+	Store(storage Storage, profile string, cred Credential) error
+
+	// Load retrieves the stored credential for this provider and profile.
+	// An empty profile refers to DefaultProfile.
+	Load(storage Storage, profile string) (*Credential, error)
+}
 
-	// Load retrieves the stored credential for this provider.
-	Load(storage Storage) (*Credential, error)
+// ValidateCredential validates a credential value using the provider's
+// validator for the credential's type.
+func ValidateCredential(provider Provider, cred Credential) error {
+	switch cred.Type {
+	case CredentialTypeSubscription:
+		return provider.ValidateSubscription(cred.Value)
+	case CredentialTypeAPIKey:
+		return provider.ValidateAPIKey(cred.Value)
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidCredentialType, cred.Type)
+	}
 }
 
 // StoreCredential is a helper function to store a credential in JSON format.
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderInfo_AccountForProfile(t *testing.T) {
+	info := ProviderInfo{KeychainAccount: "claude-credential"}
+
+	tests := []struct {
+		name    string
+		profile string
+		want    string
+	}{
+		{
+			name:    "empty profile uses base account",
+			profile: "",
+			want:    "claude-credential",
+		},
+		{
+			name:    "default profile uses base account",
+			profile: DefaultProfile,
+			want:    "claude-credential",
+		},
+		{
+			name:    "named profile is suffixed",
+			profile: "work",
+			want:    "claude-credential:work",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, info.AccountForProfile(tt.profile))
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		wantErr bool
+	}{
+		{name: "empty", profile: "", wantErr: false},
+		{name: "default", profile: DefaultProfile, wantErr: false},
+		{name: "with dash and underscore", profile: "work_api-2", wantErr: false},
+		{name: "leading dash", profile: "-work", wantErr: true},
+		{name: "contains colon", profile: "work:key", wantErr: true},
+		{name: "contains space", profile: "my work", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfile(tt.profile)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidProfile)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseCredentialType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    CredentialType
+		wantErr bool
+	}{
+		{input: "subscription", want: CredentialTypeSubscription},
+		{input: "apikey", want: CredentialTypeAPIKey},
+		{input: "api-key", want: CredentialTypeAPIKey},
+		{input: " APIKEY ", want: CredentialTypeAPIKey},
+		{input: "oauth", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCredentialType(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentialType)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateCredential(t *testing.T) {
+	provider := NewClaudeProvider()
+
+	t.Run("valid subscription", func(t *testing.T) {
+		err := ValidateCredential(provider, Credential{Type: CredentialTypeSubscription, Value: "sk-ant-oat01-abc"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("valid api key", func(t *testing.T) {
+		err := ValidateCredential(provider, Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-api03-abc"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("subscription token rejected as api key", func(t *testing.T) {
+		err := ValidateCredential(provider, Credential{Type: CredentialTypeAPIKey, Value: "sk-ant-oat01-abc"})
+		assert.ErrorContains(t, err, "sk-ant-api")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		err := ValidateCredential(provider, Credential{Type: "oauth", Value: "x"})
+		assert.ErrorIs(t, err, ErrInvalidCredentialType)
+	})
+}
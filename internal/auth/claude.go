@@ -64,14 +64,14 @@ func (p *ClaudeProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *ClaudeProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, claudeInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *ClaudeProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, claudeInfo.AccountForProfile(profile), cred)
 }
 
-// Load retrieves the stored credential for Claude.
-func (p *ClaudeProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, claudeInfo.KeychainAccount)
+// Load retrieves the stored credential for Claude and the given profile.
+func (p *ClaudeProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, claudeInfo.AccountForProfile(profile))
 }
 
 // isClaudeToken checks if a string looks like a Claude OAuth token.
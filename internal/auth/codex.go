@@ -70,14 +70,14 @@ func (p *CodexProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *CodexProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, codexInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *CodexProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, codexInfo.AccountForProfile(profile), cred)
 }
 
-// Load retrieves the stored credential for Codex.
-func (p *CodexProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, codexInfo.KeychainAccount)
+// Load retrieves the stored credential for Codex and the given profile.
+func (p *CodexProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, codexInfo.AccountForProfile(profile))
 }
 
 // readCodexAuth reads the auth.json file from the Codex config directory.
@@ -105,14 +105,14 @@ func (p *GeminiProvider) ValidateAPIKey(value string) error {
 	return nil
 }
 
-// Store saves a credential to storage.
-func (p *GeminiProvider) Store(storage Storage, cred Credential) error {
-	return StoreCredential(storage, geminiInfo.KeychainAccount, cred)
+// Store saves a credential to storage under the given profile.
+func (p *GeminiProvider) Store(storage Storage, profile string, cred Credential) error {
+	return StoreCredential(storage, geminiInfo.AccountForProfile(profile), cred)
 }
 
-// Load retrieves the stored credential for Gemini.
-func (p *GeminiProvider) Load(storage Storage) (*Credential, error) {
-	return LoadCredential(storage, geminiInfo.KeychainAccount)
+// Load retrieves the stored credential for Gemini and the given profile.
+func (p *GeminiProvider) Load(storage Storage, profile string) (*Credential, error) {
+	return LoadCredential(storage, geminiInfo.AccountForProfile(profile))
 }
 
 // readGeminiConfig reads OAuth credentials and account info from Gemini CLI's cache.
@@ -25,10 +25,10 @@ var _ auth.Provider = &ProviderMock{}
 //			InfoFunc: func() auth.ProviderInfo {
 //				panic("mock out the Info method")
 //			},
-//			LoadFunc: func(storage auth.Storage) (*auth.Credential, error) {
+//			LoadFunc: func(storage auth.Storage, profile string) (*auth.Credential, error) {
 //				panic("mock out the Load method")
 //			},
-//			StoreFunc: func(storage auth.Storage, cred auth.Credential) error {
+//			StoreFunc: func(storage auth.Storage, profile string, cred auth.Credential) error {
 //				panic("mock out the Store method")
 //			},
 //			ValidateAPIKeyFunc: func(value string) error {
@@ -51,10 +51,10 @@ type ProviderMock struct {
 	InfoFunc func() auth.ProviderInfo
 
 	// LoadFunc mocks the Load method.
-	LoadFunc func(storage auth.Storage) (*auth.Credential, error)
+	LoadFunc func(storage auth.Storage, profile string) (*auth.Credential, error)
 
 	// StoreFunc mocks the Store method.
-	StoreFunc func(storage auth.Storage, cred auth.Credential) error
+	StoreFunc func(storage auth.Storage, profile string, cred auth.Credential) error
 
 	// ValidateAPIKeyFunc mocks the ValidateAPIKey method.
 	ValidateAPIKeyFunc func(value string) error
@@ -74,11 +74,15 @@ type ProviderMock struct {
 		Load []struct {
 			// Storage is the storage argument value.
 			Storage auth.Storage
+			// Profile is the profile argument value.
+			Profile string
 		}
 		// Store holds details about calls to the Store method.
 		Store []struct {
 			// Storage is the storage argument value.
 			Storage auth.Storage
+			// Profile is the profile argument value.
+			Profile string
 			// Cred is the cred argument value.
 			Cred auth.Credential
 		}
@@ -156,19 +160,21 @@ func (mock *ProviderMock) InfoCalls() []struct {
 }
 
 // Load calls LoadFunc.
-func (mock *ProviderMock) Load(storage auth.Storage) (*auth.Credential, error) {
+func (mock *ProviderMock) Load(storage auth.Storage, profile string) (*auth.Credential, error) {
 	if mock.LoadFunc == nil {
 		panic("ProviderMock.LoadFunc: method is nil but Provider.Load was just called")
 	}
 	callInfo := struct {
 		Storage auth.Storage
+		Profile string
 	}{
 		Storage: storage,
+		Profile: profile,
 	}
 	mock.lockLoad.Lock()
 	mock.calls.Load = append(mock.calls.Load, callInfo)
 	mock.lockLoad.Unlock()
-	return mock.LoadFunc(storage)
+	return mock.LoadFunc(storage, profile)
 }
 
 // LoadCalls gets all the calls that were made to Load.
@@ -177,9 +183,11 @@ func (mock *ProviderMock) Load(storage auth.Storage) (*auth.Credential, error) {
 //	len(mockedProvider.LoadCalls())
 func (mock *ProviderMock) LoadCalls() []struct {
 	Storage auth.Storage
+	Profile string
 } {
 	var calls []struct {
 		Storage auth.Storage
+		Profile string
 	}
 	mock.lockLoad.RLock()
 	calls = mock.calls.Load
@@ -188,21 +196,23 @@ func (mock *ProviderMock) LoadCalls() []struct {
 }
 
 // Store calls StoreFunc.
-func (mock *ProviderMock) Store(storage auth.Storage, cred auth.Credential) error {
+func (mock *ProviderMock) Store(storage auth.Storage, profile string, cred auth.Credential) error {
 	if mock.StoreFunc == nil {
 		panic("ProviderMock.StoreFunc: method is nil but Provider.Store was just called")
 	}
 	callInfo := struct {
 		Storage auth.Storage
+		Profile string
 		Cred    auth.Credential
 	}{
 		Storage: storage,
+		Profile: profile,
 		Cred:    cred,
 	}
 	mock.lockStore.Lock()
 	mock.calls.Store = append(mock.calls.Store, callInfo)
 	mock.lockStore.Unlock()
-	return mock.StoreFunc(storage, cred)
+	return mock.StoreFunc(storage, profile, cred)
 }
 
 // StoreCalls gets all the calls that were made to Store.
@@ -211,10 +221,12 @@ func (mock *ProviderMock) Store(storage auth.Storage, cred auth.Credential) erro
 //	len(mockedProvider.StoreCalls())
 func (mock *ProviderMock) StoreCalls() []struct {
 	Storage auth.Storage
+	Profile string
 	Cred    auth.Credential
 } {
 	var calls []struct {
 		Storage auth.Storage
+		Profile string
 		Cred    auth.Credential
 	}
 	mock.lockStore.RLock()
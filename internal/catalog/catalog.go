@@ -36,27 +36,47 @@ const (
 	SessionTypeCodex  SessionType = "codex"
 )
 
+// MuxMode identifies where an instance's terminal multiplexer runs.
+type MuxMode string
+
+// MuxMode constants for multiplexer placement.
+const (
+	MuxModeHost      MuxMode = "host"      // Multiplexer on the host, panes exec into the container
+	MuxModeContainer MuxMode = "container" // tmux server inside the container
+)
+
 // Session represents a persistent, attachable process running within an instance.
 type Session struct {
-	ID           string      `json:"id"`             // Unique session identifier
-	Name         string      `json:"name"`           // Human-readable name (e.g., "happy-panda")
-	Type         SessionType `json:"type"`           // Session type (shell, claude, gemini, codex)
-	MuxSessionID string      `json:"mux_session_id"` // Multiplexer session identifier
-	CreatedAt    time.Time   `json:"created_at"`     // Creation timestamp
-	LastAccessed time.Time   `json:"last_accessed"`  // Last access timestamp (for MRU tracking)
+	ID           string      `json:"id"`                // Unique session identifier
+	Name         string      `json:"name"`              // Human-readable name (e.g., "happy-panda")
+	Type         SessionType `json:"type"`              // Session type (shell, claude, gemini, codex)
+	MuxSessionID string      `json:"mux_session_id"`    // Multiplexer session identifier
+	CreatedAt    time.Time   `json:"created_at"`        // Creation timestamp
+	LastAccessed time.Time   `json:"last_accessed"`     // Last access timestamp (for MRU tracking)
+	Profile      string      `json:"profile,omitempty"` // Credential profile used by agent sessions (empty for shell)
+}
+
+// PortForward represents a host port forwarded to a port inside an
+// instance's container. The host port stays assigned to the instance while
+// forwarding is stopped, so the same URL works when it is resumed.
+type PortForward struct {
+	ContainerPort int `json:"container_port"` // Port inside the container
+	HostPort      int `json:"host_port"`      // Port on the host loopback interface
 }
 
 // Entry represents a persisted instance record.
 type Entry struct {
-	ID          string    `json:"id"`
-	Repo        string    `json:"repo"`         // Absolute path to source repository
-	RepoID      string    `json:"repo_id"`      // Unique repository identifier
-	Branch      string    `json:"branch"`       // Branch name
-	Worktree    string    `json:"worktree"`     // Absolute path to worktree
-	ContainerID string    `json:"container_id"` // Container ID (may be empty)
-	CreatedAt   time.Time `json:"created_at"`
-	Status      Status    `json:"status"`
-	Sessions    []Session `json:"sessions"` // Sessions running within this instance
+	ID          string        `json:"id"`
+	Repo        string        `json:"repo"`         // Absolute path to source repository
+	RepoID      string        `json:"repo_id"`      // Unique repository identifier
+	Branch      string        `json:"branch"`       // Branch name
+	Worktree    string        `json:"worktree"`     // Absolute path to worktree
+	ContainerID string        `json:"container_id"` // Container ID (may be empty)
+	CreatedAt   time.Time     `json:"created_at"`
+	Status      Status        `json:"status"`
+	Sessions    []Session     `json:"sessions"`           // Sessions running within this instance
+	MuxMode     MuxMode       `json:"mux_mode,omitempty"` // Where sessions run (empty = host)
+	Ports       []PortForward `json:"ports,omitempty"`    // Forwarded ports
 
 	// Devcontainer-specific fields (populated when using devcontainer runtime)
 	RemoteUser    string `json:"remote_user,omitempty"`    // User for exec operations
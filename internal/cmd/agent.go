@@ -26,6 +26,9 @@ to it unless --detached is specified.
 If agent_name is not specified, the default agent from configuration is used.
 Set the default with 'hjk config default.agent <agent_name>'.
 
+Credentials are loaded from the agent's default profile unless --profile is
+given. Set a per-agent default with 'hjk config agents.<agent_name>.default_profile <profile>'.
+
 Additional flags can be passed to the agent CLI by placing them after a -- separator.
 
 All session output is captured to a log file regardless of attached/detached mode.`,
@@ -42,6 +45,9 @@ All session output is captured to a log file regardless of attached/detached mod
   # Start Gemini agent with custom session name
   hjk agent feat/auth gemini --name auth-session
 
+  # Start Claude using the credentials stored under the "work" profile
+  hjk agent feat/auth claude --profile work
+
   # Start agent in detached mode (run in background)
   hjk agent feat/auth -d --prompt "Refactor the auth module"
 
@@ -56,6 +62,7 @@ type agentFlags struct {
 	sessionName string
 	detached    bool
 	prompt      string
+	profile     string   // credential profile (empty = config default)
 	agentFlags  []string // flags to pass to the agent CLI (after --)
 }
 
@@ -73,11 +80,16 @@ func parseAgentFlags(cmd *cobra.Command, args []string) (*agentFlags, error) {
 	if err != nil {
 		return nil, fmt.Errorf("get prompt flag: %w", err)
 	}
+	profile, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return nil, fmt.Errorf("get profile flag: %w", err)
+	}
 
 	return &agentFlags{
 		sessionName: sessionName,
 		detached:    detached,
 		prompt:      prompt,
+		profile:     profile,
 		agentFlags:  parsePassthroughArgs(cmd, args),
 	}, nil
 }
@@ -103,28 +115,49 @@ var agentAuthSpecs = map[string]agentAuthSpec{
 	},
 }
 
-// injectAuthCredential retrieves the credential for the agent and configures the session.
-func injectAuthCredential(agent string, cfg *instance.CreateSessionConfig) error {
+// resolveAgentProfile determines the credential profile from the flag or the agent's configured default.
+func resolveAgentProfile(ctx context.Context, agent, override string) (string, error) {
+	profile := override
+	if profile == "" {
+		if loader := LoaderFromContext(ctx); loader != nil {
+			profile = loader.GetAgentProfile(agent)
+		}
+	}
+	if profile == "" {
+		profile = auth.DefaultProfile
+	}
+	if err := auth.ValidateProfile(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+// injectAuthCredential retrieves the credential for the agent profile and configures the session.
+func injectAuthCredential(agent, profile string, cfg *instance.CreateSessionConfig) error {
 	spec, ok := agentAuthSpecs[agent]
 	if !ok {
 		return nil
 	}
 
-	storage, err := keychain.New()
+	storage, err := openKeychain()
 	if err != nil {
 		return fmt.Errorf("initialize credential storage: %w", err)
 	}
 
 	provider := spec.provider()
-	cred, err := provider.Load(storage)
+	cred, err := provider.Load(storage, profile)
 	if err != nil {
 		if errors.Is(err, keychain.ErrNotFound) {
+			if profile != auth.DefaultProfile {
+				return fmt.Errorf("%s auth not configured for profile %q: run 'hjk auth %s --profile %s' first", agent, profile, agent, profile)
+			}
 			return errors.New(spec.notConfiguredMsg)
 		}
 		return fmt.Errorf("load %s credential: %w", agent, err)
 	}
 
 	info := provider.Info()
+	cfg.Profile = profile
 
 	// Set environment variable based on credential type
 	switch cred.Type {
@@ -221,15 +254,21 @@ func runAgentCmd(cmd *cobra.Command, args []string) error {
 		Command: buildAgentCommand(agentName, flags.prompt, mergedFlags),
 	}
 
-	// Inject agent-specific environment variables from config
+	// Inject agent-specific environment variables from config, resolving secret references
 	if loader := LoaderFromContext(cmd.Context()); loader != nil {
-		for k, v := range loader.GetAgentEnv(agentName) {
-			sessionCfg.Env = append(sessionCfg.Env, k+"="+v)
+		env, envErr := resolveSecretEnv(inst.RepoID, loader.GetAgentEnv(agentName))
+		if envErr != nil {
+			return fmt.Errorf("resolve %s env: %w", agentName, envErr)
 		}
+		sessionCfg.Env = append(sessionCfg.Env, env...)
 	}
 
 	// Inject authentication credentials from keychain
-	if authErr := injectAuthCredential(agentName, sessionCfg); authErr != nil {
+	profile, err := resolveAgentProfile(cmd.Context(), agentName, flags.profile)
+	if err != nil {
+		return err
+	}
+	if authErr := injectAuthCredential(agentName, profile, sessionCfg); authErr != nil {
 		return authErr
 	}
 
@@ -267,4 +306,5 @@ func init() {
 	agentCmd.Flags().StringP("name", "n", "", "override auto-generated session name")
 	agentCmd.Flags().BoolP("detached", "d", false, "create session but don't attach (run in background)")
 	agentCmd.Flags().StringP("prompt", "p", "", "initial prompt to send to the agent")
+	agentCmd.Flags().String("profile", "", "credential profile to use (default: agent's configured default_profile)")
 }
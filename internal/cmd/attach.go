@@ -24,7 +24,13 @@ suggesting 'hjk run' to create one.
 
 To detach from a session without terminating it, use the tmux detach keybinding
 (default: Ctrl+B, d). This returns you to your host terminal while the
-session continues running.`,
+session continues running.
+
+With --grid, all sessions of the branch's instance are shown together as tiled
+panes of a temporary tmux session. With --all, the grid has one pane per
+running instance, showing its most recently accessed session. The grid needs
+tmux on the host whichever multiplexer is configured. Detaching from the grid
+(Ctrl+B, d) closes it and leaves every session running.`,
 	Example: `  # Attach to whatever you were last working on
   hjk attach
 
@@ -32,17 +38,46 @@ session continues running.`,
   hjk attach feat/auth
 
   # Attach to specific session
-  hjk attach feat/auth claude-main`,
+  hjk attach feat/auth claude-main
+
+  # Show every session of feat/auth side by side
+  hjk attach --grid feat/auth
+
+  # Show the most recent session of every running instance
+  hjk attach --all`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: runAttachCmd,
 }
 
 func runAttachCmd(cmd *cobra.Command, args []string) error {
+	grid, err := cmd.Flags().GetBool("grid")
+	if err != nil {
+		return fmt.Errorf("get grid flag: %w", err)
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("get all flag: %w", err)
+	}
+
+	switch {
+	case all && len(args) > 0:
+		return errors.New("--all does not take arguments")
+	case grid && !all && len(args) != 1:
+		return errors.New("--grid requires a branch (or use --all)")
+	}
+
 	mgr, err := requireManager(cmd.Context())
 	if err != nil {
 		return err
 	}
 
+	switch {
+	case all:
+		return attachAllGrid(cmd, mgr)
+	case grid:
+		return attachInstanceGrid(cmd, mgr, args[0])
+	}
+
 	switch len(args) {
 	case 0:
 		return attachGlobalMRU(cmd, mgr)
@@ -103,6 +138,57 @@ func attachExplicitSession(cmd *cobra.Command, mgr *instance.Manager, branch, se
 	return mgr.AttachSession(cmd.Context(), inst.ID, sessionName)
 }
 
+// attachInstanceGrid attaches to all sessions of an instance in a grid.
+func attachInstanceGrid(cmd *cobra.Command, mgr *instance.Manager, branch string) error {
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := mgr.ListSessions(cmd.Context(), inst.ID)
+	if err != nil {
+		return fmt.Errorf("list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("no sessions exist for branch %q (use 'hjk run' to create one)", branch)
+	}
+
+	targets := make([]instance.GridTarget, len(sessions))
+	for i, s := range sessions {
+		targets[i] = instance.GridTarget{InstanceID: inst.ID, SessionName: s.Name}
+	}
+	return mgr.AttachGrid(cmd.Context(), targets)
+}
+
+// attachAllGrid attaches to the most recently accessed session of every
+// running instance in a grid.
+func attachAllGrid(cmd *cobra.Command, mgr *instance.Manager) error {
+	instances, err := mgr.List(cmd.Context(), instance.ListFilter{Status: instance.StatusRunning})
+	if err != nil {
+		return fmt.Errorf("list instances: %w", err)
+	}
+
+	var targets []instance.GridTarget
+	for _, inst := range instances {
+		session, err := mgr.GetMRUSession(cmd.Context(), inst.ID)
+		if err != nil {
+			if errors.Is(err, instance.ErrNoSessionsAvailable) {
+				continue
+			}
+			return fmt.Errorf("get MRU session: %w", err)
+		}
+		targets = append(targets, instance.GridTarget{InstanceID: inst.ID, SessionName: session.Name})
+	}
+	if len(targets) == 0 {
+		return errors.New("no sessions exist in running instances (use 'hjk run' to create one)")
+	}
+
+	return mgr.AttachGrid(cmd.Context(), targets)
+}
+
 func init() {
+	attachCmd.Flags().Bool("grid", false, "show all sessions of the instance in a split-pane grid")
+	attachCmd.Flags().Bool("all", false, "show the most recent session of every running instance in a grid")
+
 	rootCmd.AddCommand(attachCmd)
 }
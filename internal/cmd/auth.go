@@ -3,6 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -17,7 +20,15 @@ var authCmd = &cobra.Command{
 	Long: `Configure authentication for supported agent CLIs.
 
 Prompts for authentication method (subscription or API key) and stores
-credentials securely in the system keychain.`,
+credentials securely in the system keychain.
+
+Each agent can hold several named credential profiles (for example a personal
+subscription and a company API key). Use --profile to select which profile to
+configure; without it, the "default" profile is used.
+
+For CI and other non-interactive environments, pass --type together with one
+of --from-env, --from-file, or --stdin to skip the prompts. With the encrypted
+file keychain backend, HEADJACK_KEYRING_PASSWORD must be set in this mode.`,
 }
 
 var authClaudeCmd = &cobra.Command{
@@ -29,7 +40,16 @@ Choose between:
   1. Subscription: Uses your Claude Pro/Max subscription via OAuth token
   2. API Key: Uses an Anthropic API key for pay-per-use billing`,
 	Example: `  # Set up Claude Code authentication
-  headjack auth claude`,
+  headjack auth claude
+
+  # Store a separate credential under the "work" profile
+  headjack auth claude --profile work
+
+  # Non-interactive setup from an environment variable (CI)
+  headjack auth claude --type apikey --from-env ANTHROPIC_API_KEY
+
+  # Non-interactive setup from stdin
+  echo "$TOKEN" | headjack auth claude --type subscription --stdin`,
 	RunE: runAuthClaude,
 }
 
@@ -59,7 +79,14 @@ Choose between:
 	RunE: runAuthCodex,
 }
 
-var authStatusFlag bool
+var (
+	authStatusFlag   bool
+	authProfileFlag  string
+	authTypeFlag     string
+	authFromEnvFlag  string
+	authFromFileFlag string
+	authStdinFlag    bool
+)
 
 func init() {
 	rootCmd.AddCommand(authCmd)
@@ -67,9 +94,16 @@ func init() {
 	authCmd.AddCommand(authGeminiCmd)
 	authCmd.AddCommand(authCodexCmd)
 
-	// Add --status flag to all auth subcommands
+	// Add --status and --profile flags to all auth subcommands
 	for _, cmd := range []*cobra.Command{authClaudeCmd, authGeminiCmd, authCodexCmd} {
 		cmd.Flags().BoolVar(&authStatusFlag, "status", false, "Show current authentication status")
+		cmd.Flags().StringVar(&authProfileFlag, "profile", auth.DefaultProfile, "Credential profile to configure")
+		cmd.Flags().StringVar(&authTypeFlag, "type", "", "Credential type for non-interactive setup (subscription or apikey)")
+		cmd.Flags().StringVar(&authFromEnvFlag, "from-env", "", "Read the credential from the named environment variable")
+		cmd.Flags().StringVar(&authFromFileFlag, "from-file", "", "Read the credential from a file")
+		cmd.Flags().BoolVar(&authStdinFlag, "stdin", false, "Read the credential from standard input")
+		cmd.MarkFlagsMutuallyExclusive("from-env", "from-file", "stdin")
+		cmd.MarkFlagsMutuallyExclusive("status", "type")
 	}
 }
 
@@ -87,23 +121,119 @@ func runAuthCodex(_ *cobra.Command, _ []string) error {
 
 // runAuth handles both --status checks and interactive auth flows.
 func runAuth(provider auth.Provider) error {
+	if err := auth.ValidateProfile(authProfileFlag); err != nil {
+		return err
+	}
 	if authStatusFlag {
-		return showAuthStatus(provider)
+		return showAuthStatus(provider, authProfileFlag)
+	}
+	if isNonInteractiveAuth() {
+		return runNonInteractiveAuth(provider, authProfileFlag)
+	}
+	return runAuthFlow(provider, authProfileFlag)
+}
+
+// isNonInteractiveAuth reports whether any non-interactive auth flag was given.
+func isNonInteractiveAuth() bool {
+	return authTypeFlag != "" || authFromEnvFlag != "" || authFromFileFlag != "" || authStdinFlag
+}
+
+// runNonInteractiveAuth stores a credential read from the environment, a file,
+// or stdin without prompting. The credential is validated by the provider
+// before it is stored.
+func runNonInteractiveAuth(provider auth.Provider, profile string) error {
+	if authTypeFlag == "" {
+		return errors.New("--type is required with --from-env, --from-file, or --stdin")
+	}
+	credType, err := auth.ParseCredentialType(authTypeFlag)
+	if err != nil {
+		return err
+	}
+
+	value, err := readCredentialValue(provider, credType)
+	if err != nil {
+		return err
+	}
+
+	cred := auth.Credential{Type: credType, Value: value}
+	if err := auth.ValidateCredential(provider, cred); err != nil {
+		return fmt.Errorf("invalid credential: %w", err)
 	}
-	return runAuthFlow(provider)
+
+	// Never prompt for the keyring password: there may be no terminal, and
+	// stdin may already have been consumed by --stdin.
+	kcCfg := keychainConfig()
+	kcCfg.PasswordFunc = keychain.EnvPasswordFunc
+	storage, err := keychain.NewWithConfig(kcCfg)
+	if err != nil {
+		return fmt.Errorf("initialize credential storage: %w", err)
+	}
+
+	if err := provider.Store(storage, profile, cred); err != nil {
+		if errors.Is(err, keychain.ErrNoPassword) {
+			return fmt.Errorf("store credential: keychain backend requires a password: set %s", keychain.EnvKeyringPassword)
+		}
+		return fmt.Errorf("store credential: %w", err)
+	}
+
+	fmt.Printf("Stored %s credential for %s.\n", credType, authStatusLabel(provider.Info().Name, profile))
+	return nil
 }
 
-// showAuthStatus displays the current authentication status for a provider.
-func showAuthStatus(provider auth.Provider) error {
-	storage, err := keychain.New()
+// readCredentialValue reads the credential from the source selected by flags.
+// With no source, subscription credentials fall back to the provider's
+// auto-detection (e.g. ~/.codex/auth.json).
+func readCredentialValue(provider auth.Provider, credType auth.CredentialType) (string, error) {
+	var value string
+
+	switch {
+	case authFromEnvFlag != "":
+		v, ok := os.LookupEnv(authFromEnvFlag)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", authFromEnvFlag)
+		}
+		value = v
+	case authFromFileFlag != "":
+		data, err := os.ReadFile(authFromFileFlag) //nolint:gosec // Path is explicitly provided by the user
+		if err != nil {
+			return "", fmt.Errorf("read credential file: %w", err)
+		}
+		value = string(data)
+	case authStdinFlag:
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read credential from stdin: %w", err)
+		}
+		value = string(data)
+	case credType == auth.CredentialTypeSubscription:
+		v, err := provider.CheckSubscription()
+		if err != nil {
+			return "", err
+		}
+		value = v
+	default:
+		return "", errors.New("no credential source: use --from-env, --from-file, or --stdin")
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", errors.New("credential is empty")
+	}
+	return value, nil
+}
+
+// showAuthStatus displays the current authentication status for a provider profile.
+func showAuthStatus(provider auth.Provider, profile string) error {
+	storage, err := openKeychain()
 	if err != nil {
 		return fmt.Errorf("initialize credential storage: %w", err)
 	}
 
 	info := provider.Info()
-	cred, err := provider.Load(storage)
+	label := authStatusLabel(info.Name, profile)
+	cred, err := provider.Load(storage, profile)
 	if errors.Is(err, keychain.ErrNotFound) {
-		fmt.Printf("%s: not configured\n", info.Name)
+		fmt.Printf("%s: not configured\n", label)
 		return nil
 	}
 	if err != nil {
@@ -112,19 +242,27 @@ func showAuthStatus(provider auth.Provider) error {
 
 	switch cred.Type {
 	case auth.CredentialTypeSubscription:
-		fmt.Printf("%s: subscription\n", info.Name)
+		fmt.Printf("%s: subscription\n", label)
 	case auth.CredentialTypeAPIKey:
-		fmt.Printf("%s: api key\n", info.Name)
+		fmt.Printf("%s: api key\n", label)
 	default:
-		fmt.Printf("%s: configured (unknown type)\n", info.Name)
+		fmt.Printf("%s: configured (unknown type)\n", label)
 	}
 
 	return nil
 }
 
-// runAuthFlow runs the interactive authentication flow for a provider.
-func runAuthFlow(provider auth.Provider) error {
-	storage, err := keychain.New()
+// authStatusLabel returns the provider name, qualified with the profile when it is not the default.
+func authStatusLabel(name, profile string) string {
+	if profile == "" || profile == auth.DefaultProfile {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, profile)
+}
+
+// runAuthFlow runs the interactive authentication flow for a provider profile.
+func runAuthFlow(provider auth.Provider, profile string) error {
+	storage, err := openKeychain()
 	if err != nil {
 		return fmt.Errorf("initialize credential storage: %w", err)
 	}
@@ -132,7 +270,7 @@ func runAuthFlow(provider auth.Provider) error {
 	prompter := prompt.New()
 	info := provider.Info()
 
-	prompter.Print(fmt.Sprintf("Configure %s authentication", info.Name))
+	prompter.Print(fmt.Sprintf("Configure %s authentication", authStatusLabel(info.Name, profile)))
 	prompter.Print("")
 
 	choice, err := prompter.Choice("Authentication method:", []string{
@@ -158,7 +296,7 @@ func runAuthFlow(provider auth.Provider) error {
 		return err
 	}
 
-	if err := provider.Store(storage, cred); err != nil {
+	if err := provider.Store(storage, profile, cred); err != nil {
 		return fmt.Errorf("store credential: %w", err)
 	}
 
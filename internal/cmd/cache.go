@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage caches shared by instances",
+	Long: `List and clear the named volumes backing configured caches.
+
+Each entry of the cache config section names a volume, hjk-cache-<name>, and
+where to mount it in new containers. The container runtime creates the volume
+the first time an instance uses it, and every later instance shares it, so
+package downloads survive instances being removed.`,
+	Example: `  # Share the npm and Go module caches
+  hjk config cache.npm "~/.npm"
+  hjk config cache.go-mod /go/pkg/mod
+
+  # Show caches and whether their volumes exist
+  hjk cache ls
+
+  # Clear one cache, or all of them
+  hjk cache clear npm
+  hjk cache clear`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List caches and their volumes",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheLs,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [name...]",
+	Short: "Remove cache volumes",
+	Long: `Remove the volumes of the named caches, or of all caches if none are given,
+including volumes of caches no longer configured. Instances recreate them
+empty when they are next created.
+
+A volume can't be removed while any instance's container uses it, even a
+stopped one; remove those instances with 'hjk rm' first.`,
+	RunE: runCacheClear,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheLsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+// requireVolumeManager returns the volume operations of the configured runtime.
+func requireVolumeManager(ctx context.Context) (container.VolumeManager, error) {
+	mgr, err := requireManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	volumes, ok := mgr.Runtime().(container.VolumeManager)
+	if !ok {
+		return nil, errors.New("container runtime does not support volume management")
+	}
+	return volumes, nil
+}
+
+func runCacheLs(cmd *cobra.Command, _ []string) error {
+	volumes, err := requireVolumeManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	list, err := volumes.ListVolumes(cmd.Context(), instance.CacheVolumePrefix)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(list))
+	for _, v := range list {
+		existing[v.Name] = true
+	}
+
+	caches := getCaches()
+	if len(caches) == 0 && len(list) == 0 {
+		fmt.Println("No caches configured")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tVOLUME\tTARGET\tSTATUS"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	configured := make(map[string]bool, len(caches))
+	for _, c := range caches {
+		configured[c.Volume()] = true
+		status := "not created"
+		if existing[c.Volume()] {
+			status = "created"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Name, c.Volume(), c.Target, status); err != nil {
+			return fmt.Errorf("write cache: %w", err)
+		}
+	}
+	// Volumes of caches removed from the config still hold data
+	for _, v := range list {
+		if configured[v.Name] {
+			continue
+		}
+		name := strings.TrimPrefix(v.Name, instance.CacheVolumePrefix)
+		if _, err := fmt.Fprintf(w, "%s\t%s\t-\tnot configured\n", name, v.Name); err != nil {
+			return fmt.Errorf("write cache: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	volumes, err := requireVolumeManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if len(args) > 0 {
+		for _, name := range args {
+			names = append(names, instance.Cache{Name: name}.Volume())
+		}
+	} else {
+		list, listErr := volumes.ListVolumes(cmd.Context(), instance.CacheVolumePrefix)
+		if listErr != nil {
+			return listErr
+		}
+		for _, v := range list {
+			names = append(names, v.Name)
+		}
+		if len(names) == 0 {
+			fmt.Println("No cache volumes to clear")
+			return nil
+		}
+	}
+
+	var failed int
+	for _, name := range names {
+		err := volumes.RemoveVolume(cmd.Context(), name)
+		switch {
+		case err == nil:
+			fmt.Printf("Cleared %s\n", name)
+		case errors.Is(err, container.ErrVolumeNotFound):
+			fmt.Printf("Cache volume %s does not exist\n", name)
+		case errors.Is(err, container.ErrVolumeInUse):
+			fmt.Printf("Cache volume %s is in use; remove the instances using it and try again\n", name)
+			failed++
+		default:
+			slogger.L(cmd.Context()).Warn("failed to remove cache volume", slog.String("volume", name), slog.String("error", err.Error()))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to clear %d cache(s)", failed)
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -19,7 +22,14 @@ var configCmd = &cobra.Command{
 
 With no arguments, displays all configuration.
 With one argument, displays the value for the specified key.
-With two arguments, sets the value for the specified key.`,
+With two arguments, sets the value for the specified key.
+
+Inside a git repository, a .headjack.yaml file at the repository root is
+merged over the user config. It may override the default, agents, and runtime
+sections, except for settings that reach the host, such as runtime flags that
+mount host paths. Environment variables take precedence over both files. Use
+--show-origin to see where each value came from. Values are always written to
+the user config.`,
 	Example: `  # Show all config
   headjack config
 
@@ -29,6 +39,9 @@ With two arguments, sets the value for the specified key.`,
   # Set a value
   headjack config default.agent claude
 
+  # Show where each value came from
+  headjack config --show-origin
+
   # Open config file in editor
   headjack config --edit`,
 	Args:              cobra.RangeArgs(0, 2),
@@ -42,11 +55,22 @@ With two arguments, sets the value for the specified key.`,
 			return runEdit(cmd.Context())
 		}
 
-		loader, err := config.NewLoader()
+		loader, err := newConfigLoader(cmd.Context())
 		if err != nil {
 			return fmt.Errorf("init config loader: %w", err)
 		}
 
+		showOrigin, err := cmd.Flags().GetBool("show-origin")
+		if err != nil {
+			return fmt.Errorf("get show-origin flag: %w", err)
+		}
+		if showOrigin {
+			if len(args) == 2 {
+				return errors.New("--show-origin cannot be used when setting a value")
+			}
+			return runShowOrigin(loader, args)
+		}
+
 		switch len(args) {
 		case 0:
 			return runShowAll(loader)
@@ -138,6 +162,38 @@ func runShowKey(loader *config.Loader, key string) error {
 	return nil
 }
 
+// runShowOrigin prints each configuration value prefixed by its source, in the
+// style of 'git config --show-origin'. With a key, only that key (or the keys
+// beneath it) are shown.
+func runShowOrigin(loader *config.Loader, args []string) error {
+	var prefix string
+	if len(args) == 1 {
+		if err := config.ValidateKey(args[0]); err != nil {
+			return err
+		}
+		prefix = strings.ToLower(args[0])
+	}
+
+	if _, err := loader.Load(); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, o := range loader.Origins() {
+		if prefix != "" && o.Key != prefix && !strings.HasPrefix(o.Key, prefix+".") {
+			continue
+		}
+		origin := string(o.Source)
+		if o.Location != "" {
+			origin += ":" + o.Location
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s=%v\n", origin, o.Key, o.Value); err != nil {
+			return fmt.Errorf("write origin: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
 func runSetKey(loader *config.Loader, key, value string) error {
 	// Load first to ensure file exists
 	if _, err := loader.Load(); err != nil {
@@ -156,4 +212,6 @@ func init() {
 	rootCmd.AddCommand(configCmd)
 
 	configCmd.Flags().Bool("edit", false, "open config file in $EDITOR")
+	configCmd.Flags().Bool("show-origin", false, "show the source (default, user, repo, env) of each value")
+	configCmd.MarkFlagsMutuallyExclusive("edit", "show-origin")
 }
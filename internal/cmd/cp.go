@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <branch>:<path> <dest> | <src> <branch>:<path>",
+	Short: "Copy files between the host and an instance",
+	Long: `Copy a file or directory between the host and an instance's container.
+
+The container side is written as <branch>:<path>. A relative path is relative
+to the instance's workspace, so <branch>: alone refers to the workspace
+itself. Host paths containing a colon must start with / or ./.
+
+If the destination is an existing directory, the source is copied into it;
+otherwise it is copied under the destination name. Stopped instances are not
+restarted; files are copied to and from the stopped container.`,
+	Example: `  # Copy build output out of an instance
+  hjk cp feat/auth:dist ./dist
+
+  # Copy a file from anywhere in the container
+  hjk cp feat/auth:/tmp/server.log .
+
+  # Copy fixtures into the instance's workspace
+  hjk cp ./testdata feat/auth:`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCpCmd,
+}
+
+// containerRef is a path in an instance's container given as <branch>:<path>.
+type containerRef struct {
+	Branch string
+	Path   string
+}
+
+// parseContainerRef parses <branch>:<path>. Paths starting with / or . are
+// host paths even if they contain a colon; git branch names can't start
+// with either.
+func parseContainerRef(arg string) (containerRef, bool) {
+	if strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, ".") {
+		return containerRef{}, false
+	}
+	branch, p, ok := strings.Cut(arg, ":")
+	if !ok || branch == "" {
+		return containerRef{}, false
+	}
+	return containerRef{Branch: branch, Path: p}, true
+}
+
+func runCpCmd(cmd *cobra.Command, args []string) error {
+	src, dst := args[0], args[1]
+	srcRef, fromContainer := parseContainerRef(src)
+	dstRef, toContainer := parseContainerRef(dst)
+	switch {
+	case fromContainer && toContainer:
+		return errors.New("copying between instances is not supported; copy to the host first")
+	case !fromContainer && !toContainer:
+		return errors.New("one of the paths must be in an instance, written as <branch>:<path>")
+	}
+
+	branch := dstRef.Branch
+	if fromContainer {
+		branch = srcRef.Branch
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+	path, err := repoPath()
+	if err != nil {
+		return err
+	}
+	// Files can be copied while the instance is stopped
+	inst, err := lookupInstanceByBranch(cmd.Context(), mgr, path, branch)
+	if err != nil {
+		return err
+	}
+
+	if fromContainer {
+		err = mgr.CopyFrom(cmd.Context(), inst.ID, srcRef.Path, dst)
+	} else {
+		err = mgr.CopyTo(cmd.Context(), inst.ID, src, dstRef.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %s to %s\n", src, dst)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+}
@@ -19,6 +19,9 @@ the multiplexer and execute the command directly (like 'docker exec').
 
 If no command is specified, the default shell is started.
 
+Use --env to set additional environment variables. Values of the form
+secret:<name> are resolved from the repository's secret store ('hjk secret').
+
 All session output is captured to a log file (when using tmux).`,
 	Example: `  # Start a shell session in tmux
   hjk exec feat/auth
@@ -33,7 +36,10 @@ All session output is captured to a log file (when using tmux).`,
   hjk exec feat/auth --no-mux
 
   # Run with custom session name
-  hjk exec feat/auth --name build-session npm run build`,
+  hjk exec feat/auth --name build-session npm run build
+
+  # Inject a stored secret as an environment variable
+  hjk exec feat/auth --env NPM_TOKEN=secret:npm npm publish`,
 	Args:               cobra.MinimumNArgs(1),
 	RunE:               runExecCmd,
 	DisableFlagParsing: false,
@@ -44,6 +50,7 @@ type execFlags struct {
 	noMux       bool
 	sessionName string
 	detached    bool
+	env         map[string]string
 }
 
 // parseExecFlags extracts and validates flags from the command.
@@ -60,11 +67,20 @@ func parseExecFlags(cmd *cobra.Command) (*execFlags, error) {
 	if err != nil {
 		return nil, fmt.Errorf("get detached flag: %w", err)
 	}
+	envValues, err := cmd.Flags().GetStringArray("env")
+	if err != nil {
+		return nil, fmt.Errorf("get env flag: %w", err)
+	}
+	env, err := parseEnvFlags(envValues)
+	if err != nil {
+		return nil, err
+	}
 
 	return &execFlags{
 		noMux:       noMux,
 		sessionName: sessionName,
 		detached:    detached,
+		env:         env,
 	}, nil
 }
 
@@ -88,6 +104,11 @@ func runExecCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	env, err := resolveSecretEnv(inst.RepoID, flags.env)
+	if err != nil {
+		return fmt.Errorf("resolve env: %w", err)
+	}
+
 	if flags.noMux {
 		// Direct execution (bypasses multiplexer entirely)
 		// Only use interactive mode when starting a shell (no command specified)
@@ -95,6 +116,7 @@ func runExecCmd(cmd *cobra.Command, args []string) error {
 		return mgr.Attach(cmd.Context(), inst.ID, instance.AttachConfig{
 			Command:     cmdArgs,           // Empty = shell
 			Interactive: len(cmdArgs) == 0, // Interactive only for shell
+			Env:         env,
 		})
 	}
 
@@ -103,6 +125,7 @@ func runExecCmd(cmd *cobra.Command, args []string) error {
 		Type:    "shell",
 		Name:    flags.sessionName,
 		Command: cmdArgs, // Empty = shell
+		Env:     env,
 	}
 
 	session, err := mgr.CreateSession(cmd.Context(), inst.ID, sessionCfg)
@@ -138,4 +161,5 @@ func init() {
 	execCmd.Flags().Bool("no-mux", false, "bypass tmux and execute directly")
 	execCmd.Flags().StringP("name", "n", "", "override auto-generated session name (ignored with --no-mux)")
 	execCmd.Flags().BoolP("detached", "d", false, "create session but don't attach (ignored with --no-mux)")
+	execCmd.Flags().StringArrayP("env", "e", nil, "set an environment variable (KEY=VALUE, value may be secret:<name>)")
 }
@@ -10,7 +10,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/jmgilman/headjack/internal/config"
+	hjexec "github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/git"
 	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/keychain"
 )
 
 func requireManager(ctx context.Context) (*instance.Manager, error) {
@@ -29,6 +32,24 @@ func repoPath() (string, error) {
 	return path, nil
 }
 
+// newConfigLoader creates a config loader that merges the repository's
+// .headjack.yaml when the working directory is inside a git repository.
+func newConfigLoader(ctx context.Context) (*config.Loader, error) {
+	loader, err := config.NewLoader()
+	if err != nil {
+		return nil, err
+	}
+
+	// Outside a repository only the user config applies.
+	if path, pathErr := repoPath(); pathErr == nil {
+		if repo, openErr := git.NewOpener(hjexec.New()).Open(ctx, path); openErr == nil {
+			loader.SetRepoRoot(repo.Root())
+		}
+	}
+
+	return loader, nil
+}
+
 func defaultDataDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -37,6 +58,31 @@ func defaultDataDir() (string, error) {
 	return filepath.Join(home, config.DefaultDataDir), nil
 }
 
+// keychainConfig returns the keychain configuration from the loaded config.
+// HEADJACK_KEYRING_BACKEND still takes precedence over the configured backend.
+func keychainConfig() keychain.Config {
+	if appConfig == nil {
+		return keychain.Config{}
+	}
+	kc := appConfig.Keychain
+	return keychain.Config{
+		Backend:    keychain.Backend(kc.Backend),
+		PassCmd:    kc.Pass.Cmd,
+		PassDir:    kc.Pass.Dir,
+		PassPrefix: kc.Pass.Prefix,
+		Command: keychain.CommandConfig{
+			Get:    kc.Command.Get,
+			Set:    kc.Command.Set,
+			Delete: kc.Command.Delete,
+		},
+	}
+}
+
+// openKeychain opens the credential keychain using the configured backend.
+func openKeychain() (keychain.Keychain, error) {
+	return keychain.NewWithConfig(keychainConfig())
+}
+
 func resolveBaseImage(ctx context.Context, override string) string {
 	if override != "" {
 		return override
@@ -52,6 +98,8 @@ func runtimeLogsCommand(runtimeName, containerID string) string {
 	switch runtimeName {
 	case runtimeNameDocker:
 		return "docker logs " + containerID
+	case runtimeNameNerdctl:
+		return "nerdctl logs " + containerID
 	default:
 		return "podman logs " + containerID
 	}
@@ -104,12 +152,9 @@ func getInstanceByBranch(ctx context.Context, mgr *instance.Manager, branch stri
 		return nil, err
 	}
 
-	inst, err := mgr.GetByBranch(ctx, repoPath, branch)
+	inst, err := lookupInstanceByBranch(ctx, mgr, repoPath, branch)
 	if err != nil {
-		if errors.Is(err, instance.ErrNotFound) {
-			return nil, fmt.Errorf("no instance found for branch %q\nhint: run 'hjk run %s' to create one", branch, branch)
-		}
-		return nil, fmt.Errorf("get instance: %w", err)
+		return nil, err
 	}
 
 	// Auto-restart if stopped
@@ -127,3 +172,16 @@ func getInstanceByBranch(ctx context.Context, mgr *instance.Manager, branch stri
 
 	return inst, nil
 }
+
+// lookupInstanceByBranch gets an existing instance by branch without
+// restarting it, returning an error with hint if not found.
+func lookupInstanceByBranch(ctx context.Context, mgr *instance.Manager, repoPath, branch string) (*instance.Instance, error) {
+	inst, err := mgr.GetByBranch(ctx, repoPath, branch)
+	if err != nil {
+		if errors.Is(err, instance.ErrNotFound) {
+			return nil, fmt.Errorf("no instance found for branch %q\nhint: run 'hjk run %s' to create one", branch, branch)
+		}
+		return nil, fmt.Errorf("get instance: %w", err)
+	}
+	return inst, nil
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/devcontainer"
+	"github.com/jmgilman/headjack/internal/progress"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage prebuilt container images",
+	Long: `Prebuild, pull, list, and prune container images.
+
+'hjk image build' builds the repository's dev container image ahead of time,
+tagged headjack/<name>:<hash> where the hash covers devcontainer.json and its
+Dockerfile. 'hjk run' uses a matching prebuilt image instead of building, so
+instances start quickly until the configuration changes.`,
+	Example: `  # Prebuild the dev container image for this repository
+  hjk image build
+
+  # Build a base image from a directory with a Dockerfile
+  hjk image build --base images/base
+
+  # Pre-fetch the configured base image
+  hjk image pull
+
+  # List and clean up prebuilt images
+  hjk image ls
+  hjk image prune`,
+}
+
+var imageBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Prebuild the dev container or a base image",
+	Long: `Build the dev container image of the repository in the working directory
+with 'devcontainer build', including its features, and tag it by the content
+hash of devcontainer.json and its Dockerfile.
+
+With --base, build the Dockerfile in the given directory instead, tagged
+headjack/base:<hash>, for use as default.base_image.
+
+An image that is up to date is not rebuilt unless --force is given.`,
+	Args: cobra.NoArgs,
+	RunE: runImageBuild,
+}
+
+var imagePullCmd = &cobra.Command{
+	Use:   "pull [image...]",
+	Short: "Pull images ahead of time",
+	Long:  `Pull the given images, or the configured default.base_image if none are given.`,
+	RunE:  runImagePull,
+}
+
+var imageLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List images built by headjack",
+	Args:  cobra.NoArgs,
+	RunE:  runImageLs,
+}
+
+var imagePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove images built by headjack that no container uses",
+	Args:  cobra.NoArgs,
+	RunE:  runImagePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+	imageCmd.AddCommand(imageBuildCmd)
+	imageCmd.AddCommand(imagePullCmd)
+	imageCmd.AddCommand(imageLsCmd)
+	imageCmd.AddCommand(imagePruneCmd)
+
+	imageBuildCmd.Flags().String("base", "", "build the Dockerfile in this directory as a base image")
+	imageBuildCmd.Flags().Bool("force", false, "rebuild even if the image is up to date")
+	imagePruneCmd.Flags().Bool("dry-run", false, "list the images that would be removed")
+}
+
+// headjackImages is the reference filter matching images built by headjack.
+var headjackImages = container.ImageFilter{Reference: container.ImageRepository + "/*"}
+
+// requireImageManager returns the image operations of the configured runtime.
+func requireImageManager(ctx context.Context) (container.ImageManager, error) {
+	mgr, err := requireManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	images, ok := mgr.Runtime().(container.ImageManager)
+	if !ok {
+		return nil, errors.New("container runtime does not support image management")
+	}
+	return images, nil
+}
+
+// imageExists reports whether an image with the given tag exists locally.
+func imageExists(ctx context.Context, images container.ImageManager, tag string) (bool, error) {
+	list, err := images.ListImages(ctx, container.ImageFilter{Reference: tag})
+	if err != nil {
+		return false, err
+	}
+	return len(list) > 0, nil
+}
+
+// prebuiltImage returns the prebuilt image of the dev container in
+// workspaceFolder, or "" if it has not been built. Errors are logged, since
+// the image can always be built instead.
+func prebuiltImage(ctx context.Context, workspaceFolder string) string {
+	log := slogger.L(ctx)
+
+	images, err := requireImageManager(ctx)
+	if err != nil {
+		return ""
+	}
+	tag, err := devcontainer.ImageTag(workspaceFolder)
+	if err != nil {
+		log.Debug("no prebuilt image tag", slog.String("error", err.Error()))
+		return ""
+	}
+	exists, err := imageExists(ctx, images, tag)
+	if err != nil {
+		log.Warn("failed to look up prebuilt image", slog.String("error", err.Error()))
+		return ""
+	}
+	if !exists {
+		return ""
+	}
+
+	log.Debug("using prebuilt image", slog.String("image", tag))
+	return tag
+}
+
+func runImageBuild(cmd *cobra.Command, _ []string) error {
+	base, err := cmd.Flags().GetString("base")
+	if err != nil {
+		return fmt.Errorf("get base flag: %w", err)
+	}
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("get force flag: %w", err)
+	}
+
+	images, err := requireImageManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var tag, workspaceFolder string
+	if base != "" {
+		tag, err = container.ImageTag("base", filepath.Join(base, "Dockerfile"))
+	} else {
+		workspaceFolder, err = repoPath()
+		if err != nil {
+			return err
+		}
+		if !devcontainer.HasConfig(workspaceFolder) {
+			return errors.New("no devcontainer.json found\n\nUse --base to build a base image from a Dockerfile instead")
+		}
+		tag, err = devcontainer.ImageTag(workspaceFolder)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		exists, existsErr := imageExists(cmd.Context(), images, tag)
+		if existsErr != nil {
+			return fmt.Errorf("look up image: %w", existsErr)
+		}
+		if exists {
+			fmt.Printf("Image %s is up to date\n", tag)
+			return nil
+		}
+	}
+
+	if base != "" {
+		err = buildBaseImage(cmd, base, tag)
+	} else {
+		err = buildDevcontainerImage(cmd, workspaceFolder, tag)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Built image %s\n", tag)
+	return nil
+}
+
+// buildBaseImage builds the Dockerfile in dir with the container runtime,
+// printing build output to stderr.
+func buildBaseImage(cmd *cobra.Command, dir, tag string) error {
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+	// The runtime's default is the Dockerfile in the context directory
+	return mgr.Runtime().Build(cmd.Context(), &container.BuildConfig{
+		Context: dir,
+		Tag:     tag,
+		Output:  os.Stderr,
+	})
+}
+
+// buildDevcontainerImage builds the dev container in workspaceFolder with
+// the devcontainer CLI, printing progress to stderr.
+func buildDevcontainerImage(cmd *cobra.Command, workspaceFolder, tag string) error {
+	dcRuntime, err := newDevcontainerRuntime(cmd)
+	if err != nil {
+		return err
+	}
+
+	out := progress.NewWriter(progress.PlainHandler(os.Stderr))
+	defer out.Close() //nolint:errcheck // Close never fails
+	return dcRuntime.BuildImage(cmd.Context(), workspaceFolder, tag, out)
+}
+
+func runImagePull(cmd *cobra.Command, args []string) error {
+	images, err := requireImageManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	refs := args
+	if len(refs) == 0 {
+		base := resolveBaseImage(cmd.Context(), "")
+		if base == "" {
+			return errors.New("no image given and no default.base_image configured")
+		}
+		refs = []string{base}
+	}
+
+	for _, ref := range refs {
+		if err := images.Pull(cmd.Context(), ref, os.Stderr); err != nil {
+			return err
+		}
+		fmt.Printf("Pulled %s\n", ref)
+	}
+	return nil
+}
+
+func runImageLs(cmd *cobra.Command, _ []string) error {
+	images, err := requireImageManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	list, err := images.ListImages(cmd.Context(), headjackImages)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("No images found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "IMAGE\tID\tSIZE\tCREATED"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	for i := range list {
+		img := &list[i]
+		for _, tag := range img.Tags {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				tag,
+				container.ShortImageID(img.ID),
+				progress.FormatSize(img.Size),
+				formatTimeAgo(img.CreatedAt),
+			); err != nil {
+				return fmt.Errorf("write image: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+func runImagePrune(cmd *cobra.Command, _ []string) error {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("get dry-run flag: %w", err)
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+	images, err := requireImageManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	list, err := images.ListImages(cmd.Context(), headjackImages)
+	if err != nil {
+		return err
+	}
+	// Any container counts, so images of containers headjack didn't
+	// create are kept as well
+	containers, err := mgr.Runtime().List(cmd.Context(), container.ListFilter{})
+	if err != nil {
+		return fmt.Errorf("list containers: %w", err)
+	}
+
+	unused := container.UnreferencedImages(list, containers)
+	if len(unused) == 0 {
+		fmt.Println("No unused images")
+		return nil
+	}
+
+	var failed int
+	for _, img := range unused {
+		for _, tag := range img.Tags {
+			if dryRun {
+				fmt.Printf("Would remove %s\n", tag)
+				continue
+			}
+			if err := images.RemoveImage(cmd.Context(), tag); err != nil && !errors.Is(err, container.ErrImageNotFound) {
+				slogger.L(cmd.Context()).Warn("failed to remove image", slog.String("image", tag), slog.String("error", err.Error()))
+				failed++
+				continue
+			}
+			fmt.Printf("Removed %s\n", tag)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d image(s)", failed)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,7 +22,13 @@ var logsCmd = &cobra.Command{
 	Long: `View output from a session without attaching.
 
 Reads from the session's log file, useful for checking on detached agents
-without interrupting them.`,
+without interrupting them.
+
+With --clean, the session's plain-text transcript is shown instead of the raw
+terminal output. Transcript lines start with a timestamp and can be filtered
+with --since and --until, which take a duration before now (e.g., 10m) or a
+timestamp (e.g., 2024-01-02T15:04:05Z, 2024-01-02 15:04 or 2024-01-02).
+Transcripts are recorded when logs.transcript is enabled.`,
 	Example: `  # View recent output (last 100 lines)
   headjack logs feat/auth happy-panda
 
@@ -32,7 +39,10 @@ without interrupting them.`,
   headjack logs feat/auth happy-panda -n 500
 
   # Show entire log from session start
-  headjack logs feat/auth happy-panda --full`,
+  headjack logs feat/auth happy-panda --full
+
+  # Show the transcript of the last 30 minutes
+  headjack logs feat/auth happy-panda --clean --since 30m`,
 	Args: cobra.ExactArgs(2),
 	RunE: runLogsCmd,
 }
@@ -56,6 +66,19 @@ func runLogsCmd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("get full flag: %w", err)
 	}
 
+	clean, err := cmd.Flags().GetBool("clean")
+	if err != nil {
+		return fmt.Errorf("get clean flag: %w", err)
+	}
+
+	since, until, err := logTimeRange(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	if !clean && (!since.IsZero() || !until.IsZero()) {
+		return errors.New("--since and --until require --clean, since raw logs have no timestamps")
+	}
+
 	// Get the instance for this branch
 	mgr, err := requireManager(cmd.Context())
 	if err != nil {
@@ -80,9 +103,14 @@ func runLogsCmd(cmd *cobra.Command, args []string) error {
 	}
 	pathMgr := logging.NewPathManager(logsDir)
 	reader := logging.NewReader(pathMgr)
-
-	// Check if log file exists
-	if !pathMgr.LogExists(inst.ID, session.ID) {
+	if clean {
+		reader = logging.NewTranscriptReader(pathMgr).WithTimeRange(since, until)
+
+		if !pathMgr.TranscriptExists(inst.ID, session.ID) {
+			return fmt.Errorf("no transcript found for session %s (set logs.transcript to true to record transcripts of new sessions)", sessionName)
+		}
+	} else if !pathMgr.LogExists(inst.ID, session.ID) {
+		// Check if log file exists
 		return fmt.Errorf("no log file found for session %s", sessionName)
 	}
 
@@ -122,6 +150,53 @@ func init() {
 	logsCmd.Flags().BoolP("follow", "f", false, "follow log output in real-time")
 	logsCmd.Flags().IntP("lines", "n", logging.DefaultTailLines, "number of lines to show")
 	logsCmd.Flags().Bool("full", false, "show entire log from session start")
+	logsCmd.Flags().Bool("clean", false, "show the timestamped plain-text transcript")
+	logsCmd.Flags().String("since", "", "show transcript lines at or after this time (duration or timestamp)")
+	logsCmd.Flags().String("until", "", "show transcript lines at or before this time (duration or timestamp)")
+}
+
+// logTimeFormats are the timestamp formats accepted by --since and --until.
+// Formats without a zone are in local time.
+var logTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// logTimeRange parses the --since and --until flags relative to now.
+func logTimeRange(cmd *cobra.Command, now time.Time) (since, until time.Time, err error) {
+	for _, f := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &since}, {"until", &until}} {
+		value, err := cmd.Flags().GetString(f.name)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("get %s flag: %w", f.name, err)
+		}
+		if value == "" {
+			continue
+		}
+		if *f.t, err = parseLogTime(value, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --%s: %w", f.name, err)
+		}
+	}
+	return since, until, nil
+}
+
+// parseLogTime parses a duration before now or a timestamp.
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range logTimeFormats {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration or timestamp", value)
 }
 
 // getLogsDir returns the logs directory from config, or the default if config is nil.
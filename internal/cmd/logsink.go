@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// logSinkCmd records session output piped from the multiplexer. It is
+// invoked by tmux pipe-pane rather than by users directly.
+var logSinkCmd = &cobra.Command{
+	Use:   "log-sink <path>",
+	Short: "Append standard input to a session log",
+	Long: `Append standard input to a session log, rotating the log when it reaches
+--max-size. Rotated segments are compressed with --compress, and the oldest
+rotated segments of the instance are deleted when its logs exceed --max-total.
+With --transcript, a timestamped plain-text transcript is written next to the
+log.`,
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	// Runs for every session without the instance manager or a container runtime.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rotation, transcript, err := logConfigFromFlags(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		w, err := logging.OpenSessionLog(args[0], rotation, transcript)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, os.Stdin); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("record session log: %w", err)
+		}
+		return w.Close()
+	},
+}
+
+// logSinkCommand returns the command that tmux pipes session output into,
+// without the log path.
+func logSinkCommand(binary string, rotation logging.RotateConfig, transcript bool) []string {
+	return append([]string{binary, "log-sink"}, logArgs(rotation, transcript, "")...)
+}
+
+// getLogRotation returns log rotation settings from config.
+func getLogRotation() logging.RotateConfig {
+	if appConfig == nil {
+		return logging.DefaultRotateConfig()
+	}
+	return logging.RotateConfig{
+		MaxSize:  int64(appConfig.Logs.MaxSizeMB) << 20,
+		MaxTotal: int64(appConfig.Logs.MaxTotalMB) << 20,
+		Compress: appConfig.Logs.Compress,
+	}
+}
+
+// getLogTranscript reports whether session transcripts are enabled in config.
+func getLogTranscript() bool {
+	return appConfig != nil && appConfig.Logs.Transcript
+}
+
+// addLogFlags adds session log flags, with names starting with prefix.
+func addLogFlags(c *cobra.Command, prefix string) {
+	c.Flags().Int64(prefix+"max-size", 0, "rotate the log at this size in bytes (0 = never)")
+	c.Flags().Int64(prefix+"max-total", 0, "delete the oldest rotated logs of the instance above this total in bytes (0 = unlimited)")
+	c.Flags().Bool(prefix+"compress", false, "gzip rotated logs")
+	c.Flags().Bool(prefix+"transcript", false, "also write a timestamped plain-text transcript")
+}
+
+// logArgs returns the flags added by addLogFlags for the given settings.
+// Flags with default values are omitted.
+func logArgs(rotation logging.RotateConfig, transcript bool, prefix string) []string {
+	var args []string
+	if rotation.MaxSize > 0 {
+		args = append(args, "--"+prefix+"max-size", strconv.FormatInt(rotation.MaxSize, 10))
+	}
+	if rotation.MaxTotal > 0 {
+		args = append(args, "--"+prefix+"max-total", strconv.FormatInt(rotation.MaxTotal, 10))
+	}
+	if rotation.Compress {
+		args = append(args, "--"+prefix+"compress")
+	}
+	if transcript {
+		args = append(args, "--"+prefix+"transcript")
+	}
+	return args
+}
+
+// logConfigFromFlags reads the flags added by addLogFlags.
+func logConfigFromFlags(cmd *cobra.Command, prefix string) (logging.RotateConfig, bool, error) {
+	maxSize, err := cmd.Flags().GetInt64(prefix + "max-size")
+	if err != nil {
+		return logging.RotateConfig{}, false, fmt.Errorf("get %smax-size flag: %w", prefix, err)
+	}
+	maxTotal, err := cmd.Flags().GetInt64(prefix + "max-total")
+	if err != nil {
+		return logging.RotateConfig{}, false, fmt.Errorf("get %smax-total flag: %w", prefix, err)
+	}
+	compress, err := cmd.Flags().GetBool(prefix + "compress")
+	if err != nil {
+		return logging.RotateConfig{}, false, fmt.Errorf("get %scompress flag: %w", prefix, err)
+	}
+	transcript, err := cmd.Flags().GetBool(prefix + "transcript")
+	if err != nil {
+		return logging.RotateConfig{}, false, fmt.Errorf("get %stranscript flag: %w", prefix, err)
+	}
+	return logging.RotateConfig{MaxSize: maxSize, MaxTotal: maxTotal, Compress: compress}, transcript, nil
+}
+
+func init() {
+	addLogFlags(logSinkCmd, "")
+
+	rootCmd.AddCommand(logSinkCmd)
+}
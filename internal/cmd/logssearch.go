@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/git"
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+var logsSearchCmd = &cobra.Command{
+	Use:   "search <pattern>",
+	Short: "Search the output of all sessions",
+	Long: `Search the logs of every session for lines matching a regular expression
+(Go RE2 syntax).
+
+Logs of all instances across repositories are searched concurrently,
+including rotated segments. Terminal escape sequences are stripped before
+matching. Each match is shown with the branch of its instance and the name
+of its session, followed by the line number within the session log.
+
+Use --repo, --branch and --type to limit the search to some sessions. Logs
+that no longer belong to a session in the catalog are only searched without
+these filters and are shown with their instance and session IDs.`,
+	Example: `  # Which agent touched the migrations?
+  headjack logs search 'migrations/.*\.sql'
+
+  # Case-insensitive search with two lines of context
+  headjack logs search -i -C 2 'panic:'
+
+  # Only Claude sessions of one branch
+  headjack logs search --branch feat/auth --type claude 'TODO'
+
+  # Machine-readable output
+  headjack logs search --json 'error' | jq '.[].session'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogsSearchCmd,
+}
+
+// searchSession is a session resolved through the catalog.
+type searchSession struct {
+	Branch string
+	Repo   string
+	Name   string
+	Type   string
+}
+
+// searchResult is a match in JSON output.
+type searchResult struct {
+	InstanceID string   `json:"instance_id"`
+	Repo       string   `json:"repo,omitempty"`
+	Branch     string   `json:"branch,omitempty"`
+	SessionID  string   `json:"session_id"`
+	Session    string   `json:"session,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Line       int      `json:"line"`
+	Text       string   `json:"text"`
+	Before     []string `json:"before,omitempty"`
+	After      []string `json:"after,omitempty"`
+}
+
+func runLogsSearchCmd(cmd *cobra.Command, args []string) error {
+	opts, err := logsSearchFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	pattern := args[0]
+	if opts.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	filter := instance.ListFilter{}
+	if opts.repo != "" {
+		repo, openErr := git.NewOpener(exec.New()).Open(cmd.Context(), opts.repo)
+		if openErr != nil {
+			return fmt.Errorf("open repository: %w", openErr)
+		}
+		filter.RepoID = repo.Identifier()
+	}
+	sessions, err := searchSessions(cmd, mgr, filter)
+	if err != nil {
+		return err
+	}
+
+	filtered := opts.repo != "" || opts.branch != "" || opts.sessionType != ""
+	include := func(instanceID, sessionID string) bool {
+		sess, ok := sessions[instanceID+"/"+sessionID]
+		if !ok {
+			return !filtered
+		}
+		return (opts.branch == "" || sess.Branch == opts.branch) &&
+			(opts.sessionType == "" || sess.Type == opts.sessionType)
+	}
+
+	logsDir, err := getLogsDir(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("get logs directory: %w", err)
+	}
+	matches, err := logging.NewSearcher(logging.NewPathManager(logsDir)).Search(cmd.Context(), re, logging.SearchOptions{
+		Context: opts.context,
+		Workers: opts.workers,
+		Include: include,
+	})
+	if err != nil {
+		return fmt.Errorf("search logs: %w", err)
+	}
+
+	results := make([]searchResult, len(matches))
+	for i, m := range matches {
+		sess := sessions[m.InstanceID+"/"+m.SessionID]
+		results[i] = searchResult{
+			InstanceID: m.InstanceID,
+			Repo:       sess.Repo,
+			Branch:     sess.Branch,
+			SessionID:  m.SessionID,
+			Session:    sess.Name,
+			Type:       sess.Type,
+			Line:       m.Line,
+			Text:       m.Text,
+			Before:     m.Before,
+			After:      m.After,
+		}
+	}
+	// Keep matches of a session together and sessions in a readable order,
+	// with logs that are not in the catalog last
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Session == "") != (b.Session == "") {
+			return b.Session == ""
+		}
+		if a.Branch != b.Branch {
+			return a.Branch < b.Branch
+		}
+		return a.Session < b.Session
+	})
+
+	if opts.json {
+		return writeSearchJSON(os.Stdout, results)
+	}
+	if len(results) == 0 {
+		fmt.Println("No matches found")
+		return nil
+	}
+	return writeSearchText(os.Stdout, results, opts.context)
+}
+
+// logsSearchOptions holds the flags of logs search.
+type logsSearchOptions struct {
+	repo        string
+	branch      string
+	sessionType string
+	context     int
+	workers     int
+	ignoreCase  bool
+	json        bool
+}
+
+func logsSearchFlags(cmd *cobra.Command) (logsSearchOptions, error) {
+	var opts logsSearchOptions
+	var err error
+	if opts.repo, err = cmd.Flags().GetString("repo"); err != nil {
+		return opts, fmt.Errorf("get repo flag: %w", err)
+	}
+	if opts.branch, err = cmd.Flags().GetString("branch"); err != nil {
+		return opts, fmt.Errorf("get branch flag: %w", err)
+	}
+	if opts.sessionType, err = cmd.Flags().GetString("type"); err != nil {
+		return opts, fmt.Errorf("get type flag: %w", err)
+	}
+	if opts.context, err = cmd.Flags().GetInt("context"); err != nil {
+		return opts, fmt.Errorf("get context flag: %w", err)
+	}
+	if opts.workers, err = cmd.Flags().GetInt("jobs"); err != nil {
+		return opts, fmt.Errorf("get jobs flag: %w", err)
+	}
+	if opts.ignoreCase, err = cmd.Flags().GetBool("ignore-case"); err != nil {
+		return opts, fmt.Errorf("get ignore-case flag: %w", err)
+	}
+	if opts.json, err = cmd.Flags().GetBool("json"); err != nil {
+		return opts, fmt.Errorf("get json flag: %w", err)
+	}
+	if opts.context < 0 {
+		return opts, errors.New("--context must not be negative")
+	}
+	return opts, nil
+}
+
+// searchSessions returns the sessions of the instances matching filter,
+// keyed by "<instanceID>/<sessionID>".
+func searchSessions(cmd *cobra.Command, mgr *instance.Manager, filter instance.ListFilter) (map[string]searchSession, error) {
+	instances, err := mgr.List(cmd.Context(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("list instances: %w", err)
+	}
+
+	sessions := make(map[string]searchSession)
+	for i := range instances {
+		inst := &instances[i]
+		instSessions, err := mgr.ListSessions(cmd.Context(), inst.ID)
+		if err != nil {
+			if errors.Is(err, instance.ErrNotFound) {
+				continue // Removed since it was listed
+			}
+			return nil, fmt.Errorf("list sessions: %w", err)
+		}
+		for _, sess := range instSessions {
+			sessions[inst.ID+"/"+sess.ID] = searchSession{
+				Branch: inst.Branch,
+				Repo:   inst.Repo,
+				Name:   sess.Name,
+				Type:   sess.Type,
+			}
+		}
+	}
+	return sessions, nil
+}
+
+func writeSearchJSON(w io.Writer, results []searchResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		return fmt.Errorf("write results: %w", err)
+	}
+	return nil
+}
+
+// writeSearchText writes matches like grep: "<branch> <session>:<line>:" before
+// matched lines and "<branch> <session>-<line>-" before context lines. With
+// context, "--" separates groups of lines that are not adjacent, and context
+// shared by nearby matches is written once.
+func writeSearchText(w io.Writer, results []searchResult, context int) error {
+	var label string // Label of the last line written
+	lastLine := 0    // Number of the last line written
+	for _, r := range results {
+		next := r.Branch + " " + r.Session
+		if r.Session == "" {
+			next = r.InstanceID + " " + r.SessionID
+		}
+		if next != label {
+			lastLine = 0
+		}
+
+		first := r.Line - len(r.Before)
+		lines := append(append(append([]string(nil), r.Before...), r.Text), r.After...)
+		for i, text := range lines {
+			n := first + i
+			if n <= lastLine {
+				continue
+			}
+			if context > 0 && label != "" && (next != label || n > lastLine+1) {
+				if _, err := fmt.Fprintln(w, "--"); err != nil {
+					return fmt.Errorf("write results: %w", err)
+				}
+			}
+			sep := "-"
+			if n == r.Line {
+				sep = ":"
+			}
+			if _, err := fmt.Fprintf(w, "%s%s%d%s%s\n", next, sep, n, sep, text); err != nil {
+				return fmt.Errorf("write results: %w", err)
+			}
+			label, lastLine = next, n
+		}
+	}
+	return nil
+}
+
+func init() {
+	logsCmd.AddCommand(logsSearchCmd)
+
+	logsSearchCmd.Flags().String("repo", "", "only search sessions of instances of the repository at this path")
+	logsSearchCmd.Flags().String("branch", "", "only search sessions of the instance for this branch")
+	logsSearchCmd.Flags().String("type", "", "only search sessions of this type (shell, claude, gemini, codex)")
+	logsSearchCmd.Flags().IntP("context", "C", 0, "lines of context to show around each match")
+	logsSearchCmd.Flags().IntP("jobs", "j", 0, "number of logs to search concurrently (default: number of CPUs)")
+	logsSearchCmd.Flags().BoolP("ignore-case", "i", false, "match case-insensitively")
+	logsSearchCmd.Flags().Bool("json", false, "write matches as JSON")
+}
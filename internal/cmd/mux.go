@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/ptyserver"
+)
+
+// muxCmd groups the commands of the headjack-native multiplexer. They are
+// invoked by the native multiplexer backend rather than by users directly.
+var muxCmd = &cobra.Command{
+	Use:    "mux",
+	Short:  "Manage headjack-native terminal sessions",
+	Hidden: true,
+	// Sessions are managed without the instance manager or a container runtime.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var muxNewCmd = &cobra.Command{
+	Use:   "new <name> [-- command...]",
+	Short: "Start a detached session",
+	Long: `Start a detached session running command on a new pseudo-terminal.
+
+The session is served by a background headjack process that records terminal
+output to --log and accepts clients until the command exits.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ptyserver.ValidateName(args[0]); err != nil {
+			return err
+		}
+		binary, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("locate headjack executable: %w", err)
+		}
+
+		serveArgs, err := muxServeArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		return ptyserver.Start(binary, serveArgs)
+	},
+}
+
+var muxServeCmd = &cobra.Command{
+	Use:    "serve <name> [-- command...]",
+	Short:  "Serve a session in the foreground",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		notifier := ptyserver.TakeNotifier()
+
+		cfg, err := muxSessionConfig(cmd, args)
+		if err != nil {
+			ptyserver.Notify(notifier, err)
+			return err
+		}
+		server, err := ptyserver.Listen(*cfg)
+		ptyserver.Notify(notifier, err)
+		if err != nil {
+			return err
+		}
+
+		server.Run()
+		return nil
+	},
+}
+
+var muxAttachCmd = &cobra.Command{
+	Use:   "attach <name>",
+	Short: "Attach to a session",
+	Long: `Attach to a session. Type the detach key sequence (by default ctrl-p
+followed by ctrl-q) to detach and leave the session running.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMuxAttachCmd,
+}
+
+var muxLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List running sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := ptyserver.List(ptyserver.DefaultDir())
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var muxKillCmd = &cobra.Command{
+	Use:   "kill <name>",
+	Short: "Terminate a session",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ptyserver.Kill(ptyserver.DefaultDir(), args[0])
+	},
+}
+
+var muxSendCmd = &cobra.Command{
+	Use:   "send <name> [--] <text>",
+	Short: "Type text into a session",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enter, err := cmd.Flags().GetBool("enter")
+		if err != nil {
+			return fmt.Errorf("get enter flag: %w", err)
+		}
+
+		data := []byte(args[1])
+		if enter {
+			data = append(data, '\r')
+		}
+		return ptyserver.Send(ptyserver.DefaultDir(), args[0], data)
+	},
+}
+
+func runMuxAttachCmd(cmd *cobra.Command, args []string) error {
+	detachFlag, err := cmd.Flags().GetString("detach-keys")
+	if err != nil {
+		return fmt.Errorf("get detach-keys flag: %w", err)
+	}
+	detachKeys, err := ptyserver.ParseDetachKeys(detachFlag)
+	if err != nil {
+		return err
+	}
+
+	opts := ptyserver.AttachOptions{
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		DetachKeys: detachKeys,
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	if term.IsTerminal(stdinFd) {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return fmt.Errorf("set terminal raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(stdinFd, oldState) }()
+
+		stdoutFd := int(os.Stdout.Fd())
+		opts.Size = func() (uint16, uint16, bool) {
+			cols, rows, err := term.GetSize(stdoutFd)
+			if err != nil {
+				return 0, 0, false
+			}
+			return uint16(rows), uint16(cols), true //nolint:gosec // terminal dimensions fit in uint16
+		}
+
+		resize := make(chan os.Signal, 1)
+		signal.Notify(resize, syscall.SIGWINCH)
+		defer signal.Stop(resize)
+		opts.Resize = resize
+	}
+
+	result, err := ptyserver.Attach(ptyserver.DefaultDir(), args[0], opts)
+	if err != nil {
+		return err
+	}
+	if result.Detached {
+		fmt.Printf("\r\n[detached from session %s]\r\n", args[0])
+	}
+	return nil
+}
+
+// muxServeArgs builds the arguments for the serve command from the flags and
+// arguments of the new command.
+func muxServeArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	cfg, err := muxSessionConfig(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+
+	serveArgs := []string{"mux", "serve"}
+	if cfg.Cwd != "" {
+		serveArgs = append(serveArgs, "--cwd", cfg.Cwd)
+	}
+	for _, env := range cfg.Env {
+		serveArgs = append(serveArgs, "--env", env)
+	}
+	if cfg.LogPath != "" {
+		serveArgs = append(serveArgs, "--log", cfg.LogPath)
+		serveArgs = append(serveArgs, logArgs(cfg.LogRotation, cfg.LogTranscript, "log-")...)
+	}
+	serveArgs = append(serveArgs, "--", cfg.Name)
+	return append(serveArgs, cfg.Command...), nil
+}
+
+// muxSessionConfig builds a session config from the flags and arguments of
+// the new and serve commands.
+func muxSessionConfig(cmd *cobra.Command, args []string) (*ptyserver.Config, error) {
+	cwd, err := cmd.Flags().GetString("cwd")
+	if err != nil {
+		return nil, fmt.Errorf("get cwd flag: %w", err)
+	}
+	env, err := cmd.Flags().GetStringArray("env")
+	if err != nil {
+		return nil, fmt.Errorf("get env flag: %w", err)
+	}
+	logPath, err := cmd.Flags().GetString("log")
+	if err != nil {
+		return nil, fmt.Errorf("get log flag: %w", err)
+	}
+	rotation, transcript, err := logConfigFromFlags(cmd, "log-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ptyserver.Config{
+		Dir:           ptyserver.DefaultDir(),
+		Name:          args[0],
+		Command:       args[1:],
+		Cwd:           cwd,
+		Env:           env,
+		LogPath:       logPath,
+		LogRotation:   rotation,
+		LogTranscript: transcript,
+	}, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{muxNewCmd, muxServeCmd} {
+		c.Flags().String("cwd", "", "working directory for the command")
+		c.Flags().StringArrayP("env", "e", nil, "environment variable for the command (KEY=VALUE, repeatable)")
+		c.Flags().String("log", "", "file to append terminal output to")
+		addLogFlags(c, "log-")
+	}
+	muxAttachCmd.Flags().String("detach-keys", ptyserver.DefaultDetachKeys, "key sequence for detaching from the session")
+	muxSendCmd.Flags().Bool("enter", false, "press Enter after the text")
+
+	muxCmd.AddCommand(muxNewCmd, muxServeCmd, muxAttachCmd, muxLsCmd, muxKillCmd, muxSendCmd)
+	rootCmd.AddCommand(muxCmd)
+}
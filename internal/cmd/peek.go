@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/multiplexer"
+)
+
+// Default refresh interval for watching a session.
+const defaultPeekInterval = time.Second
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+var peekCmd = &cobra.Command{
+	Use:   "peek <branch> [session]",
+	Short: "Show the current screen of a session",
+	Long: `Show the current screen of a session without attaching.
+
+Unlike 'hjk logs', which replays the raw terminal output including every
+redraw, peek prints the screen as the multiplexer currently renders it. This
+makes it the easiest way to check on full-screen agents from a script or a
+second terminal.
+
+If no session is given, the most recently accessed session for the branch is
+used. Colors are kept unless --plain is given. With --watch, the screen is
+redrawn every --interval until interrupted.
+
+Not supported by the native multiplexer; use 'hjk logs' instead.`,
+	Example: `  # Show the current screen of the most recent session
+  hjk peek feat/auth
+
+  # Include 200 lines of scrollback, without colors
+  hjk peek feat/auth claude-main -s 200 --plain
+
+  # Keep the screen refreshed
+  hjk peek feat/auth claude-main --watch`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPeekCmd,
+}
+
+func runPeekCmd(cmd *cobra.Command, args []string) error {
+	plain, err := cmd.Flags().GetBool("plain")
+	if err != nil {
+		return fmt.Errorf("get plain flag: %w", err)
+	}
+	scrollback, err := cmd.Flags().GetInt("scrollback")
+	if err != nil {
+		return fmt.Errorf("get scrollback flag: %w", err)
+	}
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return fmt.Errorf("get watch flag: %w", err)
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return fmt.Errorf("get interval flag: %w", err)
+	}
+	if interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	branch := args[0]
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch)
+	if err != nil {
+		return err
+	}
+
+	var sessionName string
+	if len(args) == 2 {
+		sessionName = args[1]
+	} else {
+		session, err := mgr.GetMRUSession(cmd.Context(), inst.ID)
+		if err != nil {
+			if errors.Is(err, instance.ErrNoSessionsAvailable) {
+				return fmt.Errorf("no sessions exist for branch %q (use 'hjk run' to create one)", branch)
+			}
+			return fmt.Errorf("get MRU session: %w", err)
+		}
+		sessionName = session.Name
+	}
+
+	opts := &multiplexer.CaptureOpts{Scrollback: scrollback, Escapes: !plain}
+	capture := func(ctx context.Context) (string, error) {
+		screen, err := mgr.CapturePane(ctx, inst.ID, sessionName, opts)
+		if err != nil {
+			switch {
+			case errors.Is(err, instance.ErrSessionNotFound):
+				return "", fmt.Errorf("session %q not found in instance for branch %q", sessionName, branch)
+			case errors.Is(err, multiplexer.ErrSessionNotFound):
+				return "", fmt.Errorf("session %q is no longer running", sessionName)
+			case errors.Is(err, multiplexer.ErrCaptureNotSupported):
+				return "", fmt.Errorf("%w (use 'hjk logs %s %s' instead)", err, branch, sessionName)
+			}
+			return "", err
+		}
+		return screen, nil
+	}
+
+	if !watch {
+		screen, err := capture(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Print(screen)
+		return nil
+	}
+
+	return watchScreen(cmd.Context(), capture, interval)
+}
+
+// watchScreen redraws the captured screen every interval until interrupted.
+func watchScreen(ctx context.Context, capture func(context.Context) (string, error), interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		screen, err := capture(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fmt.Print(clearScreen + screen)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	peekCmd.Flags().Bool("plain", false, "strip colors and other escape sequences")
+	peekCmd.Flags().IntP("scrollback", "s", 0, "lines of scrollback to include above the screen (-1 for all)")
+	peekCmd.Flags().BoolP("watch", "w", false, "redraw the screen until interrupted")
+	peekCmd.Flags().Duration("interval", defaultPeekInterval, "refresh interval for --watch")
+
+	rootCmd.AddCommand(peekCmd)
+}
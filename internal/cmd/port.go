@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/portfwd"
+)
+
+var portCmd = &cobra.Command{
+	Use:   "port <branch> [container-port]",
+	Short: "Forward ports of an instance to the host",
+	Long: `Forward a port inside an instance to a port on the host, so that dev servers
+started by agents can be opened in a browser.
+
+Without a container port, lists the ports that processes in the container
+listen on, and the URLs of forwarded ports.
+
+Forwarding runs a background headjack proxy on the host that relays each
+connection through the container runtime's exec command, so the container
+needs no published ports. The container must have socat, nc or bash.
+
+Each forwarded port gets a stable host port: the container port if it is
+free, or another free port. The assignment is kept in the catalog, so the URL
+stays the same when forwarding resumes after the instance is stopped.`,
+	Example: `  # List listening and forwarded ports
+  hjk port feat/auth
+
+  # Forward port 3000 and print its URL
+  hjk port feat/auth 3000
+
+  # Stop forwarding port 3000
+  hjk port feat/auth 3000 --stop`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runPortCmd,
+}
+
+// portProxyCmd serves a forwarded port. It is started in the background by
+// the instance manager rather than by users directly.
+var portProxyCmd = &cobra.Command{
+	Use:    "port-proxy <container-id> <port> -- <exec command...>",
+	Short:  "Relay connections on an inherited listener to a container port",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(3),
+	// Runs in the background without the instance manager or a container runtime.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, err := parsePort(args[1])
+		if err != nil {
+			return err
+		}
+		ln, err := portfwd.InheritedListener()
+		if err != nil {
+			return err
+		}
+
+		proxy := &portfwd.Proxy{ExecCommand: args[2:], ContainerID: args[0], Port: port}
+		return proxy.Serve(ln)
+	},
+}
+
+func runPortCmd(cmd *cobra.Command, args []string) error {
+	stop, err := cmd.Flags().GetBool("stop")
+	if err != nil {
+		return fmt.Errorf("get stop flag: %w", err)
+	}
+	if stop && len(args) < 2 {
+		return errors.New("--stop requires a container port")
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if stop {
+		return stopPortForward(cmd, mgr, args[0], args[1])
+	}
+
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		return listPorts(cmd, mgr, inst)
+	}
+
+	port, err := parsePort(args[1])
+	if err != nil {
+		return err
+	}
+	fwd, err := mgr.ForwardPort(cmd.Context(), inst.ID, port)
+	if err != nil {
+		return fmt.Errorf("forward port: %w", err)
+	}
+
+	fmt.Printf("Forwarding port %d of %s to %s\n", port, inst.Branch, fwd.URL())
+	return nil
+}
+
+// stopPortForward stops forwarding a port without restarting a stopped
+// instance.
+func stopPortForward(cmd *cobra.Command, mgr *instance.Manager, branch, portArg string) error {
+	port, err := parsePort(portArg)
+	if err != nil {
+		return err
+	}
+	path, err := repoPath()
+	if err != nil {
+		return err
+	}
+
+	inst, err := mgr.GetByBranch(cmd.Context(), path, branch)
+	if err != nil {
+		if errors.Is(err, instance.ErrNotFound) {
+			return fmt.Errorf("no instance found for branch %q", branch)
+		}
+		return fmt.Errorf("get instance: %w", err)
+	}
+	if err := mgr.StopForward(cmd.Context(), inst.ID, port); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stopped forwarding port %d of %s\n", port, inst.Branch)
+	return nil
+}
+
+// listPorts prints the listening ports of an instance and its forwards.
+func listPorts(cmd *cobra.Command, mgr *instance.Manager, inst *instance.Instance) error {
+	listening, err := mgr.ListeningPorts(cmd.Context(), inst.ID)
+	if err != nil {
+		return err
+	}
+
+	// Forwarded ports are listed even when nothing listens on them yet
+	ports := slices.Clone(listening)
+	forwards := make(map[int]instance.PortForward, len(inst.Ports))
+	for _, fwd := range inst.Ports {
+		forwards[fwd.ContainerPort] = fwd
+		ports = append(ports, fwd.ContainerPort)
+	}
+	slices.Sort(ports)
+	ports = slices.Compact(ports)
+
+	if len(ports) == 0 {
+		fmt.Println("No listening ports found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "PORT\tLISTENING\tURL"); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+	for _, port := range ports {
+		url := "-"
+		if fwd, ok := forwards[port]; ok {
+			url = fwd.URL()
+			if !fwd.Active {
+				url += " (stopped)"
+			}
+		}
+		listen := "no"
+		if slices.Contains(listening, port) {
+			listen = "yes"
+		}
+		if _, err := fmt.Fprintf(w, "%d\t%s\t%s\n", port, listen, url); err != nil {
+			return fmt.Errorf("write port: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush output: %w", err)
+	}
+
+	return nil
+}
+
+// parsePort parses a TCP port number.
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q: must be a number from 1 to 65535", s)
+	}
+	return port, nil
+}
+
+func init() {
+	rootCmd.AddCommand(portCmd)
+	rootCmd.AddCommand(portProxyCmd)
+
+	portCmd.Flags().Bool("stop", false, "stop forwarding the container port")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -23,13 +24,18 @@ By default, lists instances for the current repository.
 If a branch is specified, lists sessions for that instance instead.
 
 Use --all to list instances across all repositories (only applies when
-listing instances, not sessions).`,
+listing instances, not sessions).
+
+Use --output wide to also show the forwarded ports of each instance.`,
 	Example: `  # List instances for current repo
   headjack ps
 
   # List all instances across all repos
   headjack ps --all
 
+  # Include forwarded ports
+  headjack ps -o wide
+
   # List sessions for a specific instance
   headjack ps feat/auth`,
 	Args: cobra.MaximumNArgs(1),
@@ -51,6 +57,18 @@ func listInstances(cmd *cobra.Command) error {
 	if err != nil {
 		return fmt.Errorf("get all flag: %w", err)
 	}
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("get output flag: %w", err)
+	}
+	var wide bool
+	switch output {
+	case "":
+	case "wide":
+		wide = true
+	default:
+		return fmt.Errorf("invalid output format %q: must be wide", output)
+	}
 
 	mgr, err := requireManager(cmd.Context())
 	if err != nil {
@@ -86,7 +104,11 @@ func listInstances(cmd *cobra.Command) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	if _, err := fmt.Fprintln(w, "BRANCH\tSTATUS\tSESSIONS\tCREATED"); err != nil {
+	header := "BRANCH\tSTATUS\tSESSIONS\tCREATED"
+	if wide {
+		header += "\tPORTS"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
 		return fmt.Errorf("write header: %w", err)
 	}
 	for i := range instances {
@@ -96,12 +118,16 @@ func listInstances(cmd *cobra.Command) error {
 			// Best effort - show 0 if we can't get the count
 			sessionCount = 0
 		}
-		if _, err := fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
+		row := fmt.Sprintf("%s\t%s\t%d\t%s",
 			inst.Branch,
 			inst.Status,
 			sessionCount,
 			formatTimeAgo(inst.CreatedAt),
-		); err != nil {
+		)
+		if wide {
+			row += "\t" + formatPorts(inst.Ports)
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
 			return fmt.Errorf("write instance: %w", err)
 		}
 	}
@@ -165,6 +191,21 @@ func getSessionCount(cmd *cobra.Command, mgr *instance.Manager, instanceID strin
 	return len(sessions), nil
 }
 
+// formatPorts formats the active port forwards of an instance as
+// localhost:<host port>-><container port>.
+func formatPorts(ports []instance.PortForward) string {
+	var parts []string
+	for _, p := range ports {
+		if p.Active {
+			parts = append(parts, fmt.Sprintf("localhost:%d->%d", p.HostPort, p.ContainerPort))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
 // formatTimeAgo formats a time as a human-readable relative time.
 func formatTimeAgo(t time.Time) string {
 	d := time.Since(t)
@@ -209,4 +250,5 @@ func init() {
 	rootCmd.AddCommand(psCmd)
 
 	psCmd.Flags().BoolP("all", "a", false, "list instances across all repositories")
+	psCmd.Flags().StringP("output", "o", "", "output format (wide)")
 }
@@ -4,11 +4,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -29,9 +31,18 @@ var baseDeps = []string{"git"}
 // runtimeNameDocker is the runtime name for Docker.
 const runtimeNameDocker = "docker"
 
+// runtimeNameNerdctl is the runtime name for nerdctl (containerd).
+const runtimeNameNerdctl = "nerdctl"
+
 // runtimeBinaryDocker is the binary name for Docker.
 const runtimeBinaryDocker = "docker"
 
+// multiplexerNameZellij is the multiplexer name for Zellij.
+const multiplexerNameZellij = "zellij"
+
+// multiplexerNameNative is the multiplexer name for the headjack-native session server.
+const multiplexerNameNative = "native"
+
 // mgr is the instance manager, initialized in PersistentPreRunE.
 var mgr *instance.Manager
 
@@ -89,7 +100,7 @@ func init() {
 }
 
 func initConfig() {
-	loader, err := config.NewLoader()
+	loader, err := newConfigLoader(context.Background())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to initialize config: %v\n", err)
 		return
@@ -135,7 +146,7 @@ func checkDependencies() error {
 // getRuntimeBinary returns the binary name for the configured runtime.
 func getRuntimeBinary() string {
 	if appConfig != nil && appConfig.Runtime.Name != "" {
-		return appConfig.Runtime.Name // Runtime name matches binary name (docker, podman)
+		return appConfig.Runtime.Name // Runtime name matches binary name (docker, podman, nerdctl)
 	}
 	// Default to docker
 	return runtimeBinaryDocker
@@ -167,22 +178,21 @@ func initManager() error {
 	store := catalog.NewStore(catalogPath)
 
 	// Select runtime: config > default (docker)
-	var runtime container.Runtime
 	runtimeName := runtimeNameDocker // default
 	if appConfig != nil && appConfig.Runtime.Name != "" {
 		runtimeName = appConfig.Runtime.Name
 	}
-	switch runtimeName {
-	case runtimeNameDocker:
-		runtime = container.NewDockerRuntime(executor, container.DockerConfig{})
-	default:
-		runtime = container.NewPodmanRuntime(executor, container.PodmanConfig{})
+	runtime, err := newRuntime(executor, runtimeName)
+	if err != nil {
+		return err
 	}
 
 	opener := git.NewOpener(executor)
 
-	// Use tmux as the terminal multiplexer
-	mux := multiplexer.NewTmux(executor)
+	mux, err := newMultiplexer(executor)
+	if err != nil {
+		return err
+	}
 
 	// Map runtime name to RuntimeType
 	runtimeType := runtimeNameToType(runtimeName)
@@ -193,16 +203,54 @@ func initManager() error {
 		RuntimeType:  runtimeType,
 		ConfigFlags:  getConfigFlags(),
 		Executor:     executor,
+		MuxMode:      getMuxMode(),
+		LogSink:      getLogSink(),
+		MapUser:      appConfig != nil && appConfig.Runtime.MapUser,
+		Caches:       getCaches(),
+		Mounts:       getMounts(),
+		Dotfiles:     getDotfiles(),
+		ProxyCommand: getProxyCommand(),
+		PortsDir:     filepath.Join(filepath.Dir(catalogPath), "ports"),
 	})
 
 	return nil
 }
 
+// newRuntime creates the container runtime with the given name, using the
+// engine API instead of the CLI if runtime.api is set.
+func newRuntime(executor hjexec.Executor, runtimeName string) (container.Runtime, error) {
+	if appConfig != nil && appConfig.Runtime.API {
+		if runtimeName == runtimeNameNerdctl {
+			return nil, errors.New("runtime.api is not supported with nerdctl, which has no engine API")
+		}
+		host := appConfig.Runtime.Socket
+		if host == "" {
+			host = container.DefaultAPIHost(runtimeName)
+		}
+		runtime, err := container.NewAPIRuntime(container.APIConfig{Host: host, Binary: runtimeName})
+		if err != nil {
+			return nil, fmt.Errorf("create API runtime: %w", err)
+		}
+		return runtime, nil
+	}
+
+	switch runtimeName {
+	case runtimeNameDocker:
+		return container.NewDockerRuntime(executor, container.DockerConfig{}), nil
+	case runtimeNameNerdctl:
+		return container.NewNerdctlRuntime(executor, container.NerdctlConfig{}), nil
+	default:
+		return container.NewPodmanRuntime(executor, container.PodmanConfig{}), nil
+	}
+}
+
 // runtimeNameToType converts a runtime name string to RuntimeType.
 func runtimeNameToType(name string) instance.RuntimeType {
 	switch name {
 	case runtimeNameDocker:
 		return instance.RuntimeDocker
+	case runtimeNameNerdctl:
+		return instance.RuntimeNerdctl
 	default:
 		return instance.RuntimePodman
 	}
@@ -239,3 +287,90 @@ func formatList(items []string) string {
 		return builder.String()
 	}
 }
+
+// getMuxMode returns where new instances run their multiplexer.
+func getMuxMode() catalog.MuxMode {
+	if appConfig != nil && appConfig.Multiplexer.Mode != "" {
+		return catalog.MuxMode(appConfig.Multiplexer.Mode)
+	}
+	return catalog.MuxModeHost
+}
+
+// getCaches returns the configured caches, sorted by name so that containers
+// mount them in a stable order.
+func getCaches() []instance.Cache {
+	if appConfig == nil {
+		return nil
+	}
+	caches := make([]instance.Cache, 0, len(appConfig.Cache))
+	for name, target := range appConfig.Cache {
+		caches = append(caches, instance.Cache{Name: name, Target: target})
+	}
+	slices.SortFunc(caches, func(a, b instance.Cache) int { return strings.Compare(a.Name, b.Name) })
+	return caches
+}
+
+// getMounts returns the configured host paths to mount into new instances.
+func getMounts() []container.Mount {
+	if appConfig == nil {
+		return nil
+	}
+	mounts := make([]container.Mount, 0, len(appConfig.Mounts))
+	for _, m := range appConfig.Mounts {
+		mounts = append(mounts, container.Mount{Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly})
+	}
+	return mounts
+}
+
+// getDotfiles returns the configured dotfiles, or nil if none are configured.
+func getDotfiles() *instance.Dotfiles {
+	if appConfig == nil || appConfig.Dotfiles.Source == "" {
+		return nil
+	}
+	return &instance.Dotfiles{Source: appConfig.Dotfiles.Source, Install: appConfig.Dotfiles.Install}
+}
+
+// getLogSink returns the command that records host tmux and zellij session
+// logs with rotation. Without it, output is appended to the log unchanged.
+func getLogSink() []string {
+	binary, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	return logSinkCommand(binary, getLogRotation(), getLogTranscript())
+}
+
+// getProxyCommand returns the command that serves forwarded ports.
+func getProxyCommand() []string {
+	binary, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	return []string{binary, "port-proxy"}
+}
+
+// newMultiplexer creates the configured terminal multiplexer: config > default (tmux).
+func newMultiplexer(executor hjexec.Executor) (multiplexer.Multiplexer, error) {
+	var muxCfg config.MultiplexerConfig
+	if appConfig != nil {
+		muxCfg = appConfig.Multiplexer
+	}
+
+	switch muxCfg.Name {
+	case multiplexerNameZellij:
+		return multiplexer.NewZellij(executor), nil
+	case multiplexerNameNative:
+		binary, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("locate headjack executable: %w", err)
+		}
+		return multiplexer.NewNative(executor, multiplexer.NativeConfig{
+			Binary:        binary,
+			DetachKeys:    muxCfg.DetachKeys,
+			LogRotation:   getLogRotation(),
+			LogTranscript: getLogTranscript(),
+		}), nil
+	default:
+		return multiplexer.NewTmux(executor), nil
+	}
+}
@@ -7,10 +7,11 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
-	"github.com/jmgilman/headjack/internal/container"
 	"github.com/jmgilman/headjack/internal/devcontainer"
 	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/progress"
 	"github.com/jmgilman/headjack/internal/prompt"
 	"github.com/jmgilman/headjack/internal/slogger"
 	"github.com/jmgilman/headjack/internal/spinner"
@@ -32,6 +33,11 @@ stopped). The container environment is determined by:
 Additional flags can be passed to the container runtime (or devcontainer CLI)
 by placing them after a -- separator.
 
+Progress of image pulls, builds and devcontainer setup is shown on stderr.
+Use --progress to choose how: "tty" draws a live display (the default on a
+terminal), "plain" prints one line per event (the default otherwise and with
+-v), and "json" prints one JSON object per event.
+
 This command only creates the instance. To start a session, use:
   - 'hjk agent <branch> <agent>' to start an agent session
   - 'hjk exec <branch>' to start a shell session`,
@@ -44,6 +50,9 @@ This command only creates the instance. To start a session, use:
   # Pass additional flags to the container runtime
   hjk run feat/auth -- --memory=4g --privileged
 
+  # Emit progress as JSON lines for other tools
+  hjk run feat/auth --progress=json 2> progress.jsonl
+
   # Typical workflow: create instance, then start agent
   hjk run feat/auth
   hjk agent feat/auth claude --prompt "Implement JWT authentication"`,
@@ -56,8 +65,16 @@ type runFlags struct {
 	image         string
 	imageExplicit bool     // true if --image was explicitly passed
 	runtimeFlags  []string // flags to pass to the container runtime (after --)
+	progress      string   // progress output mode (plain, tty, json)
 }
 
+// Progress output modes for the --progress flag.
+const (
+	progressPlain = "plain"
+	progressTTY   = "tty"
+	progressJSON  = "json"
+)
+
 // parseRunFlags extracts and validates flags from the command.
 func parseRunFlags(cmd *cobra.Command, args []string) (*runFlags, error) {
 	image, err := cmd.Flags().GetString("image")
@@ -68,13 +85,40 @@ func parseRunFlags(cmd *cobra.Command, args []string) (*runFlags, error) {
 
 	image = resolveBaseImage(cmd.Context(), image)
 
+	mode, err := cmd.Flags().GetString("progress")
+	if err != nil {
+		return nil, fmt.Errorf("get progress flag: %w", err)
+	}
+	if !cmd.Flags().Changed("progress") {
+		mode = defaultProgressMode(cmd)
+	}
+	switch mode {
+	case progressPlain, progressTTY, progressJSON:
+	default:
+		return nil, fmt.Errorf("invalid progress mode %q (valid: plain, tty, json)", mode)
+	}
+
 	return &runFlags{
 		image:         image,
 		imageExplicit: imageExplicit,
 		runtimeFlags:  parsePassthroughArgs(cmd, args),
+		progress:      mode,
 	}, nil
 }
 
+// defaultProgressMode returns tty when stderr is a terminal, and plain when
+// it is not or when verbose output (-v) is requested.
+func defaultProgressMode(cmd *cobra.Command) string {
+	// Info level is enabled when -v is passed (verbosity >= 1)
+	if slogger.L(cmd.Context()).Enabled(cmd.Context(), slog.LevelInfo) {
+		return progressPlain
+	}
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return progressPlain
+	}
+	return progressTTY
+}
+
 func runRunCmd(cmd *cobra.Command, args []string) error {
 	branch := args[0]
 
@@ -135,42 +179,49 @@ func getOrCreateInstance(cmd *cobra.Command, mgr *instance.Manager, repoPath, br
 		return nil, err
 	}
 
-	// Determine output mode from verbosity
-	// Info level is enabled when -v is passed (verbosity >= 1)
-	verbose := log.Enabled(cmd.Context(), slog.LevelInfo)
+	inst, err = createWithProgress(cmd, mgr, repoPath, &createCfg, flags.progress)
+	if err != nil {
+		return nil, fmt.Errorf("create instance: %w", err)
+	}
 
-	if verbose {
-		// Verbose mode: stream CLI output directly to stderr
-		createCfg.Stderr = os.Stderr
-		inst, err = mgr.Create(cmd.Context(), repoPath, &createCfg)
-		if err != nil {
-			return nil, fmt.Errorf("create instance: %w", err)
-		}
-	} else {
-		// Default mode: show ticker spinner with progress from CLI
-		spin := spinner.New(os.Stderr)
-		createCfg.Stderr = spin.Writer()
+	log.Debug("created new instance", slog.String("id", inst.ID), slog.String("branch", inst.Branch))
+	return inst, nil
+}
 
-		// Run creation in a goroutine while spinner displays
+// createWithProgress creates an instance, reporting progress parsed from the
+// runtime's output on stderr in the given mode.
+func createWithProgress(cmd *cobra.Command, mgr *instance.Manager, repoPath string, cfg *instance.CreateConfig, mode string) (*instance.Instance, error) {
+	switch mode {
+	case progressTTY:
+		display := spinner.NewProgress(os.Stderr)
+		out := progress.NewWriter(display.Handle)
+		cfg.Stderr = out
+
+		// Run creation in a goroutine while the display runs
+		var inst *instance.Instance
 		var createErr error
 		go func() {
-			inst, createErr = mgr.Create(cmd.Context(), repoPath, &createCfg)
-			spin.Stop()
+			inst, createErr = mgr.Create(cmd.Context(), repoPath, cfg)
+			_ = out.Close() //nolint:errcheck // Close never fails
+			display.Stop()
 		}()
 
 		// Start blocks until Stop() is called
-		if spinErr := spin.Start(); spinErr != nil {
-			// Spinner error is non-fatal, continue if we got an instance
-			log.Debug("spinner error", slog.String("error", spinErr.Error()))
-		}
-
-		if createErr != nil {
-			return nil, fmt.Errorf("create instance: %w", createErr)
+		if displayErr := display.Start(); displayErr != nil {
+			// Display error is non-fatal, continue if we got an instance
+			slogger.L(cmd.Context()).Debug("progress display error", slog.String("error", displayErr.Error()))
 		}
+		return inst, createErr
+	case progressJSON:
+		out := progress.NewWriter(progress.JSONHandler(os.Stderr))
+		defer out.Close() //nolint:errcheck // Close never fails
+		cfg.Stderr = out
+	default:
+		out := progress.NewWriter(progress.PlainHandler(os.Stderr))
+		defer out.Close() //nolint:errcheck // Close never fails
+		cfg.Stderr = out
 	}
-
-	log.Debug("created new instance", slog.String("id", inst.ID), slog.String("branch", inst.Branch))
-	return inst, nil
+	return mgr.Create(cmd.Context(), repoPath, cfg)
 }
 
 // buildCreateConfig builds the instance creation config, detecting devcontainer mode if applicable.
@@ -196,38 +247,17 @@ func buildCreateConfig(cmd *cobra.Command, repoPath, branch string, flags *runFl
 	hasDevcontainer := devcontainer.HasConfig(repoPath)
 
 	if hasDevcontainer {
-		// Resolve devcontainer CLI (may prompt for installation)
-		mgr := ManagerFromContext(cmd.Context())
-		if mgr == nil {
-			return cfg, errors.New("manager not available")
-		}
-
-		loader := LoaderFromContext(cmd.Context())
-		if loader == nil {
-			return cfg, errors.New("config loader not available")
-		}
-
-		resolver := devcontainer.NewCLIResolver(loader, prompt.New(), mgr.Executor())
-		cliPath, err := resolver.Resolve(cmd.Context())
+		dcRuntime, err := newDevcontainerRuntime(cmd)
 		if err != nil {
 			return cfg, err
 		}
 
-		// Create devcontainer runtime wrapping the underlying runtime
-		runtimeName := runtimeNameDocker
-		if appCfg := ConfigFromContext(cmd.Context()); appCfg != nil && appCfg.Runtime.Name != "" {
-			runtimeName = appCfg.Runtime.Name
-		}
-		dcRuntime := createDevcontainerRuntime(cmd, runtimeName, cliPath)
-		if dcRuntime == nil {
-			return cfg, errors.New("failed to create devcontainer runtime")
-		}
-
 		slogger.L(cmd.Context()).Debug("detected devcontainer.json, using devcontainer mode")
 
 		cfg.WorkspaceFolder = repoPath
 		cfg.Runtime = dcRuntime
-		cfg.Image = "" // Not needed in devcontainer mode
+		cfg.Image = prebuiltImage(cmd.Context(), repoPath) // Empty builds the image
+		cfg.Home = instance.HomeFor(devcontainer.RemoteUser(repoPath))
 
 		return cfg, nil
 	}
@@ -240,8 +270,40 @@ func buildCreateConfig(cmd *cobra.Command, repoPath, branch string, flags *runFl
 	return cfg, nil
 }
 
+// newDevcontainerRuntime resolves the devcontainer CLI, which may prompt for
+// installation, and creates a devcontainer runtime for the configured
+// container runtime.
+func newDevcontainerRuntime(cmd *cobra.Command) (*devcontainer.Runtime, error) {
+	mgr := ManagerFromContext(cmd.Context())
+	if mgr == nil {
+		return nil, errors.New("manager not available")
+	}
+
+	loader := LoaderFromContext(cmd.Context())
+	if loader == nil {
+		return nil, errors.New("config loader not available")
+	}
+
+	resolver := devcontainer.NewCLIResolver(loader, prompt.New(), mgr.Executor())
+	cliPath, err := resolver.Resolve(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// Create devcontainer runtime wrapping the underlying runtime
+	runtimeName := runtimeNameDocker
+	if appCfg := ConfigFromContext(cmd.Context()); appCfg != nil && appCfg.Runtime.Name != "" {
+		runtimeName = appCfg.Runtime.Name
+	}
+	dcRuntime := createDevcontainerRuntime(cmd, runtimeName, cliPath)
+	if dcRuntime == nil {
+		return nil, errors.New("failed to create devcontainer runtime")
+	}
+	return dcRuntime, nil
+}
+
 // createDevcontainerRuntime creates a DevcontainerRuntime wrapping the appropriate underlying runtime.
-func createDevcontainerRuntime(cmd *cobra.Command, runtimeName, cliPath string) container.Runtime {
+func createDevcontainerRuntime(cmd *cobra.Command, runtimeName, cliPath string) *devcontainer.Runtime {
 	// Get the underlying runtime from the manager
 	mgr := ManagerFromContext(cmd.Context())
 	if mgr == nil {
@@ -253,6 +315,8 @@ func createDevcontainerRuntime(cmd *cobra.Command, runtimeName, cliPath string)
 	switch runtimeName {
 	case runtimeNameDocker:
 		dockerPath = "docker"
+	case runtimeNameNerdctl:
+		dockerPath = "nerdctl"
 	default:
 		dockerPath = "podman"
 	}
@@ -271,4 +335,5 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 
 	runCmd.Flags().String("image", "", "use a container image instead of devcontainer")
+	runCmd.Flags().String("progress", "", "progress output: plain, tty, or json (default tty on a terminal, else plain)")
 }
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/git"
+	"github.com/jmgilman/headjack/internal/prompt"
+	"github.com/jmgilman/headjack/internal/secret"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage project secrets",
+	Long: `Manage project secrets stored in the system keychain.
+
+Secrets are scoped to the current repository. Reference them from agent
+environment configuration or 'hjk exec --env' using the secret:<name> syntax;
+references are resolved when a session is created.`,
+	Example: `  # Store a secret (prompts for the value)
+  hjk secret set npm
+
+  # Store a secret from stdin
+  echo "$NPM_TOKEN" | hjk secret set npm --stdin
+
+  # Inject the secret into a session
+  hjk exec feat/auth --env NPM_TOKEN=secret:npm npm test
+
+  # List secrets for this repository
+  hjk secret ls`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Store a secret",
+	Long: `Store a secret for the current repository, replacing any existing value.
+
+The value is taken from the argument, from standard input with --stdin, or
+prompted for interactively. Prefer --stdin or the prompt to keep the value out
+of shell history.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretSet,
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a secret value",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretGet,
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretRm,
+}
+
+var secretLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List secrets for the current repository",
+	Args:  cobra.NoArgs,
+	RunE:  runSecretLs,
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretRmCmd)
+	secretCmd.AddCommand(secretLsCmd)
+
+	secretSetCmd.Flags().Bool("stdin", false, "read the secret value from standard input")
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := secret.ValidateName(name); err != nil {
+		return err
+	}
+
+	useStdin, err := cmd.Flags().GetBool("stdin")
+	if err != nil {
+		return fmt.Errorf("get stdin flag: %w", err)
+	}
+	if useStdin && len(args) == 2 {
+		return errors.New("cannot use --stdin with a value argument")
+	}
+
+	var value string
+	switch {
+	case len(args) == 2:
+		value = args[1]
+	case useStdin:
+		data, readErr := io.ReadAll(os.Stdin)
+		if readErr != nil {
+			return fmt.Errorf("read secret from stdin: %w", readErr)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	default:
+		value, err = prompt.New().Secret(fmt.Sprintf("Value for %s: ", name))
+		if err != nil {
+			return fmt.Errorf("read secret: %w", err)
+		}
+	}
+	if value == "" {
+		return errors.New("secret value is empty")
+	}
+
+	store, err := openSecretStore(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := store.Set(name, value); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stored secret %s\n", name)
+	return nil
+}
+
+func runSecretGet(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore(cmd.Context())
+	if err != nil {
+		return err
+	}
+	value, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runSecretRm(cmd *cobra.Command, args []string) error {
+	store, err := openSecretStore(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Removed secret %s\n", args[0])
+	return nil
+}
+
+func runSecretLs(cmd *cobra.Command, _ []string) error {
+	store, err := openSecretStore(cmd.Context())
+	if err != nil {
+		return err
+	}
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No secrets found")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// openSecretStore opens the secret store scoped to the repository in the working directory.
+func openSecretStore(ctx context.Context) (*secret.Store, error) {
+	repoPathValue, err := repoPath()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.NewOpener(exec.New()).Open(ctx, repoPathValue)
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %w", err)
+	}
+
+	return newSecretStore(repo.Identifier())
+}
+
+// newSecretStore opens the secret store for the given repository identifier.
+func newSecretStore(repoID string) (*secret.Store, error) {
+	storage, err := openKeychain()
+	if err != nil {
+		return nil, fmt.Errorf("initialize secret storage: %w", err)
+	}
+	return secret.NewStore(storage, repoID), nil
+}
+
+// resolveSecretEnv converts env to KEY=VALUE pairs, resolving secret:<name>
+// references against the repository's secret store. The keychain is only
+// opened when at least one reference is present.
+func resolveSecretEnv(repoID string, env map[string]string) ([]string, error) {
+	if secret.HasRefs(env) {
+		store, err := newSecretStore(repoID)
+		if err != nil {
+			return nil, err
+		}
+		env, err = store.Resolve(env)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pairs := make([]string, 0, len(env))
+	for k, v := range env {
+		pairs = append(pairs, k+"="+v)
+	}
+	return pairs, nil
+}
+
+// parseEnvFlags parses KEY=VALUE pairs from repeated --env flags.
+func parseEnvFlags(values []string) (map[string]string, error) {
+	env := make(map[string]string, len(values))
+	for _, kv := range values {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid env %q: expected KEY=VALUE", kv)
+		}
+		env[k] = v
+	}
+	return env, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/multiplexer"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send <branch> <session> [message]",
+	Short: "Send input to a session without attaching",
+	Long: `Send input to a running session without attaching to it.
+
+The message is typed into the session's terminal exactly as given, followed by
+Enter. This lets scripts give follow-up instructions to a detached agent.
+
+With --stdin, the message is read from standard input instead of the command
+line. A single trailing newline is removed, so piping the output of echo sends
+one line. Use --no-enter to type the message without submitting it.`,
+	Example: `  # Give a running agent a follow-up instruction
+  hjk send feat/auth claude-main "Now add tests for the login handler"
+
+  # Send a longer prompt from a file
+  hjk send feat/auth claude-main --stdin < prompt.txt
+
+  # Type into a shell session without pressing Enter
+  hjk send feat/auth debug-shell --no-enter "git status"`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runSendCmd,
+}
+
+func runSendCmd(cmd *cobra.Command, args []string) error {
+	fromStdin, err := cmd.Flags().GetBool("stdin")
+	if err != nil {
+		return fmt.Errorf("get stdin flag: %w", err)
+	}
+	noEnter, err := cmd.Flags().GetBool("no-enter")
+	if err != nil {
+		return fmt.Errorf("get no-enter flag: %w", err)
+	}
+
+	message, err := sendMessage(args, fromStdin, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	mgr, err := requireManager(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	branch, sessionName := args[0], args[1]
+	inst, err := getInstanceByBranch(cmd.Context(), mgr, branch)
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.SendKeys(cmd.Context(), inst.ID, sessionName, message, !noEnter); err != nil {
+		switch {
+		case errors.Is(err, instance.ErrSessionNotFound):
+			return fmt.Errorf("session %q not found in instance for branch %q", sessionName, branch)
+		case errors.Is(err, multiplexer.ErrSessionNotFound):
+			return fmt.Errorf("session %q is no longer running", sessionName)
+		}
+		return fmt.Errorf("send to session: %w", err)
+	}
+
+	return nil
+}
+
+// sendMessage returns the message to send, taken from the third argument or,
+// with fromStdin, read from stdin.
+func sendMessage(args []string, fromStdin bool, stdin io.Reader) (string, error) {
+	if !fromStdin {
+		if len(args) < 3 {
+			return "", errors.New("message is required (or use --stdin)")
+		}
+		return args[2], nil
+	}
+
+	if len(args) > 2 {
+		return "", errors.New("cannot use both a message argument and --stdin")
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("read message from stdin: %w", err)
+	}
+	message := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(message, "\r"), nil
+}
+
+func init() {
+	sendCmd.Flags().Bool("stdin", false, "read the message from standard input")
+	sendCmd.Flags().Bool("no-enter", false, "type the message without pressing Enter")
+
+	rootCmd.AddCommand(sendCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jmgilman/headjack/internal/logging"
+	"github.com/jmgilman/headjack/internal/tui"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Manage instances and sessions interactively",
+	Long: `Open an interactive dashboard of all instances and their sessions.
+
+The list refreshes in the background. Selecting a session previews its log
+output as it is written. Keys:
+
+  ↑/↓, j/k   move the selection
+  enter, a   attach to the selected session (or the instance's most recent one)
+  n          start a shell session in the selected instance and attach
+  x          kill the selected session
+  s          stop the selected instance
+  d          remove the selected instance
+  r          refresh now
+  q          quit
+
+Kill, stop and remove ask for confirmation. After detaching from a session
+you return to the dashboard.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := requireManager(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		logsDir, err := getLogsDir(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("get logs directory: %w", err)
+		}
+		reader := logging.NewReader(logging.NewPathManager(logsDir))
+
+		refresh, err := cmd.Flags().GetDuration("refresh")
+		if err != nil {
+			return fmt.Errorf("get refresh flag: %w", err)
+		}
+
+		return tui.Run(cmd.Context(), mgr, reader, tui.Config{RefreshInterval: refresh})
+	},
+}
+
+func init() {
+	tuiCmd.Flags().Duration("refresh", tui.DefaultRefreshInterval, "how often to reload instances")
+
+	rootCmd.AddCommand(tuiCmd)
+}
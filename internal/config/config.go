@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -27,10 +28,16 @@ const DefaultBaseImage = "ghcr.io/gilmanlab/headjack:base"
 
 // Sentinel errors for configuration operations.
 var (
-	ErrInvalidKey     = errors.New("invalid configuration key")
-	ErrInvalidAgent   = errors.New("invalid agent name")
-	ErrInvalidRuntime = errors.New("invalid runtime name")
-	ErrNoEditor       = errors.New("$EDITOR environment variable not set")
+	ErrInvalidKey         = errors.New("invalid configuration key")
+	ErrInvalidAgent       = errors.New("invalid agent name")
+	ErrInvalidRuntime     = errors.New("invalid runtime name")
+	ErrInvalidBackend     = errors.New("invalid keychain backend")
+	ErrInvalidMultiplexer = errors.New("invalid multiplexer name")
+	ErrInvalidMuxMode     = errors.New("invalid multiplexer mode")
+	ErrInvalidLogSize     = errors.New("invalid log size")
+	ErrContainerLogs      = errors.New("log rotation and transcripts are not supported in container mode")
+	ErrInvalidCache       = errors.New("invalid cache")
+	ErrNoEditor           = errors.New("$EDITOR environment variable not set")
 )
 
 // validAgents contains the allowed agent names (unexported).
@@ -42,8 +49,33 @@ var validAgents = map[string]bool{
 
 // validRuntimes contains the allowed runtime names (unexported).
 var validRuntimes = map[string]bool{
-	"podman": true,
-	"docker": true,
+	"podman":  true,
+	"docker":  true,
+	"nerdctl": true,
+}
+
+// validMultiplexers contains the allowed terminal multiplexer names (unexported).
+var validMultiplexers = map[string]bool{
+	"tmux":   true,
+	"zellij": true,
+	"native": true,
+}
+
+// validMuxModes contains the allowed multiplexer modes (unexported).
+var validMuxModes = map[string]bool{
+	"host":      true,
+	"container": true,
+}
+
+// validKeychainBackends contains the allowed keychain backend names (unexported).
+var validKeychainBackends = map[string]bool{
+	"keychain":       true,
+	"secret-service": true,
+	"keyctl":         true,
+	"wincred":        true,
+	"file":           true,
+	"pass":           true,
+	"command":        true,
 }
 
 // validKeys is built once from Config struct reflection.
@@ -59,6 +91,12 @@ type Config struct {
 	Storage      StorageConfig          `mapstructure:"storage" validate:"required"`
 	Runtime      RuntimeConfig          `mapstructure:"runtime"`
 	Devcontainer DevcontainerConfig     `mapstructure:"devcontainer"`
+	Keychain     KeychainConfig         `mapstructure:"keychain"`
+	Multiplexer  MultiplexerConfig      `mapstructure:"multiplexer"`
+	Logs         LogsConfig             `mapstructure:"logs"`
+	Cache        map[string]string      `mapstructure:"cache" validate:"dive,keys,hostname_rfc1123,excludes=.,endkeys,startswith=/|startswith=~/"`
+	Mounts       []MountConfig          `mapstructure:"mounts" validate:"dive"`
+	Dotfiles     DotfilesConfig         `mapstructure:"dotfiles"`
 }
 
 // DefaultConfig holds default values for new instances.
@@ -69,8 +107,9 @@ type DefaultConfig struct {
 
 // AgentConfig holds agent-specific configuration.
 type AgentConfig struct {
-	Env   map[string]string `mapstructure:"env"`
-	Flags []string          `mapstructure:"flags"`
+	Env            map[string]string `mapstructure:"env"`
+	Flags          []string          `mapstructure:"flags"`
+	DefaultProfile string            `mapstructure:"default_profile"`
 }
 
 // StorageConfig holds storage location configuration.
@@ -82,8 +121,11 @@ type StorageConfig struct {
 
 // RuntimeConfig holds container runtime configuration.
 type RuntimeConfig struct {
-	Name  string   `mapstructure:"name" validate:"omitempty,oneof=podman docker"`
-	Flags []string `mapstructure:"flags"`
+	Name    string   `mapstructure:"name" validate:"omitempty,oneof=podman docker nerdctl"`
+	Flags   []string `mapstructure:"flags"`
+	API     bool     `mapstructure:"api"`      // Use the engine REST API instead of the CLI
+	Socket  string   `mapstructure:"socket"`   // Engine API address (empty = engine default)
+	MapUser bool     `mapstructure:"map_user"` // Run vanilla containers as the host user
 }
 
 // DevcontainerConfig holds devcontainer CLI configuration.
@@ -91,19 +133,94 @@ type DevcontainerConfig struct {
 	Path string `mapstructure:"path"`
 }
 
+// MountConfig holds a host path mounted into new containers.
+type MountConfig struct {
+	Source   string `mapstructure:"source" validate:"required,startswith=/|startswith=~"`
+	Target   string `mapstructure:"target" validate:"required,startswith=/|startswith=~/"`
+	ReadOnly bool   `mapstructure:"read_only"`
+}
+
+// DotfilesConfig holds the dotfiles installed into new containers. Source
+// is a git repository URL or a host directory; Install is the install script
+// relative to the dotfiles root (empty = detect).
+type DotfilesConfig struct {
+	Source  string `mapstructure:"source" validate:"omitempty,startswith=/|startswith=~|contains=://|contains=@"`
+	Install string `mapstructure:"install"`
+}
+
+// MultiplexerConfig holds terminal multiplexer configuration.
+type MultiplexerConfig struct {
+	Name       string `mapstructure:"name" validate:"omitempty,oneof=tmux zellij native"`
+	DetachKeys string `mapstructure:"detach_keys"`
+	Mode       string `mapstructure:"mode" validate:"omitempty,oneof=host container"`
+}
+
+// LogsConfig holds session log rotation configuration.
+type LogsConfig struct {
+	MaxSizeMB  int  `mapstructure:"max_size_mb" validate:"gte=0"`
+	MaxTotalMB int  `mapstructure:"max_total_mb" validate:"gte=0"`
+	Compress   bool `mapstructure:"compress"`
+	Transcript bool `mapstructure:"transcript"`
+}
+
+// KeychainConfig holds credential storage configuration.
+type KeychainConfig struct {
+	Backend string                `mapstructure:"backend" validate:"omitempty,oneof=keychain secret-service keyctl wincred file pass command"`
+	Pass    KeychainPassConfig    `mapstructure:"pass"`
+	Command KeychainCommandConfig `mapstructure:"command"`
+}
+
+// KeychainPassConfig holds settings for the pass keychain backend.
+type KeychainPassConfig struct {
+	Cmd    string `mapstructure:"cmd"`
+	Dir    string `mapstructure:"dir"`
+	Prefix string `mapstructure:"prefix"`
+}
+
+// KeychainCommandConfig holds shell commands for the external command keychain backend.
+type KeychainCommandConfig struct {
+	Get    string `mapstructure:"get"`
+	Set    string `mapstructure:"set"`
+	Delete string `mapstructure:"delete"`
+}
+
 // Validate checks the configuration for errors using struct tags.
 func (c *Config) Validate() error {
 	if err := validate.Struct(c); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
+	if err := validateContainerLogs(c.Multiplexer.Mode, c.Logs.MaxSizeMB, c.Logs.Transcript); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
 	return nil
 }
 
+// validateContainerLogs rejects log rotation and transcripts in container
+// mode. The tmux server in the container appends session output to the log
+// itself, since the hjk log sink that applies them only runs on the host.
+func validateContainerLogs(mode string, maxSizeMB int, transcript bool) error {
+	if mode != "container" || (maxSizeMB == 0 && !transcript) {
+		return nil
+	}
+	return fmt.Errorf("%w: set logs.max_size_mb to 0 and logs.transcript to false", ErrContainerLogs)
+}
+
 // Loader provides configuration loading and saving.
 type Loader struct {
-	v       *viper.Viper
-	path    string
-	homeDir string
+	v        *viper.Viper
+	path     string
+	homeDir  string
+	repoPath string          // Repository config path (empty = none)
+	userKeys map[string]bool // Keys set by the user config file
+	repoKeys map[string]bool // Keys set by the repository config file
+}
+
+// envBindings maps configuration keys to environment variables whose names
+// differ from the automatic HEADJACK_<KEY> form.
+var envBindings = map[string]string{
+	"default.agent":      "HEADJACK_DEFAULT_AGENT",
+	"default.base_image": "HEADJACK_BASE_IMAGE",
+	"storage.worktrees":  "HEADJACK_WORKTREE_DIR",
 }
 
 // NewLoader creates a new configuration loader.
@@ -126,12 +243,10 @@ func NewLoader() (*Loader, error) {
 
 	// Bind specific env vars to config keys.
 	// We intentionally ignore errors here as BindEnv only fails if called with zero arguments.
-	//nolint:errcheck // BindEnv only fails with zero arguments
-	v.BindEnv("default.agent", "HEADJACK_DEFAULT_AGENT")
-	//nolint:errcheck // BindEnv only fails with zero arguments
-	v.BindEnv("default.base_image", "HEADJACK_BASE_IMAGE")
-	//nolint:errcheck // BindEnv only fails with zero arguments
-	v.BindEnv("storage.worktrees", "HEADJACK_WORKTREE_DIR")
+	for key, env := range envBindings {
+		//nolint:errcheck // BindEnv only fails with zero arguments
+		v.BindEnv(key, env)
+	}
 
 	l := &Loader{
 		v:       v,
@@ -154,16 +269,41 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("storage.logs", "~/.local/share/headjack/logs")
 	l.v.SetDefault("agents.claude.env", map[string]string{"CLAUDE_CODE_MAX_TURNS": "100"})
 	l.v.SetDefault("agents.claude.flags", []string{})
+	l.v.SetDefault("agents.claude.default_profile", "")
 	l.v.SetDefault("agents.gemini.env", map[string]string{})
 	l.v.SetDefault("agents.gemini.flags", []string{})
+	l.v.SetDefault("agents.gemini.default_profile", "")
 	l.v.SetDefault("agents.codex.env", map[string]string{})
 	l.v.SetDefault("agents.codex.flags", []string{})
+	l.v.SetDefault("agents.codex.default_profile", "")
 	l.v.SetDefault("runtime.name", "docker")
 	l.v.SetDefault("runtime.flags", []string{})
+	l.v.SetDefault("runtime.api", false)
+	l.v.SetDefault("runtime.socket", "")
+	l.v.SetDefault("runtime.map_user", false)
 	l.v.SetDefault("devcontainer.path", "")
+	l.v.SetDefault("keychain.backend", "")
+	l.v.SetDefault("keychain.pass.cmd", "")
+	l.v.SetDefault("keychain.pass.dir", "")
+	l.v.SetDefault("keychain.pass.prefix", "")
+	l.v.SetDefault("keychain.command.get", "")
+	l.v.SetDefault("keychain.command.set", "")
+	l.v.SetDefault("keychain.command.delete", "")
+	l.v.SetDefault("multiplexer.name", "tmux")
+	l.v.SetDefault("multiplexer.detach_keys", "ctrl-p,ctrl-q")
+	l.v.SetDefault("multiplexer.mode", "host")
+	l.v.SetDefault("logs.max_size_mb", 10)
+	l.v.SetDefault("logs.max_total_mb", 200)
+	l.v.SetDefault("logs.compress", true)
+	l.v.SetDefault("logs.transcript", false)
+	l.v.SetDefault("cache", map[string]string{})
+	l.v.SetDefault("mounts", []map[string]any{})
+	l.v.SetDefault("dotfiles.source", "")
+	l.v.SetDefault("dotfiles.install", "")
 }
 
 // Load reads the configuration file, creating defaults if it doesn't exist.
+// If a repository root was set, the repository config is merged over it.
 func (l *Loader) Load() (*Config, error) {
 	if _, err := os.Stat(l.path); os.IsNotExist(err) {
 		if err := l.createDefault(); err != nil {
@@ -175,6 +315,10 @@ func (l *Loader) Load() (*Config, error) {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
+	if err := l.mergeRepoConfig(); err != nil {
+		return nil, fmt.Errorf("%s: %w", l.repoPath, err)
+	}
+
 	var cfg Config
 	if err := l.v.Unmarshal(&cfg, func(dc *mapstructure.DecoderConfig) {
 		dc.WeaklyTypedInput = true
@@ -187,6 +331,18 @@ func (l *Loader) Load() (*Config, error) {
 	cfg.Storage.Catalog = l.expandPath(cfg.Storage.Catalog)
 	cfg.Storage.Logs = l.expandPath(cfg.Storage.Logs)
 	cfg.Devcontainer.Path = l.expandPath(cfg.Devcontainer.Path)
+	cfg.Runtime.Socket = l.expandPath(cfg.Runtime.Socket)
+	cfg.Keychain.Pass.Dir = l.expandPath(cfg.Keychain.Pass.Dir)
+	cfg.Dotfiles.Source = l.expandPath(cfg.Dotfiles.Source)
+	for i := range cfg.Mounts {
+		cfg.Mounts[i].Source = l.expandPath(cfg.Mounts[i].Source)
+	}
+
+	if len(l.repoKeys) > 0 {
+		if err := validateRepoSections(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", l.repoPath, err)
+		}
+	}
 
 	return &cfg, nil
 }
@@ -222,12 +378,25 @@ func (l *Loader) GetAgentFlags(agent string) []string {
 	return l.v.GetStringSlice(key)
 }
 
+// GetAgentProfile returns the default credential profile for a specific agent.
+// Returns an empty string if the agent has no default profile configured.
+func (l *Loader) GetAgentProfile(agent string) string {
+	key := fmt.Sprintf("agents.%s.default_profile", agent)
+	return l.v.GetString(key)
+}
+
 // Set sets a configuration value by dot-notation key.
+// The value is written to the user config file, never the repository config.
 func (l *Loader) Set(key, value string) error {
 	if err := ValidateKey(key); err != nil {
 		return err
 	}
 
+	// Mounts are a list of tables, which can't be written as a single value
+	if key == "mounts" || strings.HasPrefix(key, "mounts.") {
+		return fmt.Errorf("%w: %s (edit the config file to change mounts)", ErrInvalidKey, key)
+	}
+
 	// Validate agent name if setting default.agent
 	if key == "default.agent" && value != "" {
 		if !validAgents[value] {
@@ -238,12 +407,84 @@ func (l *Loader) Set(key, value string) error {
 	// Validate runtime name if setting runtime.name
 	if key == "runtime.name" && value != "" {
 		if !validRuntimes[value] {
-			return fmt.Errorf("%w: %s (valid: podman, docker)", ErrInvalidRuntime, value)
+			return fmt.Errorf("%w: %s (valid: podman, docker, nerdctl)", ErrInvalidRuntime, value)
+		}
+	}
+
+	// Validate backend name if setting keychain.backend
+	if key == "keychain.backend" && value != "" {
+		if !validKeychainBackends[value] {
+			return fmt.Errorf("%w: %s (valid: keychain, secret-service, keyctl, wincred, file, pass, command)", ErrInvalidBackend, value)
+		}
+	}
+
+	// Validate multiplexer name if setting multiplexer.name
+	if key == "multiplexer.name" && value != "" {
+		if !validMultiplexers[value] {
+			return fmt.Errorf("%w: %s (valid: tmux, zellij, native)", ErrInvalidMultiplexer, value)
+		}
+	}
+
+	// Validate mode if setting multiplexer.mode
+	if key == "multiplexer.mode" && value != "" {
+		if !validMuxModes[value] {
+			return fmt.Errorf("%w: %s (valid: host, container)", ErrInvalidMuxMode, value)
+		}
+	}
+
+	// Validate sizes if setting logs.max_size_mb or logs.max_total_mb
+	if key == "logs.max_size_mb" || key == "logs.max_total_mb" {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%w: %s (must be a whole number of megabytes, 0 to disable)", ErrInvalidLogSize, value)
+		}
+	}
+
+	// Reject log rotation and transcripts combined with container mode
+	if key == "multiplexer.mode" || key == "logs.max_size_mb" || key == "logs.transcript" {
+		if err := l.validateContainerLogsWith(key, value); err != nil {
+			return err
+		}
+	}
+
+	// Validate cache targets if setting cache.<name>
+	if strings.HasPrefix(key, "cache.") {
+		if !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "~/") {
+			return fmt.Errorf("%w: %s (target must be an absolute path or start with ~/)", ErrInvalidCache, value)
 		}
 	}
 
 	l.v.Set(key, value)
-	return l.v.WriteConfig()
+	return l.writeUserKey(key, value)
+}
+
+// validateContainerLogsWith checks the multiplexer mode and log settings as
+// they would be after setting key to value.
+func (l *Loader) validateContainerLogsWith(key, value string) error {
+	mode := l.v.GetString("multiplexer.mode")
+	maxSizeMB := l.v.GetInt("logs.max_size_mb")
+	transcript := l.v.GetBool("logs.transcript")
+	switch key {
+	case "multiplexer.mode":
+		mode = value
+	case "logs.max_size_mb":
+		maxSizeMB, _ = strconv.Atoi(value) //nolint:errcheck // validated above
+	case "logs.transcript":
+		transcript, _ = strconv.ParseBool(value) //nolint:errcheck // invalid values are stored as false
+	}
+	return validateContainerLogs(mode, maxSizeMB, transcript)
+}
+
+// writeUserKey persists a single key to the user config file. The file is
+// re-read so that values merged from a repository config are not written back.
+func (l *Loader) writeUserKey(key, value string) error {
+	uv := viper.New()
+	uv.SetConfigFile(l.path)
+	uv.SetConfigType("yaml")
+	if err := uv.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read config: %w", err)
+	}
+	uv.Set(key, value)
+	return uv.WriteConfig()
 }
 
 // createDefault writes the default configuration file using Viper.
@@ -291,6 +532,14 @@ func ValidateKey(key string) error {
 		}
 	}
 
+	// Check for cache.<name> pattern; names become volume names
+	if name, ok := strings.CutPrefix(key, "cache."); ok {
+		if !IsValidCacheName(name) {
+			return fmt.Errorf("%w: %s (names may contain letters, digits, and hyphens)", ErrInvalidCache, name)
+		}
+		return nil
+	}
+
 	return fmt.Errorf("%w: %s", ErrInvalidKey, key)
 }
 
@@ -340,5 +589,11 @@ func IsValidRuntime(name string) bool {
 
 // ValidRuntimeNames returns the list of valid runtime names.
 func ValidRuntimeNames() []string {
-	return []string{"podman", "docker"}
+	return []string{"podman", "docker", "nerdctl"}
+}
+
+// IsValidCacheName reports whether name can name a cache: letters, digits,
+// and hyphens, not starting or ending with a hyphen.
+func IsValidCacheName(name string) bool {
+	return validate.Var(name, "required,hostname_rfc1123,excludes=.") == nil
 }
@@ -49,6 +49,7 @@ storage:
   logs: ~/custom/logs
 agents:
   claude:
+    default_profile: work
     env:
       FOO: bar
 `
@@ -74,6 +75,50 @@ agents:
 	// Note: viper lowercases all keys
 	env := loader.GetAgentEnv("claude")
 	assert.Equal(t, "bar", env["foo"])
+
+	assert.Equal(t, "work", loader.GetAgentProfile("claude"))
+	assert.Equal(t, "work", cfg.Agents["claude"].DefaultProfile)
+
+	// Agents without a configured profile fall back to empty
+	assert.Empty(t, loader.GetAgentProfile("gemini"))
+}
+
+func TestLoader_Load_MountsAndDotfiles(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	configDir := filepath.Join(tmpHome, ".config", "headjack")
+	require.NoError(t, os.MkdirAll(configDir, 0o750))
+
+	configContent := `
+mounts:
+  - source: ~/.ssh
+    target: ~/.ssh
+    read_only: true
+  - source: /srv/data
+    target: /data
+dotfiles:
+  source: ~/dotfiles
+  install: setup.sh
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configContent), 0o600))
+
+	loader, err := NewLoader()
+	require.NoError(t, err)
+
+	cfg, err := loader.Load()
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	// Sources are host paths; targets are container paths and kept as is
+	assert.Equal(t, []MountConfig{
+		{Source: filepath.Join(tmpHome, ".ssh"), Target: "~/.ssh", ReadOnly: true},
+		{Source: "/srv/data", Target: "/data"},
+	}, cfg.Mounts)
+	assert.Equal(t, DotfilesConfig{Source: filepath.Join(tmpHome, "dotfiles"), Install: "setup.sh"}, cfg.Dotfiles)
+
+	err = loader.Set("mounts", "/srv/data")
+	assert.ErrorIs(t, err, ErrInvalidKey)
 }
 
 func TestLoader_Load_EnvVarOverride(t *testing.T) {
@@ -159,6 +204,90 @@ func TestLoader_Set(t *testing.T) {
 		err := loader.Set("default.agent", "")
 		assert.NoError(t, err)
 	})
+
+	t.Run("sets keychain backend", func(t *testing.T) {
+		require.NoError(t, loader.Set("keychain.backend", "pass"))
+
+		val, err := loader.Get("keychain.backend")
+		require.NoError(t, err)
+		assert.Equal(t, "pass", val)
+	})
+
+	t.Run("rejects invalid keychain backend", func(t *testing.T) {
+		err := loader.Set("keychain.backend", "vault")
+		assert.ErrorIs(t, err, ErrInvalidBackend)
+	})
+
+	t.Run("sets multiplexer name", func(t *testing.T) {
+		require.NoError(t, loader.Set("multiplexer.name", "native"))
+
+		val, err := loader.Get("multiplexer.name")
+		require.NoError(t, err)
+		assert.Equal(t, "native", val)
+	})
+
+	t.Run("rejects invalid multiplexer name", func(t *testing.T) {
+		err := loader.Set("multiplexer.name", "screen")
+		assert.ErrorIs(t, err, ErrInvalidMultiplexer)
+	})
+
+	t.Run("rejects container mode with log rotation", func(t *testing.T) {
+		err := loader.Set("multiplexer.mode", "container")
+		assert.ErrorIs(t, err, ErrContainerLogs)
+	})
+
+	t.Run("sets multiplexer mode", func(t *testing.T) {
+		require.NoError(t, loader.Set("logs.max_size_mb", "0"))
+		require.NoError(t, loader.Set("multiplexer.mode", "container"))
+
+		val, err := loader.Get("multiplexer.mode")
+		require.NoError(t, err)
+		assert.Equal(t, "container", val)
+	})
+
+	t.Run("rejects log rotation and transcripts in container mode", func(t *testing.T) {
+		require.ErrorIs(t, loader.Set("logs.max_size_mb", "10"), ErrContainerLogs)
+		require.ErrorIs(t, loader.Set("logs.transcript", "true"), ErrContainerLogs)
+		require.NoError(t, loader.Set("multiplexer.mode", "host"))
+	})
+
+	t.Run("rejects invalid multiplexer mode", func(t *testing.T) {
+		err := loader.Set("multiplexer.mode", "remote")
+		assert.ErrorIs(t, err, ErrInvalidMuxMode)
+	})
+
+	t.Run("sets log size", func(t *testing.T) {
+		require.NoError(t, loader.Set("logs.max_size_mb", "50"))
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+		assert.Equal(t, 50, cfg.Logs.MaxSizeMB)
+		assert.Equal(t, 200, cfg.Logs.MaxTotalMB)
+		assert.True(t, cfg.Logs.Compress)
+	})
+
+	t.Run("rejects invalid log size", func(t *testing.T) {
+		for _, value := range []string{"-1", "10MB", ""} {
+			err := loader.Set("logs.max_total_mb", value)
+			assert.ErrorIs(t, err, ErrInvalidLogSize, value)
+		}
+	})
+
+	t.Run("sets cache", func(t *testing.T) {
+		require.NoError(t, loader.Set("cache.npm", "~/.npm"))
+		require.NoError(t, loader.Set("cache.go-mod", "/go/pkg/mod"))
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+		// Targets are container paths, so ~ is not expanded
+		assert.Equal(t, map[string]string{"npm": "~/.npm", "go-mod": "/go/pkg/mod"}, cfg.Cache)
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("rejects relative cache target", func(t *testing.T) {
+		err := loader.Set("cache.pip", ".cache/pip")
+		assert.ErrorIs(t, err, ErrInvalidCache)
+	})
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -199,6 +328,48 @@ func TestConfig_Validate(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("invalid cache", func(t *testing.T) {
+		for name, target := range map[string]string{"npm": "npm", "go_mod": "/go/pkg/mod"} {
+			cfg := &Config{
+				Default: DefaultConfig{BaseImage: "test:latest"},
+				Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+				Cache:   map[string]string{name: target},
+			}
+			assert.Error(t, cfg.Validate(), name)
+		}
+	})
+
+	t.Run("invalid mount", func(t *testing.T) {
+		for _, mount := range []MountConfig{
+			{Source: "relative/path", Target: "/data"},
+			{Source: "/srv/data", Target: "data"},
+			{Source: "/srv/data"},
+		} {
+			cfg := &Config{
+				Default: DefaultConfig{BaseImage: "test:latest"},
+				Storage: StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+				Mounts:  []MountConfig{mount},
+			}
+			assert.Error(t, cfg.Validate(), mount)
+		}
+	})
+
+	t.Run("log rotation in container mode", func(t *testing.T) {
+		cfg := &Config{
+			Default:     DefaultConfig{BaseImage: "test:latest"},
+			Storage:     StorageConfig{Worktrees: "/tmp/worktrees", Catalog: "/tmp/catalog.json", Logs: "/tmp/logs"},
+			Multiplexer: MultiplexerConfig{Mode: "container"},
+			Logs:        LogsConfig{MaxSizeMB: 10},
+		}
+		require.ErrorIs(t, cfg.Validate(), ErrContainerLogs)
+
+		cfg.Logs = LogsConfig{Transcript: true}
+		require.ErrorIs(t, cfg.Validate(), ErrContainerLogs)
+
+		cfg.Logs = LogsConfig{MaxTotalMB: 200, Compress: true}
+		assert.NoError(t, cfg.Validate())
+	})
+
 	t.Run("valid config without base_image", func(t *testing.T) {
 		cfg := &Config{
 			Default: DefaultConfig{Agent: ""},
@@ -236,6 +407,10 @@ func TestValidateKey(t *testing.T) {
 		{"storage.worktrees is valid", "storage.worktrees", nil},
 		{"storage.catalog is valid", "storage.catalog", nil},
 		{"storage.logs is valid", "storage.logs", nil},
+		{"keychain.backend is valid", "keychain.backend", nil},
+		{"keychain.command.get is valid", "keychain.command.get", nil},
+		{"multiplexer.name is valid", "multiplexer.name", nil},
+		{"multiplexer.detach_keys is valid", "multiplexer.detach_keys", nil},
 		{"agents is valid", "agents", nil},
 		{"default is valid", "default", nil},
 		{"storage is valid", "storage", nil},
@@ -244,6 +419,11 @@ func TestValidateKey(t *testing.T) {
 		{"agents.gemini is valid", "agents.gemini", nil},
 		{"agents.codex is valid", "agents.codex", nil},
 		{"agents.invalid returns error", "agents.invalid", ErrInvalidAgent},
+		{"cache is valid", "cache", nil},
+		{"cache.npm is valid", "cache.npm", nil},
+		{"cache.go-mod is valid", "cache.go-mod", nil},
+		{"cache.with.dot returns error", "cache.go.mod", ErrInvalidCache},
+		{"cache with empty name returns error", "cache.", ErrInvalidCache},
 		{"unknown.key returns error", "unknown.key", ErrInvalidKey},
 		{"empty key returns error", "", ErrInvalidKey},
 		{"random key returns error", "foo", ErrInvalidKey},
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// RepoConfigFile is the name of the per-repository configuration file,
+// located at the repository root.
+const RepoConfigFile = ".headjack.yaml"
+
+// ErrRepoKeyNotAllowed is returned when a repository config sets a key that
+// only the user config may set.
+var ErrRepoKeyNotAllowed = errors.New("key not allowed in repository config")
+
+// repoSections lists the top-level sections a repository config may override.
+// Storage locations, keychain commands and the devcontainer binary are
+// excluded because a cloned repository must not be able to redirect data or
+// run arbitrary host commands.
+var repoSections = map[string]bool{
+	"default": true,
+	"agents":  true,
+	"runtime": true,
+}
+
+// repoDeniedKeys lists patterns, in path.Match syntax, of keys within
+// repoSections that a repository config may still not set. The engine
+// settings are excluded so that a repository cannot send its containers and
+// mounts to another engine or change how they run as the host user, and agent
+// flags so that it cannot change what agents are permitted to do.
+var repoDeniedKeys = []string{
+	"runtime.socket",
+	"runtime.api",
+	"runtime.map_user",
+	"agents.*.flags",
+}
+
+// hostAccessFlags lists container runtime flags that give a container access
+// to host resources: files, devices, namespaces or privileges. A repository
+// config may not set them in runtime.flags.
+var hostAccessFlags = map[string]bool{
+	"--privileged":         true,
+	"--volume":             true,
+	"-v":                   true,
+	"--mount":              true,
+	"--volumes-from":       true,
+	"--device":             true,
+	"--device-cgroup-rule": true,
+	"--pid":                true,
+	"--network":            true,
+	"--net":                true,
+	"--ipc":                true,
+	"--uts":                true,
+	"--userns":             true,
+	"--cgroupns":           true,
+	"--cgroup-parent":      true,
+	"--cap-add":            true,
+	"--security-opt":       true,
+	"--env-file":           true,
+	"--label-file":         true,
+	"--cidfile":            true,
+	"--rootfs":             true,
+}
+
+// shortBoolFlags are the boolean shorthands of docker run, which may precede
+// another shorthand in a single argument, as in -itv.
+const shortBoolFlags = "ditPq"
+
+// Source identifies where a configuration value came from.
+type Source string
+
+// Configuration sources, from lowest to highest precedence.
+const (
+	SourceDefault Source = "default"
+	SourceUser    Source = "user"
+	SourceRepo    Source = "repo"
+	SourceEnv     Source = "env"
+)
+
+// Origin describes the effective value of a configuration key and its source.
+type Origin struct {
+	Key      string
+	Value    any
+	Source   Source
+	Location string // File path or environment variable name (empty for defaults)
+}
+
+// SetRepoRoot enables merging of the repository config found at root.
+// Must be called before Load.
+func (l *Loader) SetRepoRoot(root string) {
+	l.repoPath = filepath.Join(root, RepoConfigFile)
+}
+
+// RepoPath returns the repository config path, or an empty string if no
+// repository root was set.
+func (l *Loader) RepoPath() string {
+	return l.repoPath
+}
+
+// mergeRepoConfig merges the repository config over the user config.
+// Maps (such as agent env) are merged key by key; other values are replaced.
+func (l *Loader) mergeRepoConfig() error {
+	l.userKeys = make(map[string]bool)
+	for _, key := range l.v.AllKeys() {
+		if l.v.InConfig(key) {
+			l.userKeys[key] = true
+		}
+	}
+	l.repoKeys = make(map[string]bool)
+
+	if l.repoPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(l.repoPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	rv := viper.New()
+	rv.SetConfigFile(l.repoPath)
+	rv.SetConfigType("yaml")
+	if err := rv.ReadInConfig(); err != nil {
+		return fmt.Errorf("read repo config: %w", err)
+	}
+
+	keys := rv.AllKeys()
+	for _, key := range keys {
+		section, _, _ := strings.Cut(key, ".")
+		if !repoSections[section] {
+			return fmt.Errorf("%w: %s (allowed: default, agents, runtime)", ErrRepoKeyNotAllowed, key)
+		}
+		if isRepoDeniedKey(key) {
+			return fmt.Errorf("%w: %s", ErrRepoKeyNotAllowed, key)
+		}
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+	}
+
+	if flag := hostAccessFlag(rv.GetStringSlice("runtime.flags")); flag != "" {
+		return fmt.Errorf("%w: runtime.flags %s gives containers access to the host", ErrRepoKeyNotAllowed, flag)
+	}
+
+	if err := l.v.MergeConfigMap(rv.AllSettings()); err != nil {
+		return fmt.Errorf("merge repo config: %w", err)
+	}
+	for _, key := range keys {
+		l.repoKeys[key] = true
+	}
+	return nil
+}
+
+// isRepoDeniedKey reports whether key matches one of repoDeniedKeys.
+func isRepoDeniedKey(key string) bool {
+	for _, pattern := range repoDeniedKeys {
+		if ok, _ := path.Match(pattern, key); ok { //nolint:errcheck // patterns are constant and valid
+			return true
+		}
+	}
+	return false
+}
+
+// hostAccessFlag returns the first of flags that is one of hostAccessFlags,
+// or "" if there is none. Values may be attached with = and, for
+// shorthands, directly.
+func hostAccessFlag(flags []string) string {
+	for _, flag := range flags {
+		name := flag
+		if strings.HasPrefix(flag, "--") {
+			name, _, _ = strings.Cut(flag, "=")
+		} else if strings.HasPrefix(flag, "-") {
+			// Skip boolean shorthands to the shorthand that may take a value
+			short := strings.TrimLeft(flag[1:], shortBoolFlags)
+			if short == "" {
+				continue
+			}
+			name = "-" + short[:1]
+		}
+		if hostAccessFlags[name] {
+			return flag
+		}
+	}
+	return ""
+}
+
+// validateRepoSections validates the sections a repository config may override,
+// so that errors in the user-only sections are not attributed to the repo file.
+func validateRepoSections(cfg *Config) error {
+	if err := validate.StructPartial(cfg, repoSectionFields()...); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+	return nil
+}
+
+// repoSectionFields returns the names of the Config fields of repoSections,
+// including the fields of nested structs, which StructPartial only
+// validates if they are named.
+func repoSectionFields() []string {
+	t := reflect.TypeOf(Config{})
+	var fields []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if repoSections[field.Tag.Get("mapstructure")] {
+			fields = appendFieldNames(fields, field, "")
+		}
+	}
+	return fields
+}
+
+// appendFieldNames appends the namespaced name of field and, for structs,
+// the names of its fields.
+func appendFieldNames(names []string, field reflect.StructField, prefix string) []string {
+	name := prefix + field.Name
+	names = append(names, name)
+	if field.Type.Kind() == reflect.Struct {
+		for i := range field.Type.NumField() {
+			names = appendFieldNames(names, field.Type.Field(i), name+".")
+		}
+	}
+	return names
+}
+
+// Origins returns every configuration key with its effective value and
+// source, sorted by key. Load must be called first.
+//
+// Precedence, from lowest to highest: built-in defaults, the user config
+// file, the repository config file, environment variables.
+func (l *Loader) Origins() []Origin {
+	keys := l.v.AllKeys()
+	sort.Strings(keys)
+
+	origins := make([]Origin, 0, len(keys))
+	for _, key := range keys {
+		origin := Origin{Key: key, Value: l.v.Get(key), Source: SourceDefault}
+		switch env := envVarName(key); {
+		case os.Getenv(env) != "":
+			origin.Source = SourceEnv
+			origin.Location = env
+		case l.repoKeys[key]:
+			origin.Source = SourceRepo
+			origin.Location = l.repoPath
+		case l.userKeys[key]:
+			origin.Source = SourceUser
+			origin.Location = l.path
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// envVarName returns the environment variable that overrides key.
+func envVarName(key string) string {
+	if env, ok := envBindings[key]; ok {
+		return env
+	}
+	return "HEADJACK_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
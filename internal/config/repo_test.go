@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRepoConfig writes user and repository configs and returns a loader for them.
+func setupRepoConfig(t *testing.T, userContent, repoContent string) *Loader {
+	t.Helper()
+
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+
+	if userContent != "" {
+		configDir := filepath.Join(tmpHome, ".config", "headjack")
+		require.NoError(t, os.MkdirAll(configDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(userContent), 0o600))
+	}
+
+	repoRoot := t.TempDir()
+	if repoContent != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(repoRoot, RepoConfigFile), []byte(repoContent), 0o600))
+	}
+
+	loader, err := NewLoader()
+	require.NoError(t, err)
+	loader.SetRepoRoot(repoRoot)
+	return loader
+}
+
+func TestLoader_Load_RepoConfig(t *testing.T) {
+	t.Run("overrides user config", func(t *testing.T) {
+		loader := setupRepoConfig(t, `
+default:
+  agent: claude
+  base_image: user:latest
+agents:
+  claude:
+    env:
+      USER_VAR: user
+runtime:
+  flags: ["--memory=2g"]
+`, `
+default:
+  base_image: repo:latest
+agents:
+  claude:
+    env:
+      REPO_VAR: repo
+runtime:
+  flags: ["--cpus=4"]
+`)
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "claude", cfg.Default.Agent, "unset repo keys keep the user value")
+		assert.Equal(t, "repo:latest", cfg.Default.BaseImage)
+		assert.Equal(t, []string{"--cpus=4"}, cfg.Runtime.Flags, "lists are replaced")
+
+		env := loader.GetAgentEnv("claude")
+		assert.Equal(t, "user", env["user_var"], "maps are merged")
+		assert.Equal(t, "repo", env["repo_var"])
+	})
+
+	t.Run("env vars take precedence over repo config", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "default:\n  agent: codex\n")
+		t.Setenv("HEADJACK_DEFAULT_AGENT", "gemini")
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "gemini", cfg.Default.Agent)
+	})
+
+	t.Run("missing repo config is ignored", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "")
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Default.Agent)
+	})
+
+	t.Run("rejects disallowed sections", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "keychain:\n  backend: command\n")
+
+		_, err := loader.Load()
+		assert.ErrorIs(t, err, ErrRepoKeyNotAllowed)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "default:\n  bogus: x\n")
+
+		_, err := loader.Load()
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("rejects denied keys in allowed sections", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "runtime:\n  socket: tcp://example.com:2375\n")
+
+		_, err := loader.Load()
+		assert.ErrorIs(t, err, ErrRepoKeyNotAllowed)
+	})
+
+	t.Run("rejects engine settings and agent flags", func(t *testing.T) {
+		for _, content := range []string{
+			"runtime:\n  api: true\n",
+			"runtime:\n  map_user: true\n",
+			"agents:\n  claude:\n    flags: [\"--dangerously-skip-permissions\"]\n",
+		} {
+			loader := setupRepoConfig(t, "", content)
+
+			_, err := loader.Load()
+			assert.ErrorIs(t, err, ErrRepoKeyNotAllowed, content)
+		}
+	})
+
+	t.Run("rejects runtime flags that give access to the host", func(t *testing.T) {
+		for _, flags := range []string{
+			`["--privileged"]`,
+			`["-v", "/:/host"]`,
+			`["-v/:/host"]`,
+			`["-itv", "/:/host"]`,
+			`["--volume=/:/host"]`,
+			`["--pid=host"]`,
+			`["--network", "host"]`,
+			`["--cpus=4", "--cap-add=SYS_ADMIN"]`,
+		} {
+			loader := setupRepoConfig(t, "runtime:\n  flags: [\"--memory=2g\"]\n", "runtime:\n  flags: "+flags+"\n")
+
+			_, err := loader.Load()
+			assert.ErrorIs(t, err, ErrRepoKeyNotAllowed, flags)
+		}
+	})
+
+	t.Run("validates values with struct tags", func(t *testing.T) {
+		loader := setupRepoConfig(t, "", "runtime:\n  name: lxc\n")
+
+		_, err := loader.Load()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), RepoConfigFile)
+	})
+
+	t.Run("ignores invalid values of user-only sections", func(t *testing.T) {
+		loader := setupRepoConfig(t, `
+cache:
+  npm: relative/path
+mounts:
+  - target: /data
+`, "default:\n  agent: claude\n")
+
+		cfg, err := loader.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "claude", cfg.Default.Agent)
+	})
+}
+
+func TestRepoSectionFields(t *testing.T) {
+	fields := repoSectionFields()
+
+	assert.Contains(t, fields, "Default")
+	assert.Contains(t, fields, "Default.Agent")
+	assert.Contains(t, fields, "Agents")
+	assert.Contains(t, fields, "Runtime.Name")
+	assert.NotContains(t, fields, "Cache")
+	assert.NotContains(t, fields, "Mounts")
+	assert.NotContains(t, fields, "Dotfiles")
+}
+
+func TestLoader_Origins(t *testing.T) {
+	loader := setupRepoConfig(t, `
+default:
+  agent: claude
+`, `
+runtime:
+  name: podman
+`)
+	t.Setenv("HEADJACK_BASE_IMAGE", "env:image")
+
+	_, err := loader.Load()
+	require.NoError(t, err)
+
+	origins := make(map[string]Origin)
+	for _, o := range loader.Origins() {
+		origins[o.Key] = o
+	}
+
+	assert.Equal(t, SourceUser, origins["default.agent"].Source)
+	assert.Equal(t, loader.Path(), origins["default.agent"].Location)
+
+	assert.Equal(t, SourceRepo, origins["runtime.name"].Source)
+	assert.Equal(t, loader.RepoPath(), origins["runtime.name"].Location)
+	assert.Equal(t, "podman", origins["runtime.name"].Value)
+
+	assert.Equal(t, SourceEnv, origins["default.base_image"].Source)
+	assert.Equal(t, "HEADJACK_BASE_IMAGE", origins["default.base_image"].Location)
+
+	assert.Equal(t, SourceDefault, origins["storage.logs"].Source)
+}
+
+func TestLoader_Set_DoesNotPersistRepoValues(t *testing.T) {
+	loader := setupRepoConfig(t, "", "default:\n  base_image: repo:latest\n")
+
+	_, err := loader.Load()
+	require.NoError(t, err)
+	require.NoError(t, loader.Set("default.agent", "gemini"))
+
+	data, err := os.ReadFile(loader.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "gemini")
+	assert.NotContains(t, string(data), "repo:latest")
+}
+
+func TestHostAccessFlag(t *testing.T) {
+	assert.Empty(t, hostAccessFlag([]string{"--cpus=4", "--memory", "2g", "-it", "-e", "FOO=bar"}))
+	assert.Equal(t, "--privileged", hostAccessFlag([]string{"--cpus=4", "--privileged"}))
+	assert.Equal(t, "-dv", hostAccessFlag([]string{"-dv", "/:/host"}))
+	assert.Equal(t, "--mount=type=bind,source=/,target=/host", hostAccessFlag([]string{"--mount=type=bind,source=/,target=/host"}))
+}
@@ -0,0 +1,901 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+// Default API sockets of Docker and rootful Podman.
+const (
+	DefaultDockerSocket = "/var/run/docker.sock"
+	DefaultPodmanSocket = "/run/podman/podman.sock"
+)
+
+// APIError is an error response from the container engine API that does not
+// map to one of the sentinel errors.
+type APIError struct {
+	StatusCode int    // HTTP status code
+	Message    string // Message reported by the engine
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// ExitError reports a command run with Exec that exited with a non-zero code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+// APIConfig holds configuration for the engine API runtime.
+type APIConfig struct {
+	// Host is the address of the API: a socket path, unix://<path>,
+	// tcp://<host>:<port> or http://<host>:<port>.
+	Host string
+
+	// Binary is the CLI of the same engine (docker or podman). It is only
+	// used for ExecCommand, since multiplexer panes exec through the CLI.
+	Binary string
+}
+
+// apiRuntime implements Runtime using the Docker Engine REST API, which
+// Podman also serves through its compatibility socket. Errors are derived
+// from HTTP status codes rather than CLI messages.
+type apiRuntime struct {
+	client  *http.Client
+	baseURL string
+	dial    dialFunc
+	binary  string
+
+	// Standard streams of Exec (replaced in tests)
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// NewAPIRuntime creates a Runtime using the container engine API at
+// cfg.Host.
+func NewAPIRuntime(cfg APIConfig) (Runtime, error) {
+	client, baseURL, dial, err := apiClient(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &apiRuntime{
+		client:  client,
+		baseURL: baseURL,
+		dial:    dial,
+		binary:  cfg.Binary,
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}, nil
+}
+
+// DefaultAPIHost returns the API address of the named engine (docker or
+// podman). DOCKER_HOST or CONTAINER_HOST is used when set. Otherwise Podman
+// uses the rootless socket of the current user when it exists.
+func DefaultAPIHost(engine string) string {
+	if engine == "docker" {
+		if host := os.Getenv("DOCKER_HOST"); host != "" {
+			return host
+		}
+		return DefaultDockerSocket
+	}
+
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		rootless := filepath.Join(dir, "podman", "podman.sock")
+		if _, err := os.Stat(rootless); err == nil {
+			return rootless
+		}
+	}
+	return DefaultPodmanSocket
+}
+
+// dialFunc opens a connection to the API.
+type dialFunc func(ctx context.Context) (net.Conn, error)
+
+// apiClient returns an HTTP client and base URL for an API address, and a
+// function to dial it for requests that take over the connection.
+func apiClient(host string) (*http.Client, string, dialFunc, error) {
+	var network, address string
+	switch {
+	case strings.HasPrefix(host, "unix://"):
+		network, address = "unix", strings.TrimPrefix(host, "unix://")
+	case strings.HasPrefix(host, "/"):
+		network, address = "unix", host
+	case strings.HasPrefix(host, "tcp://"):
+		network, address = "tcp", strings.TrimPrefix(host, "tcp://")
+	case strings.HasPrefix(host, "http://"):
+		network, address = "tcp", strings.TrimSuffix(strings.TrimPrefix(host, "http://"), "/")
+	default:
+		return nil, "", nil, fmt.Errorf("unsupported API host %q (use a socket path, unix://, tcp:// or http://)", host)
+	}
+	dial := func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, address)
+	}
+
+	if network == "tcp" {
+		return &http.Client{}, "http://" + address, dial, nil
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+	}
+	// The host name is ignored when dialing the socket
+	return &http.Client{Transport: transport}, "http://localhost", dial, nil
+}
+
+// apiContainerConfig is the body of POST /containers/create.
+type apiContainerConfig struct {
+	Image      string            `json:"Image"`
+	Entrypoint []string          `json:"Entrypoint,omitempty"`
+	Cmd        []string          `json:"Cmd,omitempty"`
+	Env        []string          `json:"Env,omitempty"`
+	User       string            `json:"User,omitempty"`
+	WorkingDir string            `json:"WorkingDir,omitempty"`
+	Hostname   string            `json:"Hostname,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+	HostConfig apiHostConfig     `json:"HostConfig"`
+}
+
+// apiMount is a volume or tmpfs mount of a container create request.
+// Volumes that don't exist are created.
+type apiMount struct {
+	Type     string `json:"Type"`
+	Source   string `json:"Source,omitempty"`
+	Target   string `json:"Target"`
+	ReadOnly bool   `json:"ReadOnly,omitempty"`
+}
+
+// apiHostConfig is the HostConfig of a container create request.
+type apiHostConfig struct {
+	Binds       []string          `json:"Binds,omitempty"`
+	Mounts      []apiMount        `json:"Mounts,omitempty"`
+	Privileged  bool              `json:"Privileged,omitempty"`
+	NetworkMode string            `json:"NetworkMode,omitempty"`
+	CapAdd      []string          `json:"CapAdd,omitempty"`
+	CapDrop     []string          `json:"CapDrop,omitempty"`
+	SecurityOpt []string          `json:"SecurityOpt,omitempty"`
+	ExtraHosts  []string          `json:"ExtraHosts,omitempty"`
+	Devices     []apiDevice       `json:"Devices,omitempty"`
+	Tmpfs       map[string]string `json:"Tmpfs,omitempty"`
+	Init        *bool             `json:"Init,omitempty"`
+	UsernsMode  string            `json:"UsernsMode,omitempty"`
+}
+
+// apiDevice is a device mapping of a container create request.
+type apiDevice struct {
+	PathOnHost        string `json:"PathOnHost"`
+	PathInContainer   string `json:"PathInContainer"`
+	CgroupPermissions string `json:"CgroupPermissions"`
+}
+
+// Run creates and starts a new container, pulling its image if it is not
+// present. Pull progress is written to cfg.Stderr.
+func (r *apiRuntime) Run(ctx context.Context, cfg *RunConfig) (*Container, error) {
+	log := slogger.L(ctx)
+	log.Debug("running container", slog.String("name", cfg.Name), slog.String("image", cfg.Image))
+
+	spec, err := buildContainerConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	addPasswd := false
+	if cfg.User != nil && spec.User == "" && spec.HostConfig.UsernsMode == "" {
+		// As with the CLIs: Podman maps the host user into the container,
+		// Docker runs the process with the host IDs
+		if r.binary == "podman" {
+			spec.HostConfig.UsernsMode = "keep-id"
+		} else {
+			spec.User = cfg.User.owner()
+			addPasswd = true
+		}
+	}
+
+	id, err := r.createContainer(ctx, cfg.Name, spec)
+	if errors.Is(err, errImageNotFound) {
+		if err := r.pullImage(ctx, cfg.Image, cfg.Stderr); err != nil {
+			return nil, err
+		}
+		id, err = r.createContainer(ctx, cfg.Name, spec)
+	}
+	if errors.Is(err, errImageNotFound) {
+		return nil, fmt.Errorf("create container: %w: %s", err, cfg.Image)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Start(ctx, id); err != nil {
+		return nil, err
+	}
+	log.Debug("container started", slog.String("id", id))
+
+	if addPasswd {
+		// Best-effort, as with the CLI runtimes
+		if err := r.Exec(ctx, id, &ExecConfig{Command: passwdCommand(cfg.User), User: "0"}); err != nil {
+			log.Warn("failed to add passwd entry", slog.String("id", id), slog.String("error", err.Error()))
+		}
+	}
+
+	return &Container{
+		ID:        id,
+		Name:      cfg.Name,
+		Image:     cfg.Image,
+		Status:    StatusRunning,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// errImageNotFound reports that a container could not be created because
+// its image is not present.
+var errImageNotFound = errors.New("image not found")
+
+// createContainer creates a container and returns its ID.
+func (r *apiRuntime) createContainer(ctx context.Context, name string, spec *apiContainerConfig) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	var query url.Values
+	if name != "" {
+		query = url.Values{"name": {name}}
+	}
+	err := r.doJSON(ctx, http.MethodPost, "/containers/create", query, spec, &created)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			return "", errImageNotFound
+		case http.StatusConflict:
+			return "", ErrAlreadyExists
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("create container: %w", err)
+	}
+	return created.ID, nil
+}
+
+// pullImage pulls an image, writing progress to out if it is not nil.
+func (r *apiRuntime) pullImage(ctx context.Context, ref string, out io.Writer) error {
+	name, tag := splitImageRef(ref)
+	query := url.Values{"fromImage": {name}}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+
+	resp, err := r.do(ctx, http.MethodPost, "/images/create", query, nil, "")
+	if err != nil {
+		return fmt.Errorf("pull image %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+
+	if err := streamProgress(resp.Body, out); err != nil {
+		return fmt.Errorf("pull image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// splitImageRef splits an image reference into the name and tag parameters
+// of an image pull. Without a tag or digest, "latest" is used, since an
+// empty tag pulls every tag of the image.
+func splitImageRef(ref string) (name, tag string) {
+	if strings.Contains(ref, "@") {
+		return ref, ""
+	}
+	slash := strings.LastIndexByte(ref, '/')
+	if colon := strings.LastIndexByte(ref, ':'); colon > slash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, "latest"
+}
+
+// progressEvent is a message of a streamed pull or build response.
+type progressEvent struct {
+	Stream   string `json:"stream"`
+	Status   string `json:"status"`
+	ID       string `json:"id"`
+	Progress string `json:"progress"`
+	Error    string `json:"error"`
+}
+
+// streamProgress writes the events of a streamed response to out, one line
+// per event, and returns the first error event.
+func streamProgress(body io.Reader, out io.Writer) error {
+	dec := json.NewDecoder(body)
+	for {
+		var ev progressEvent
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read progress: %w", err)
+		}
+		if ev.Error != "" {
+			return errors.New(ev.Error)
+		}
+		if out == nil {
+			continue
+		}
+
+		var line string
+		switch {
+		case ev.Stream != "":
+			line = ev.Stream
+		case ev.ID != "":
+			line = strings.TrimSpace(ev.ID+": "+ev.Status+" "+ev.Progress) + "\n"
+		case ev.Status != "":
+			line = ev.Status + "\n"
+		default:
+			continue
+		}
+		if _, err := io.WriteString(out, line); err != nil {
+			return fmt.Errorf("write progress: %w", err)
+		}
+	}
+}
+
+// Exec executes a command in a running container.
+func (r *apiRuntime) Exec(ctx context.Context, id string, cfg *ExecConfig) error {
+	log := slogger.L(ctx)
+	log.Debug("executing in container", slog.String("id", id), slog.Any("command", cfg.Command))
+
+	tty := cfg.Interactive && isTerminal(r.stdin)
+	create := map[string]any{
+		"Cmd":          cfg.Command,
+		"Env":          cfg.Env,
+		"User":         cfg.User,
+		"WorkingDir":   cfg.Workdir,
+		"AttachStdin":  cfg.Interactive,
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"Tty":          tty,
+	}
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := r.doJSON(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/exec", nil, create, &created)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			return ErrNotFound
+		case http.StatusConflict:
+			return ErrNotRunning
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("create exec: %w", err)
+	}
+
+	if err := r.startExec(ctx, created.ID, cfg.Interactive, tty); err != nil {
+		return err
+	}
+
+	var inspect struct {
+		ExitCode int `json:"ExitCode"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, "/exec/"+created.ID+"/json", nil, nil, &inspect); err != nil {
+		return fmt.Errorf("inspect exec: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return &ExitError{Code: inspect.ExitCode}
+	}
+	return nil
+}
+
+// startExec starts an exec instance and copies its output until it exits.
+// With stdin, the connection is upgraded to a raw stream so that input can
+// be sent; with tty, the terminal is put in raw mode and resized with the
+// exec.
+func (r *apiRuntime) startExec(ctx context.Context, execID string, stdin, tty bool) error {
+	body, err := json.Marshal(map[string]any{"Detach": false, "Tty": tty})
+	if err != nil {
+		return fmt.Errorf("encode exec start: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/exec/"+execID+"/start", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var output io.Reader
+	if stdin {
+		conn, err := r.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("start exec: %w", err)
+		}
+		defer conn.Close()
+		stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+		defer stop()
+		stream, err := upgrade(conn, req)
+		if err != nil {
+			return fmt.Errorf("start exec: %w", err)
+		}
+		if tty {
+			restore, err := r.setupTerminal(ctx, execID)
+			if err != nil {
+				return err
+			}
+			defer restore()
+		}
+		go func() {
+			_, _ = io.Copy(conn, r.stdin)
+			// Half-close the connection so that the command sees the end of
+			// its input, while its output is still read
+			if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+				_ = cw.CloseWrite()
+			}
+		}()
+		output = stream
+	} else {
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("start exec: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("start exec: %w", readAPIError(resp))
+		}
+		output = resp.Body
+	}
+
+	if tty {
+		if _, err := io.Copy(r.stdout, output); err != nil && !errors.Is(err, net.ErrClosed) {
+			return fmt.Errorf("read exec output: %w", err)
+		}
+		return nil
+	}
+	return demuxStream(output, r.stdout, r.stderr)
+}
+
+// upgrade sends req on conn and returns a reader of the raw stream once
+// the engine upgrades the connection. Unlike the body of an upgraded
+// response of http.Client, conn can then be half-closed.
+func upgrade(conn net.Conn, req *http.Request) (io.Reader, error) {
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	stream := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(stream, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return stream, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, readAPIError(resp)
+	}
+	return nil, errors.New("engine did not upgrade the connection")
+}
+
+// setupTerminal puts the terminal in raw mode and keeps the exec's TTY the
+// size of the terminal. The returned function restores the terminal.
+func (r *apiRuntime) setupTerminal(ctx context.Context, execID string) (func(), error) {
+	stdinFd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return nil, fmt.Errorf("set terminal raw mode: %w", err)
+	}
+
+	resize := func() {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil {
+			return
+		}
+		query := url.Values{"h": {strconv.Itoa(height)}, "w": {strconv.Itoa(width)}}
+		_ = r.doJSON(ctx, http.MethodPost, "/exec/"+execID+"/resize", query, nil, nil) //nolint:errcheck // best effort
+	}
+	resize()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				resize()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+		_ = term.Restore(stdinFd, oldState)
+	}, nil
+}
+
+// isTerminal reports whether r is a terminal.
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// demuxStream copies a multiplexed exec stream to stdout and stderr. Each
+// frame has an 8-byte header: the stream type, three zero bytes and the
+// big-endian payload size.
+func demuxStream(body io.Reader, stdout, stderr io.Writer) error {
+	reader := bufio.NewReader(body)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read exec output: %w", err)
+		}
+
+		out := stdout
+		if header[0] == 2 {
+			out = stderr
+		}
+		size := int64(header[4])<<24 | int64(header[5])<<16 | int64(header[6])<<8 | int64(header[7])
+		if _, err := io.CopyN(out, reader, size); err != nil {
+			return fmt.Errorf("read exec output: %w", err)
+		}
+	}
+}
+
+// Stop stops a running container gracefully.
+func (r *apiRuntime) Stop(ctx context.Context, id string) error {
+	slogger.L(ctx).Debug("stopping container", slog.String("id", id))
+	return r.lifecycle(ctx, "stop", id)
+}
+
+// Start starts a stopped container.
+func (r *apiRuntime) Start(ctx context.Context, id string) error {
+	slogger.L(ctx).Debug("starting container", slog.String("id", id))
+	return r.lifecycle(ctx, "start", id)
+}
+
+// lifecycle posts a stop or start request. The engine answers 304 Not
+// Modified if the container is already in the requested state.
+func (r *apiRuntime) lifecycle(ctx context.Context, action, id string) error {
+	err := r.doJSON(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/"+action, nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s container: %w", action, err)
+	}
+	return nil
+}
+
+// Remove deletes a container.
+func (r *apiRuntime) Remove(ctx context.Context, id string) error {
+	err := r.doJSON(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("remove container: %w", err)
+	}
+	return nil
+}
+
+// RepairOwnership changes the owner of everything under dir to user in a
+// helper container running as root.
+func (r *apiRuntime) RepairOwnership(ctx context.Context, dir string, user *UserMapping, image string) error {
+	owner := user.owner()
+	if r.binary == "podman" && os.Geteuid() != 0 {
+		// Root of a rootless user namespace is the host user
+		owner = "0:0"
+	}
+	spec := &apiContainerConfig{
+		Image:      image,
+		Entrypoint: []string{"chown"},
+		Cmd:        []string{"-R", owner, repairMount},
+		User:       "0:0",
+		HostConfig: apiHostConfig{Binds: []string{dir + ":" + repairMount}},
+	}
+
+	id, err := r.createContainer(ctx, "", spec)
+	if err != nil {
+		return fmt.Errorf("repair ownership of %s: %w", dir, err)
+	}
+	defer func() {
+		_ = r.Stop(ctx, id)   //nolint:errcheck // best-effort cleanup
+		_ = r.Remove(ctx, id) //nolint:errcheck // best-effort cleanup
+	}()
+	if err := r.Start(ctx, id); err != nil {
+		return fmt.Errorf("repair ownership of %s: %w", dir, err)
+	}
+
+	var result struct {
+		StatusCode int `json:"StatusCode"`
+	}
+	if err := r.doJSON(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/wait", nil, nil, &result); err != nil {
+		return fmt.Errorf("repair ownership of %s: %w", dir, err)
+	}
+	if result.StatusCode != 0 {
+		return fmt.Errorf("repair ownership of %s: %w", dir, &ExitError{Code: result.StatusCode})
+	}
+	return nil
+}
+
+// Get retrieves container information by ID or name.
+func (r *apiRuntime) Get(ctx context.Context, id string) (*Container, error) {
+	var info dockerInspect
+	err := r.doJSON(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, nil, &info)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inspect container: %w", err)
+	}
+	return info.toContainer(), nil
+}
+
+// apiListItem is an item of GET /containers/json.
+type apiListItem struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"`
+	Image   string   `json:"Image"`
+	State   string   `json:"State"`
+	Created int64    `json:"Created"`
+}
+
+// List returns all containers matching the filter.
+func (r *apiRuntime) List(ctx context.Context, filter ListFilter) ([]Container, error) {
+	query := url.Values{"all": {"true"}}
+	filterArgs := make(map[string][]string)
+	if filter.Name != "" {
+		filterArgs["name"] = []string{filter.Name}
+	}
+	if len(filter.Labels) > 0 {
+		filterArgs["label"] = labelPairs(filter.Labels)
+	}
+	if len(filterArgs) > 0 {
+		filters, err := json.Marshal(filterArgs)
+		if err != nil {
+			return nil, fmt.Errorf("encode filters: %w", err)
+		}
+		query.Set("filters", string(filters))
+	}
+
+	var items []apiListItem
+	if err := r.doJSON(ctx, http.MethodGet, "/containers/json", query, nil, &items); err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+
+	containers := make([]Container, 0, len(items))
+	for _, item := range items {
+		var name string
+		if len(item.Names) > 0 {
+			name = strings.TrimPrefix(item.Names[0], "/")
+		}
+		containers = append(containers, Container{
+			ID:        item.ID,
+			Name:      name,
+			Image:     item.Image,
+			Status:    parseContainerStatus(item.State),
+			CreatedAt: time.Unix(item.Created, 0),
+		})
+	}
+	return containers, nil
+}
+
+// Build builds an OCI image from a Dockerfile. The build context is sent
+// to the engine as a tar archive, and build output is written to
+// cfg.Output if it is not nil.
+func (r *apiRuntime) Build(ctx context.Context, cfg *BuildConfig) error {
+	query := url.Values{"t": {cfg.Tag}}
+	if cfg.Dockerfile != "" {
+		query.Set("dockerfile", cfg.Dockerfile)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeContextTar(pw, cfg.Context, cfg.Dockerfile))
+	}()
+	defer pr.Close()
+
+	resp, err := r.do(ctx, http.MethodPost, "/build", query, pr, "application/x-tar")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBuildFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if err := streamProgress(resp.Body, cfg.Output); err != nil {
+		return fmt.Errorf("%w: %w", ErrBuildFailed, err)
+	}
+	return nil
+}
+
+// Pull pulls an image, writing progress to out if it is not nil.
+func (r *apiRuntime) Pull(ctx context.Context, ref string, out io.Writer) error {
+	return r.pullImage(ctx, ref, out)
+}
+
+// apiImageItem is an item of GET /images/json.
+type apiImageItem struct {
+	ID       string   `json:"Id"`
+	RepoTags []string `json:"RepoTags"`
+	Size     int64    `json:"Size"`
+	Created  int64    `json:"Created"`
+}
+
+// ListImages returns the local images matching the filter.
+func (r *apiRuntime) ListImages(ctx context.Context, filter ImageFilter) ([]Image, error) {
+	var query url.Values
+	if filter.Reference != "" {
+		filters, err := json.Marshal(map[string][]string{"reference": {filter.Reference}})
+		if err != nil {
+			return nil, fmt.Errorf("encode filters: %w", err)
+		}
+		query = url.Values{"filters": {string(filters)}}
+	}
+
+	var items []apiImageItem
+	if err := r.doJSON(ctx, http.MethodGet, "/images/json", query, nil, &items); err != nil {
+		return nil, fmt.Errorf("list images: %w", err)
+	}
+
+	images := make([]Image, 0, len(items))
+	for _, item := range items {
+		tags := slices.DeleteFunc(item.RepoTags, func(tag string) bool { return tag == "<none>:<none>" })
+		if len(tags) == 0 {
+			continue // Dangling image
+		}
+		images = append(images, Image{
+			ID:        item.ID,
+			Tags:      tags,
+			Size:      item.Size,
+			CreatedAt: time.Unix(item.Created, 0),
+		})
+	}
+	return images, nil
+}
+
+// RemoveImage removes an image by reference or ID.
+func (r *apiRuntime) RemoveImage(ctx context.Context, ref string) error {
+	err := r.doJSON(ctx, http.MethodDelete, "/images/"+ref, nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return ErrImageNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("remove image %s: %w", ref, err)
+	}
+	return nil
+}
+
+// ExecCommand returns the command prefix for executing commands in a
+// container through the engine's CLI.
+func (r *apiRuntime) ExecCommand() []string {
+	return []string{r.binary, "exec"}
+}
+
+// doJSON sends a request with an optional JSON body and decodes the JSON
+// response into out if it is not nil.
+func (r *apiRuntime) doJSON(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := r.do(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends a request and returns an *APIError for error responses.
+func (r *apiRuntime) do(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	u := r.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connect to container engine: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, readAPIError(resp)
+	}
+	return resp, nil
+}
+
+// readAPIError reads the error message of an error response.
+func readAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10)) //nolint:errcheck // the status is reported regardless
+	var body struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Message != "" {
+		message = body.Message
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// writeContextTar writes the build context directory dir to w as a tar
+// archive, leaving out the paths its .dockerignore file matches. Like docker,
+// the Dockerfile and the .dockerignore file are always sent. Symlinks are
+// archived as links and not followed.
+func writeContextTar(w io.Writer, dir, dockerfile string) error {
+	ignore, err := readDockerignore(dir)
+	if err != nil {
+		return fmt.Errorf("archive build context: %w", err)
+	}
+	if ignore != nil {
+		if dockerfile == "" {
+			dockerfile = "Dockerfile"
+		}
+		ignore.keep(path.Clean(filepath.ToSlash(dockerfile)))
+		ignore.keep(dockerignoreFile)
+	}
+	if err := writeTar(w, dir, "", ignore); err != nil {
+		return fmt.Errorf("archive build context: %w", err)
+	}
+	return nil
+}
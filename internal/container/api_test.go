@@ -0,0 +1,976 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeContainer is a container of fakeEngine.
+type fakeContainer struct {
+	id      string
+	name    string
+	spec    apiContainerConfig
+	running bool
+}
+
+// fakeEngine is an in-process fake of the container engine API.
+type fakeEngine struct {
+	mu         sync.Mutex
+	images     map[string]bool
+	containers map[string]*fakeContainer // By ID and by name
+	execs      map[string][]string       // Command by exec ID
+	pulls      []string                  // fromImage:tag of each pull
+	buildFiles []string                  // Files of the last build context
+	waited     []apiContainerConfig      // Specs of containers waited for
+	volumes    map[string]bool           // In use by name
+	root       string                    // Directory holding the filesystems of all containers
+}
+
+// newFakeEngine starts a fake engine on a unix socket and returns it with
+// an API runtime connected to it.
+func newFakeEngine(t *testing.T) (*fakeEngine, *apiRuntime) {
+	t.Helper()
+	// Socket paths are limited in length, so t.TempDir() can be too long
+	dir, err := os.MkdirTemp("", "hjk-api")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "engine.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	engine := &fakeEngine{
+		images:     map[string]bool{"ubuntu:24.04": true},
+		containers: make(map[string]*fakeContainer),
+		execs:      make(map[string][]string),
+		volumes:    make(map[string]bool),
+		root:       t.TempDir(),
+	}
+	server := httptest.NewUnstartedServer(engine.handler())
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	rt, err := NewAPIRuntime(APIConfig{Host: "unix://" + socket, Binary: "docker"})
+	require.NoError(t, err)
+	return engine, rt.(*apiRuntime)
+}
+
+// add adds a container to the engine.
+func (e *fakeEngine) add(name string, running bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c := &fakeContainer{id: "id-" + name, name: name, running: running, spec: apiContainerConfig{Image: "ubuntu:24.04"}}
+	e.containers[c.id] = c
+	e.containers[name] = c
+}
+
+// hasLabels reports whether labels contains all KEY=VALUE pairs.
+func hasLabels(labels map[string]string, pairs []string) bool {
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+//nolint:gocognit // a fake engine handles many endpoints in one place
+func (e *fakeEngine) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /containers/create", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var spec apiContainerConfig
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if image, tag := splitImageRef(spec.Image); !e.images[image+":"+tag] {
+			writeAPIError(w, http.StatusNotFound, "No such image: "+spec.Image)
+			return
+		}
+		if _, ok := e.containers[name]; ok {
+			writeAPIError(w, http.StatusConflict, "Conflict. The container name is already in use")
+			return
+		}
+		c := &fakeContainer{id: "id-" + name, name: name, spec: spec}
+		e.containers[c.id] = c
+		e.containers[name] = c
+		writeAPIJSON(w, http.StatusCreated, map[string]string{"Id": c.id})
+	})
+
+	setRunning := func(running bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			c, ok := e.containers[r.PathValue("id")]
+			switch {
+			case !ok:
+				writeAPIError(w, http.StatusNotFound, "No such container")
+			case c.running == running:
+				w.WriteHeader(http.StatusNotModified)
+			default:
+				c.running = running
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}
+	}
+	mux.HandleFunc("POST /containers/{id}/start", setRunning(true))
+	mux.HandleFunc("POST /containers/{id}/stop", setRunning(false))
+
+	mux.HandleFunc("POST /containers/{id}/wait", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		c, ok := e.containers[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "No such container")
+			return
+		}
+		c.running = false
+		e.waited = append(e.waited, c.spec)
+		writeAPIJSON(w, http.StatusOK, map[string]int{"StatusCode": 0})
+	})
+
+	mux.HandleFunc("DELETE /containers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		c, ok := e.containers[r.PathValue("id")]
+		switch {
+		case !ok:
+			writeAPIError(w, http.StatusNotFound, "No such container")
+		case c.running:
+			writeAPIError(w, http.StatusConflict, "cannot remove a running container")
+		default:
+			delete(e.containers, c.id)
+			delete(e.containers, c.name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	state := func(c *fakeContainer) string {
+		if c.running {
+			return "running"
+		}
+		return "exited"
+	}
+	mux.HandleFunc("GET /containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		c, ok := e.containers[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "No such container")
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, map[string]any{
+			"Id":      c.id,
+			"Name":    "/" + c.name,
+			"Created": "2024-01-02T15:04:05.123456789Z",
+			"State":   map[string]string{"Status": state(c)},
+			"Config":  map[string]string{"Image": c.spec.Image},
+		})
+	})
+
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var filters map[string][]string
+		if f := r.URL.Query().Get("filters"); f != "" {
+			_ = json.Unmarshal([]byte(f), &filters)
+		}
+		items := []map[string]any{}
+		for key, c := range e.containers {
+			if key != c.id || (len(filters["name"]) > 0 && !strings.Contains(c.name, filters["name"][0])) {
+				continue
+			}
+			if !hasLabels(c.spec.Labels, filters["label"]) {
+				continue
+			}
+			items = append(items, map[string]any{
+				"Id": c.id, "Names": []string{"/" + c.name}, "Image": c.spec.Image, "State": state(c), "Created": 1704207845,
+			})
+		}
+		writeAPIJSON(w, http.StatusOK, items)
+	})
+
+	mux.HandleFunc("POST /images/create", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		ref := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+		e.pulls = append(e.pulls, ref)
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		if strings.HasPrefix(ref, "private/") {
+			_ = enc.Encode(map[string]string{"error": "pull access denied for " + ref})
+			return
+		}
+		_ = enc.Encode(map[string]string{"status": "Pulling from library/alpine", "id": "3.19"})
+		_ = enc.Encode(map[string]string{"status": "Downloading", "id": "abc123", "progress": "[==>   ] 1MB/3MB"})
+		_ = enc.Encode(map[string]string{"status": "Status: Downloaded newer image for " + ref})
+		e.images[ref] = true
+	})
+
+	mux.HandleFunc("GET /images/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var filters map[string][]string
+		if f := r.URL.Query().Get("filters"); f != "" {
+			_ = json.Unmarshal([]byte(f), &filters)
+		}
+		items := []apiImageItem{{ID: "sha256:dangling", RepoTags: []string{"<none>:<none>"}}}
+		for ref := range e.images {
+			if patterns := filters["reference"]; len(patterns) > 0 {
+				if ok, _ := path.Match(patterns[0], ref); !ok {
+					continue
+				}
+			}
+			items = append(items, apiImageItem{ID: "sha256:" + ref, RepoTags: []string{ref}, Size: 1000, Created: 1704207845})
+		}
+		writeAPIJSON(w, http.StatusOK, items)
+	})
+
+	mux.HandleFunc("DELETE /images/{name...}", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		name := r.PathValue("name")
+		if !e.images[name] {
+			writeAPIError(w, http.StatusNotFound, "No such image: "+name)
+			return
+		}
+		delete(e.images, name)
+		writeAPIJSON(w, http.StatusOK, []map[string]string{{"Untagged": name}})
+	})
+
+	mux.HandleFunc("GET /volumes", func(w http.ResponseWriter, _ *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var resp struct {
+			Volumes []volumeItem `json:"Volumes"`
+		}
+		for _, name := range slices.Sorted(maps.Keys(e.volumes)) {
+			resp.Volumes = append(resp.Volumes, volumeItem{Name: name, Mountpoint: "/var/lib/docker/volumes/" + name + "/_data"})
+		}
+		writeAPIJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("DELETE /volumes/{name}", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		name := r.PathValue("name")
+		inUse, ok := e.volumes[name]
+		switch {
+		case !ok:
+			writeAPIError(w, http.StatusNotFound, "get "+name+": no such volume")
+		case inUse:
+			writeAPIError(w, http.StatusConflict, "remove "+name+": volume is in use")
+		default:
+			delete(e.volumes, name)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	// Archive endpoints serve paths under the container's directory of root
+	archivePath := func(w http.ResponseWriter, r *http.Request) (string, bool) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		c, ok := e.containers[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "No such container")
+			return "", false
+		}
+		return filepath.Join(e.root, c.id, filepath.FromSlash(r.URL.Query().Get("path"))), true
+	}
+	mux.HandleFunc("HEAD /containers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := archivePath(w, r)
+		if !ok {
+			return
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		stat, _ := json.Marshal(map[string]any{"name": info.Name(), "mode": uint32(info.Mode())})
+		w.Header().Set(pathStatHeader, base64.StdEncoding.EncodeToString(stat))
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /containers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := archivePath(w, r)
+		if !ok {
+			return
+		}
+		if _, err := os.Stat(p); err != nil {
+			writeAPIError(w, http.StatusNotFound, "Could not find the file "+r.URL.Query().Get("path")+" in container")
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-tar")
+		_ = writeTar(w, p, filepath.Base(p), nil)
+	})
+	mux.HandleFunc("PUT /containers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := archivePath(w, r)
+		if !ok {
+			return
+		}
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			writeAPIError(w, http.StatusNotFound, "Could not find the file "+r.URL.Query().Get("path")+" in container")
+			return
+		}
+		if err := extractTar(r.Body, p, ""); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("POST /containers/{id}/exec", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		c, ok := e.containers[r.PathValue("id")]
+		if !ok {
+			writeAPIError(w, http.StatusNotFound, "No such container")
+			return
+		}
+		if !c.running {
+			writeAPIError(w, http.StatusConflict, "container is not running")
+			return
+		}
+		var body struct{ Cmd []string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		id := "exec-" + c.name
+		e.execs[id] = body.Cmd
+		writeAPIJSON(w, http.StatusCreated, map[string]string{"Id": id})
+	})
+
+	mux.HandleFunc("POST /exec/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "tcp" {
+			w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+			w.WriteHeader(http.StatusOK)
+			writeFrame(w, 1, "out\n")
+			writeFrame(w, 2, "err\n")
+			return
+		}
+
+		// Hijack the connection and echo the input once it ends
+		_, _ = io.Copy(io.Discard, r.Body)
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		_ = buf.Flush()
+		input, _ := io.ReadAll(buf)
+		writeFrame(conn, 1, "echo: "+string(input))
+	})
+
+	mux.HandleFunc("GET /exec/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		code := 0
+		if cmd := e.execs[r.PathValue("id")]; len(cmd) > 0 && cmd[0] == "false" {
+			code = 1
+		}
+		writeAPIJSON(w, http.StatusOK, map[string]any{"ExitCode": code, "Running": false})
+	})
+
+	mux.HandleFunc("POST /build", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.buildFiles = nil
+		tr := tar.NewReader(r.Body)
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				break
+			}
+			e.buildFiles = append(e.buildFiles, header.Name)
+		}
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		_ = enc.Encode(map[string]string{"stream": "Step 1/1 : FROM ubuntu\n"})
+		if r.URL.Query().Get("dockerfile") == "Broken.Dockerfile" {
+			_ = enc.Encode(map[string]string{"error": "unknown instruction: BROKEN"})
+		}
+	})
+
+	return mux
+}
+
+// writeFrame writes a frame of a multiplexed exec stream.
+func writeFrame(w io.Writer, stream byte, payload string) {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload))) //nolint:gosec // test payloads are small
+	_, _ = w.Write(header)
+	_, _ = io.WriteString(w, payload)
+}
+
+func TestNewAPIRuntime(t *testing.T) {
+	tests := []struct {
+		host    string
+		baseURL string
+		wantErr bool
+	}{
+		{host: "unix:///var/run/docker.sock", baseURL: "http://localhost"},
+		{host: "/run/podman/podman.sock", baseURL: "http://localhost"},
+		{host: "tcp://127.0.0.1:2375", baseURL: "http://127.0.0.1:2375"},
+		{host: "http://127.0.0.1:2375/", baseURL: "http://127.0.0.1:2375"},
+		{host: "ssh://user@host", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rt, err := NewAPIRuntime(APIConfig{Host: tt.host, Binary: "podman"})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.baseURL, rt.(*apiRuntime).baseURL)
+			assert.Equal(t, []string{"podman", "exec"}, rt.ExecCommand())
+		})
+	}
+}
+
+func TestDefaultAPIHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("CONTAINER_HOST", "")
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	assert.Equal(t, DefaultDockerSocket, DefaultAPIHost("docker"))
+	assert.Equal(t, DefaultPodmanSocket, DefaultAPIHost("podman"))
+
+	rootless := filepath.Join(runtimeDir, "podman", "podman.sock")
+	require.NoError(t, os.MkdirAll(filepath.Dir(rootless), 0o750))
+	require.NoError(t, os.WriteFile(rootless, nil, 0o600))
+	assert.Equal(t, rootless, DefaultAPIHost("podman"))
+
+	t.Setenv("DOCKER_HOST", "tcp://docker:2375")
+	t.Setenv("CONTAINER_HOST", "unix:///custom.sock")
+	assert.Equal(t, "tcp://docker:2375", DefaultAPIHost("docker"))
+	assert.Equal(t, "unix:///custom.sock", DefaultAPIHost("podman"))
+}
+
+func TestAPIRuntime_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates and starts container", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+
+		c, err := rt.Run(ctx, &RunConfig{
+			Name:   "hjk-test",
+			Image:  "ubuntu:24.04",
+			Mounts: []Mount{{Source: "/src", Target: "/workspace"}, {Source: "/cfg", Target: "/cfg", ReadOnly: true}},
+			Env:    []string{"A=1"},
+			Labels: map[string]string{LabelInstanceID: "abc"},
+			Flags:  []string{"--privileged", "--network", "host", "-e", "B=2", "--label=team=core", "--device=/dev/fuse"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "id-hjk-test", c.ID)
+		assert.Equal(t, StatusRunning, c.Status)
+
+		spec := engine.containers["hjk-test"].spec
+		assert.True(t, engine.containers["hjk-test"].running)
+		assert.Equal(t, []string{"sleep", "infinity"}, spec.Cmd)
+		assert.Equal(t, []string{"A=1", "B=2"}, spec.Env)
+		assert.Equal(t, map[string]string{LabelInstanceID: "abc", "team": "core"}, spec.Labels)
+		assert.Equal(t, []string{"/src:/workspace", "/cfg:/cfg:ro"}, spec.HostConfig.Binds)
+		assert.True(t, spec.HostConfig.Privileged)
+		assert.Equal(t, "host", spec.HostConfig.NetworkMode)
+		assert.Equal(t, []apiDevice{{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}}, spec.HostConfig.Devices)
+		assert.Empty(t, engine.pulls)
+	})
+
+	t.Run("mounts volumes and tmpfs", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+
+		_, err := rt.Run(ctx, &RunConfig{
+			Name:  "hjk-test",
+			Image: "ubuntu:24.04",
+			Mounts: []Mount{
+				{Type: MountBind, Source: "/src", Target: "/workspace"},
+				{Type: MountVolume, Source: "hjk-cache-npm", Target: "/root/.npm"},
+				{Type: MountTmpfs, Target: "/tmp"},
+			},
+		})
+		require.NoError(t, err)
+
+		spec := engine.containers["hjk-test"].spec
+		assert.Equal(t, []string{"/src:/workspace"}, spec.HostConfig.Binds)
+		assert.Equal(t, []apiMount{
+			{Type: "volume", Source: "hjk-cache-npm", Target: "/root/.npm"},
+			{Type: "tmpfs", Target: "/tmp"},
+		}, spec.HostConfig.Mounts)
+	})
+
+	t.Run("pulls missing image and streams progress", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+
+		var progress bytes.Buffer
+		_, err := rt.Run(ctx, &RunConfig{Name: "hjk-test", Image: "alpine", Stderr: &progress})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"alpine:latest"}, engine.pulls)
+		assert.Equal(t, "3.19: Pulling from library/alpine\n"+
+			"abc123: Downloading [==>   ] 1MB/3MB\n"+
+			"Status: Downloaded newer image for alpine:latest\n", progress.String())
+		assert.True(t, engine.containers["hjk-test"].running)
+	})
+
+	t.Run("returns pull errors", func(t *testing.T) {
+		_, rt := newFakeEngine(t)
+
+		_, err := rt.Run(ctx, &RunConfig{Name: "hjk-test", Image: "private/image:1.0"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pull access denied")
+	})
+
+	t.Run("returns ErrAlreadyExists for duplicate names", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		engine.add("hjk-test", false)
+
+		_, err := rt.Run(ctx, &RunConfig{Name: "hjk-test", Image: "ubuntu:24.04"})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("rejects unsupported flags", func(t *testing.T) {
+		_, rt := newFakeEngine(t)
+
+		_, err := rt.Run(ctx, &RunConfig{Name: "hjk-test", Image: "ubuntu:24.04", Flags: []string{"--systemd=always"}})
+		require.ErrorIs(t, err, ErrUnsupportedFlag)
+		assert.Contains(t, err.Error(), "--systemd=always")
+	})
+}
+
+func TestAPIRuntime_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	engine, rt := newFakeEngine(t)
+	engine.add("hjk-test", true)
+
+	require.NoError(t, rt.Stop(ctx, "hjk-test"))
+	require.NoError(t, rt.Stop(ctx, "hjk-test"), "stopping a stopped container is a no-op")
+
+	c, err := rt.Get(ctx, "hjk-test")
+	require.NoError(t, err)
+	assert.Equal(t, "id-hjk-test", c.ID)
+	assert.Equal(t, "hjk-test", c.Name)
+	assert.Equal(t, StatusStopped, c.Status)
+	assert.Equal(t, 2024, c.CreatedAt.Year())
+
+	require.NoError(t, rt.Start(ctx, "hjk-test"))
+	require.NoError(t, rt.Start(ctx, "hjk-test"), "starting a running container is a no-op")
+
+	var apiErr *APIError
+	require.ErrorAs(t, rt.Remove(ctx, "hjk-test"), &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+
+	require.NoError(t, rt.Stop(ctx, "hjk-test"))
+	require.NoError(t, rt.Remove(ctx, "hjk-test"))
+
+	_, err = rt.Get(ctx, "hjk-test")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, rt.Stop(ctx, "hjk-test"), ErrNotFound)
+	require.ErrorIs(t, rt.Start(ctx, "hjk-test"), ErrNotFound)
+	require.ErrorIs(t, rt.Remove(ctx, "hjk-test"), ErrNotFound)
+}
+
+func TestAPIRuntime_Run_User(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("docker runs as the user and adds passwd entry", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		rt.stdout, rt.stderr = io.Discard, io.Discard
+
+		_, err := rt.Run(ctx, &RunConfig{
+			Name:  "hjk-test",
+			Image: "ubuntu:24.04",
+			User:  &UserMapping{UID: 1000, GID: 1001, Name: "dev"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "1000:1001", engine.containers["hjk-test"].spec.User)
+		assert.Empty(t, engine.containers["hjk-test"].spec.HostConfig.UsernsMode)
+		assert.Equal(t, passwdCommand(&UserMapping{UID: 1000, GID: 1001, Name: "dev"}), engine.execs["exec-hjk-test"])
+	})
+
+	t.Run("podman maps the user namespace", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		rt.binary = "podman"
+
+		_, err := rt.Run(ctx, &RunConfig{
+			Name:  "hjk-test",
+			Image: "ubuntu:24.04",
+			User:  &UserMapping{UID: 1000, GID: 1001, Name: "dev"},
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, engine.containers["hjk-test"].spec.User)
+		assert.Equal(t, "keep-id", engine.containers["hjk-test"].spec.HostConfig.UsernsMode)
+		assert.Empty(t, engine.execs)
+	})
+
+	t.Run("explicit user flag wins", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+
+		_, err := rt.Run(ctx, &RunConfig{
+			Name:  "hjk-test",
+			Image: "ubuntu:24.04",
+			Flags: []string{"--user", "root"},
+			User:  &UserMapping{UID: 1000, GID: 1001, Name: "dev"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "root", engine.containers["hjk-test"].spec.User)
+		assert.Empty(t, engine.execs)
+	})
+}
+
+func TestAPIRuntime_RepairOwnership(t *testing.T) {
+	engine, rt := newFakeEngine(t)
+
+	err := rt.RepairOwnership(context.Background(), "/worktree", &UserMapping{UID: 1000, GID: 1001}, "ubuntu:24.04")
+	require.NoError(t, err)
+
+	require.Len(t, engine.waited, 1)
+	spec := engine.waited[0]
+	assert.Equal(t, []string{"chown"}, spec.Entrypoint)
+	assert.Equal(t, []string{"-R", "1000:1001", "/hjk-repair"}, spec.Cmd)
+	assert.Equal(t, "0:0", spec.User)
+	assert.Equal(t, []string{"/worktree:/hjk-repair"}, spec.HostConfig.Binds)
+	// The helper container is removed
+	assert.Empty(t, engine.containers)
+}
+
+func TestAPIRuntime_List(t *testing.T) {
+	ctx := context.Background()
+	engine, rt := newFakeEngine(t)
+	engine.add("hjk-a", true)
+	engine.add("hjk-b", false)
+	engine.add("other", true)
+
+	containers, err := rt.List(ctx, ListFilter{Name: "hjk-"})
+	require.NoError(t, err)
+
+	require.Len(t, containers, 2)
+	byName := map[string]Container{}
+	for _, c := range containers {
+		byName[c.Name] = c
+	}
+	assert.Equal(t, StatusRunning, byName["hjk-a"].Status)
+	assert.Equal(t, StatusStopped, byName["hjk-b"].Status)
+	assert.Equal(t, int64(1704207845), byName["hjk-a"].CreatedAt.Unix())
+
+	engine.containers["hjk-b"].spec.Labels = map[string]string{LabelBranch: "main", LabelRepoID: "repo"}
+	containers, err = rt.List(ctx, ListFilter{Labels: map[string]string{LabelBranch: "main", LabelRepoID: "repo"}})
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, "hjk-b", containers[0].Name)
+
+	all, err := rt.List(ctx, ListFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestAPIRuntime_Exec(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("demultiplexes output", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		engine.add("hjk-test", true)
+		var stdout, stderr bytes.Buffer
+		rt.stdout, rt.stderr = &stdout, &stderr
+
+		require.NoError(t, rt.Exec(ctx, "hjk-test", &ExecConfig{Command: []string{"ls"}}))
+
+		assert.Equal(t, "out\n", stdout.String())
+		assert.Equal(t, "err\n", stderr.String())
+		assert.Equal(t, []string{"ls"}, engine.execs["exec-hjk-test"])
+	})
+
+	t.Run("attaches stdin when interactive", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		engine.add("hjk-test", true)
+		var stdout bytes.Buffer
+		rt.stdin, rt.stdout = strings.NewReader("hello\n"), &stdout
+
+		require.NoError(t, rt.Exec(ctx, "hjk-test", &ExecConfig{Command: []string{"cat"}, Interactive: true}))
+
+		assert.Equal(t, "echo: hello\n", stdout.String())
+	})
+
+	t.Run("returns exit code", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		engine.add("hjk-test", true)
+		rt.stdout, rt.stderr = io.Discard, io.Discard
+
+		err := rt.Exec(ctx, "hjk-test", &ExecConfig{Command: []string{"false"}})
+		var exitErr *ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.Code)
+	})
+
+	t.Run("returns typed errors", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+		engine.add("stopped", false)
+
+		require.ErrorIs(t, rt.Exec(ctx, "missing", &ExecConfig{Command: []string{"ls"}}), ErrNotFound)
+		require.ErrorIs(t, rt.Exec(ctx, "stopped", &ExecConfig{Command: []string{"ls"}}), ErrNotRunning)
+	})
+}
+
+func TestAPIRuntime_Build(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM ubuntu\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Broken.Dockerfile"), []byte("BROKEN\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "main.go"), nil, 0o600))
+
+	t.Run("sends build context", func(t *testing.T) {
+		engine, rt := newFakeEngine(t)
+
+		require.NoError(t, rt.Build(ctx, &BuildConfig{Context: dir, Tag: "hjk:test"}))
+		assert.ElementsMatch(t, []string{"Broken.Dockerfile", "Dockerfile", "src", "src/main.go"}, engine.buildFiles)
+	})
+
+	t.Run("leaves out paths matched by .dockerignore", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"Dockerfile", "src/main.go", "node_modules/pkg/index.js", "docs/a.md", "docs/keep.md"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".dockerignore"),
+			[]byte("# build artifacts\nnode_modules\ndocs\n!docs/keep.md\nDockerfile\n.dockerignore\n"), 0o600))
+		engine, rt := newFakeEngine(t)
+
+		require.NoError(t, rt.Build(ctx, &BuildConfig{Context: dir, Tag: "hjk:test"}))
+		assert.ElementsMatch(t, []string{".dockerignore", "Dockerfile", "docs/keep.md", "src", "src/main.go"}, engine.buildFiles)
+	})
+
+	t.Run("writes build output", func(t *testing.T) {
+		_, rt := newFakeEngine(t)
+		var out strings.Builder
+
+		require.NoError(t, rt.Build(ctx, &BuildConfig{Context: dir, Tag: "hjk:test", Output: &out}))
+		assert.Equal(t, "Step 1/1 : FROM ubuntu\n", out.String())
+	})
+
+	t.Run("returns ErrBuildFailed", func(t *testing.T) {
+		_, rt := newFakeEngine(t)
+
+		err := rt.Build(ctx, &BuildConfig{Context: dir, Dockerfile: "Broken.Dockerfile", Tag: "hjk:test"})
+		require.ErrorIs(t, err, ErrBuildFailed)
+		assert.Contains(t, err.Error(), "unknown instruction")
+	})
+}
+
+func TestAPIRuntime_Images(t *testing.T) {
+	ctx := context.Background()
+	engine, rt := newFakeEngine(t)
+	engine.images["headjack/app:0123456789ab"] = true
+
+	var out strings.Builder
+	require.NoError(t, rt.Pull(ctx, "alpine:3.19", &out))
+	assert.Contains(t, out.String(), "abc123: Downloading")
+
+	images, err := rt.ListImages(ctx, ImageFilter{Reference: "headjack/*"})
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, []string{"headjack/app:0123456789ab"}, images[0].Tags)
+	assert.Equal(t, int64(1000), images[0].Size)
+
+	all, err := rt.ListImages(ctx, ImageFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 3, "dangling images are omitted")
+
+	require.NoError(t, rt.RemoveImage(ctx, "headjack/app:0123456789ab"))
+	assert.False(t, engine.images["headjack/app:0123456789ab"])
+	require.ErrorIs(t, rt.RemoveImage(ctx, "headjack/app:0123456789ab"), ErrImageNotFound)
+}
+
+func TestAPIRuntime_Volumes(t *testing.T) {
+	ctx := context.Background()
+	engine, rt := newFakeEngine(t)
+	engine.volumes["hjk-cache-npm"] = false
+	engine.volumes["hjk-cache-gomod"] = true
+	engine.volumes["other-hjk-cache-x"] = false
+
+	volumes, err := rt.ListVolumes(ctx, "hjk-cache-")
+	require.NoError(t, err)
+	assert.Equal(t, []Volume{
+		{Name: "hjk-cache-gomod", Mountpoint: "/var/lib/docker/volumes/hjk-cache-gomod/_data"},
+		{Name: "hjk-cache-npm", Mountpoint: "/var/lib/docker/volumes/hjk-cache-npm/_data"},
+	}, volumes)
+
+	require.NoError(t, rt.RemoveVolume(ctx, "hjk-cache-npm"))
+	require.ErrorIs(t, rt.RemoveVolume(ctx, "hjk-cache-npm"), ErrVolumeNotFound)
+	require.ErrorIs(t, rt.RemoveVolume(ctx, "hjk-cache-gomod"), ErrVolumeInUse)
+}
+
+func TestAPIRuntime_Copy(t *testing.T) {
+	ctx := context.Background()
+	engine, rt := newFakeEngine(t)
+	engine.add("dev", true)
+	root := filepath.Join(engine.root, "id-dev")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "workspace"), 0o755))
+
+	host := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(host, "dist", "js"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(host, "dist", "js", "app.js"), []byte("app"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(host, "notes.txt"), []byte("notes"), 0o644))
+
+	t.Run("copies into an existing directory", func(t *testing.T) {
+		require.NoError(t, rt.CopyTo(ctx, "dev", filepath.Join(host, "dist"), "/workspace"))
+
+		data, err := os.ReadFile(filepath.Join(root, "workspace", "dist", "js", "app.js"))
+		require.NoError(t, err)
+		assert.Equal(t, "app", string(data))
+	})
+
+	t.Run("copies as a new path", func(t *testing.T) {
+		require.NoError(t, rt.CopyTo(ctx, "dev", filepath.Join(host, "notes.txt"), "/workspace/README"))
+
+		data, err := os.ReadFile(filepath.Join(root, "workspace", "README"))
+		require.NoError(t, err)
+		assert.Equal(t, "notes", string(data))
+	})
+
+	t.Run("copies from the container", func(t *testing.T) {
+		out := t.TempDir()
+		require.NoError(t, rt.CopyFrom(ctx, "dev", "/workspace/dist", out))
+		require.NoError(t, rt.CopyFrom(ctx, "dev", "/workspace/README", filepath.Join(out, "notes.md")))
+
+		data, err := os.ReadFile(filepath.Join(out, "dist", "js", "app.js"))
+		require.NoError(t, err)
+		assert.Equal(t, "app", string(data))
+		data, err = os.ReadFile(filepath.Join(out, "notes.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "notes", string(data))
+	})
+
+	t.Run("returns ErrNotFound for a missing container", func(t *testing.T) {
+		require.ErrorIs(t, rt.CopyTo(ctx, "missing", filepath.Join(host, "notes.txt"), "/workspace"), ErrNotFound)
+		require.ErrorIs(t, rt.CopyFrom(ctx, "missing", "/workspace", host), ErrNotFound)
+	})
+
+	t.Run("reports a missing path", func(t *testing.T) {
+		err := rt.CopyFrom(ctx, "dev", "/missing", host)
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNotFound)
+		assert.Contains(t, err.Error(), "Could not find the file /missing")
+	})
+}
+
+func TestAPIRuntime_ConnectionError(t *testing.T) {
+	rt, err := NewAPIRuntime(APIConfig{Host: filepath.Join(t.TempDir(), "missing.sock"), Binary: "docker"})
+	require.NoError(t, err)
+
+	_, err = rt.Get(context.Background(), "hjk-test")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+	assert.Contains(t, err.Error(), "connect to container engine")
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct {
+		ref, name, tag string
+	}{
+		{ref: "alpine", name: "alpine", tag: "latest"},
+		{ref: "alpine:3.19", name: "alpine", tag: "3.19"},
+		{ref: "localhost:5000/team/app", name: "localhost:5000/team/app", tag: "latest"},
+		{ref: "localhost:5000/team/app:v1", name: "localhost:5000/team/app", tag: "v1"},
+		{ref: "alpine@sha256:abc", name: "alpine@sha256:abc", tag: ""},
+	}
+	for _, tt := range tests {
+		name, tag := splitImageRef(tt.ref)
+		assert.Equal(t, tt.name, name, tt.ref)
+		assert.Equal(t, tt.tag, tag, tt.ref)
+	}
+}
+
+func TestApplyFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		check   func(t *testing.T, spec *apiContainerConfig)
+		wantErr error
+	}{
+		{
+			name:  "aliases and value forms",
+			flags: []string{"-u", "1000", "--net=none", "-w", "/src", "--cap-add", "SYS_PTRACE", "--init", "--tmpfs", "/tmp:size=64m"},
+			check: func(t *testing.T, spec *apiContainerConfig) {
+				t.Helper()
+				assert.Equal(t, "1000", spec.User)
+				assert.Equal(t, "none", spec.HostConfig.NetworkMode)
+				assert.Equal(t, "/src", spec.WorkingDir)
+				assert.Equal(t, []string{"SYS_PTRACE"}, spec.HostConfig.CapAdd)
+				require.NotNil(t, spec.HostConfig.Init)
+				assert.True(t, *spec.HostConfig.Init)
+				assert.Equal(t, map[string]string{"/tmp": "size=64m"}, spec.HostConfig.Tmpfs)
+			},
+		},
+		{
+			name:  "explicit boolean",
+			flags: []string{"--privileged=false"},
+			check: func(t *testing.T, spec *apiContainerConfig) {
+				t.Helper()
+				assert.False(t, spec.HostConfig.Privileged)
+			},
+		},
+		{name: "unknown flag", flags: []string{"--pids-limit", "100"}, wantErr: ErrUnsupportedFlag},
+		{name: "positional argument", flags: []string{"ubuntu"}, wantErr: ErrUnsupportedFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &apiContainerConfig{}
+			err := applyFlags(spec, tt.flags)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, spec)
+		})
+	}
+
+	t.Run("missing value", func(t *testing.T) {
+		require.Error(t, applyFlags(&apiContainerConfig{}, []string{"--network"}))
+	})
+}
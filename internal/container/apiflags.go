@@ -0,0 +1,197 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedFlag is returned by the API runtime for a runtime flag it
+// cannot translate into a container create request.
+var ErrUnsupportedFlag = errors.New("runtime flag not supported by the API runtime")
+
+// apiFlag applies a `docker run` flag to a container create request.
+type apiFlag struct {
+	isBool bool
+	apply  func(spec *apiContainerConfig, value string) error
+}
+
+// apiFlags are the `docker run` flags the API runtime understands, by name
+// without leading dashes.
+var apiFlags = map[string]apiFlag{
+	"privileged": {isBool: true, apply: func(spec *apiContainerConfig, value string) error {
+		v, err := strconv.ParseBool(value)
+		spec.HostConfig.Privileged = v
+		return err
+	}},
+	"init": {isBool: true, apply: func(spec *apiContainerConfig, value string) error {
+		v, err := strconv.ParseBool(value)
+		spec.HostConfig.Init = &v
+		return err
+	}},
+	"network": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.NetworkMode = value
+		return nil
+	}},
+	"user": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.User = value
+		return nil
+	}},
+	"workdir": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.WorkingDir = value
+		return nil
+	}},
+	"hostname": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.Hostname = value
+		return nil
+	}},
+	"env": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.Env = append(spec.Env, value)
+		return nil
+	}},
+	"volume": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.Binds = append(spec.HostConfig.Binds, value)
+		return nil
+	}},
+	"label": {apply: func(spec *apiContainerConfig, value string) error {
+		key, val, _ := strings.Cut(value, "=")
+		if spec.Labels == nil {
+			spec.Labels = make(map[string]string)
+		}
+		spec.Labels[key] = val
+		return nil
+	}},
+	"cap-add": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.CapAdd = append(spec.HostConfig.CapAdd, value)
+		return nil
+	}},
+	"cap-drop": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.CapDrop = append(spec.HostConfig.CapDrop, value)
+		return nil
+	}},
+	"security-opt": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.SecurityOpt = append(spec.HostConfig.SecurityOpt, value)
+		return nil
+	}},
+	"add-host": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.ExtraHosts = append(spec.HostConfig.ExtraHosts, value)
+		return nil
+	}},
+	"device": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.Devices = append(spec.HostConfig.Devices, parseDevice(value))
+		return nil
+	}},
+	"userns": {apply: func(spec *apiContainerConfig, value string) error {
+		spec.HostConfig.UsernsMode = value
+		return nil
+	}},
+	"tmpfs": {apply: func(spec *apiContainerConfig, value string) error {
+		path, opts, _ := strings.Cut(value, ":")
+		if spec.HostConfig.Tmpfs == nil {
+			spec.HostConfig.Tmpfs = make(map[string]string)
+		}
+		spec.HostConfig.Tmpfs[path] = opts
+		return nil
+	}},
+}
+
+// apiFlagAliases maps short and alternative flag names to names in apiFlags.
+var apiFlagAliases = map[string]string{
+	"net": "network",
+	"u":   "user",
+	"w":   "workdir",
+	"h":   "hostname",
+	"e":   "env",
+	"v":   "volume",
+	"l":   "label",
+}
+
+// buildContainerConfig translates a RunConfig into a container create
+// request. Flags are applied after mounts and environment, as with the CLI.
+func buildContainerConfig(cfg *RunConfig) (*apiContainerConfig, error) {
+	initCmd := cfg.Init
+	if initCmd == "" {
+		initCmd = "sleep infinity"
+	}
+
+	spec := &apiContainerConfig{
+		Image: cfg.Image,
+		Cmd:   strings.Fields(initCmd),
+		Env:   append([]string(nil), cfg.Env...),
+	}
+	for _, m := range cfg.Mounts {
+		if m.Type == MountVolume || m.Type == MountTmpfs {
+			spec.HostConfig.Mounts = append(spec.HostConfig.Mounts, apiMount{
+				Type:     string(m.Type),
+				Source:   m.Source,
+				Target:   m.Target,
+				ReadOnly: m.ReadOnly,
+			})
+			continue
+		}
+		bind := m.Source + ":" + m.Target
+		if m.ReadOnly {
+			bind += ":ro"
+		}
+		spec.HostConfig.Binds = append(spec.HostConfig.Binds, bind)
+	}
+	if len(cfg.Labels) > 0 {
+		spec.Labels = maps.Clone(cfg.Labels)
+	}
+
+	if err := applyFlags(spec, cfg.Flags); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// applyFlags applies `docker run` flags in the forms --name=value,
+// --name value and, for boolean flags, --name.
+func applyFlags(spec *apiContainerConfig, flags []string) error {
+	for i := 0; i < len(flags); i++ {
+		arg := flags[i]
+		if !strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("%w: unexpected argument %q", ErrUnsupportedFlag, arg)
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if alias, ok := apiFlagAliases[name]; ok {
+			name = alias
+		}
+		flag, ok := apiFlags[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedFlag, arg)
+		}
+
+		if !hasValue {
+			if flag.isBool {
+				value = "true"
+			} else {
+				if i+1 >= len(flags) {
+					return fmt.Errorf("flag %s needs a value", arg)
+				}
+				i++
+				value = flags[i]
+			}
+		}
+		if err := flag.apply(spec, value); err != nil {
+			return fmt.Errorf("invalid value for flag %s: %w", arg, err)
+		}
+	}
+	return nil
+}
+
+// parseDevice parses a --device value: host[:container[:permissions]].
+func parseDevice(value string) apiDevice {
+	parts := strings.SplitN(value, ":", 3)
+	device := apiDevice{PathOnHost: parts[0], PathInContainer: parts[0], CgroupPermissions: "rwm"}
+	if len(parts) > 1 && parts[1] != "" {
+		device.PathInContainer = parts[1]
+	}
+	if len(parts) > 2 {
+		device.CgroupPermissions = parts[2]
+	}
+	return device
+}
@@ -1,11 +1,15 @@
 package container
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -16,7 +20,8 @@ import (
 	"github.com/jmgilman/headjack/internal/slogger"
 )
 
-// containerParser handles runtime-specific JSON parsing for container inspect and list operations.
+// containerParser handles runtime-specific JSON parsing for container inspect and list operations,
+// and for image listings.
 // Each runtime implementation provides its own parser to handle the different JSON formats
 // returned by each container CLI.
 type containerParser interface {
@@ -24,6 +29,8 @@ type containerParser interface {
 	parseInspect(data []byte) (*Container, error)
 	// parseList parses the JSON output of the list command.
 	parseList(data []byte) ([]Container, error)
+	// parseImages parses the JSON output of the images command.
+	parseImages(data []byte) ([]Image, error)
 }
 
 // baseRuntime provides shared functionality for container runtimes.
@@ -35,6 +42,13 @@ type baseRuntime struct {
 	execCommand []string
 	listArgs    []string        // e.g., ["ps", "-a"] for Docker/Podman
 	parser      containerParser // Runtime-specific JSON parser
+
+	// userArgs returns the run flags that map the container user to a host user.
+	userArgs func(u *UserMapping) []string
+	// addPasswd adds a passwd entry for a mapped user after the container starts.
+	addPasswd bool
+	// repairArgs returns the command that returns files in dir to a host user.
+	repairArgs func(dir string, u *UserMapping, image string) []string
 }
 
 // cliError formats an error from a container CLI, including stderr if available.
@@ -53,7 +67,11 @@ func (r *baseRuntime) Run(ctx context.Context, cfg *RunConfig) (*Container, erro
 	log := slogger.L(ctx)
 	log.Debug("running container", slog.String("name", cfg.Name), slog.String("image", cfg.Image))
 
-	args := buildRunArgs(cfg)
+	var userFlags []string
+	if cfg.User != nil && r.userArgs != nil {
+		userFlags = r.userArgs(cfg.User)
+	}
+	args := buildRunArgs(cfg, userFlags)
 
 	result, err := r.exec.Run(ctx, &exec.RunOptions{
 		Name:   r.binaryName,
@@ -72,6 +90,14 @@ func (r *baseRuntime) Run(ctx context.Context, cfg *RunConfig) (*Container, erro
 	containerID := strings.TrimSpace(string(result.Stdout))
 	log.Debug("container started", slog.String("id", containerID))
 
+	if cfg.User != nil && r.addPasswd {
+		// Best-effort: images without a shell still run, only without a
+		// name for the mapped user
+		if err := r.addPasswdEntry(ctx, containerID, cfg.User); err != nil {
+			log.Warn("failed to add passwd entry", slog.String("id", containerID), slog.String("error", err.Error()))
+		}
+	}
+
 	return &Container{
 		ID:        containerID,
 		Name:      cfg.Name,
@@ -217,6 +243,9 @@ func (r *baseRuntime) List(ctx context.Context, filter ListFilter) ([]Container,
 	if filter.Name != "" {
 		args = append(args, "--filter", "name="+filter.Name)
 	}
+	for _, label := range labelPairs(filter.Labels) {
+		args = append(args, "--filter", "label="+label)
+	}
 
 	result, err := r.exec.Run(ctx, &exec.RunOptions{
 		Name: r.binaryName,
@@ -239,12 +268,22 @@ func (r *baseRuntime) List(ctx context.Context, filter ListFilter) ([]Container,
 func (r *baseRuntime) Build(ctx context.Context, cfg *BuildConfig) error {
 	args := buildBuildArgs(cfg)
 
-	result, err := r.exec.Run(ctx, &exec.RunOptions{
+	opts := &exec.RunOptions{
 		Name: r.binaryName,
 		Args: args,
-	})
+	}
+	// Streamed output is also kept for the error message
+	var stderr bytes.Buffer
+	if cfg.Output != nil {
+		opts.Stdout = cfg.Output
+		opts.Stderr = io.MultiWriter(cfg.Output, &stderr)
+	}
+	result, err := r.exec.Run(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("%w: %s", ErrBuildFailed, strings.TrimSpace(string(result.Stderr)))
+		if cfg.Output == nil {
+			stderr.Write(result.Stderr)
+		}
+		return fmt.Errorf("%w: %s", ErrBuildFailed, strings.TrimSpace(stderr.String()))
 	}
 
 	return nil
@@ -296,14 +335,26 @@ func (r *baseRuntime) ExecCommand() []string {
 	return r.execCommand
 }
 
-// buildRunArgs constructs the common container run arguments.
-func buildRunArgs(cfg *RunConfig) []string {
+// buildRunArgs constructs the common container run arguments. User flags
+// come before cfg.Flags so that explicit flags take precedence.
+func buildRunArgs(cfg *RunConfig, userFlags []string) []string {
 	args := []string{"run", "--detach", "--name", cfg.Name}
 
+	args = append(args, userFlags...)
+
 	// Add merged flags (image labels + config, merged by manager)
 	args = append(args, cfg.Flags...)
 
+	for _, label := range labelPairs(cfg.Labels) {
+		args = append(args, "--label", label)
+	}
+
 	for _, m := range cfg.Mounts {
+		if m.Type == MountTmpfs {
+			args = append(args, "--tmpfs", m.Target)
+			continue
+		}
+		// Bind mounts and named volumes share the -v syntax
 		mountSpec := fmt.Sprintf("%s:%s", m.Source, m.Target)
 		if m.ReadOnly {
 			mountSpec += ":ro"
@@ -327,6 +378,17 @@ func buildRunArgs(cfg *RunConfig) []string {
 	return args
 }
 
+// labelPairs returns labels as KEY=VALUE pairs sorted by key, so generated
+// arguments are stable.
+func labelPairs(labels map[string]string) []string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
 // buildExecArgs constructs the common container exec arguments.
 func buildExecArgs(id string, cfg *ExecConfig) []string {
 	args := []string{"exec"}
@@ -358,7 +420,12 @@ func buildBuildArgs(cfg *BuildConfig) []string {
 	args := []string{"build", "-t", cfg.Tag}
 
 	if cfg.Dockerfile != "" {
-		args = append(args, "-f", cfg.Dockerfile)
+		// The CLIs resolve -f against the working directory, not the context
+		dockerfile := cfg.Dockerfile
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(cfg.Context, dockerfile)
+		}
+		args = append(args, "-f", dockerfile)
 	}
 
 	args = append(args, cfg.Context)
@@ -379,7 +446,9 @@ func parseContainerStatus(cliStatus string) Status {
 
 // isAlreadyExistsError checks if stderr indicates container already exists.
 func isAlreadyExistsError(stderr string) bool {
-	return strings.Contains(stderr, "already in use") || strings.Contains(stderr, "already exists")
+	return strings.Contains(stderr, "already in use") ||
+		strings.Contains(stderr, "already exists") ||
+		strings.Contains(stderr, "already used") // nerdctl
 }
 
 // isNotFoundError checks if stderr indicates container not found.
@@ -10,11 +10,24 @@ import (
 
 // Sentinel errors for container operations.
 var (
-	ErrNotFound      = errors.New("container not found")
-	ErrNotRunning    = errors.New("container not running")
-	ErrAlreadyExists = errors.New("container already exists")
-	ErrBuildFailed   = errors.New("image build failed")
-	ErrNoParser      = errors.New("runtime has no parser configured")
+	ErrNotFound       = errors.New("container not found")
+	ErrNotRunning     = errors.New("container not running")
+	ErrAlreadyExists  = errors.New("container already exists")
+	ErrBuildFailed    = errors.New("image build failed")
+	ErrNoParser       = errors.New("runtime has no parser configured")
+	ErrImageNotFound  = errors.New("image not found")
+	ErrVolumeNotFound = errors.New("volume not found")
+	ErrVolumeInUse    = errors.New("volume in use")
+)
+
+// Labels set on containers created by headjack. They identify the instance
+// a container belongs to without relying on the container name.
+const (
+	LabelInstanceID = "io.headjack.instance-id"
+	LabelRepoID     = "io.headjack.repo-id"
+	LabelBranch     = "io.headjack.branch"
+	LabelVersion    = "io.headjack.version"
+	LabelCreatedBy  = "io.headjack.created-by"
 )
 
 // Status represents the container state.
@@ -48,23 +61,44 @@ type Container struct {
 	RemoteWorkspaceFolder string // Working directory inside container (e.g., "/workspaces/project")
 }
 
-// Mount defines a host-to-container volume mount.
+// MountType is the kind of a mount.
+type MountType string
+
+// MountType constants.
+const (
+	MountBind   MountType = "bind"   // Host directory
+	MountVolume MountType = "volume" // Named volume, created on demand by the runtime
+	MountTmpfs  MountType = "tmpfs"  // In-memory filesystem
+)
+
+// Mount defines a volume mount.
 type Mount struct {
-	Source   string // Host path
-	Target   string // Container path
+	Type     MountType // Mount type (empty = bind)
+	Source   string    // Host path for bind mounts, volume name for volumes, unused for tmpfs
+	Target   string    // Container path
 	ReadOnly bool
 }
 
+// UserMapping identifies a host user that a container runs as, so files it
+// writes to bind mounts stay owned by that user.
+type UserMapping struct {
+	UID  int    // Host user ID
+	GID  int    // Host group ID
+	Name string // User name for the passwd entry inside the container
+}
+
 // RunConfig configures container creation.
 type RunConfig struct {
-	Name            string    // Container name (required)
-	Image           string    // OCI image reference (required for vanilla runtimes)
-	Mounts          []Mount   // Volume mounts
-	Env             []string  // Environment variables (KEY=VALUE format)
-	Init            string    // Init command to run as PID 1 (default: "sleep infinity")
-	Flags           []string  // Runtime-specific flags (e.g., "--systemd=always" for Podman)
-	WorkspaceFolder string    // For devcontainer: path to folder with devcontainer.json
-	Stderr          io.Writer // Optional: stream stderr during container creation (for progress output)
+	Name            string            // Container name (required)
+	Image           string            // OCI image reference (required for vanilla runtimes)
+	Mounts          []Mount           // Volume mounts
+	Env             []string          // Environment variables (KEY=VALUE format)
+	Init            string            // Init command to run as PID 1 (default: "sleep infinity")
+	Flags           []string          // Runtime-specific flags (e.g., "--systemd=always" for Podman)
+	Labels          map[string]string // Container labels (KEY -> VALUE)
+	User            *UserMapping      // Run as this host user (nil = image default)
+	WorkspaceFolder string            // For devcontainer: path to folder with devcontainer.json
+	Stderr          io.Writer         // Optional: stream stderr during container creation (for progress output)
 }
 
 // ExecConfig configures command execution in a container.
@@ -78,14 +112,16 @@ type ExecConfig struct {
 
 // BuildConfig configures image builds.
 type BuildConfig struct {
-	Context    string // Build context directory
-	Dockerfile string // Path to Dockerfile (relative to context)
-	Tag        string // Image tag to apply (required)
+	Context    string    // Build context directory
+	Dockerfile string    // Path to Dockerfile (relative to context)
+	Tag        string    // Image tag to apply (required)
+	Output     io.Writer // Build output is written here if not nil
 }
 
 // ListFilter filters container listings.
 type ListFilter struct {
-	Name string // Filter by name prefix (empty = all)
+	Name   string            // Filter by name prefix (empty = all)
+	Labels map[string]string // Filter by labels (all must match)
 }
 
 // Runtime provides container lifecycle operations.
@@ -130,8 +166,27 @@ type Runtime interface {
 	// Returns ErrBuildFailed if the build fails.
 	Build(ctx context.Context, cfg *BuildConfig) error
 
+	// CopyTo copies the file or directory src on the host to dst in a
+	// container, like `docker cp src id:dst`: if dst is an existing
+	// directory, src is copied into it, otherwise it is copied as dst.
+	// Returns ErrNotFound if container doesn't exist.
+	CopyTo(ctx context.Context, id, src, dst string) error
+
+	// CopyFrom copies the file or directory src in a container to dst on
+	// the host, with the same rules as CopyTo.
+	// Returns ErrNotFound if container doesn't exist.
+	CopyFrom(ctx context.Context, id, src, dst string) error
+
 	// ExecCommand returns the command prefix for executing commands in a container.
 	// This is used by the multiplexer to build commands that run inside containers.
 	// For example, Docker returns ["docker", "exec"] and Podman returns ["podman", "exec"].
 	ExecCommand() []string
 }
+
+// OwnershipRepairer is implemented by runtimes that can return files created
+// by containers in a host directory to a host user.
+type OwnershipRepairer interface {
+	// RepairOwnership changes the owner of everything under dir to user. The
+	// image may be used for a short-lived helper container.
+	RepairOwnership(ctx context.Context, dir string, user *UserMapping, image string) error
+}
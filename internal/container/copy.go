@@ -0,0 +1,366 @@
+package container
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// pathStatHeader carries the stat of a container path in archive responses,
+// as base64 encoded JSON.
+const pathStatHeader = "X-Docker-Container-Path-Stat"
+
+// isContainerNotFoundError checks if cp stderr indicates the container
+// doesn't exist, as opposed to one of the copied paths.
+func isContainerNotFoundError(stderr string) bool {
+	normalized := strings.ToLower(stderr)
+	return strings.Contains(normalized, "no such container") ||
+		strings.Contains(normalized, "no container with")
+}
+
+// CopyTo copies src on the host to dst in a container with the CLI's cp command.
+func (r *baseRuntime) CopyTo(ctx context.Context, id, src, dst string) error {
+	return r.copy(ctx, src, id+":"+dst)
+}
+
+// CopyFrom copies src in a container to dst on the host with the CLI's cp command.
+func (r *baseRuntime) CopyFrom(ctx context.Context, id, src, dst string) error {
+	return r.copy(ctx, id+":"+src, dst)
+}
+
+// copy runs `cp src dst`, where one of the paths is prefixed with a
+// container ID.
+func (r *baseRuntime) copy(ctx context.Context, src, dst string) error {
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: []string{"cp", src, dst},
+	})
+	if err != nil {
+		if isContainerNotFoundError(string(result.Stderr)) {
+			return ErrNotFound
+		}
+		return cliError("copy "+src+" to "+dst, result, err)
+	}
+	return nil
+}
+
+// CopyTo copies src on the host to dst in a container by uploading a tar
+// archive of it.
+func (r *apiRuntime) CopyTo(ctx context.Context, id, src, dst string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(src); err != nil {
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+
+	// Like docker cp, copy into an existing directory, or else as dst
+	dir, name := dst, filepath.Base(src)
+	isDir, err := r.isContainerDir(ctx, id, dst)
+	if err != nil {
+		return err
+	}
+	if !isDir && !strings.HasSuffix(dst, "/") {
+		dir, name = path.Dir(dst), path.Base(dst)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, src, name, nil))
+	}()
+	defer pr.Close()
+
+	resp, err := r.do(ctx, http.MethodPut, "/containers/"+url.PathEscape(id)+"/archive",
+		url.Values{"path": {dir}}, pr, "application/x-tar")
+	if err != nil {
+		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// CopyFrom copies src in a container to dst on the host by downloading a
+// tar archive of it.
+func (r *apiRuntime) CopyFrom(ctx context.Context, id, src, dst string) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+
+	resp, err := r.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/archive",
+		url.Values{"path": {src}}, nil, "")
+	if err != nil {
+		return fmt.Errorf("copy %s from container: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	// Like docker cp, copy into an existing directory, or else as dst
+	dir, rename := dst, ""
+	if info, statErr := os.Stat(dst); statErr != nil || !info.IsDir() {
+		dir, rename = filepath.Dir(dst), filepath.Base(dst)
+	}
+	if err := extractTar(resp.Body, dir, rename); err != nil {
+		return fmt.Errorf("copy %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
+
+// isContainerDir reports whether p is an existing directory in a container.
+func (r *apiRuntime) isContainerDir(ctx context.Context, id, p string) (bool, error) {
+	resp, err := r.do(ctx, http.MethodHead, "/containers/"+url.PathEscape(id)+"/archive",
+		url.Values{"path": {p}}, nil, "")
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stat %s: %w", p, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := base64.StdEncoding.DecodeString(resp.Header.Get(pathStatHeader))
+	if err != nil {
+		return false, fmt.Errorf("stat %s: decode %s: %w", p, pathStatHeader, err)
+	}
+	var stat struct {
+		Mode uint32 `json:"mode"`
+	}
+	if err := json.Unmarshal(data, &stat); err != nil {
+		return false, fmt.Errorf("stat %s: decode %s: %w", p, pathStatHeader, err)
+	}
+	return os.FileMode(stat.Mode).IsDir(), nil
+}
+
+// writeTar writes the file or directory root to w as a tar archive, with
+// root archived as name. If name is empty, root itself is left out and the
+// entries of the directory are archived at the top level. Symlinks are
+// archived as links and not followed. Paths that ignore matches, relative
+// to root, are left out.
+func writeTar(w io.Writer, root, name string, ignore *dockerignore) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			slashRel := filepath.ToSlash(rel)
+			if info.IsDir() && ignore.skipDir(slashRel) {
+				return filepath.SkipDir
+			}
+			if ignore.ignored(slashRel) {
+				return nil
+			}
+		}
+		switch {
+		case rel == "." && name == "":
+			return nil
+		case rel == ".":
+			rel = name
+		case name != "":
+			rel = filepath.Join(name, rel)
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		//nolint:gosec // G304: path is within the tree chosen by the user
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts a tar archive of a single file or directory into dir,
+// renaming the archived root to rename if it is not empty. Directories,
+// regular files, symlinks and hard links are extracted with their
+// modification times; other entries are an error.
+//
+// Entries are written through an os.Root, so that symlinks in the archive
+// can't redirect later entries outside dir.
+func extractTar(r io.Reader, dir, rename string) error {
+	type extractedDir struct {
+		path   string
+		header *tar.Header
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gosec // G301: like docker cp
+		return fmt.Errorf("create directory: %w", err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return fmt.Errorf("open destination: %w", err)
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(r)
+	var dirs []extractedDir
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		target, err := entryTarget(header.Name, rename)
+		if err != nil {
+			return err
+		}
+		linkTarget := ""
+		if header.Typeflag == tar.TypeLink {
+			if linkTarget, err = entryTarget(header.Linkname, rename); err != nil {
+				return err
+			}
+		}
+		if err := extractEntry(root, tr, header, target, linkTarget); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeDir {
+			dirs = append(dirs, extractedDir{path: target, header: header})
+		}
+	}
+
+	// Extracting entries changes the times of their directories, so those
+	// are restored last, innermost first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := dirs[i]
+		if err := root.Chtimes(d.path, accessTime(d.header), d.header.ModTime); err != nil {
+			return fmt.Errorf("set times of %s: %w", d.path, err)
+		}
+	}
+	return nil
+}
+
+// entryTarget returns the path, relative to the destination, of the
+// archived path name, renaming the archived root to rename if it is not
+// empty.
+func entryTarget(name, rename string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(name, "/"))
+	if rename != "" {
+		_, rest, nested := strings.Cut(clean, "/")
+		clean = rename
+		if nested {
+			clean += "/" + rest
+		}
+	}
+	if !filepath.IsLocal(clean) {
+		return "", fmt.Errorf("archive entry %q is outside the destination", name)
+	}
+	return filepath.FromSlash(clean), nil
+}
+
+// extractEntry writes the archive entry header to target in root. linkTarget
+// is the path in root a hard link points to.
+func extractEntry(root *os.Root, tr *tar.Reader, header *tar.Header, target, linkTarget string) error {
+	mode := os.FileMode(header.Mode).Perm()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := root.MkdirAll(target, mode|0o700); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+	case tar.TypeReg:
+		if err := root.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		f, err := root.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			return fmt.Errorf("create file: %w", err)
+		}
+		_, copyErr := io.Copy(f, tr) //nolint:gosec // G110: the archive is not compressed
+		if err := f.Close(); err != nil && copyErr == nil {
+			copyErr = err
+		}
+		if copyErr != nil {
+			return fmt.Errorf("write %s: %w", target, copyErr)
+		}
+		if err := root.Chtimes(target, accessTime(header), header.ModTime); err != nil {
+			return fmt.Errorf("set times of %s: %w", target, err)
+		}
+	case tar.TypeSymlink:
+		_ = root.Remove(target) //nolint:errcheck // replaced if it exists
+		if err := root.Symlink(header.Linkname, target); err != nil {
+			return fmt.Errorf("create symlink: %w", err)
+		}
+		if err := symlinkTimes(root, target, header); err != nil {
+			return fmt.Errorf("set times of %s: %w", target, err)
+		}
+	case tar.TypeLink:
+		// The link shares the inode, and so the times, of its target
+		_ = root.Remove(target) //nolint:errcheck // replaced if it exists
+		if err := root.Link(linkTarget, target); err != nil {
+			return fmt.Errorf("create hard link: %w", err)
+		}
+	default:
+		return fmt.Errorf("archive entry %q has unsupported type %q", header.Name, header.Typeflag)
+	}
+	return nil
+}
+
+// symlinkTimes sets the times of the symlink target in root to those of
+// header, without following it. os.Root has no Lchtimes, so the times are
+// set relative to the symlink's directory, opened through root.
+func symlinkTimes(root *os.Root, target string, header *tar.Header) error {
+	parent, err := root.Open(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	defer parent.Close()
+	times := []unix.Timespec{
+		unix.NsecToTimespec(accessTime(header).UnixNano()),
+		unix.NsecToTimespec(header.ModTime.UnixNano()),
+	}
+	return unix.UtimesNanoAt(int(parent.Fd()), filepath.Base(target), times, unix.AT_SYMLINK_NOFOLLOW) //nolint:gosec // G115: file descriptors fit in an int
+}
+
+// accessTime returns the access time of an archive entry, which defaults to
+// its modification time.
+func accessTime(header *tar.Header) time.Time {
+	if header.AccessTime.IsZero() {
+		return header.ModTime
+	}
+	return header.AccessTime
+}
@@ -0,0 +1,240 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+func TestBaseRuntime_Copy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("copies to the container", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker", opts.Name)
+				assert.Equal(t, []string{"cp", "./dist", "abc123:/workspace"}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		require.NoError(t, runtime.CopyTo(ctx, "abc123", "./dist", "/workspace"))
+	})
+
+	t.Run("copies from the container", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "podman", opts.Name)
+				assert.Equal(t, []string{"cp", "abc123:/workspace/out.log", "."}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		runtime := NewPodmanRuntime(mockExec, PodmanConfig{})
+		require.NoError(t, runtime.CopyFrom(ctx, "abc123", "/workspace/out.log", "."))
+	})
+
+	tests := []struct {
+		name    string
+		stderr  string
+		wantErr error
+	}{
+		{name: "docker missing container", stderr: "Error response from daemon: No such container: abc123", wantErr: ErrNotFound},
+		{name: "podman missing container", stderr: `Error: no container with name or ID "abc123" found: no such container`, wantErr: ErrNotFound},
+		{name: "missing path", stderr: "Error response from daemon: Could not find the file /missing in container abc123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+					return &exec.Result{Stderr: []byte(tt.stderr), ExitCode: 1}, errors.New("exit code 1")
+				},
+			}
+
+			runtime := NewDockerRuntime(mockExec, DockerConfig{})
+			err := runtime.CopyFrom(ctx, "abc123", "/missing", ".")
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NotErrorIs(t, err, ErrNotFound)
+				assert.Contains(t, err.Error(), "Could not find the file")
+			}
+		})
+	}
+}
+
+func TestWriteTar(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("a"), 0o600))
+	require.NoError(t, os.Symlink("sub/a.txt", filepath.Join(src, "link")))
+
+	t.Run("round trips with the root renamed", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTar(&buf, src, "src", nil))
+
+		dst := t.TempDir()
+		require.NoError(t, extractTar(&buf, dst, "copy"))
+
+		data, err := os.ReadFile(filepath.Join(dst, "copy", "sub", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+		info, err := os.Stat(filepath.Join(dst, "copy", "sub", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		link, err := os.Readlink(filepath.Join(dst, "copy", "link"))
+		require.NoError(t, err)
+		assert.Equal(t, "sub/a.txt", link)
+	})
+
+	t.Run("archives a single file", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeTar(&buf, filepath.Join(src, "sub", "a.txt"), "b.txt", nil))
+
+		dst := t.TempDir()
+		require.NoError(t, extractTar(&buf, dst, ""))
+
+		data, err := os.ReadFile(filepath.Join(dst, "b.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+	})
+}
+
+func TestExtractTar_RejectsEscapingEntries(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0o644}))
+	require.NoError(t, tw.Close())
+
+	dst := t.TempDir()
+	err := extractTar(&buf, filepath.Join(dst, "out"), "")
+
+	require.ErrorContains(t, err, "outside the destination")
+	assert.NoFileExists(t, filepath.Join(dst, "evil"))
+}
+
+func TestExtractTar(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive := func(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range headers {
+			require.NoError(t, tw.WriteHeader(h))
+			if h.Size > 0 {
+				_, err := tw.Write([]byte("data"))
+				require.NoError(t, err)
+			}
+		}
+		require.NoError(t, tw.Close())
+		return &buf
+	}
+
+	t.Run("restores modification times", func(t *testing.T) {
+		buf := archive(t,
+			&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: modTime},
+			&tar.Header{Name: "src/a.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4, ModTime: modTime},
+			&tar.Header{Name: "src/link", Typeflag: tar.TypeSymlink, Linkname: "a.txt", ModTime: modTime},
+		)
+
+		dst := t.TempDir()
+		require.NoError(t, extractTar(buf, dst, ""))
+
+		for _, name := range []string{"src", "src/a.txt", "src/link"} {
+			info, err := os.Lstat(filepath.Join(dst, name))
+			require.NoError(t, err)
+			assert.True(t, modTime.Equal(info.ModTime()), name)
+		}
+	})
+
+	t.Run("extracts hard links", func(t *testing.T) {
+		buf := archive(t,
+			&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755},
+			&tar.Header{Name: "src/a.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4},
+			&tar.Header{Name: "src/b.txt", Typeflag: tar.TypeLink, Linkname: "src/a.txt"},
+		)
+
+		dst := t.TempDir()
+		require.NoError(t, extractTar(buf, dst, "renamed"))
+
+		a, err := os.Stat(filepath.Join(dst, "renamed", "a.txt"))
+		require.NoError(t, err)
+		b, err := os.Stat(filepath.Join(dst, "renamed", "b.txt"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(a, b))
+	})
+
+	t.Run("rejects hard links outside the destination", func(t *testing.T) {
+		buf := archive(t, &tar.Header{Name: "b.txt", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd"})
+
+		err := extractTar(buf, t.TempDir(), "")
+
+		require.ErrorContains(t, err, "outside the destination")
+	})
+
+	t.Run("rejects unsupported entries", func(t *testing.T) {
+		buf := archive(t, &tar.Header{Name: "fifo", Typeflag: tar.TypeFifo, Mode: 0o644})
+
+		err := extractTar(buf, t.TempDir(), "")
+
+		require.ErrorContains(t, err, "unsupported type")
+	})
+
+	t.Run("does not write through extracted symlinks", func(t *testing.T) {
+		outside := t.TempDir()
+		buf := archive(t,
+			&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755},
+			&tar.Header{Name: "src/x", Typeflag: tar.TypeSymlink, Linkname: outside},
+			&tar.Header{Name: "src/x/authorized_keys", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4},
+		)
+
+		err := extractTar(buf, t.TempDir(), "")
+
+		require.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(outside, "authorized_keys"))
+	})
+
+	t.Run("does not write through relative symlinks", func(t *testing.T) {
+		dst := t.TempDir()
+		buf := archive(t,
+			&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755},
+			&tar.Header{Name: "src/x", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+			&tar.Header{Name: "src/x/evil", Typeflag: tar.TypeReg, Mode: 0o644, Size: 4},
+		)
+
+		err := extractTar(buf, filepath.Join(dst, "out"), "")
+
+		require.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dst, "evil"))
+	})
+
+	t.Run("rejects hard links through extracted symlinks", func(t *testing.T) {
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o600))
+		buf := archive(t,
+			&tar.Header{Name: "src/", Typeflag: tar.TypeDir, Mode: 0o755},
+			&tar.Header{Name: "src/x", Typeflag: tar.TypeSymlink, Linkname: outside},
+			&tar.Header{Name: "src/y", Typeflag: tar.TypeLink, Linkname: "src/x/secret"},
+		)
+
+		dst := t.TempDir()
+		err := extractTar(buf, dst, "")
+
+		require.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dst, "src", "y"))
+	})
+}
@@ -35,6 +35,9 @@ func NewDockerRuntime(e exec.Executor, cfg DockerConfig) Runtime {
 			execCommand: []string{"docker", "exec"},
 			listArgs:    []string{"ps", "-a"},
 			parser:      parser,
+			userArgs:    dockerUserArgs,
+			addPasswd:   true,
+			repairArgs:  dockerRepairArgs,
 		},
 		config: cfg,
 	}
@@ -143,3 +146,8 @@ func (p *dockerParser) parseList(data []byte) ([]Container, error) {
 
 	return containers, nil
 }
+
+// parseImages parses the JSON output of `docker images --format json`.
+func (p *dockerParser) parseImages(data []byte) ([]Image, error) {
+	return parseDockerImages(data)
+}
@@ -3,6 +3,8 @@ package container
 import (
 	"context"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -194,6 +196,31 @@ func TestDockerRuntime_Run(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("includes volume and tmpfs mounts", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Subset(t, opts.Args, []string{"-v", "hjk-cache-npm:/root/.npm", "--tmpfs", "/tmp"})
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Mounts: []Mount{
+				{Type: MountVolume, Source: "hjk-cache-npm", Target: "/root/.npm"},
+				{Type: MountTmpfs, Target: "/tmp"},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
 	t.Run("includes environment variables", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
@@ -217,6 +244,83 @@ func TestDockerRuntime_Run(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("includes labels sorted by key", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"run", "--detach", "--name", "test",
+					"--label", "io.headjack.branch=main",
+					"--label", "io.headjack.instance-id=abc",
+					"ubuntu", "sleep", "infinity",
+				}, opts.Args)
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Labels: map[string]string{
+				LabelInstanceID: "abc",
+				LabelBranch:     "main",
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("runs as mapped user and adds passwd entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == "exec" {
+					assert.Equal(t, []string{"exec", "-u", "0", "abc123"}, opts.Args[:4])
+					assert.Equal(t, []string{"1000", "1001", "dev"}, opts.Args[len(opts.Args)-3:])
+					return &exec.Result{}, nil
+				}
+				assert.Equal(t, []string{"run", "--detach", "--name", "test", "--user", "1000:1001", "--privileged"}, opts.Args[:7])
+
+				return &exec.Result{Stdout: []byte("abc123\n")}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Flags: []string{"--privileged"},
+			User:  &UserMapping{UID: 1000, GID: 1001, Name: "dev"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, mockExec.RunCalls(), 2)
+	})
+
+	t.Run("ignores passwd entry failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == "exec" {
+					return &exec.Result{Stderr: []byte("sh: not found")}, errors.New("exit code 127")
+				}
+				return &exec.Result{Stdout: []byte("abc123\n")}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		c, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "distroless",
+			User:  &UserMapping{UID: 1000, GID: 1000, Name: "dev"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", c.ID)
+	})
+
 	t.Run("returns ErrAlreadyExists when container exists", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
@@ -672,6 +776,28 @@ func TestDockerRuntime_List(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("includes label filters", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"ps", "-a", "--format", "json",
+					"--filter", "label=io.headjack.branch=main",
+					"--filter", "label=io.headjack.repo-id=repo",
+				}, opts.Args)
+
+				return &exec.Result{Stdout: []byte("")}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		_, err := runtime.List(ctx, ListFilter{Labels: map[string]string{
+			LabelRepoID: "repo",
+			LabelBranch: "main",
+		}})
+
+		require.NoError(t, err)
+	})
+
 	t.Run("uses ps -a for listing", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
@@ -691,6 +817,47 @@ func TestDockerRuntime_List(t *testing.T) {
 	})
 }
 
+func TestDockerRuntime_RepairOwnership(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("chowns in a helper container", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker", opts.Name)
+				assert.Equal(t, []string{
+					"run", "--rm", "--user", "0:0", "--entrypoint", "chown",
+					"-v", "/worktree:/hjk-repair",
+					"myimage:latest", "-R", "1000:1000", "/hjk-repair",
+				}, opts.Args)
+
+				return &exec.Result{}, nil
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		repairer, ok := runtime.(OwnershipRepairer)
+		require.True(t, ok)
+
+		err := repairer.RepairOwnership(ctx, "/worktree", &UserMapping{UID: 1000, GID: 1000}, "myimage:latest")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns stderr on failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte("Unable to find image")}, errors.New("exit code 125")
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		err := runtime.(OwnershipRepairer).RepairOwnership(ctx, "/worktree", &UserMapping{UID: 1000, GID: 1000}, "gone")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Unable to find image")
+	})
+}
+
 func TestDockerRuntime_Build(t *testing.T) {
 	ctx := context.Background()
 
@@ -720,7 +887,7 @@ func TestDockerRuntime_Build(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
 				assert.Contains(t, opts.Args, "-f")
-				assert.Contains(t, opts.Args, "custom.Dockerfile")
+				assert.Contains(t, opts.Args, "/build/context/custom.Dockerfile")
 
 				return &exec.Result{ExitCode: 0}, nil
 			},
@@ -755,6 +922,84 @@ func TestDockerRuntime_Build(t *testing.T) {
 		require.ErrorIs(t, err, ErrBuildFailed)
 		assert.Contains(t, err.Error(), "missing base image")
 	})
+
+	t.Run("streams build output", func(t *testing.T) {
+		var out strings.Builder
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, &out, opts.Stdout)
+				_, _ = io.WriteString(opts.Stderr, "build error: missing base image")
+				return &exec.Result{ExitCode: 1}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewDockerRuntime(mockExec, DockerConfig{})
+		err := runtime.Build(ctx, &BuildConfig{
+			Context: "/build/context",
+			Tag:     "myimage:latest",
+			Output:  &out,
+		})
+
+		require.ErrorIs(t, err, ErrBuildFailed)
+		assert.Contains(t, err.Error(), "missing base image")
+		assert.Equal(t, "build error: missing base image", out.String())
+	})
+}
+
+func TestDockerRuntime_Images(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pulls image", func(t *testing.T) {
+		var out strings.Builder
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"pull", "alpine:3.19"}, opts.Args)
+				assert.Equal(t, &out, opts.Stdout)
+				return &exec.Result{}, nil
+			},
+		}
+
+		images := NewDockerRuntime(mockExec, DockerConfig{}).(ImageManager)
+		require.NoError(t, images.Pull(ctx, "alpine:3.19", &out))
+	})
+
+	t.Run("lists images", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"images", "--format", "json", "--filter", "reference=headjack/*"}, opts.Args)
+				return &exec.Result{Stdout: []byte(
+					`{"ID":"abc123","Repository":"headjack/app","Tag":"0123456789ab","Size":"77.9MB","CreatedAt":"2024-01-02 15:04:05 +0000 UTC"}
+{"ID":"abc123","Repository":"headjack/app","Tag":"latest","Size":"77.9MB","CreatedAt":"2024-01-02 15:04:05 +0000 UTC"}
+{"ID":"def456","Repository":"<none>","Tag":"<none>","Size":"1kB","CreatedAt":"2024-01-02 15:04:05 +0000 UTC"}
+`)}, nil
+			},
+		}
+
+		images := NewDockerRuntime(mockExec, DockerConfig{}).(ImageManager)
+		list, err := images.ListImages(ctx, ImageFilter{Reference: "headjack/*"})
+
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "abc123", list[0].ID)
+		assert.Equal(t, []string{"headjack/app:0123456789ab", "headjack/app:latest"}, list[0].Tags)
+		assert.Equal(t, int64(77_900_000), list[0].Size)
+		assert.Equal(t, 2024, list[0].CreatedAt.Year())
+	})
+
+	t.Run("returns ErrImageNotFound when removing missing image", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"rmi", "headjack/app:gone"}, opts.Args)
+				return &exec.Result{
+					Stderr:   []byte("Error response from daemon: No such image: headjack/app:gone"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		images := NewDockerRuntime(mockExec, DockerConfig{}).(ImageManager)
+		assert.ErrorIs(t, images.RemoveImage(ctx, "headjack/app:gone"), ErrImageNotFound)
+	})
 }
 
 func TestDockerRuntime_ExecCommand(t *testing.T) {
@@ -0,0 +1,170 @@
+package container
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// dockerignoreFile is the file in a build context listing paths that are
+// left out of the context.
+const dockerignoreFile = ".dockerignore"
+
+// ignorePattern is a pattern of a .dockerignore file.
+type ignorePattern struct {
+	re        *regexp.Regexp
+	exception bool // The pattern started with !, re-including matches
+}
+
+// dockerignore matches paths of a build context against the patterns of its
+// .dockerignore file. Like docker, the last matching pattern decides, and a
+// pattern matching a directory also matches everything in it.
+type dockerignore struct {
+	patterns      []ignorePattern
+	hasExceptions bool
+}
+
+// readDockerignore reads the .dockerignore file of the build context dir.
+// It returns nil if there is none.
+func readDockerignore(dir string) (*dockerignore, error) {
+	f, err := os.Open(filepath.Join(dir, dockerignoreFile)) //nolint:gosec // G304: file of the build context
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil //nolint:nilnil // no file means nothing is ignored
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", dockerignoreFile, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", dockerignoreFile, err)
+	}
+	ignore, err := parseDockerignore(lines)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", dockerignoreFile, err)
+	}
+	return ignore, nil
+}
+
+// parseDockerignore parses the lines of a .dockerignore file. Empty lines
+// and lines starting with # are skipped.
+func parseDockerignore(lines []string) (*dockerignore, error) {
+	ignore := &dockerignore{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		exception := strings.HasPrefix(line, "!")
+		if exception {
+			line = strings.TrimSpace(line[1:])
+		}
+		// Patterns are relative to the context, with or without a leading /
+		pattern := strings.TrimPrefix(path.Clean(filepath.ToSlash(line)), "/")
+		re, err := patternRegexp(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", line, err)
+		}
+		ignore.patterns = append(ignore.patterns, ignorePattern{re: re, exception: exception})
+		ignore.hasExceptions = ignore.hasExceptions || exception
+	}
+	return ignore, nil
+}
+
+// patternRegexp converts a .dockerignore pattern to a regular expression.
+// It follows filepath.Match, except that ** matches any number of
+// directories.
+func patternRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "\\" + class
+			} else if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// ignored reports whether the context path rel, with / separators, is
+// left out of the build context. A nil dockerignore ignores nothing.
+func (d *dockerignore) ignored(rel string) bool {
+	if d == nil {
+		return false
+	}
+	ignored := false
+	for _, p := range d.patterns {
+		if p.matches(rel) {
+			ignored = !p.exception
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the pattern matches rel or one of its parent
+// directories.
+func (p *ignorePattern) matches(rel string) bool {
+	for {
+		if p.re.MatchString(rel) {
+			return true
+		}
+		i := strings.LastIndexByte(rel, '/')
+		if i < 0 {
+			return false
+		}
+		rel = rel[:i]
+	}
+}
+
+// skipDir reports whether the directory rel and everything in it is left
+// out of the build context. Ignored directories are still walked if
+// exceptions could re-include some of their contents.
+func (d *dockerignore) skipDir(rel string) bool {
+	return d.ignored(rel) && !d.hasExceptions
+}
+
+// keep re-includes the context path rel even if a pattern matches it.
+func (d *dockerignore) keep(rel string) {
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(rel) + "$")
+	d.patterns = append(d.patterns, ignorePattern{re: re, exception: true})
+	d.hasExceptions = d.hasExceptions || strings.Contains(rel, "/")
+}
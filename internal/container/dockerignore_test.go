@@ -0,0 +1,53 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerignore(t *testing.T) {
+	ignore, err := parseDockerignore([]string{
+		"# comment",
+		"",
+		"*.log",
+		"/build",
+		"**/node_modules",
+		"docs/*.md",
+		"!docs/README.md",
+		"tmp?",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"app.log", true},
+		{"src/app.log", false},
+		{"build", true},
+		{"build/out/app", true},
+		{"src/build", false},
+		{"node_modules/pkg/index.js", true},
+		{"web/node_modules", true},
+		{"docs/guide.md", true},
+		{"docs/README.md", false},
+		{"docs/api/ref.md", false},
+		{"tmp1", true},
+		{"tmp", false},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, ignore.ignored(tt.path))
+		})
+	}
+
+	assert.False(t, (*dockerignore)(nil).ignored("app.log"), "nil ignores nothing")
+}
+
+func TestDockerignore_InvalidPattern(t *testing.T) {
+	_, err := parseDockerignore([]string{"[abc"})
+	require.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/progress"
+)
+
+// ImageRepository is the repository namespace of images built by headjack.
+// Prebuilt images are tagged "headjack/<name>:<content hash>".
+const ImageRepository = "headjack"
+
+// imageHashLength is the number of hex digits of the content hash in tags.
+const imageHashLength = 12
+
+// Image holds local image metadata.
+type Image struct {
+	ID        string
+	Tags      []string // Repository:tag references (e.g., "headjack/app:0123456789ab")
+	Size      int64    // Size in bytes
+	CreatedAt time.Time
+}
+
+// ImageFilter filters image listings.
+type ImageFilter struct {
+	Reference string // Filter by reference pattern, e.g. "headjack/*" (empty = all)
+}
+
+// ImageManager is implemented by runtimes that manage local images.
+type ImageManager interface {
+	// Pull pulls an image, writing progress to out if it is not nil.
+	Pull(ctx context.Context, ref string, out io.Writer) error
+
+	// ListImages returns the local images matching the filter.
+	// Images without tags are omitted.
+	ListImages(ctx context.Context, filter ImageFilter) ([]Image, error)
+
+	// RemoveImage removes an image by reference or ID.
+	// Returns ErrImageNotFound if the image doesn't exist.
+	RemoveImage(ctx context.Context, ref string) error
+}
+
+// ImageTag returns the tag of a headjack image named name, built from the
+// given files: "headjack/<name>:<hash>", where hash covers the contents of
+// the files. Empty file paths are skipped.
+func ImageTag(name string, files ...string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("hash image source: %w", err)
+		}
+		// Length-prefix each file so that content can't shift between files
+		_, _ = fmt.Fprintf(h, "%d\n", len(data)) //nolint:errcheck // hash writes never fail
+		h.Write(data)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))[:imageHashLength]
+	return ImageRepository + "/" + imageName(name) + ":" + sum, nil
+}
+
+// imageName converts name into a valid image repository path component:
+// lowercase letters, digits, and separators.
+func imageName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
+	name = strings.Trim(name, ".-_")
+	if name == "" {
+		return "image"
+	}
+	return name
+}
+
+// UnreferencedImages returns the images that none of the containers use,
+// matching containers by image ID or tag.
+func UnreferencedImages(images []Image, containers []Container) []Image {
+	used := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		used[c.Image] = true
+	}
+
+	var unused []Image
+	for _, img := range images {
+		if used[img.ID] || used[ShortImageID(img.ID)] || slices.ContainsFunc(img.Tags, func(tag string) bool {
+			return used[tag] || used[strings.TrimPrefix(tag, "localhost/")]
+		}) {
+			continue
+		}
+		unused = append(unused, img)
+	}
+	return unused
+}
+
+// ShortImageID returns the 12 digit form of an image ID.
+func ShortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > imageHashLength {
+		return id[:imageHashLength]
+	}
+	return id
+}
+
+// Pull pulls an image, writing progress to out if it is not nil.
+func (r *baseRuntime) Pull(ctx context.Context, ref string, out io.Writer) error {
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name:   r.binaryName,
+		Args:   []string{"pull", ref},
+		Stdout: out,
+		Stderr: out,
+	})
+	if err != nil {
+		return cliError("pull image "+ref, result, err)
+	}
+	return nil
+}
+
+// ListImages returns the local images matching the filter.
+func (r *baseRuntime) ListImages(ctx context.Context, filter ImageFilter) ([]Image, error) {
+	if r.parser == nil {
+		return nil, ErrNoParser
+	}
+
+	args := []string{"images", "--format", "json"}
+	if filter.Reference != "" {
+		args = append(args, "--filter", "reference="+filter.Reference)
+	}
+
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: args,
+	})
+	if err != nil {
+		return nil, cliError("list images", result, err)
+	}
+
+	stdout := strings.TrimSpace(string(result.Stdout))
+	if stdout == "" || stdout == "[]" {
+		return []Image{}, nil
+	}
+
+	return r.parser.parseImages(result.Stdout)
+}
+
+// RemoveImage removes an image by reference or ID.
+func (r *baseRuntime) RemoveImage(ctx context.Context, ref string) error {
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: []string{"rmi", ref},
+	})
+	if err != nil {
+		stderr := string(result.Stderr)
+		if isNotFoundError(stderr) || strings.Contains(stderr, "image not known") {
+			return ErrImageNotFound
+		}
+		return cliError("remove image "+ref, result, err)
+	}
+	return nil
+}
+
+// dockerImageItem represents a single item in `docker images --format json`
+// output, which nerdctl shares. Each tag of an image is a separate item.
+type dockerImageItem struct {
+	ID         string `json:"ID"`
+	Repository string `json:"Repository"`
+	Tag        string `json:"Tag"`
+	Size       string `json:"Size"` // Human-readable, e.g. "77.9MB"
+	CreatedAt  string `json:"CreatedAt"`
+}
+
+// parseDockerImages parses NDJSON image listings of Docker and nerdctl,
+// merging the tags of each image.
+func parseDockerImages(data []byte) ([]Image, error) {
+	var images []Image
+	index := make(map[string]int)
+
+	for line := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var item dockerImageItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("parse image list item: %w", err)
+		}
+		if item.Repository == "" || item.Repository == "<none>" || item.Tag == "<none>" {
+			continue
+		}
+
+		ref := item.Repository + ":" + item.Tag
+		if i, ok := index[item.ID]; ok {
+			images[i].Tags = append(images[i].Tags, ref)
+			continue
+		}
+
+		createdAt, err := time.Parse(cliCreatedAtLayout, item.CreatedAt)
+		if err != nil {
+			createdAt = time.Time{}
+		}
+		index[item.ID] = len(images)
+		images = append(images, Image{
+			ID:        item.ID,
+			Tags:      []string{ref},
+			Size:      progress.ParseSize(item.Size),
+			CreatedAt: createdAt,
+		})
+	}
+
+	if images == nil {
+		images = []Image{}
+	}
+	return images, nil
+}
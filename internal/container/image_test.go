@@ -0,0 +1,53 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageTag(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "devcontainer.json")
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	require.NoError(t, os.WriteFile(config, []byte(`{"build":{"dockerfile":"Dockerfile"}}`), 0o600))
+	require.NoError(t, os.WriteFile(dockerfile, []byte("FROM ubuntu\n"), 0o600))
+
+	tag, err := ImageTag("My Repo", config, dockerfile)
+	require.NoError(t, err)
+	assert.Regexp(t, `^headjack/my-repo:[0-9a-f]{12}$`, tag)
+
+	again, err := ImageTag("My Repo", config, dockerfile, "")
+	require.NoError(t, err)
+	assert.Equal(t, tag, again, "same content gives the same tag")
+
+	require.NoError(t, os.WriteFile(dockerfile, []byte("FROM debian\n"), 0o600))
+	changed, err := ImageTag("My Repo", config, dockerfile)
+	require.NoError(t, err)
+	assert.NotEqual(t, tag, changed)
+
+	_, err = ImageTag("app", filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestUnreferencedImages(t *testing.T) {
+	images := []Image{
+		{ID: "sha256:aaaaaaaaaaaaaaaa", Tags: []string{"headjack/a:1"}},
+		{ID: "sha256:bbbbbbbbbbbbbbbb", Tags: []string{"headjack/b:1"}},
+		{ID: "sha256:cccccccccccccccc", Tags: []string{"localhost/headjack/c:1"}},
+		{ID: "sha256:dddddddddddddddd", Tags: []string{"headjack/d:1"}},
+	}
+	containers := []Container{
+		{Image: "headjack/a:1"},
+		{Image: "bbbbbbbbbbbb"},
+		{Image: "headjack/c:1"},
+	}
+
+	unused := UnreferencedImages(images, containers)
+
+	require.Len(t, unused, 1)
+	assert.Equal(t, "headjack/d:1", unused[0].Tags[0])
+}
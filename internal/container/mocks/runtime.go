@@ -23,6 +23,12 @@ var _ container.Runtime = &RuntimeMock{}
 //			BuildFunc: func(ctx context.Context, cfg *container.BuildConfig) error {
 //				panic("mock out the Build method")
 //			},
+//			CopyFromFunc: func(ctx context.Context, id string, src string, dst string) error {
+//				panic("mock out the CopyFrom method")
+//			},
+//			CopyToFunc: func(ctx context.Context, id string, src string, dst string) error {
+//				panic("mock out the CopyTo method")
+//			},
 //			ExecFunc: func(ctx context.Context, id string, cfg *container.ExecConfig) error {
 //				panic("mock out the Exec method")
 //			},
@@ -57,6 +63,12 @@ type RuntimeMock struct {
 	// BuildFunc mocks the Build method.
 	BuildFunc func(ctx context.Context, cfg *container.BuildConfig) error
 
+	// CopyFromFunc mocks the CopyFrom method.
+	CopyFromFunc func(ctx context.Context, id string, src string, dst string) error
+
+	// CopyToFunc mocks the CopyTo method.
+	CopyToFunc func(ctx context.Context, id string, src string, dst string) error
+
 	// ExecFunc mocks the Exec method.
 	ExecFunc func(ctx context.Context, id string, cfg *container.ExecConfig) error
 
@@ -90,6 +102,28 @@ type RuntimeMock struct {
 			// Cfg is the cfg argument value.
 			Cfg *container.BuildConfig
 		}
+		// CopyFrom holds details about calls to the CopyFrom method.
+		CopyFrom []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Src is the src argument value.
+			Src string
+			// Dst is the dst argument value.
+			Dst string
+		}
+		// CopyTo holds details about calls to the CopyTo method.
+		CopyTo []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+			// Src is the src argument value.
+			Src string
+			// Dst is the dst argument value.
+			Dst string
+		}
 		// Exec holds details about calls to the Exec method.
 		Exec []struct {
 			// Ctx is the ctx argument value.
@@ -146,6 +180,8 @@ type RuntimeMock struct {
 		}
 	}
 	lockBuild       sync.RWMutex
+	lockCopyFrom    sync.RWMutex
+	lockCopyTo      sync.RWMutex
 	lockExec        sync.RWMutex
 	lockExecCommand sync.RWMutex
 	lockGet         sync.RWMutex
@@ -192,6 +228,94 @@ func (mock *RuntimeMock) BuildCalls() []struct {
 	return calls
 }
 
+// CopyFrom calls CopyFromFunc.
+func (mock *RuntimeMock) CopyFrom(ctx context.Context, id string, src string, dst string) error {
+	if mock.CopyFromFunc == nil {
+		panic("RuntimeMock.CopyFromFunc: method is nil but Runtime.CopyFrom was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		Src string
+		Dst string
+	}{
+		Ctx: ctx,
+		ID:  id,
+		Src: src,
+		Dst: dst,
+	}
+	mock.lockCopyFrom.Lock()
+	mock.calls.CopyFrom = append(mock.calls.CopyFrom, callInfo)
+	mock.lockCopyFrom.Unlock()
+	return mock.CopyFromFunc(ctx, id, src, dst)
+}
+
+// CopyFromCalls gets all the calls that were made to CopyFrom.
+// Check the length with:
+//
+//	len(mockedRuntime.CopyFromCalls())
+func (mock *RuntimeMock) CopyFromCalls() []struct {
+	Ctx context.Context
+	ID  string
+	Src string
+	Dst string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		Src string
+		Dst string
+	}
+	mock.lockCopyFrom.RLock()
+	calls = mock.calls.CopyFrom
+	mock.lockCopyFrom.RUnlock()
+	return calls
+}
+
+// CopyTo calls CopyToFunc.
+func (mock *RuntimeMock) CopyTo(ctx context.Context, id string, src string, dst string) error {
+	if mock.CopyToFunc == nil {
+		panic("RuntimeMock.CopyToFunc: method is nil but Runtime.CopyTo was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+		Src string
+		Dst string
+	}{
+		Ctx: ctx,
+		ID:  id,
+		Src: src,
+		Dst: dst,
+	}
+	mock.lockCopyTo.Lock()
+	mock.calls.CopyTo = append(mock.calls.CopyTo, callInfo)
+	mock.lockCopyTo.Unlock()
+	return mock.CopyToFunc(ctx, id, src, dst)
+}
+
+// CopyToCalls gets all the calls that were made to CopyTo.
+// Check the length with:
+//
+//	len(mockedRuntime.CopyToCalls())
+func (mock *RuntimeMock) CopyToCalls() []struct {
+	Ctx context.Context
+	ID  string
+	Src string
+	Dst string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+		Src string
+		Dst string
+	}
+	mock.lockCopyTo.RLock()
+	calls = mock.calls.CopyTo
+	mock.lockCopyTo.RUnlock()
+	return calls
+}
+
 // Exec calls ExecFunc.
 func (mock *RuntimeMock) Exec(ctx context.Context, id string, cfg *container.ExecConfig) error {
 	if mock.ExecFunc == nil {
@@ -0,0 +1,164 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// cliCreatedAtLayout is the format of CreatedAt in `nerdctl ps` and
+// `docker images` output.
+const cliCreatedAtLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// NerdctlConfig holds nerdctl-specific runtime configuration.
+type NerdctlConfig struct {
+	// Currently empty - all flags go through RunConfig.Flags after merging
+	// at the manager level. Kept for future runtime-specific settings.
+}
+
+// nerdctlRuntime implements Runtime using the nerdctl CLI for containerd.
+// All common functionality is provided by the embedded baseRuntime.
+type nerdctlRuntime struct {
+	baseRuntime
+	config NerdctlConfig
+}
+
+// nerdctlParser implements containerParser for nerdctl JSON output.
+type nerdctlParser struct{}
+
+// NewNerdctlRuntime creates a Runtime using nerdctl CLI.
+func NewNerdctlRuntime(e exec.Executor, cfg NerdctlConfig) Runtime {
+	parser := &nerdctlParser{}
+	return &nerdctlRuntime{
+		baseRuntime: baseRuntime{
+			exec:        e,
+			binaryName:  "nerdctl",
+			execCommand: []string{"nerdctl", "exec"},
+			listArgs:    []string{"ps", "-a"},
+			parser:      parser,
+			// nerdctl follows Docker: no user namespace remapping by default
+			userArgs:   dockerUserArgs,
+			addPasswd:  true,
+			repairArgs: dockerRepairArgs,
+		},
+		config: cfg,
+	}
+}
+
+// nerdctlInspect represents the JSON output of `nerdctl inspect`.
+// nerdctl mimics Docker, but reports the image at the top level and the
+// name without a leading "/".
+type nerdctlInspect struct {
+	ID      string `json:"Id"`
+	Name    string `json:"Name"`
+	Created string `json:"Created"`
+	Image   string `json:"Image"`
+	State   struct {
+		Status string `json:"Status"`
+	} `json:"State"`
+}
+
+func (n *nerdctlInspect) toContainer() *Container {
+	status := parseContainerStatus(n.State.Status)
+
+	// nerdctl uses RFC3339Nano format, fall back to RFC3339
+	createdAt, err := time.Parse(time.RFC3339Nano, n.Created)
+	if err != nil {
+		createdAt, err = time.Parse(time.RFC3339, n.Created)
+		if err != nil {
+			createdAt = time.Time{}
+		}
+	}
+
+	return &Container{
+		ID:        n.ID,
+		Name:      strings.TrimPrefix(n.Name, "/"),
+		Image:     n.Image,
+		Status:    status,
+		CreatedAt: createdAt,
+	}
+}
+
+// nerdctlListItem represents a single item in `nerdctl ps --format json` output.
+// Like Docker, nerdctl outputs one JSON object per line (NDJSON). It has no
+// State field, so the state is taken from the human-readable Status.
+type nerdctlListItem struct {
+	ID        string `json:"ID"`
+	Names     string `json:"Names"`
+	Image     string `json:"Image"`
+	Status    string `json:"Status"`    // "Up", "Created", "Exited (0) 2 minutes ago", etc.
+	CreatedAt string `json:"CreatedAt"` // e.g., "2024-01-15 10:30:00 +0000 UTC"
+}
+
+func (n *nerdctlListItem) toContainer() Container {
+	createdAt, err := time.Parse(cliCreatedAtLayout, n.CreatedAt)
+	if err != nil {
+		createdAt = time.Time{}
+	}
+
+	return Container{
+		ID:        n.ID,
+		Name:      n.Names,
+		Image:     n.Image,
+		Status:    parseNerdctlStatus(n.Status),
+		CreatedAt: createdAt,
+	}
+}
+
+// parseNerdctlStatus converts the Status column of `nerdctl ps` to a Status.
+func parseNerdctlStatus(status string) Status {
+	word, _, _ := strings.Cut(strings.TrimSpace(status), " ")
+	if strings.EqualFold(word, "up") {
+		return StatusRunning
+	}
+	return parseContainerStatus(word)
+}
+
+// parseInspect parses the JSON output of `nerdctl inspect`.
+func (p *nerdctlParser) parseInspect(data []byte) (*Container, error) {
+	var infos []nerdctlInspect
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, fmt.Errorf("parse container info: %w", err)
+	}
+
+	if len(infos) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return infos[0].toContainer(), nil
+}
+
+// parseList parses the JSON output of `nerdctl ps --format json`.
+func (p *nerdctlParser) parseList(data []byte) ([]Container, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" || trimmed == "[]" {
+		return []Container{}, nil
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	containers := make([]Container, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var item nerdctlListItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("parse container list item: %w", err)
+		}
+		containers = append(containers, item.toContainer())
+	}
+
+	return containers, nil
+}
+
+// parseImages parses the JSON output of `nerdctl images --format json`,
+// which matches Docker's.
+func (p *nerdctlParser) parseImages(data []byte) ([]Image, error) {
+	return parseDockerImages(data)
+}
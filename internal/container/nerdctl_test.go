@@ -0,0 +1,928 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+func TestNewNerdctlRuntime(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+	runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+
+	require.NotNil(t, runtime)
+}
+
+func TestNerdctlRuntime_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates container successfully with default init command", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Contains(t, opts.Args, "run")
+				assert.Contains(t, opts.Args, "--detach")
+				assert.Contains(t, opts.Args, "--name")
+				assert.Contains(t, opts.Args, "test-container")
+				assert.Contains(t, opts.Args, "ubuntu:24.04")
+				// Default init command should be "sleep infinity"
+				assert.Contains(t, opts.Args, "sleep")
+				assert.Contains(t, opts.Args, "infinity")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123def456\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		container, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test-container",
+			Image: "ubuntu:24.04",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123def456", container.ID)
+		assert.Equal(t, "test-container", container.Name)
+		assert.Equal(t, "ubuntu:24.04", container.Image)
+		assert.Equal(t, StatusRunning, container.Status)
+	})
+
+	t.Run("uses custom init command when specified", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				// Custom init command should be at the end
+				assert.Contains(t, opts.Args, "/lib/systemd/systemd")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Init:  "/lib/systemd/systemd",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes image-specific flags from RunConfig", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "--custom-flag")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Flags: []string{"--custom-flag"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes privileged flag when configured", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "--privileged")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Flags: []string{"--privileged"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes custom flags from RunConfig", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "--memory=2g")
+				assert.Contains(t, opts.Args, "--cpus=2")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Flags: []string{"--memory=2g", "--cpus=2"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes volume mounts", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "-v")
+				assert.Contains(t, opts.Args, "/host/path:/container/path")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Mounts: []Mount{
+				{Source: "/host/path", Target: "/container/path"},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes read-only mount flag", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "/host:/container:ro")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Mounts: []Mount{
+				{Source: "/host", Target: "/container", ReadOnly: true},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes environment variables", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "-e")
+				assert.Contains(t, opts.Args, "FOO=bar")
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Env:   []string{"FOO=bar"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes labels sorted by key", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"run", "--detach", "--name", "test",
+					"--label", "io.headjack.branch=main",
+					"--label", "io.headjack.instance-id=abc",
+					"ubuntu", "sleep", "infinity",
+				}, opts.Args)
+
+				return &exec.Result{
+					Stdout:   []byte("abc123\n"),
+					ExitCode: 0,
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Labels: map[string]string{
+				LabelInstanceID: "abc",
+				LabelBranch:     "main",
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("runs as mapped user and adds passwd entry", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == "exec" {
+					assert.Equal(t, []string{"exec", "-u", "0", "abc123"}, opts.Args[:4])
+					assert.Equal(t, []string{"1000", "1001", "dev"}, opts.Args[len(opts.Args)-3:])
+					return &exec.Result{}, nil
+				}
+				assert.Equal(t, []string{"run", "--detach", "--name", "test", "--user", "1000:1001", "--privileged"}, opts.Args[:7])
+
+				return &exec.Result{Stdout: []byte("abc123\n")}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "ubuntu",
+			Flags: []string{"--privileged"},
+			User:  &UserMapping{UID: 1000, GID: 1001, Name: "dev"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, mockExec.RunCalls(), 2)
+	})
+
+	t.Run("ignores passwd entry failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == "exec" {
+					return &exec.Result{Stderr: []byte("sh: not found")}, errors.New("exit code 127")
+				}
+				return &exec.Result{Stdout: []byte("abc123\n")}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		c, err := runtime.Run(ctx, &RunConfig{
+			Name:  "test",
+			Image: "distroless",
+			User:  &UserMapping{UID: 1000, GID: 1000, Name: "dev"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", c.ID)
+	})
+
+	t.Run("returns ErrAlreadyExists when container exists", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte(`name "existing" is already used by ID "abc123"`),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Run(ctx, &RunConfig{
+			Name:  "existing",
+			Image: "ubuntu",
+		})
+
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+}
+
+func TestNerdctlRuntime_Exec(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("executes command in running container", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				if callCount == 1 {
+					// Get call - Nerdctl format
+					return &exec.Result{
+						Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu"}]`),
+					}, nil
+				}
+				// Exec call
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Contains(t, opts.Args, "exec")
+				assert.Contains(t, opts.Args, "abc123")
+				assert.Contains(t, opts.Args, "bash")
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Exec(ctx, "abc123", &ExecConfig{
+			Command: []string{"bash"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes workdir when specified", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				if callCount == 1 {
+					// Get call - Nerdctl format
+					return &exec.Result{
+						Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu"}]`),
+					}, nil
+				}
+				assert.Contains(t, opts.Args, "-w")
+				assert.Contains(t, opts.Args, "/app")
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Exec(ctx, "abc123", &ExecConfig{
+			Command: []string{"ls"},
+			Workdir: "/app",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrNotFound when container missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("no such container: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Exec(ctx, "missing", &ExecConfig{
+			Command: []string{"bash"},
+		})
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("returns ErrNotRunning when container stopped", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				// Get call - Nerdctl format with exited status
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"exited"},"Image":"ubuntu"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Exec(ctx, "abc123", &ExecConfig{
+			Command: []string{"bash"},
+		})
+
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+}
+
+func TestNerdctlRuntime_Stop(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stops running container", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				if callCount == 1 {
+					// Get call - Nerdctl format
+					return &exec.Result{
+						Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu"}]`),
+					}, nil
+				}
+				// Stop call
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Equal(t, []string{"stop", "abc123"}, opts.Args)
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Stop(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, callCount)
+	})
+
+	t.Run("no-op for already stopped container", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				// Get call - Nerdctl format with exited status
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"exited"},"Image":"ubuntu"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Stop(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, callCount) // Only Get call, no Stop call
+	})
+
+	t.Run("returns ErrNotFound when container missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("no such container: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Stop(ctx, "missing")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestNerdctlRuntime_Start(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts stopped container", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				if callCount == 1 {
+					// Get call - Nerdctl format with exited status
+					return &exec.Result{
+						Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"exited"},"Image":"ubuntu"}]`),
+					}, nil
+				}
+				// Start call
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Equal(t, []string{"start", "abc123"}, opts.Args)
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Start(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, callCount)
+	})
+
+	t.Run("no-op for already running container", func(t *testing.T) {
+		callCount := 0
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				callCount++
+				// Get call - Nerdctl format
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Start(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, callCount) // Only Get call, no Start call
+	})
+}
+
+func TestNerdctlRuntime_Remove(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes container", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Equal(t, []string{"rm", "abc123"}, opts.Args)
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Remove(ctx, "abc123")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrNotFound when container missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("no such container: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Remove(ctx, "missing")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestNerdctlRuntime_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns container info", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Equal(t, []string{"inspect", "abc123"}, opts.Args)
+
+				// nerdctl format with RFC3339Nano timestamp
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123def456","Name":"test-container","State":{"Status":"running"},"Image":"ubuntu:24.04","Created":"2024-01-15T10:30:00.123456789Z"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		container, err := runtime.Get(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc123def456", container.ID)
+		assert.Equal(t, "test-container", container.Name)
+		assert.Equal(t, "ubuntu:24.04", container.Image)
+		assert.Equal(t, StatusRunning, container.Status)
+	})
+
+	t.Run("parses stopped state", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				// nerdctl format with exited status
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"exited"},"Image":"ubuntu"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		container, err := runtime.Get(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.Equal(t, StatusStopped, container.Status)
+	})
+
+	t.Run("parses RFC3339Nano timestamp", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu","Created":"2024-01-15T10:30:00.123456789Z"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		container, err := runtime.Get(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.False(t, container.CreatedAt.IsZero())
+	})
+
+	t.Run("parses RFC3339 timestamp fallback", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				// nerdctl format with RFC3339 timestamp (no nanoseconds)
+				return &exec.Result{
+					Stdout: []byte(`[{"Id":"abc123","Name":"test","State":{"Status":"running"},"Image":"ubuntu","Created":"2024-01-15T10:30:00Z"}]`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		container, err := runtime.Get(ctx, "abc123")
+
+		require.NoError(t, err)
+		assert.False(t, container.CreatedAt.IsZero())
+	})
+
+	t.Run("returns ErrNotFound when container missing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("no such container: missing"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.Get(ctx, "missing")
+
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestNerdctlRuntime_List(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns empty list for empty output", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte(""),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		containers, err := runtime.List(ctx, ListFilter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, containers)
+	})
+
+	t.Run("returns empty list for bracket array", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte("[]"),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		containers, err := runtime.List(ctx, ListFilter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, containers)
+	})
+
+	t.Run("parses single container NDJSON", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				// nerdctl ps --format json outputs one JSON object per line (NDJSON)
+				return &exec.Result{
+					Stdout: []byte(`{"ID":"abc123","Names":"container1","Image":"ubuntu","Status":"Up","CreatedAt":"2024-01-15 10:30:00 +0000 UTC"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		containers, err := runtime.List(ctx, ListFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, containers, 1)
+		assert.Equal(t, "abc123", containers[0].ID)
+		assert.Equal(t, "container1", containers[0].Name)
+		assert.Equal(t, "ubuntu", containers[0].Image)
+		assert.Equal(t, StatusRunning, containers[0].Status)
+		assert.Equal(t, int64(1705314600), containers[0].CreatedAt.Unix())
+	})
+
+	t.Run("parses multiple containers NDJSON", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				// nerdctl ps --format json outputs NDJSON - one object per line
+				return &exec.Result{
+					Stdout: []byte(`{"ID":"abc","Names":"container1","Image":"ubuntu","Status":"Up 2 minutes"}
+{"ID":"def","Names":"container2","Image":"alpine","Status":"Exited (0) 3 minutes ago"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		containers, err := runtime.List(ctx, ListFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, containers, 2)
+		assert.Equal(t, "abc", containers[0].ID)
+		assert.Equal(t, "container1", containers[0].Name)
+		assert.Equal(t, StatusRunning, containers[0].Status)
+		assert.Equal(t, "def", containers[1].ID)
+		assert.Equal(t, "container2", containers[1].Name)
+		assert.Equal(t, StatusStopped, containers[1].Status)
+	})
+
+	t.Run("includes name filter", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "--filter")
+				assert.Contains(t, opts.Args, "name=my-prefix")
+
+				return &exec.Result{Stdout: []byte("")}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.List(ctx, ListFilter{Name: "my-prefix"})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes label filters", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"ps", "-a", "--format", "json",
+					"--filter", "label=io.headjack.branch=main",
+					"--filter", "label=io.headjack.repo-id=repo",
+				}, opts.Args)
+
+				return &exec.Result{Stdout: []byte("")}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.List(ctx, ListFilter{Labels: map[string]string{
+			LabelRepoID: "repo",
+			LabelBranch: "main",
+		}})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("uses ps -a for listing", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "ps")
+				assert.Contains(t, opts.Args, "-a")
+				assert.Contains(t, opts.Args, "--format")
+				assert.Contains(t, opts.Args, "json")
+
+				return &exec.Result{Stdout: []byte("")}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		_, err := runtime.List(ctx, ListFilter{})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestNerdctlRuntime_RepairOwnership(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("chowns in a helper container", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Equal(t, []string{
+					"run", "--rm", "--user", "0:0", "--entrypoint", "chown",
+					"-v", "/worktree:/hjk-repair",
+					"myimage:latest", "-R", "1000:1000", "/hjk-repair",
+				}, opts.Args)
+
+				return &exec.Result{}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		repairer, ok := runtime.(OwnershipRepairer)
+		require.True(t, ok)
+
+		err := repairer.RepairOwnership(ctx, "/worktree", &UserMapping{UID: 1000, GID: 1000}, "myimage:latest")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns stderr on failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{Stderr: []byte("Unable to find image")}, errors.New("exit code 125")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.(OwnershipRepairer).RepairOwnership(ctx, "/worktree", &UserMapping{UID: 1000, GID: 1000}, "gone")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Unable to find image")
+	})
+}
+
+func TestParseNerdctlStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   Status
+	}{
+		{"Up", StatusRunning},
+		{"Up 2 minutes", StatusRunning},
+		{"Created", StatusStopped},
+		{"Exited (137) 5 seconds ago", StatusStopped},
+		{"Paused", StatusUnknown},
+		{"", StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseNerdctlStatus(tt.status))
+		})
+	}
+}
+
+func TestNerdctlRuntime_Build(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("builds image", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "nerdctl", opts.Name)
+				assert.Contains(t, opts.Args, "build")
+				assert.Contains(t, opts.Args, "-t")
+				assert.Contains(t, opts.Args, "myimage:latest")
+				assert.Contains(t, opts.Args, "/build/context")
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Build(ctx, &BuildConfig{
+			Context: "/build/context",
+			Tag:     "myimage:latest",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("includes nerdctlfile path", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Contains(t, opts.Args, "-f")
+				assert.Contains(t, opts.Args, "/build/context/custom.Dockerfile")
+
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Build(ctx, &BuildConfig{
+			Context:    "/build/context",
+			Dockerfile: "custom.Dockerfile",
+			Tag:        "myimage:latest",
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrBuildFailed on failure", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(_ context.Context, _ *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stderr:   []byte("build error: missing base image"),
+					ExitCode: 1,
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+		err := runtime.Build(ctx, &BuildConfig{
+			Context: "/build/context",
+			Tag:     "myimage:latest",
+		})
+
+		require.ErrorIs(t, err, ErrBuildFailed)
+		assert.Contains(t, err.Error(), "missing base image")
+	})
+}
+
+func TestNerdctlRuntime_ExecCommand(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+	runtime := NewNerdctlRuntime(mockExec, NerdctlConfig{})
+
+	cmd := runtime.ExecCommand()
+
+	assert.Equal(t, []string{"nerdctl", "exec"}, cmd)
+}
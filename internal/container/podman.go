@@ -35,6 +35,8 @@ func NewPodmanRuntime(e exec.Executor, cfg PodmanConfig) Runtime {
 			execCommand: []string{"podman", "exec"},
 			listArgs:    []string{"ps", "-a"},
 			parser:      parser,
+			userArgs:    podmanUserArgs,
+			repairArgs:  podmanRepairArgs,
 		},
 		config: cfg,
 	}
@@ -138,3 +140,34 @@ func (p *podmanParser) parseList(data []byte) ([]Container, error) {
 
 	return containers, nil
 }
+
+// podmanImageItem represents a single item in `podman images` JSON output.
+type podmanImageItem struct {
+	ID      string   `json:"Id"`
+	Names   []string `json:"Names"` // e.g., "localhost/headjack/app:0123456789ab"
+	Size    int64    `json:"Size"`
+	Created int64    `json:"Created"`
+}
+
+// parseImages parses the JSON output of `podman images --format json`.
+func (p *podmanParser) parseImages(data []byte) ([]Image, error) {
+	var items []podmanImageItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parse image list: %w", err)
+	}
+
+	images := make([]Image, 0, len(items))
+	for _, item := range items {
+		if len(item.Names) == 0 {
+			continue // Dangling image
+		}
+		images = append(images, Image{
+			ID:        item.ID,
+			Tags:      item.Names,
+			Size:      item.Size,
+			CreatedAt: time.Unix(item.Created, 0),
+		})
+	}
+
+	return images, nil
+}
@@ -3,7 +3,9 @@ package container
 import (
 	"context"
 	"errors"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -237,6 +239,47 @@ func TestPodmanRuntime_Run(t *testing.T) {
 	})
 }
 
+func TestPodmanRuntime_Run_User(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			assert.Equal(t, []string{"run", "--detach", "--name", "test", "--userns=keep-id"}, opts.Args[:5])
+
+			return &exec.Result{Stdout: []byte("abc123\n")}, nil
+		},
+	}
+
+	runtime := NewPodmanRuntime(mockExec, PodmanConfig{})
+	_, err := runtime.Run(context.Background(), &RunConfig{
+		Name:  "test",
+		Image: "ubuntu",
+		User:  &UserMapping{UID: 1000, GID: 1000, Name: "dev"},
+	})
+
+	require.NoError(t, err)
+	// Podman adds the passwd entry itself
+	require.Len(t, mockExec.RunCalls(), 1)
+}
+
+func TestPodmanRuntime_RepairOwnership(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			return &exec.Result{}, nil
+		},
+	}
+
+	runtime := NewPodmanRuntime(mockExec, PodmanConfig{})
+	err := runtime.(OwnershipRepairer).RepairOwnership(context.Background(), "/worktree", &UserMapping{UID: 1000, GID: 1000}, "myimage:latest")
+
+	require.NoError(t, err)
+	require.Len(t, mockExec.RunCalls(), 1)
+	args := mockExec.RunCalls()[0].Opts.Args
+	if os.Geteuid() == 0 {
+		assert.Equal(t, "run", args[0])
+	} else {
+		assert.Equal(t, []string{"unshare", "chown", "-R", "0:0", "/worktree"}, args)
+	}
+}
+
 func TestPodmanRuntime_Exec(t *testing.T) {
 	ctx := context.Background()
 
@@ -666,7 +709,7 @@ func TestPodmanRuntime_Build(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
 				assert.Contains(t, opts.Args, "-f")
-				assert.Contains(t, opts.Args, "custom.Dockerfile")
+				assert.Contains(t, opts.Args, "/build/context/custom.Dockerfile")
 
 				return &exec.Result{ExitCode: 0}, nil
 			},
@@ -702,3 +745,27 @@ func TestPodmanRuntime_Build(t *testing.T) {
 		assert.Contains(t, err.Error(), "missing base image")
 	})
 }
+
+func TestPodmanRuntime_ListImages(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			assert.Equal(t, "podman", opts.Name)
+			return &exec.Result{Stdout: []byte(`[
+				{"Id":"abc123","Names":["localhost/headjack/app:0123456789ab"],"Size":1024,"Created":1704207845},
+				{"Id":"def456","Names":null,"Size":10,"Created":1704207845}
+			]`)}, nil
+		},
+	}
+
+	images := NewPodmanRuntime(mockExec, PodmanConfig{}).(ImageManager)
+	list, err := images.ListImages(context.Background(), ImageFilter{})
+
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, Image{
+		ID:        "abc123",
+		Tags:      []string{"localhost/headjack/app:0123456789ab"},
+		Size:      1024,
+		CreatedAt: time.Unix(1704207845, 0),
+	}, list[0])
+}
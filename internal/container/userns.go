@@ -0,0 +1,89 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// repairMount is where RepairOwnership mounts the directory to repair in its
+// helper container.
+const repairMount = "/hjk-repair"
+
+// passwdScript adds group and passwd entries for a mapped user, unless the
+// image already has entries for its IDs, and creates its home directory.
+// Arguments: UID, GID and user name.
+const passwdScript = `uid=$1 gid=$2 name=$3
+grep -q "^[^:]*:[^:]*:$gid:" /etc/group || echo "$name:x:$gid:" >> /etc/group
+grep -q "^[^:]*:[^:]*:$uid:" /etc/passwd || echo "$name:x:$uid:$gid::/home/$name:/bin/sh" >> /etc/passwd
+mkdir -p "/home/$name" && chown "$uid:$gid" "/home/$name"`
+
+// owner returns the UID:GID form of a mapped user.
+func (u *UserMapping) owner() string {
+	return strconv.Itoa(u.UID) + ":" + strconv.Itoa(u.GID)
+}
+
+// dockerUserArgs runs the container process as the host user. Docker does not
+// remap user namespaces by default, so files keep the host IDs.
+func dockerUserArgs(u *UserMapping) []string {
+	return []string{"--user", u.owner()}
+}
+
+// podmanUserArgs maps the host user to the same IDs inside the container.
+// Podman also adds a passwd entry for the user.
+func podmanUserArgs(_ *UserMapping) []string {
+	return []string{"--userns=keep-id"}
+}
+
+// passwdCommand returns the command that adds passwd entries for u as root.
+func passwdCommand(u *UserMapping) []string {
+	return []string{"sh", "-c", passwdScript, "sh", strconv.Itoa(u.UID), strconv.Itoa(u.GID), u.Name}
+}
+
+// dockerRepairArgs chowns dir to the host user in a helper container running
+// as root.
+func dockerRepairArgs(dir string, u *UserMapping, image string) []string {
+	return []string{
+		"run", "--rm", "--user", "0:0", "--entrypoint", "chown",
+		"-v", dir + ":" + repairMount,
+		image, "-R", u.owner(), repairMount,
+	}
+}
+
+// podmanRepairArgs chowns dir to root of the rootless user namespace, which is
+// the host user. Files owned by subordinate IDs can only be changed there.
+// Rootful Podman repairs like Docker.
+func podmanRepairArgs(dir string, u *UserMapping, image string) []string {
+	if os.Geteuid() == 0 {
+		return dockerRepairArgs(dir, u, image)
+	}
+	return []string{"unshare", "chown", "-R", "0:0", dir}
+}
+
+// addPasswdEntry adds passwd entries for a mapped user to a running container.
+func (r *baseRuntime) addPasswdEntry(ctx context.Context, id string, u *UserMapping) error {
+	args := append([]string{"exec", "-u", "0", id}, passwdCommand(u)...)
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: args,
+	})
+	if err != nil {
+		return cliError("add passwd entry", result, err)
+	}
+	return nil
+}
+
+// RepairOwnership changes the owner of everything under dir to user.
+func (r *baseRuntime) RepairOwnership(ctx context.Context, dir string, user *UserMapping, image string) error {
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: r.repairArgs(dir, user, image),
+	})
+	if err != nil {
+		return cliError(fmt.Sprintf("repair ownership of %s", dir), result, err)
+	}
+	return nil
+}
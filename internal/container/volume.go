@@ -0,0 +1,160 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// Volume holds named volume metadata.
+type Volume struct {
+	Name       string
+	Mountpoint string // Path of the volume data on the engine host
+}
+
+// VolumeManager is implemented by runtimes that manage named volumes.
+// Volumes are created on demand when a container mounts them.
+type VolumeManager interface {
+	// ListVolumes returns the volumes whose names start with prefix.
+	ListVolumes(ctx context.Context, prefix string) ([]Volume, error)
+
+	// RemoveVolume removes a volume and its data.
+	// Returns ErrVolumeNotFound if the volume doesn't exist.
+	// Returns ErrVolumeInUse if a container uses the volume.
+	RemoveVolume(ctx context.Context, name string) error
+}
+
+// volumeItem is an item of a volume listing. Docker and nerdctl output
+// NDJSON, Podman a JSON array, with the same field names.
+type volumeItem struct {
+	Name       string `json:"Name"`
+	Mountpoint string `json:"Mountpoint"`
+}
+
+// parseVolumes parses `volume ls --format json` output of any CLI and keeps
+// the volumes whose names start with prefix.
+func parseVolumes(data []byte, prefix string) ([]Volume, error) {
+	trimmed := strings.TrimSpace(string(data))
+
+	var items []volumeItem
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return nil, fmt.Errorf("parse volume list: %w", err)
+		}
+	} else {
+		for line := range strings.SplitSeq(trimmed, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			var item volumeItem
+			if err := json.Unmarshal([]byte(line), &item); err != nil {
+				return nil, fmt.Errorf("parse volume list item: %w", err)
+			}
+			items = append(items, item)
+		}
+	}
+
+	volumes := []Volume{}
+	for _, item := range items {
+		// Name filters match substrings, so check the prefix here
+		if strings.HasPrefix(item.Name, prefix) {
+			volumes = append(volumes, Volume(item))
+		}
+	}
+	return volumes, nil
+}
+
+// isVolumeInUseError checks if stderr indicates a volume is used by a container.
+func isVolumeInUseError(stderr string) bool {
+	return strings.Contains(stderr, "in use") ||
+		strings.Contains(stderr, "being used") // podman
+}
+
+// ListVolumes returns the volumes whose names start with prefix.
+func (r *baseRuntime) ListVolumes(ctx context.Context, prefix string) ([]Volume, error) {
+	args := []string{"volume", "ls", "--format", "json"}
+	if prefix != "" {
+		args = append(args, "--filter", "name="+prefix)
+	}
+
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: args,
+	})
+	if err != nil {
+		return nil, cliError("list volumes", result, err)
+	}
+
+	return parseVolumes(result.Stdout, prefix)
+}
+
+// RemoveVolume removes a volume and its data.
+func (r *baseRuntime) RemoveVolume(ctx context.Context, name string) error {
+	result, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.binaryName,
+		Args: []string{"volume", "rm", name},
+	})
+	if err != nil {
+		stderr := string(result.Stderr)
+		switch {
+		case isVolumeInUseError(stderr):
+			return fmt.Errorf("%w: %s", ErrVolumeInUse, name)
+		case isNotFoundError(stderr):
+			return ErrVolumeNotFound
+		}
+		return cliError("remove volume "+name, result, err)
+	}
+	return nil
+}
+
+// ListVolumes returns the volumes whose names start with prefix.
+func (r *apiRuntime) ListVolumes(ctx context.Context, prefix string) ([]Volume, error) {
+	var query url.Values
+	if prefix != "" {
+		filters, err := json.Marshal(map[string][]string{"name": {prefix}})
+		if err != nil {
+			return nil, fmt.Errorf("encode filters: %w", err)
+		}
+		query = url.Values{"filters": {string(filters)}}
+	}
+
+	var resp struct {
+		Volumes []volumeItem `json:"Volumes"`
+	}
+	if err := r.doJSON(ctx, http.MethodGet, "/volumes", query, nil, &resp); err != nil {
+		return nil, fmt.Errorf("list volumes: %w", err)
+	}
+
+	volumes := []Volume{}
+	for _, item := range resp.Volumes {
+		if strings.HasPrefix(item.Name, prefix) {
+			volumes = append(volumes, Volume(item))
+		}
+	}
+	return volumes, nil
+}
+
+// RemoveVolume removes a volume and its data.
+func (r *apiRuntime) RemoveVolume(ctx context.Context, name string) error {
+	err := r.doJSON(ctx, http.MethodDelete, "/volumes/"+url.PathEscape(name), nil, nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			return ErrVolumeNotFound
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", ErrVolumeInUse, name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("remove volume %s: %w", name, err)
+	}
+	return nil
+}
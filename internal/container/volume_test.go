@@ -0,0 +1,66 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+func TestParseVolumes(t *testing.T) {
+	t.Run("parses NDJSON", func(t *testing.T) {
+		volumes, err := parseVolumes([]byte(`{"Driver":"local","Mountpoint":"/v/hjk-cache-npm","Name":"hjk-cache-npm"}
+{"Driver":"local","Mountpoint":"/v/x-hjk-cache-go","Name":"x-hjk-cache-go"}
+`), "hjk-cache-")
+
+		require.NoError(t, err)
+		assert.Equal(t, []Volume{{Name: "hjk-cache-npm", Mountpoint: "/v/hjk-cache-npm"}}, volumes)
+	})
+
+	t.Run("parses JSON array", func(t *testing.T) {
+		volumes, err := parseVolumes([]byte(`[{"Name":"hjk-cache-npm","Mountpoint":"/v/npm","Labels":{}}]`), "hjk-cache-")
+
+		require.NoError(t, err)
+		assert.Equal(t, []Volume{{Name: "hjk-cache-npm", Mountpoint: "/v/npm"}}, volumes)
+	})
+
+	t.Run("returns empty list for no output", func(t *testing.T) {
+		volumes, err := parseVolumes(nil, "")
+
+		require.NoError(t, err)
+		assert.Empty(t, volumes)
+	})
+}
+
+func TestBaseRuntime_RemoveVolume(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		stderr  string
+		wantErr error
+	}{
+		{name: "docker in use", stderr: "Error response from daemon: remove hjk-cache-npm: volume is in use - [abc123]", wantErr: ErrVolumeInUse},
+		{name: "podman in use", stderr: "Error: volume hjk-cache-npm is being used by the following container(s): abc123", wantErr: ErrVolumeInUse},
+		{name: "not found", stderr: "Error: no such volume hjk-cache-npm", wantErr: ErrVolumeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, []string{"volume", "rm", "hjk-cache-npm"}, opts.Args)
+					return &exec.Result{Stderr: []byte(tt.stderr), ExitCode: 1}, errors.New("exit code 1")
+				},
+			}
+
+			volumes := NewPodmanRuntime(mockExec, PodmanConfig{}).(VolumeManager)
+			assert.ErrorIs(t, volumes.RemoveVolume(ctx, "hjk-cache-npm"), tt.wantErr)
+		})
+	}
+}
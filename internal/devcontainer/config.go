@@ -0,0 +1,182 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jmgilman/headjack/internal/container"
+)
+
+// ErrComposeConfig is returned when a prebuilt image is requested for a
+// dev container defined by Docker Compose files.
+var ErrComposeConfig = errors.New("prebuilt images are not supported for Docker Compose dev containers")
+
+// ErrComposeMounts is returned when read-only mounts are requested for a
+// dev container defined by Docker Compose files.
+var ErrComposeMounts = errors.New("read-only mounts are not supported for Docker Compose dev containers")
+
+// buildKeys are the devcontainer.json properties that describe how the
+// image is built. A prebuilt image replaces all of them.
+var buildKeys = []string{"image", "build", "dockerFile", "context", "features"}
+
+// readConfig reads a devcontainer.json, which may contain comments and
+// trailing commas.
+func readConfig(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read devcontainer.json: %w", err)
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(standardizeJSON(data), &config); err != nil {
+		return nil, fmt.Errorf("parse devcontainer.json: %w", err)
+	}
+	return config, nil
+}
+
+// standardizeJSON converts JSON with comments into plain JSON by removing
+// comments and trailing commas.
+func standardizeJSON(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	pendingComma := -1 // Index in out of a comma that may be trailing
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			// Copy the string, including escaped quotes
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			out = append(out, data[start:min(i+1, len(data))]...)
+			pendingComma = -1
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i-- // Keep the newline
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && (data[i] != '*' || data[i+1] != '/') {
+				i++
+			}
+			i++
+		case c == ',':
+			pendingComma = len(out)
+			out = append(out, c)
+		case c == '}' || c == ']':
+			if pendingComma >= 0 {
+				out = append(out[:pendingComma], out[pendingComma+1:]...)
+				pendingComma = -1
+			}
+			out = append(out, c)
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			out = append(out, c)
+		default:
+			out = append(out, c)
+			pendingComma = -1
+		}
+	}
+	return out
+}
+
+// dockerfilePath returns the Dockerfile referenced by a devcontainer.json
+// at configPath, or "" if the dev container uses an image.
+func dockerfilePath(configPath string, config map[string]any) string {
+	var dockerfile string
+	if build, ok := config["build"].(map[string]any); ok {
+		dockerfile, _ = build["dockerfile"].(string)
+	}
+	if dockerfile == "" {
+		dockerfile, _ = config["dockerFile"].(string) // Deprecated spelling
+	}
+	if dockerfile == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(configPath), dockerfile)
+}
+
+// ImageTag returns the tag of the prebuilt image for the dev container in
+// workspaceFolder. The tag is named after the folder and changes whenever
+// devcontainer.json or its Dockerfile changes.
+// Returns ErrComposeConfig for Docker Compose dev containers.
+func ImageTag(workspaceFolder string) (string, error) {
+	configPath := Detect(workspaceFolder)
+	if configPath == "" {
+		return "", errors.New("no devcontainer.json found")
+	}
+
+	config, err := readConfig(configPath)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := config["dockerComposeFile"]; ok {
+		return "", ErrComposeConfig
+	}
+
+	return container.ImageTag(filepath.Base(workspaceFolder), configPath, dockerfilePath(configPath, config))
+}
+
+// overrideConfig returns the devcontainer.json at configPath rewritten to
+// use a prebuilt image instead of building one, if image is not empty, and
+// with mounts (in --mount format) added to its mounts. Features are dropped
+// with a prebuilt image: they are part of the image, and their metadata is
+// read from its labels.
+func overrideConfig(configPath, image string, mounts []string) ([]byte, error) {
+	config, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := config["dockerComposeFile"]; ok {
+		if image != "" {
+			return nil, ErrComposeConfig
+		}
+		return nil, ErrComposeMounts
+	}
+
+	if image != "" {
+		for _, key := range buildKeys {
+			delete(config, key)
+		}
+		config["image"] = image
+	}
+	if len(mounts) > 0 {
+		existing, _ := config["mounts"].([]any)
+		for _, m := range mounts {
+			existing = append(existing, m)
+		}
+		config["mounts"] = existing
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("encode devcontainer.json: %w", err)
+	}
+	return data, nil
+}
+
+// RemoteUser returns the user the dev container in workspaceFolder runs
+// commands as: remoteUser, or else containerUser, from devcontainer.json.
+// Returns "" if neither is set or the file can't be read, in which case the
+// image decides.
+func RemoteUser(workspaceFolder string) string {
+	configPath := Detect(workspaceFolder)
+	if configPath == "" {
+		return ""
+	}
+	config, err := readConfig(configPath)
+	if err != nil {
+		return ""
+	}
+	if user, ok := config["remoteUser"].(string); ok && user != "" {
+		return user
+	}
+	user, _ := config["containerUser"].(string)
+	return user
+}
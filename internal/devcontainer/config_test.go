@@ -0,0 +1,135 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeWorkspace creates a workspace with the given .devcontainer files.
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "My App")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0o750))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".devcontainer", name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestStandardizeJSON(t *testing.T) {
+	input := `{
+	// The image
+	"image": "ubuntu", /* inline */
+	"url": "http://example.com/a//b",
+	"quote": "say \"hi\" // not a comment",
+	"list": [1, 2,],
+}`
+
+	var config map[string]any
+	require.NoError(t, json.Unmarshal(standardizeJSON([]byte(input)), &config))
+
+	assert.Equal(t, "ubuntu", config["image"])
+	assert.Equal(t, "http://example.com/a//b", config["url"])
+	assert.Equal(t, `say "hi" // not a comment`, config["quote"])
+	assert.Equal(t, []any{float64(1), float64(2)}, config["list"])
+}
+
+func TestImageTag(t *testing.T) {
+	t.Run("hashes devcontainer.json and Dockerfile", func(t *testing.T) {
+		dir := writeWorkspace(t, map[string]string{
+			"devcontainer.json": `{"build": {"dockerfile": "Dockerfile"}}`,
+			"Dockerfile":        "FROM ubuntu\n",
+		})
+
+		tag, err := ImageTag(dir)
+		require.NoError(t, err)
+		assert.Regexp(t, `^headjack/my-app:[0-9a-f]{12}$`, tag)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".devcontainer", "Dockerfile"), []byte("FROM debian\n"), 0o600))
+		changed, err := ImageTag(dir)
+		require.NoError(t, err)
+		assert.NotEqual(t, tag, changed, "Dockerfile changes change the tag")
+	})
+
+	t.Run("returns ErrComposeConfig for compose dev containers", func(t *testing.T) {
+		dir := writeWorkspace(t, map[string]string{
+			"devcontainer.json": `{"dockerComposeFile": "compose.yml", "service": "app"}`,
+		})
+
+		_, err := ImageTag(dir)
+		assert.ErrorIs(t, err, ErrComposeConfig)
+	})
+
+	t.Run("returns error without devcontainer.json", func(t *testing.T) {
+		_, err := ImageTag(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestPrebuiltConfig(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"devcontainer.json": `{
+			"name": "app",
+			"build": {"dockerfile": "Dockerfile"},
+			"features": {"ghcr.io/devcontainers/features/go:1": {}},
+			"postCreateCommand": "make setup", // keep
+		}`,
+	})
+
+	data, err := overrideConfig(filepath.Join(dir, ".devcontainer", "devcontainer.json"), "headjack/app:0123456789ab", nil)
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "app", "image": "headjack/app:0123456789ab", "postCreateCommand": "make setup"}`, string(data))
+}
+
+func TestOverrideConfig_Mounts(t *testing.T) {
+	t.Run("appends mounts and keeps the build", func(t *testing.T) {
+		dir := writeWorkspace(t, map[string]string{
+			"devcontainer.json": `{
+				"build": {"dockerfile": "Dockerfile"},
+				"mounts": [{"type": "volume", "source": "data", "target": "/data"}]
+			}`,
+		})
+
+		data, err := overrideConfig(filepath.Join(dir, ".devcontainer", "devcontainer.json"), "",
+			[]string{"type=bind,source=/home/me/.ssh,target=/root/.ssh,readonly"})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"build": {"dockerfile": "Dockerfile"},
+			"mounts": [
+				{"type": "volume", "source": "data", "target": "/data"},
+				"type=bind,source=/home/me/.ssh,target=/root/.ssh,readonly"
+			]
+		}`, string(data))
+	})
+
+	t.Run("rejects compose configs", func(t *testing.T) {
+		dir := writeWorkspace(t, map[string]string{
+			"devcontainer.json": `{"dockerComposeFile": "compose.yaml", "service": "app"}`,
+		})
+
+		_, err := overrideConfig(filepath.Join(dir, ".devcontainer", "devcontainer.json"), "", []string{"type=bind,source=/a,target=/b,readonly"})
+
+		assert.ErrorIs(t, err, ErrComposeMounts)
+	})
+}
+
+func TestRemoteUser(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"devcontainer.json": `{"containerUser": "root", "remoteUser": "vscode"}`,
+	})
+	assert.Equal(t, "vscode", RemoteUser(dir))
+
+	dir = writeWorkspace(t, map[string]string{
+		"devcontainer.json": `{"containerUser": "dev"}`,
+	})
+	assert.Equal(t, "dev", RemoteUser(dir))
+
+	assert.Empty(t, RemoteUser(t.TempDir()))
+}
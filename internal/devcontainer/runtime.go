@@ -2,12 +2,17 @@
 package devcontainer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 
@@ -44,6 +49,80 @@ type upResult struct {
 	ContainerID           string `json:"containerId"`
 	RemoteUser            string `json:"remoteUser"`
 	RemoteWorkspaceFolder string `json:"remoteWorkspaceFolder"`
+	Message               string `json:"message"`     // Set if outcome is error
+	Description           string `json:"description"` // Set if outcome is error
+}
+
+// errorText returns the message of a failed devcontainer up.
+func (u *upResult) errorText() string {
+	switch {
+	case u.Message != "" && u.Description != "":
+		return u.Message + ": " + u.Description
+	case u.Message != "":
+		return u.Message
+	default:
+		return u.Description
+	}
+}
+
+// logRecord is a line of devcontainer CLI output in --log-format json.
+type logRecord struct {
+	Type  string `json:"type"` // text, raw, start, stop, progress
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// logLevelError is the level of error records in devcontainer CLI logs.
+const logLevelError = 5
+
+// logErrorText returns the text to report when a devcontainer CLI command
+// logging in --log-format json fails: the text of the last error record, or
+// else of the last text record. Output that isn't a log record, such as a
+// crash of the CLI itself, is returned as it is if there are no records.
+func logErrorText(data []byte) string {
+	var lastError, lastText string
+	var plain []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var rec logRecord
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &rec) != nil || rec.Type == "" {
+			if line != "" {
+				plain = append(plain, line)
+			}
+			continue
+		}
+		if rec.Type != "text" && rec.Type != "raw" {
+			continue
+		}
+		text := strings.TrimSpace(rec.Text)
+		if text == "" {
+			continue
+		}
+		if rec.Level >= logLevelError {
+			lastError = text
+		}
+		lastText = text
+	}
+	switch {
+	case lastError != "":
+		return lastError
+	case lastText != "":
+		return lastText
+	default:
+		return strings.Join(plain, "\n")
+	}
+}
+
+// captureStderr returns a writer for the stderr of a devcontainer CLI
+// command that streams to w, if it is not nil, and keeps a copy in buf for
+// error messages.
+func captureStderr(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
 }
 
 // Run creates a container using devcontainer up.
@@ -56,34 +135,78 @@ func (r *Runtime) Run(ctx context.Context, cfg *container.RunConfig) (*container
 		"up",
 		"--workspace-folder", cfg.WorkspaceFolder,
 		"--docker-path", r.dockerPath,
+		"--log-format", "json", // Structured logs for progress display
+	}
+
+	// Labels identify the container; devcontainer up also uses them to find
+	// an existing container for the workspace
+	for _, key := range slices.Sorted(maps.Keys(cfg.Labels)) {
+		args = append(args, "--id-label", key+"="+cfg.Labels[key])
+	}
+
+	// Mounts are added to the mounts of devcontainer.json. --mount has no
+	// read-only option, so read-only mounts go through an override config.
+	// tmpfs mounts are supported by neither.
+	var readOnly []string
+	for _, m := range cfg.Mounts {
+		if m.Type == container.MountTmpfs {
+			continue
+		}
+		typ := container.MountBind
+		if m.Type == container.MountVolume {
+			typ = container.MountVolume
+		}
+		mount := "type=" + string(typ) + ",source=" + m.Source + ",target=" + m.Target
+		if m.ReadOnly {
+			readOnly = append(readOnly, mount+",readonly")
+			continue
+		}
+		args = append(args, "--mount", mount)
+	}
+
+	// Use a prebuilt image instead of building one
+	if cfg.Image != "" || len(readOnly) > 0 {
+		overridePath, err := writeOverrideConfig(cfg.WorkspaceFolder, cfg.Image, readOnly)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(overridePath) }()
+		args = append(args, "--override-config", overridePath)
 	}
 
 	// Append any additional flags (passed via --)
 	args = append(args, cfg.Flags...)
 
+	var stderr bytes.Buffer
 	result, err := r.exec.Run(ctx, &exec.RunOptions{
 		Name:   r.cliPath,
 		Args:   args,
-		Stderr: cfg.Stderr, // Stream stderr if writer provided (for progress output)
+		Stderr: captureStderr(cfg.Stderr, &stderr), // Stream stderr if writer provided (for progress output)
 	})
+
+	// The result is printed to stdout even if up fails
+	var upRes upResult
+	var parseErr error
+	if result != nil {
+		parseErr = json.Unmarshal(result.Stdout, &upRes)
+	}
 	if err != nil {
-		stderr := ""
-		if result != nil {
-			stderr = strings.TrimSpace(string(result.Stderr))
+		if msg := upRes.errorText(); msg != "" {
+			return nil, fmt.Errorf("devcontainer up: %s", msg)
 		}
-		if stderr != "" {
-			return nil, fmt.Errorf("devcontainer up: %s", stderr)
+		if msg := logErrorText(stderr.Bytes()); msg != "" {
+			return nil, fmt.Errorf("devcontainer up: %s", msg)
 		}
 		return nil, fmt.Errorf("devcontainer up: %w", err)
 	}
-
-	// Parse JSON output
-	var upRes upResult
-	if err := json.Unmarshal(result.Stdout, &upRes); err != nil {
-		return nil, fmt.Errorf("parse devcontainer output: %w", err)
+	if parseErr != nil {
+		return nil, fmt.Errorf("parse devcontainer output: %w", parseErr)
 	}
 
 	if upRes.Outcome != "success" {
+		if msg := upRes.errorText(); msg != "" {
+			return nil, fmt.Errorf("devcontainer up failed: %s", msg)
+		}
 		return nil, fmt.Errorf("devcontainer up failed: %s", upRes.Outcome)
 	}
 
@@ -97,6 +220,58 @@ func (r *Runtime) Run(ctx context.Context, cfg *container.RunConfig) (*container
 	}, nil
 }
 
+// writeOverrideConfig writes the workspace's devcontainer.json, rewritten
+// by overrideConfig, to a temporary file and returns its path.
+func writeOverrideConfig(workspaceFolder, image string, mounts []string) (string, error) {
+	configPath := Detect(workspaceFolder)
+	if configPath == "" {
+		return "", errors.New("no devcontainer.json found")
+	}
+	data, err := overrideConfig(configPath, image, mounts)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "hjk-devcontainer-*.json")
+	if err != nil {
+		return "", fmt.Errorf("create devcontainer config: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("write devcontainer config: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// BuildImage builds the image of the dev container in workspaceFolder with
+// devcontainer build, including its features, and tags it. Build output
+// is streamed to stderr if it is not nil.
+func (r *Runtime) BuildImage(ctx context.Context, workspaceFolder, tag string, stderr io.Writer) error {
+	var output bytes.Buffer
+	_, err := r.exec.Run(ctx, &exec.RunOptions{
+		Name: r.cliPath,
+		Args: []string{
+			"build",
+			"--workspace-folder", workspaceFolder,
+			"--docker-path", r.dockerPath,
+			"--image-name", tag,
+			"--log-format", "json",
+		},
+		Stderr: captureStderr(stderr, &output),
+	})
+	if err != nil {
+		if msg := logErrorText(output.Bytes()); msg != "" {
+			return fmt.Errorf("%w: %s", container.ErrBuildFailed, msg)
+		}
+		return fmt.Errorf("%w: devcontainer build: %w", container.ErrBuildFailed, err)
+	}
+	return nil
+}
+
 // Exec executes a command using devcontainer exec.
 func (r *Runtime) Exec(ctx context.Context, id string, cfg *container.ExecConfig) error {
 	// Verify container exists and is running via underlying runtime
@@ -221,6 +396,16 @@ func (r *Runtime) Build(ctx context.Context, cfg *container.BuildConfig) error {
 	return r.underlying.Build(ctx, cfg)
 }
 
+// CopyTo delegates to the underlying runtime.
+func (r *Runtime) CopyTo(ctx context.Context, id, src, dst string) error {
+	return r.underlying.CopyTo(ctx, id, src, dst)
+}
+
+// CopyFrom delegates to the underlying runtime.
+func (r *Runtime) CopyFrom(ctx context.Context, id, src, dst string) error {
+	return r.underlying.CopyFrom(ctx, id, src, dst)
+}
+
 // ExecCommand returns the underlying runtime's exec command.
 func (r *Runtime) ExecCommand() []string {
 	return r.underlying.ExecCommand()
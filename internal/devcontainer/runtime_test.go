@@ -1,7 +1,13 @@
 package devcontainer
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +60,171 @@ func TestRuntime_Run(t *testing.T) {
 		assert.Equal(t, container.StatusRunning, c.Status)
 	})
 
+	t.Run("passes labels as id labels", func(t *testing.T) {
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"up",
+					"--workspace-folder", "/path/to/workspace",
+					"--docker-path", "docker",
+					"--log-format", "json",
+					"--id-label", "io.headjack.branch=main",
+					"--id-label", "io.headjack.instance-id=abc",
+					"--remove-existing-container",
+				}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"success","containerId":"abc123"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: "/path/to/workspace",
+			Flags:           []string{"--remove-existing-container"},
+			Labels: map[string]string{
+				container.LabelInstanceID: "abc",
+				container.LabelBranch:     "main",
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("passes volume mounts", func(t *testing.T) {
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"up",
+					"--workspace-folder", "/path/to/workspace",
+					"--docker-path", "docker",
+					"--log-format", "json",
+					"--mount", "type=volume,source=hjk-cache-npm,target=/home/vscode/.npm",
+				}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"success","containerId":"abc123"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: "/path/to/workspace",
+			Mounts: []container.Mount{
+				{Type: container.MountVolume, Source: "hjk-cache-npm", Target: "/home/vscode/.npm"},
+				{Type: container.MountTmpfs, Target: "/tmp"},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("passes read-only mounts through an override config", func(t *testing.T) {
+		workspace := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(workspace, ".devcontainer"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, ".devcontainer", "devcontainer.json"),
+			[]byte(`{"image": "ubuntu"}`), 0o600))
+
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.NotContains(t, opts.Args, "--mount")
+				i := slices.Index(opts.Args, "--override-config")
+				require.GreaterOrEqual(t, i, 0)
+
+				data, err := os.ReadFile(opts.Args[i+1])
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"image": "ubuntu", "mounts": ["type=bind,source=/home/me/.ssh,target=/root/.ssh,readonly"]}`, string(data))
+
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"success","containerId":"abc123"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: workspace,
+			Mounts: []container.Mount{
+				{Source: "/home/me/.ssh", Target: "/root/.ssh", ReadOnly: true},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("uses prebuilt image through an override config", func(t *testing.T) {
+		workspace := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(workspace, ".devcontainer"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, ".devcontainer", "devcontainer.json"),
+			[]byte(`{"build": {"dockerfile": "Dockerfile"}, "remoteUser": "vscode"}`), 0o600))
+
+		var overridePath string
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				i := slices.Index(opts.Args, "--override-config")
+				require.GreaterOrEqual(t, i, 0)
+				overridePath = opts.Args[i+1]
+
+				data, err := os.ReadFile(overridePath)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"image": "headjack/app:0123456789ab", "remoteUser": "vscode"}`, string(data))
+
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"success","containerId":"abc123"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			Image:           "headjack/app:0123456789ab",
+			WorkspaceFolder: workspace,
+		})
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, overridePath, "override config is removed")
+	})
+
+	t.Run("passes mounts", func(t *testing.T) {
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{
+					"up",
+					"--workspace-folder", "/path/to/workspace",
+					"--docker-path", "docker",
+					"--log-format", "json",
+					"--mount", "type=bind,source=/logs/abc12345,target=/var/log/headjack",
+				}, opts.Args)
+
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"success","containerId":"abc123"}`),
+				}, nil
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: "/path/to/workspace",
+			Mounts: []container.Mount{
+				{Source: "/logs/abc12345", Target: "/var/log/headjack"},
+			},
+		})
+
+		require.NoError(t, err)
+	})
+
 	t.Run("returns error when WorkspaceFolder is empty", func(t *testing.T) {
 		mockRT := &containermocks.RuntimeMock{}
 		mockExec := &execmocks.ExecutorMock{}
@@ -86,6 +257,126 @@ func TestRuntime_Run(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "devcontainer up failed")
 	})
+
+	t.Run("reports the message of a failed up", func(t *testing.T) {
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				_, _ = io.WriteString(opts.Stderr, `{"type":"text","level":3,"timestamp":1,"text":"Start: Run: docker build"}`+"\n")
+				return &exec.Result{
+					Stdout: []byte(`{"outcome":"error","message":"Command failed: docker build","description":"An error occurred building the image."}`),
+				}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: "/path/to/workspace",
+		})
+
+		require.EqualError(t, err, "devcontainer up: Command failed: docker build: An error occurred building the image.")
+	})
+
+	t.Run("reports log text instead of JSON records", func(t *testing.T) {
+		var streamed bytes.Buffer
+		mockRT := &containermocks.RuntimeMock{}
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				_, _ = io.WriteString(opts.Stderr,
+					`{"type":"start","level":2,"timestamp":1,"text":"Run: docker run"}`+"\n"+
+						`{"type":"text","level":5,"timestamp":2,"text":"Error: port is already allocated"}`+"\n"+
+						`{"type":"text","level":3,"timestamp":3,"text":"Exit code 125"}`+"\n")
+				return &exec.Result{}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		_, err := runtime.Run(ctx, &container.RunConfig{
+			Name:            "test-container",
+			WorkspaceFolder: "/path/to/workspace",
+			Stderr:          &streamed,
+		})
+
+		require.EqualError(t, err, "devcontainer up: Error: port is already allocated")
+		assert.Contains(t, streamed.String(), "Exit code 125", "stderr is still streamed")
+	})
+}
+
+func TestLogErrorText(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "last error record",
+			data: `{"type":"text","level":5,"text":"first error"}` + "\n" +
+				`{"type":"text","level":5,"text":"second error"}` + "\n" +
+				`{"type":"text","level":3,"text":"info"}` + "\n",
+			want: "second error",
+		},
+		{
+			name: "last text record without errors",
+			data: `{"type":"text","level":3,"text":"first"}` + "\n" +
+				`{"type":"raw","level":3,"text":"last\n"}` + "\n" +
+				`{"type":"stop","level":2,"text":"Run: docker build"}` + "\n",
+			want: "last",
+		},
+		{
+			name: "plain output",
+			data: "node: bad option\nusage: node\n",
+			want: "node: bad option\nusage: node",
+		},
+		{
+			name: "empty",
+			data: "",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, logErrorText([]byte(tt.data)))
+		})
+	}
+}
+
+func TestRuntime_BuildImage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("builds and tags image", func(t *testing.T) {
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "/usr/bin/devcontainer", opts.Name)
+				assert.Equal(t, []string{
+					"build",
+					"--workspace-folder", "/path/to/workspace",
+					"--docker-path", "podman",
+					"--image-name", "headjack/app:0123456789ab",
+					"--log-format", "json",
+				}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		runtime := NewRuntime(&containermocks.RuntimeMock{}, mockExec, "/usr/bin/devcontainer", "podman")
+		require.NoError(t, runtime.BuildImage(ctx, "/path/to/workspace", "headjack/app:0123456789ab", nil))
+	})
+
+	t.Run("returns ErrBuildFailed", func(t *testing.T) {
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(_ context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				_, _ = io.WriteString(opts.Stderr, `{"type":"text","level":5,"timestamp":1,"text":"Dockerfile not found"}`+"\n")
+				return &exec.Result{}, errors.New("exit code 1")
+			},
+		}
+
+		runtime := NewRuntime(&containermocks.RuntimeMock{}, mockExec, "/usr/bin/devcontainer", "docker")
+		err := runtime.BuildImage(ctx, "/path/to/workspace", "headjack/app:0123456789ab", nil)
+
+		require.ErrorIs(t, err, container.ErrBuildFailed)
+		assert.Contains(t, err.Error(), "Dockerfile not found")
+	})
 }
 
 func TestRuntime_Exec(t *testing.T) {
@@ -269,6 +560,27 @@ func TestRuntime_DelegatedMethods(t *testing.T) {
 		assert.True(t, buildCalled)
 	})
 
+	t.Run("CopyTo and CopyFrom delegate to underlying runtime", func(t *testing.T) {
+		mockRT := &containermocks.RuntimeMock{
+			CopyToFunc: func(_ context.Context, _, _, _ string) error {
+				return nil
+			},
+			CopyFromFunc: func(_ context.Context, _, _, _ string) error {
+				return nil
+			},
+		}
+		mockExec := &execmocks.ExecutorMock{}
+
+		runtime := NewRuntime(mockRT, mockExec, "/usr/bin/devcontainer", "docker")
+		require.NoError(t, runtime.CopyTo(ctx, "abc123", "./dist", "/workspaces/app"))
+		require.NoError(t, runtime.CopyFrom(ctx, "abc123", "/workspaces/app/out.log", "."))
+
+		require.Len(t, mockRT.CopyToCalls(), 1)
+		assert.Equal(t, "/workspaces/app", mockRT.CopyToCalls()[0].Dst)
+		require.Len(t, mockRT.CopyFromCalls(), 1)
+		assert.Equal(t, "/workspaces/app/out.log", mockRT.CopyFromCalls()[0].Src)
+	})
+
 	t.Run("ExecCommand delegates to underlying runtime", func(t *testing.T) {
 		mockRT := &containermocks.RuntimeMock{
 			ExecCommandFunc: func() []string {
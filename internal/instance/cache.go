@@ -0,0 +1,78 @@
+package instance
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+// CacheVolumePrefix is the name prefix of the volumes backing caches.
+const CacheVolumePrefix = containerNamePrefix + "-cache-"
+
+// defaultHome is the home directory of the container user when it is not
+// known otherwise.
+const defaultHome = "/root"
+
+// Cache is a named volume shared by all instances, such as a package
+// manager's download cache.
+type Cache struct {
+	Name   string // Cache name (e.g., "npm")
+	Target string // Mount point in the container; "~/" is the container user's home
+}
+
+// Volume returns the name of the volume backing the cache.
+func (c Cache) Volume() string {
+	return CacheVolumePrefix + c.Name
+}
+
+// HomeFor returns the home directory of a container user, by the usual
+// convention of /root for root and /home/<user> for everyone else.
+func HomeFor(user string) string {
+	if user == "" || user == "root" {
+		return defaultHome
+	}
+	return "/home/" + user
+}
+
+// cacheMounts returns volume mounts for caches, resolving targets under ~/
+// against home.
+func cacheMounts(caches []Cache, home string) []container.Mount {
+	mounts := make([]container.Mount, 0, len(caches))
+	for _, c := range caches {
+		target := c.Target
+		if rest, ok := strings.CutPrefix(target, "~/"); ok {
+			target = path.Join(home, rest)
+		}
+		mounts = append(mounts, container.Mount{Type: container.MountVolume, Source: c.Volume(), Target: target})
+	}
+	return mounts
+}
+
+// chownCaches gives the cache mount points of a new container to owner, the
+// non-root user commands run as. Volumes are created owned by root, so tools
+// would otherwise fail to write to them. If the chown fails, only a warning
+// is logged: the container still starts, but the caches stay owned by root,
+// so tools writing to them may fail.
+func (m *Manager) chownCaches(ctx context.Context, containerID, owner string, mounts []container.Mount) {
+	if owner == "" || owner == "root" || owner == "0:0" {
+		return
+	}
+
+	cmd := []string{"chown", owner}
+	for _, mount := range mounts {
+		if mount.Type == container.MountVolume && strings.HasPrefix(mount.Source, CacheVolumePrefix) {
+			cmd = append(cmd, mount.Target)
+		}
+	}
+	if len(cmd) == 2 {
+		return
+	}
+
+	if err := m.runtime.Exec(ctx, containerID, &container.ExecConfig{Command: cmd, User: "root"}); err != nil {
+		slogger.L(ctx).Warn("failed to set cache ownership", slog.String("owner", owner), slog.String("error", err.Error()))
+	}
+}
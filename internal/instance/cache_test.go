@@ -0,0 +1,86 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+)
+
+var testCaches = []Cache{
+	{Name: "npm", Target: "~/.npm"},
+	{Name: "go-mod", Target: "/go/pkg/mod"},
+}
+
+func TestHomeFor(t *testing.T) {
+	assert.Equal(t, "/root", HomeFor(""))
+	assert.Equal(t, "/root", HomeFor("root"))
+	assert.Equal(t, "/home/vscode", HomeFor("vscode"))
+}
+
+func TestManager_buildRunConfig_Caches(t *testing.T) {
+	t.Run("mounts cache volumes under the root home by default", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{Caches: testCaches})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{Image: "ubuntu"}, "hjk-test", "/worktree")
+
+		assert.Equal(t, []container.Mount{
+			{Source: "/worktree", Target: "/workspace"},
+			{Type: container.MountVolume, Source: "hjk-cache-npm", Target: "/root/.npm"},
+			{Type: container.MountVolume, Source: "hjk-cache-go-mod", Target: "/go/pkg/mod"},
+		}, runCfg.Mounts)
+	})
+
+	t.Run("resolves targets against the mapped user's home", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{Caches: testCaches, MapUser: true})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{Image: "ubuntu"}, "hjk-test", "/worktree")
+
+		require.Len(t, runCfg.Mounts, 3)
+		assert.Equal(t, HomeFor(hostUser().Name)+"/.npm", runCfg.Mounts[1].Target)
+	})
+
+	t.Run("resolves targets against the given home in devcontainer mode", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{Caches: testCaches})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{WorkspaceFolder: "/repo", Home: "/home/vscode"}, "hjk-test", "/worktree")
+
+		// The devcontainer CLI mounts the workspace itself
+		require.Len(t, runCfg.Mounts, 2)
+		assert.Equal(t, "/home/vscode/.npm", runCfg.Mounts[0].Target)
+	})
+}
+
+func TestManager_chownCaches(t *testing.T) {
+	mounts := cacheMounts(testCaches, "/home/vscode")
+	mounts = append(mounts, container.Mount{Type: container.MountVolume, Source: "data", Target: "/data"})
+
+	t.Run("gives cache mount points to the user", func(t *testing.T) {
+		runtime := &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, id string, cfg *container.ExecConfig) error { return nil },
+		}
+		mgr := NewManager(nil, runtime, nil, nil, &ManagerConfig{})
+
+		mgr.chownCaches(context.Background(), "c1", "vscode", mounts)
+
+		require.Len(t, runtime.ExecCalls(), 1)
+		assert.Equal(t, "c1", runtime.ExecCalls()[0].ID)
+		assert.Equal(t, &container.ExecConfig{
+			Command: []string{"chown", "vscode", "/home/vscode/.npm", "/go/pkg/mod"},
+			User:    "root",
+		}, runtime.ExecCalls()[0].Cfg)
+	})
+
+	t.Run("skips root", func(t *testing.T) {
+		runtime := &containermocks.RuntimeMock{}
+		mgr := NewManager(nil, runtime, nil, nil, &ManagerConfig{})
+
+		mgr.chownCaches(context.Background(), "c1", "root", mounts)
+
+		assert.Empty(t, runtime.ExecCalls())
+	})
+}
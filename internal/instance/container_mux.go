@@ -0,0 +1,106 @@
+package instance
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/multiplexer"
+)
+
+// containerLogDir is where the instance log directory is mounted inside the
+// container when the multiplexer runs in the container.
+const containerLogDir = "/var/log/headjack"
+
+// containerExecutor runs commands inside a container through the runtime's
+// exec command. It lets the tmux backend drive a tmux server that lives in
+// the container instead of on the host.
+type containerExecutor struct {
+	exec        exec.Executor
+	execCmd     []string // Runtime exec command prefix (e.g., ["docker", "exec"])
+	containerID string
+	user        string // User to run as (empty = container default)
+}
+
+// Run executes opts.Name inside the container. A TTY is allocated when stdin
+// is a terminal, so interactive commands such as tmux attach-session work.
+func (c *containerExecutor) Run(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+	var ttyFlags, flags []string
+	if opts.Stdin != nil {
+		ttyFlags = append(ttyFlags, "-i")
+		if f, ok := opts.Stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			ttyFlags = append(ttyFlags, "-t")
+		}
+	}
+	if opts.Dir != "" {
+		flags = append(flags, "-w", opts.Dir)
+	}
+	for _, e := range opts.Env {
+		flags = append(flags, "-e", e)
+	}
+	argv := c.argv(ttyFlags, flags, opts.Name, opts.Args)
+
+	return c.exec.Run(ctx, &exec.RunOptions{
+		Name:   argv[0],
+		Args:   argv[1:],
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Stderr: opts.Stderr,
+	})
+}
+
+// CommandLine returns the command line that runs name interactively inside
+// the container, for use in another terminal such as a grid pane.
+func (c *containerExecutor) CommandLine(name string, args []string) []string {
+	return c.argv([]string{"-i", "-t"}, nil, name, args)
+}
+
+// argv builds the runtime exec command line. ttyFlags and flags are exec
+// options placed before and after the user option.
+func (c *containerExecutor) argv(ttyFlags, flags []string, name string, args []string) []string {
+	argv := append([]string{}, c.execCmd...)
+	argv = append(argv, ttyFlags...)
+	if c.user != "" {
+		argv = append(argv, "-u", c.user)
+	}
+	argv = append(argv, flags...)
+	argv = append(argv, c.containerID, name)
+	return append(argv, args...)
+}
+
+// LookPath searches for an executable in the container's PATH.
+func (c *containerExecutor) LookPath(name string) (string, error) {
+	result, err := c.Run(context.Background(), &exec.RunOptions{
+		Name: "sh",
+		Args: []string{"-c", `command -v "$1"`, "sh", name},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
+
+// sessionMux returns the multiplexer that manages the entry's sessions.
+// Instances created in container mode use a tmux server inside the
+// container; all others use the host multiplexer.
+func (m *Manager) sessionMux(entry *catalog.Entry) sessionMultiplexer {
+	if entry.MuxMode != catalog.MuxModeContainer {
+		return m.mux
+	}
+	return multiplexer.NewTmux(&containerExecutor{
+		exec:        m.executor,
+		execCmd:     m.runtime.ExecCommand(),
+		containerID: entry.ContainerID,
+		user:        entry.RemoteUser,
+	})
+}
+
+// containerLogPath returns the path of a session log inside the container.
+func containerLogPath(sessionID string) string {
+	return path.Join(containerLogDir, sessionID+".log")
+}
@@ -0,0 +1,221 @@
+package instance
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+	"github.com/jmgilman/headjack/internal/exec"
+	execmocks "github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/git"
+	gitmocks "github.com/jmgilman/headjack/internal/git/mocks"
+)
+
+func TestContainerExecutor_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wraps command with runtime exec", func(t *testing.T) {
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker", opts.Name)
+				assert.Equal(t, []string{
+					"exec", "-u", "vscode", "-w", "/workspace", "-e", "FOO=bar",
+					"container-123", "tmux", "list-sessions",
+				}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+		ce := &containerExecutor{exec: mockExec, execCmd: []string{"docker", "exec"}, containerID: "container-123", user: "vscode"}
+
+		_, err := ce.Run(ctx, &exec.RunOptions{
+			Name: "tmux",
+			Args: []string{"list-sessions"},
+			Dir:  "/workspace",
+			Env:  []string{"FOO=bar"},
+		})
+
+		require.NoError(t, err)
+	})
+
+	t.Run("keeps stdin open without allocating a TTY for non-terminal input", func(t *testing.T) {
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"exec", "-i", "container-123", "cat"}, opts.Args)
+				assert.NotNil(t, opts.Stdin)
+				return &exec.Result{}, nil
+			},
+		}
+		ce := &containerExecutor{exec: mockExec, execCmd: []string{"docker", "exec"}, containerID: "container-123"}
+
+		_, err := ce.Run(ctx, &exec.RunOptions{Name: "cat", Stdin: strings.NewReader("input")})
+
+		require.NoError(t, err)
+	})
+}
+
+func TestContainerExecutor_CommandLine(t *testing.T) {
+	ce := &containerExecutor{execCmd: []string{"podman", "exec"}, containerID: "container-123", user: "vscode"}
+
+	got := ce.CommandLine("tmux", []string{"attach-session", "-t", "hjk-abc-main"})
+
+	assert.Equal(t, []string{
+		"podman", "exec", "-i", "-t", "-u", "vscode",
+		"container-123", "tmux", "attach-session", "-t", "hjk-abc-main",
+	}, got)
+}
+
+func TestManager_ContainerMuxMode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates session with tmux inside the container", func(t *testing.T) {
+		var tmuxCalls [][]string
+		mockExec := &execmocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "docker", opts.Name)
+				tmuxCalls = append(tmuxCalls, opts.Args)
+				if opts.Args[len(opts.Args)-3] == "list-sessions" {
+					return &exec.Result{Stderr: []byte("no server running on /tmp/tmux-1000/default"), ExitCode: 1}, assert.AnError
+				}
+				return &exec.Result{}, nil
+			},
+		}
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:          "abc12345",
+					ContainerID: "container-123",
+					MuxMode:     catalog.MuxModeContainer,
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"docker", "exec"}
+			},
+		}
+
+		// The log sink runs on the host, so it is not used inside the container
+		mgr := NewManager(store, runtime, nil, nil, &ManagerConfig{
+			LogsDir:  t.TempDir(),
+			Executor: mockExec,
+			LogSink:  []string{"hjk", "log-sink"},
+		})
+
+		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{
+			Command: []string{"npm", "test"},
+			Env:     []string{"CI=1"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, tmuxCalls, 3)
+		assert.Equal(t, []string{
+			"exec", "container-123", "tmux", "new-session", "-d", "-s", session.MuxSessionID,
+			"-c", "/workspace", "-e", "CI=1", "npm", "test",
+		}, tmuxCalls[1])
+		assert.Equal(t, []string{
+			"exec", "container-123", "tmux", "pipe-pane", "-t", session.MuxSessionID,
+			"cat >> '/var/log/headjack/" + session.ID + ".log'",
+		}, tmuxCalls[2])
+	})
+
+	t.Run("mounts instance log directory on create", func(t *testing.T) {
+		logsDir := t.TempDir()
+		var added *catalog.Entry
+		repo := &gitmocks.RepositoryMock{
+			IdentifierFunc:     func() string { return testRepoID },
+			RootFunc:           func() string { return testRepoPath },
+			CreateWorktreeFunc: func(ctx context.Context, path, branch string) error { return nil },
+		}
+		opener := &gitmocks.OpenerMock{
+			OpenFunc: func(ctx context.Context, path string) (git.Repository, error) { return repo, nil },
+		}
+		store := &catalogmocks.StoreMock{
+			GetByRepoBranchFunc: func(ctx context.Context, repoID, branch string) (*catalog.Entry, error) {
+				return nil, catalog.ErrNotFound
+			},
+			AddFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				added = entry
+				return nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error { return nil },
+		}
+		runtime := &containermocks.RuntimeMock{
+			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+		}
+
+		mgr := NewManager(store, runtime, opener, nil, &ManagerConfig{
+			WorktreesDir: "/data/worktrees",
+			LogsDir:      logsDir,
+			MuxMode:      catalog.MuxModeContainer,
+		})
+
+		_, err := mgr.Create(ctx, testRepoPath, &CreateConfig{Branch: "main", Image: "myimage:latest"})
+
+		require.NoError(t, err)
+		require.NotNil(t, added)
+		assert.Equal(t, catalog.MuxModeContainer, added.MuxMode)
+		runCfg := runtime.RunCalls()[0].Cfg
+		require.Len(t, runCfg.Mounts, 2)
+		assert.Equal(t, containerLogDir, runCfg.Mounts[1].Target)
+		assert.Equal(t, mgr.logPaths.InstanceDir(added.ID), runCfg.Mounts[1].Source)
+		assert.DirExists(t, runCfg.Mounts[1].Source)
+	})
+
+	t.Run("mounts instance log directory on devcontainer create", func(t *testing.T) {
+		logsDir := t.TempDir()
+		var added *catalog.Entry
+		repo := &gitmocks.RepositoryMock{
+			IdentifierFunc:     func() string { return testRepoID },
+			RootFunc:           func() string { return testRepoPath },
+			CreateWorktreeFunc: func(ctx context.Context, path, branch string) error { return nil },
+		}
+		opener := &gitmocks.OpenerMock{
+			OpenFunc: func(ctx context.Context, path string) (git.Repository, error) { return repo, nil },
+		}
+		store := &catalogmocks.StoreMock{
+			GetByRepoBranchFunc: func(ctx context.Context, repoID, branch string) (*catalog.Entry, error) {
+				return nil, catalog.ErrNotFound
+			},
+			AddFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				added = entry
+				return nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error { return nil },
+		}
+		runtime := &containermocks.RuntimeMock{
+			RunFunc: func(ctx context.Context, cfg *container.RunConfig) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+		}
+
+		mgr := NewManager(store, runtime, opener, nil, &ManagerConfig{
+			WorktreesDir: "/data/worktrees",
+			LogsDir:      logsDir,
+			MuxMode:      catalog.MuxModeContainer,
+		})
+
+		_, err := mgr.Create(ctx, testRepoPath, &CreateConfig{Branch: "main", WorkspaceFolder: "/data/worktrees/main"})
+
+		require.NoError(t, err)
+		require.NotNil(t, added)
+		// The devcontainer runtime passes the mount to devcontainer up with --mount
+		runCfg := runtime.RunCalls()[0].Cfg
+		require.Len(t, runCfg.Mounts, 1)
+		assert.Equal(t, container.Mount{Source: mgr.logPaths.InstanceDir(added.ID), Target: containerLogDir}, runCfg.Mounts[0])
+	})
+}
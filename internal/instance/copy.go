@@ -0,0 +1,91 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	"github.com/jmgilman/headjack/internal/container"
+)
+
+// containerWorkdir returns the working directory of an instance's
+// container: the devcontainer's remote workspace folder if set, otherwise
+// the mounted worktree.
+func containerWorkdir(entry *catalog.Entry) string {
+	if entry.RemoteWorkdir != "" {
+		return entry.RemoteWorkdir
+	}
+	return "/workspace"
+}
+
+// containerPath resolves a path in an instance's container, relative to its
+// working directory.
+func containerPath(entry *catalog.Entry, p string) string {
+	if path.IsAbs(p) {
+		return p
+	}
+	// Keep a trailing slash, which marks a directory
+	resolved := path.Join(containerWorkdir(entry), p)
+	if strings.HasSuffix(p, "/") {
+		resolved += "/"
+	}
+	return resolved
+}
+
+// CopyTo copies the file or directory src on the host to dst in an
+// instance's container. A relative dst is relative to the instance's
+// working directory. If dst is an existing directory, src is copied into
+// it, otherwise it is copied as dst. The container may be stopped; ErrNotFound
+// is returned if it no longer exists.
+func (m *Manager) CopyTo(ctx context.Context, instanceID, src, dst string) error {
+	entry, err := m.getCopyEntry(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+
+	err = m.runtime.CopyTo(ctx, entry.ContainerID, src, containerPath(entry, dst))
+	if errors.Is(err, container.ErrNotFound) {
+		return fmt.Errorf("%w: container %s no longer exists", ErrNotFound, entry.ContainerID)
+	}
+	if err != nil {
+		return fmt.Errorf("copy to container: %w", err)
+	}
+	return nil
+}
+
+// CopyFrom copies the file or directory src in an instance's container to
+// dst on the host, with the same rules as CopyTo.
+func (m *Manager) CopyFrom(ctx context.Context, instanceID, src, dst string) error {
+	entry, err := m.getCopyEntry(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+
+	err = m.runtime.CopyFrom(ctx, entry.ContainerID, containerPath(entry, src), dst)
+	if errors.Is(err, container.ErrNotFound) {
+		return fmt.Errorf("%w: container %s no longer exists", ErrNotFound, entry.ContainerID)
+	}
+	if err != nil {
+		return fmt.Errorf("copy from container: %w", err)
+	}
+	return nil
+}
+
+// getCopyEntry returns the catalog entry of an instance to copy files to or
+// from. Unlike exec, copying doesn't need the container to be running.
+func (m *Manager) getCopyEntry(ctx context.Context, instanceID string) (*catalog.Entry, error) {
+	entry, err := m.catalog.Get(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get catalog entry: %w", err)
+	}
+	if entry.ContainerID == "" {
+		return nil, fmt.Errorf("%w: instance has no container", ErrNotFound)
+	}
+	return entry, nil
+}
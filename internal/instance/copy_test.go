@@ -0,0 +1,81 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+)
+
+func TestContainerPath(t *testing.T) {
+	vanilla := &catalog.Entry{}
+	devcontainer := &catalog.Entry{RemoteWorkdir: "/workspaces/app"}
+
+	assert.Equal(t, "/workspace/dist", containerPath(vanilla, "dist"))
+	assert.Equal(t, "/workspaces/app/dist/", containerPath(devcontainer, "dist/"))
+	assert.Equal(t, "/workspaces/app", containerPath(devcontainer, "."))
+	assert.Equal(t, "/tmp/out.log", containerPath(devcontainer, "/tmp/out.log"))
+}
+
+func TestManager_Copy(t *testing.T) {
+	ctx := context.Background()
+	store := &catalogmocks.StoreMock{
+		GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+			return &catalog.Entry{ID: id, ContainerID: "container-123", RemoteWorkdir: "/workspaces/app"}, nil
+		},
+	}
+
+	t.Run("copies to the workdir", func(t *testing.T) {
+		rt := runningRuntime()
+		rt.CopyToFunc = func(_ context.Context, _, _, _ string) error { return nil }
+		mgr := NewManager(store, rt, nil, nil, &ManagerConfig{})
+
+		require.NoError(t, mgr.CopyTo(ctx, "inst-1", "./fixtures", "testdata"))
+
+		require.Len(t, rt.CopyToCalls(), 1)
+		call := rt.CopyToCalls()[0]
+		assert.Equal(t, "container-123", call.ID)
+		assert.Equal(t, "./fixtures", call.Src)
+		assert.Equal(t, "/workspaces/app/testdata", call.Dst)
+	})
+
+	t.Run("copies from the workdir", func(t *testing.T) {
+		rt := runningRuntime()
+		rt.CopyFromFunc = func(_ context.Context, _, _, _ string) error { return nil }
+		mgr := NewManager(store, rt, nil, nil, &ManagerConfig{})
+
+		require.NoError(t, mgr.CopyFrom(ctx, "inst-1", "coverage.out", "."))
+
+		require.Len(t, rt.CopyFromCalls(), 1)
+		call := rt.CopyFromCalls()[0]
+		assert.Equal(t, "/workspaces/app/coverage.out", call.Src)
+		assert.Equal(t, ".", call.Dst)
+	})
+
+	t.Run("copies while the container is stopped", func(t *testing.T) {
+		rt := &containermocks.RuntimeMock{
+			CopyFromFunc: func(_ context.Context, _, _, _ string) error { return nil },
+		}
+		mgr := NewManager(store, rt, nil, nil, &ManagerConfig{})
+
+		require.NoError(t, mgr.CopyFrom(ctx, "inst-1", "coverage.out", "."))
+		assert.Len(t, rt.CopyFromCalls(), 1)
+	})
+
+	t.Run("returns ErrNotFound when the container is gone", func(t *testing.T) {
+		rt := &containermocks.RuntimeMock{
+			CopyToFunc: func(_ context.Context, _, _, _ string) error { return container.ErrNotFound },
+		}
+		mgr := NewManager(store, rt, nil, nil, &ManagerConfig{})
+
+		err := mgr.CopyTo(ctx, "inst-1", "./fixtures", "testdata")
+
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
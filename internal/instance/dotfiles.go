@@ -0,0 +1,130 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+// containerDotfilesDir is where a host dotfiles directory is mounted inside
+// the container. The install script copies it into the home directory.
+const containerDotfilesDir = "/opt/headjack/dotfiles"
+
+// dotfilesLog is the log of the dotfiles install, relative to the home
+// directory of the container user.
+const dotfilesLog = ".dotfiles-install.log"
+
+// dotfilesScript installs dotfiles into the home directory of the user it
+// runs as, unless they are already there. The dotfiles are copied from the
+// mounted host directory or cloned, then the install script is run, or else
+// they are linked into the home directory. Output goes to the log, since
+// creation progress is shown on the terminal.
+const dotfilesScript = `set -e
+dir="$HOME/dotfiles"
+[ -e "$dir" ] && exit 0
+exec >"$HOME/` + dotfilesLog + `" 2>&1
+if [ -n "$HJK_DOTFILES_DIR" ]; then
+	cp -R "$HJK_DOTFILES_DIR" "$dir"
+else
+	git clone --depth 1 "$HJK_DOTFILES_REPO" "$dir"
+fi
+cd "$dir"
+script="$HJK_DOTFILES_INSTALL"
+if [ -z "$script" ]; then
+	for f in install.sh install bootstrap.sh bootstrap script/bootstrap setup.sh setup script/setup; do
+		if [ -f "$f" ]; then script="$f"; break; fi
+	done
+fi
+if [ -n "$script" ]; then
+	chmod +x "$script"
+	exec "./$script"
+fi
+for f in .[!.]*; do
+	[ -e "$f" ] && [ "$f" != .git ] && ln -sf "$dir/$f" "$HOME/$f"
+done
+exit 0`
+
+// Dotfiles configures the dotfiles installed into new containers.
+type Dotfiles struct {
+	Source  string // Git repository URL, or absolute path of a host directory
+	Install string // Install script relative to the dotfiles root (empty = detect)
+}
+
+// isDir reports whether the dotfiles come from a host directory.
+func (d *Dotfiles) isDir() bool {
+	return filepath.IsAbs(d.Source)
+}
+
+// validateSources checks that the host paths of mounts and dotfiles exist.
+func (m *Manager) validateSources() error {
+	sources := make([]string, 0, len(m.mounts)+1)
+	for _, mount := range m.mounts {
+		sources = append(sources, mount.Source)
+	}
+	if m.dotfiles != nil && m.dotfiles.isDir() {
+		sources = append(sources, m.dotfiles.Source)
+	}
+
+	for _, source := range sources {
+		if _, err := os.Stat(source); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%w: %s", ErrMountSourceNotFound, source)
+			}
+			return fmt.Errorf("check mount source: %w", err)
+		}
+	}
+	return nil
+}
+
+// extraMounts returns the mounts configured for all new containers: host
+// paths, the dotfiles directory and caches. Targets under ~/ are resolved
+// against the home directory of the container user.
+func (m *Manager) extraMounts(cfg *CreateConfig) []container.Mount {
+	home := m.containerHome(cfg)
+
+	var mounts []container.Mount
+	for _, mount := range m.mounts {
+		if rest, ok := strings.CutPrefix(mount.Target, "~/"); ok {
+			mount.Target = path.Join(home, rest)
+		}
+		mounts = append(mounts, mount)
+	}
+	if m.dotfiles != nil && m.dotfiles.isDir() {
+		mounts = append(mounts, container.Mount{Source: m.dotfiles.Source, Target: containerDotfilesDir, ReadOnly: true})
+	}
+	return append(mounts, cacheMounts(m.caches, home)...)
+}
+
+// installDotfiles installs the configured dotfiles into a new container as
+// user (empty = container default). A failed clone or install script doesn't
+// fail the run: a warning points to the install log in the container, whose
+// home may then be partially set up.
+func (m *Manager) installDotfiles(ctx context.Context, containerID, user string) {
+	if m.dotfiles == nil || m.dotfiles.Source == "" {
+		return
+	}
+
+	env := []string{"HJK_DOTFILES_INSTALL=" + m.dotfiles.Install}
+	if m.dotfiles.isDir() {
+		env = append(env, "HJK_DOTFILES_DIR="+containerDotfilesDir)
+	} else {
+		env = append(env, "HJK_DOTFILES_REPO="+m.dotfiles.Source)
+	}
+
+	slogger.L(ctx).Debug("installing dotfiles", slog.String("source", m.dotfiles.Source))
+	if err := m.runtime.Exec(ctx, containerID, &container.ExecConfig{
+		Command: []string{"sh", "-c", dotfilesScript},
+		Env:     env,
+		User:    user,
+	}); err != nil {
+		slogger.L(ctx).Warn("failed to install dotfiles, see ~/"+dotfilesLog+" in the instance",
+			slog.String("source", m.dotfiles.Source), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,121 @@
+package instance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+)
+
+func TestManager_buildRunConfig_Mounts(t *testing.T) {
+	dotfiles := t.TempDir()
+
+	t.Run("adds host mounts and the dotfiles directory", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{
+			Mounts: []container.Mount{
+				{Source: "/home/me/.ssh", Target: "~/.ssh", ReadOnly: true},
+				{Source: "/data", Target: "/data"},
+			},
+			Dotfiles: &Dotfiles{Source: dotfiles},
+		})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{Image: "ubuntu"}, "hjk-test", "/worktree")
+
+		assert.Equal(t, []container.Mount{
+			{Source: "/worktree", Target: "/workspace"},
+			{Source: "/home/me/.ssh", Target: "/root/.ssh", ReadOnly: true},
+			{Source: "/data", Target: "/data"},
+			{Source: dotfiles, Target: containerDotfilesDir, ReadOnly: true},
+		}, runCfg.Mounts)
+	})
+
+	t.Run("does not mount dotfiles repositories", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{
+			Dotfiles: &Dotfiles{Source: "https://github.com/me/dotfiles"},
+		})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{WorkspaceFolder: "/repo"}, "hjk-test", "/worktree")
+
+		assert.Empty(t, runCfg.Mounts)
+	})
+}
+
+func TestManager_validateSources(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("accepts existing sources", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{
+			Mounts:   []container.Mount{{Source: dir, Target: "/data"}},
+			Dotfiles: &Dotfiles{Source: "https://github.com/me/dotfiles"},
+		})
+
+		assert.NoError(t, mgr.validateSources())
+	})
+
+	t.Run("rejects a missing mount source", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{
+			Mounts: []container.Mount{{Source: dir + "/missing", Target: "/data"}},
+		})
+
+		assert.ErrorIs(t, mgr.validateSources(), ErrMountSourceNotFound)
+	})
+
+	t.Run("rejects a missing dotfiles directory", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{
+			Dotfiles: &Dotfiles{Source: dir + "/dotfiles"},
+		})
+
+		assert.ErrorIs(t, mgr.validateSources(), ErrMountSourceNotFound)
+	})
+}
+
+func TestManager_installDotfiles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clones a repository as the given user", func(t *testing.T) {
+		runtime := &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, id string, cfg *container.ExecConfig) error { return nil },
+		}
+		mgr := NewManager(nil, runtime, nil, nil, &ManagerConfig{
+			Dotfiles: &Dotfiles{Source: "https://github.com/me/dotfiles", Install: "setup.sh"},
+		})
+
+		mgr.installDotfiles(ctx, "c1", "vscode")
+
+		require.Len(t, runtime.ExecCalls(), 1)
+		cfg := runtime.ExecCalls()[0].Cfg
+		assert.Equal(t, []string{"sh", "-c", dotfilesScript}, cfg.Command)
+		assert.Equal(t, "vscode", cfg.User)
+		assert.ElementsMatch(t, []string{
+			"HJK_DOTFILES_INSTALL=setup.sh",
+			"HJK_DOTFILES_REPO=https://github.com/me/dotfiles",
+		}, cfg.Env)
+	})
+
+	t.Run("copies a mounted directory", func(t *testing.T) {
+		runtime := &containermocks.RuntimeMock{
+			ExecFunc: func(ctx context.Context, id string, cfg *container.ExecConfig) error { return nil },
+		}
+		mgr := NewManager(nil, runtime, nil, nil, &ManagerConfig{
+			Dotfiles: &Dotfiles{Source: "/home/me/dotfiles"},
+		})
+
+		mgr.installDotfiles(ctx, "c1", "")
+
+		require.Len(t, runtime.ExecCalls(), 1)
+		assert.Contains(t, runtime.ExecCalls()[0].Cfg.Env, "HJK_DOTFILES_DIR="+containerDotfilesDir)
+	})
+
+	t.Run("does nothing without dotfiles", func(t *testing.T) {
+		runtime := &containermocks.RuntimeMock{}
+		mgr := NewManager(nil, runtime, nil, nil, &ManagerConfig{})
+
+		mgr.installDotfiles(ctx, "c1", "")
+
+		assert.Empty(t, runtime.ExecCalls())
+	})
+}
@@ -18,6 +18,8 @@ var (
 	ErrSessionExists       = errors.New("session already exists")
 	ErrInstanceNotRunning  = errors.New("instance is not running")
 	ErrNoSessionsAvailable = errors.New("no sessions available")
+	ErrMountSourceNotFound = errors.New("mount source does not exist")
+	ErrPortNotForwarded    = errors.New("port is not forwarded")
 )
 
 // NotRunningError describes an instance whose container is not running.
@@ -56,16 +58,18 @@ type Instance struct {
 	Container   *container.Container // Live container state (nil if not running)
 	CreatedAt   time.Time
 	Status      Status
+	Ports       []PortForward // Forwarded ports
 }
 
 // CreateConfig configures instance creation.
 type CreateConfig struct {
 	Branch          string            // Branch to create or checkout
-	Image           string            // OCI image to use for container (vanilla mode)
+	Image           string            // OCI image to use for container (vanilla mode), or prebuilt image (devcontainer mode)
 	WorkspaceFolder string            // Path to folder with devcontainer.json (devcontainer mode)
 	Runtime         container.Runtime // Optional runtime override (for devcontainer)
 	RuntimeFlags    []string          // Additional flags to pass to the container runtime
 	Stderr          io.Writer         // Optional: stream stderr during creation (for progress output)
+	Home            string            // Home directory of the container user, for cache targets under ~/ (default: /root)
 }
 
 // AttachConfig configures instance attachment.
@@ -91,6 +95,13 @@ type Session struct {
 	MuxSessionID string    // Multiplexer session identifier
 	CreatedAt    time.Time // Creation timestamp
 	LastAccessed time.Time // Last access timestamp (for MRU tracking)
+	Profile      string    // Credential profile used by the agent (empty for shell)
+}
+
+// GridTarget identifies a session to show in a grid (see Manager.AttachGrid).
+type GridTarget struct {
+	InstanceID  string // Instance containing the session
+	SessionName string // Human-readable session name
 }
 
 // CreateSessionConfig configures session creation.
@@ -101,4 +112,5 @@ type CreateSessionConfig struct {
 	Env                []string // Additional environment variables
 	CredentialType     string   // Credential type: "subscription" or "apikey" (empty for shell)
 	RequiresAgentSetup bool     // Whether agent needs file setup in container
+	Profile            string   // Credential profile the agent authenticated with (empty for shell)
 }
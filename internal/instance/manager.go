@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"github.com/jmgilman/headjack/internal/multiplexer"
 	"github.com/jmgilman/headjack/internal/names"
 	"github.com/jmgilman/headjack/internal/slogger"
+	"github.com/jmgilman/headjack/internal/version"
 )
 
 // containerNamePrefix is the prefix for all managed containers.
@@ -44,6 +46,8 @@ type containerRuntime interface {
 	Remove(ctx context.Context, id string) error
 	Get(ctx context.Context, id string) (*container.Container, error)
 	List(ctx context.Context, filter container.ListFilter) ([]container.Container, error)
+	CopyTo(ctx context.Context, id, src, dst string) error
+	CopyFrom(ctx context.Context, id, src, dst string) error
 	ExecCommand() []string
 }
 
@@ -56,8 +60,11 @@ type gitOpener interface {
 type sessionMultiplexer interface {
 	CreateSession(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error)
 	AttachSession(ctx context.Context, sessionName string) error
+	AttachCommand(sessionName string) []string
 	ListSessions(ctx context.Context) ([]multiplexer.Session, error)
 	KillSession(ctx context.Context, sessionName string) error
+	SendKeys(ctx context.Context, sessionName, text string, enter bool) error
+	CapturePane(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error)
 }
 
 // RuntimeType identifies the container runtime being used.
@@ -65,17 +72,26 @@ type RuntimeType string
 
 // Runtime type constants.
 const (
-	RuntimePodman RuntimeType = "podman"
-	RuntimeDocker RuntimeType = "docker"
+	RuntimePodman  RuntimeType = "podman"
+	RuntimeDocker  RuntimeType = "docker"
+	RuntimeNerdctl RuntimeType = "nerdctl"
 )
 
 // ManagerConfig configures the Manager.
 type ManagerConfig struct {
-	WorktreesDir string        // Directory for storing worktrees (e.g., ~/.local/share/headjack/git)
-	LogsDir      string        // Directory for storing logs (e.g., ~/.local/share/headjack/logs)
-	RuntimeType  RuntimeType   // Container runtime type (docker or podman)
-	ConfigFlags  []string      // Additional flags to pass to the container runtime
-	Executor     exec.Executor // Command executor (for devcontainer runtime creation)
+	WorktreesDir string            // Directory for storing worktrees (e.g., ~/.local/share/headjack/git)
+	LogsDir      string            // Directory for storing logs (e.g., ~/.local/share/headjack/logs)
+	RuntimeType  RuntimeType       // Container runtime type (docker, podman or nerdctl)
+	ConfigFlags  []string          // Additional flags to pass to the container runtime
+	Executor     exec.Executor     // Command executor (for devcontainer runtime creation and in-container tmux)
+	MuxMode      catalog.MuxMode   // Where new instances run their multiplexer (default: host)
+	LogSink      []string          // Command that records host multiplexer output (optional, see multiplexer.CreateSessionOpts)
+	MapUser      bool              // Run vanilla containers as the host user
+	Caches       []Cache           // Volumes shared by all new instances
+	Mounts       []container.Mount // Host paths mounted into all new instances
+	Dotfiles     *Dotfiles         // Dotfiles installed into new instances (optional)
+	ProxyCommand []string          // Command that serves a forwarded port (see ForwardPort)
+	PortsDir     string            // Directory for the pidfiles of port proxies
 }
 
 // Manager orchestrates instance lifecycle operations.
@@ -90,6 +106,14 @@ type Manager struct {
 	worktreesDir string
 	runtimeType  RuntimeType
 	configFlags  []string
+	muxMode      catalog.MuxMode
+	logSink      []string
+	mapUser      bool
+	caches       []Cache
+	mounts       []container.Mount
+	dotfiles     *Dotfiles
+	proxyCommand []string
+	portsDir     string
 }
 
 // NewManager creates a new instance manager.
@@ -99,6 +123,11 @@ func NewManager(store catalogStore, runtime containerRuntime, opener gitOpener,
 		runtimeType = RuntimeDocker
 	}
 
+	muxMode := cfg.MuxMode
+	if muxMode == "" {
+		muxMode = catalog.MuxModeHost
+	}
+
 	// Type assert to get public runtime interface (all container.Runtime implementations satisfy containerRuntime)
 	var publicRT container.Runtime
 	if rt, ok := runtime.(container.Runtime); ok {
@@ -116,6 +145,14 @@ func NewManager(store catalogStore, runtime containerRuntime, opener gitOpener,
 		worktreesDir: cfg.WorktreesDir,
 		runtimeType:  runtimeType,
 		configFlags:  cfg.ConfigFlags,
+		muxMode:      muxMode,
+		logSink:      cfg.LogSink,
+		mapUser:      cfg.MapUser,
+		caches:       cfg.Caches,
+		mounts:       cfg.Mounts,
+		dotfiles:     cfg.Dotfiles,
+		proxyCommand: cfg.ProxyCommand,
+		portsDir:     cfg.PortsDir,
 	}
 }
 
@@ -142,6 +179,10 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 		return nil, fmt.Errorf("open repository: %w", err)
 	}
 
+	if err := m.validateSources(); err != nil {
+		return nil, err
+	}
+
 	repoID := repo.Identifier()
 	log.Debug("opened repository", slog.String("id", repoID), slog.String("root", repo.Root()))
 
@@ -173,6 +214,7 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 		Worktree:  worktreePath,
 		CreatedAt: time.Now(),
 		Status:    catalog.StatusCreating,
+		MuxMode:   m.muxMode,
 	}
 	if addErr := m.catalog.Add(ctx, &entry); addErr != nil {
 		return nil, fmt.Errorf("add catalog entry: %w", addErr)
@@ -183,6 +225,17 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 		_ = m.catalog.Remove(ctx, id) //nolint:errcheck // best-effort cleanup
 	}
 
+	// In container mode, tmux inside the container streams session output
+	// to the instance log directory through a bind mount.
+	var logDir string
+	if m.muxMode == catalog.MuxModeContainer {
+		logDir, err = m.logPaths.EnsureInstanceDir(id)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
 	// Create worktree
 	log.Debug("creating worktree", slog.String("path", worktreePath), slog.String("branch", cfg.Branch))
 	if wtErr := repo.CreateWorktree(ctx, worktreePath, cfg.Branch); wtErr != nil {
@@ -196,6 +249,10 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 	// Build container run config based on mode (devcontainer vs vanilla)
 	runCfg := m.buildRunConfig(cfg, containerName, worktreePath)
 	runCfg.Stderr = cfg.Stderr // Pass through stderr writer for progress output
+	runCfg.Labels = containerLabels(id, repoID, cfg.Branch)
+	if logDir != "" {
+		runCfg.Mounts = append(runCfg.Mounts, container.Mount{Source: logDir, Target: containerLogDir})
+	}
 
 	// Create container
 	log.Debug("creating container", slog.String("name", containerName), slog.String("image", cfg.Image))
@@ -209,6 +266,8 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 		cleanup()
 		return nil, fmt.Errorf("create container: %w", err)
 	}
+	m.chownCaches(ctx, c.ID, cacheOwner(c, runCfg), runCfg.Mounts)
+	m.installDotfiles(ctx, c.ID, c.RemoteUser)
 
 	// Update catalog with container info (including devcontainer-specific fields if present)
 	entry.ContainerID = c.ID
@@ -248,6 +307,17 @@ func (m *Manager) Create(ctx context.Context, repoPath string, cfg *CreateConfig
 	}, nil
 }
 
+// containerLabels returns the labels identifying the container of an instance.
+func containerLabels(id, repoID, branch string) map[string]string {
+	return map[string]string{
+		container.LabelInstanceID: id,
+		container.LabelRepoID:     repoID,
+		container.LabelBranch:     branch,
+		container.LabelVersion:    version.Version,
+		container.LabelCreatedBy:  "headjack",
+	}
+}
+
 // selectRuntime returns the provided runtime override if set, otherwise the manager's default.
 func (m *Manager) selectRuntime(override containerRuntime) containerRuntime {
 	if override != nil {
@@ -264,27 +334,56 @@ func (m *Manager) buildRunConfig(cfg *CreateConfig, containerName, worktreePath
 	// Merge config flags with CLI flags (config first, CLI flags appended)
 	flags := m.mergeFlags(cfg.RuntimeFlags)
 
-	// Devcontainer mode: minimal config, devcontainer CLI handles the rest
+	// Devcontainer mode: minimal config, devcontainer CLI handles the rest,
+	// including mounting the workspace
 	if cfg.WorkspaceFolder != "" {
 		return &container.RunConfig{
 			Name:            containerName,
+			Image:           cfg.Image, // Prebuilt image, if any
 			WorkspaceFolder: cfg.WorkspaceFolder,
-			Mounts: []container.Mount{
-				{Source: worktreePath, Target: "/workspace", ReadOnly: false},
-			},
-			Flags: flags,
+			Mounts:          m.extraMounts(cfg),
+			Flags:           flags,
 		}
 	}
 
 	// Vanilla mode: use merged flags
-	return &container.RunConfig{
+	runCfg := &container.RunConfig{
 		Name:  containerName,
 		Image: cfg.Image,
-		Mounts: []container.Mount{
+		Mounts: append([]container.Mount{
 			{Source: worktreePath, Target: "/workspace", ReadOnly: false},
-		},
+		}, m.extraMounts(cfg)...),
 		Flags: flags,
 	}
+	if m.mapUser {
+		runCfg.User = hostUser()
+	}
+	return runCfg
+}
+
+// containerHome returns the home directory of the user commands run as in
+// the new container, which cache targets under ~/ are relative to.
+func (m *Manager) containerHome(cfg *CreateConfig) string {
+	switch {
+	case cfg.Home != "":
+		return cfg.Home
+	case cfg.WorkspaceFolder == "" && m.mapUser:
+		return HomeFor(hostUser().Name)
+	default:
+		return defaultHome
+	}
+}
+
+// cacheOwner returns the user that should own cache mount points of a new
+// container: the devcontainer remote user, or the mapped host user.
+func cacheOwner(c *container.Container, runCfg *container.RunConfig) string {
+	if c.RemoteUser != "" {
+		return c.RemoteUser
+	}
+	if runCfg.User != nil {
+		return fmt.Sprintf("%d:%d", runCfg.User.UID, runCfg.User.GID)
+	}
+	return ""
 }
 
 // mergeFlags combines config flags with CLI flags.
@@ -374,6 +473,10 @@ func (m *Manager) Stop(ctx context.Context, id string) error {
 		return err
 	}
 
+	// Files written by the container as another user break git operations
+	// on the worktree from the host
+	m.repairOwnership(ctx, entry)
+
 	entry.Status = catalog.StatusStopped
 	if err := m.catalog.Update(ctx, entry); err != nil {
 		return fmt.Errorf("update catalog entry: %w", err)
@@ -413,7 +516,7 @@ func (m *Manager) Start(ctx context.Context, id string) error {
 }
 
 // waitForSessionsTerminated polls until all sessions are gone or timeout.
-func (m *Manager) waitForSessionsTerminated(ctx context.Context, sessions []catalog.Session) error {
+func (m *Manager) waitForSessionsTerminated(ctx context.Context, mux sessionMultiplexer, sessions []catalog.Session) error {
 	// Build a set of session names to wait for
 	waiting := make(map[string]bool)
 	for _, s := range sessions {
@@ -432,7 +535,7 @@ func (m *Manager) waitForSessionsTerminated(ctx context.Context, sessions []cata
 		case <-timeout:
 			return errors.New("timeout waiting for sessions to terminate")
 		case <-ticker.C:
-			liveSessions, err := m.mux.ListSessions(ctx)
+			liveSessions, err := mux.ListSessions(ctx)
 			if err != nil {
 				// If we can't list, assume they're gone
 				return nil
@@ -468,10 +571,10 @@ func (m *Manager) shutdownContainer(ctx context.Context, entry *catalog.Entry, o
 	// Kill all sessions before stopping the container.
 	// The container stop will fail with "Resource busy" if there are active
 	// multiplexer sessions connected to processes inside the container.
-	if m.mux != nil && len(entry.Sessions) > 0 {
+	if mux := m.sessionMux(entry); mux != nil && len(entry.Sessions) > 0 {
 		for _, sess := range entry.Sessions {
 			// Best-effort kill - session may already be dead
-			_ = m.mux.KillSession(ctx, sess.MuxSessionID) //nolint:errcheck
+			_ = mux.KillSession(ctx, sess.MuxSessionID) //nolint:errcheck
 
 			// Remove session log (best-effort)
 			_ = m.logPaths.RemoveSessionLog(entry.ID, sess.ID) //nolint:errcheck
@@ -480,11 +583,12 @@ func (m *Manager) shutdownContainer(ctx context.Context, entry *catalog.Entry, o
 		// Wait for sessions to fully terminate. The kill is async and processes
 		// inside the container may still be running briefly after the kill returns.
 		// Best-effort wait - we'll try to stop the container anyway
-		_ = m.waitForSessionsTerminated(ctx, entry.Sessions) //nolint:errcheck
+		_ = m.waitForSessionsTerminated(ctx, mux, entry.Sessions) //nolint:errcheck
 	}
 
 	// Clear sessions from entry (caller must persist this change)
 	entry.Sessions = nil
+	m.stopForwards(ctx, entry)
 
 	// Stop container
 	if entry.ContainerID != "" {
@@ -650,6 +754,7 @@ func (m *Manager) entryToInstance(ctx context.Context, entry *catalog.Entry) (*I
 		ContainerID: entry.ContainerID,
 		CreatedAt:   entry.CreatedAt,
 		Status:      catalogStatusToInstanceStatus(entry.Status),
+		Ports:       m.portForwards(entry),
 	}
 
 	// Fetch live container status if we have a container ID
@@ -784,38 +889,50 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		return nil, fmt.Errorf("agent setup: %w", setupErr)
 	}
 
-	// Determine workdir: use devcontainer remote workdir if set, otherwise default to /workspace
-	workdir := "/workspace"
-	if entry.RemoteWorkdir != "" {
-		workdir = entry.RemoteWorkdir
-	}
+	workdir := containerWorkdir(entry)
 
-	// Build the command to execute inside the container
-	// The multiplexer runs on the host, so we wrap the command with the runtime's exec command
-	execCmd := append(m.runtime.ExecCommand(), "-it")
-	if entry.RemoteUser != "" {
-		execCmd = append(execCmd, "-u", entry.RemoteUser)
-	}
-	execCmd = append(execCmd, "-w", workdir)
-	for _, e := range cfg.Env {
-		execCmd = append(execCmd, "-e", e)
-	}
-	execCmd = append(execCmd, entry.ContainerID)
-	if len(cfg.Command) > 0 {
-		execCmd = append(execCmd, cfg.Command...)
-	} else {
+	command := cfg.Command
+	if len(command) == 0 {
 		// Default to shell if no command specified
-		execCmd = append(execCmd, "/bin/bash")
+		command = []string{"/bin/bash"}
+	}
+
+	mux := m.sessionMux(entry)
+	var muxOpts *multiplexer.CreateSessionOpts
+	if entry.MuxMode == catalog.MuxModeContainer {
+		// The tmux server runs inside the container, so the command runs
+		// directly and output is piped to the mounted instance log directory.
+		muxOpts = &multiplexer.CreateSessionOpts{
+			Name:    muxSessionName,
+			Command: command,
+			Cwd:     workdir,
+			Env:     cfg.Env,
+			LogPath: containerLogPath(sessionID),
+		}
+	} else {
+		// The multiplexer runs on the host, so we wrap the command with the runtime's exec command
+		execCmd := append(m.runtime.ExecCommand(), "-it")
+		if entry.RemoteUser != "" {
+			execCmd = append(execCmd, "-u", entry.RemoteUser)
+		}
+		execCmd = append(execCmd, "-w", workdir)
+		for _, e := range cfg.Env {
+			execCmd = append(execCmd, "-e", e)
+		}
+		execCmd = append(execCmd, entry.ContainerID)
+		execCmd = append(execCmd, command...)
+
+		muxOpts = &multiplexer.CreateSessionOpts{
+			Name:    muxSessionName,
+			Command: execCmd,
+			Cwd:     entry.Worktree,
+			LogPath: logPath,
+			LogSink: m.logSink,
+		}
 	}
 
 	// Create multiplexer session with logging
-	// The multiplexer runs on the host, executing the runtime's exec command to run inside the container
-	_, err = m.mux.CreateSession(ctx, &multiplexer.CreateSessionOpts{
-		Name:    muxSessionName,
-		Command: execCmd,
-		Cwd:     entry.Worktree,
-		LogPath: logPath,
-	})
+	_, err = mux.CreateSession(ctx, muxOpts)
 	if err != nil {
 		return nil, fmt.Errorf("create multiplexer session: %w", err)
 	}
@@ -828,12 +945,13 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		MuxSessionID: muxSessionName,
 		CreatedAt:    now,
 		LastAccessed: now,
+		Profile:      cfg.Profile,
 	}
 
 	entry.Sessions = append(entry.Sessions, catSession)
 	if updateErr := m.catalog.Update(ctx, entry); updateErr != nil {
 		// Cleanup the multiplexer session we just created
-		if killErr := m.mux.KillSession(ctx, muxSessionName); killErr != nil {
+		if killErr := mux.KillSession(ctx, muxSessionName); killErr != nil {
 			// Session kill failed - return combined error so user knows cleanup failed
 			return nil, fmt.Errorf("update catalog entry: %w (additionally, failed to kill session %q: %v)", updateErr, muxSessionName, killErr)
 		}
@@ -849,6 +967,7 @@ func (m *Manager) CreateSession(ctx context.Context, instanceID string, cfg *Cre
 		MuxSessionID: muxSessionName,
 		CreatedAt:    now,
 		LastAccessed: now,
+		Profile:      cfg.Profile,
 	}, nil
 }
 
@@ -955,6 +1074,7 @@ func (m *Manager) GetSession(ctx context.Context, instanceID, sessionName string
 				MuxSessionID: s.MuxSessionID,
 				CreatedAt:    s.CreatedAt,
 				LastAccessed: s.LastAccessed,
+				Profile:      s.Profile,
 			}, nil
 		}
 	}
@@ -981,6 +1101,7 @@ func (m *Manager) ListSessions(ctx context.Context, instanceID string) ([]Sessio
 			MuxSessionID: s.MuxSessionID,
 			CreatedAt:    s.CreatedAt,
 			LastAccessed: s.LastAccessed,
+			Profile:      s.Profile,
 		}
 	}
 
@@ -1015,7 +1136,7 @@ func (m *Manager) KillSession(ctx context.Context, instanceID, sessionName strin
 	}
 
 	// Kill the multiplexer session (best-effort)
-	if killErr := m.mux.KillSession(ctx, session.MuxSessionID); killErr != nil {
+	if killErr := m.sessionMux(entry).KillSession(ctx, session.MuxSessionID); killErr != nil {
 		// Only return error if it's not "session not found" (already dead)
 		if !errors.Is(killErr, multiplexer.ErrSessionNotFound) {
 			return fmt.Errorf("kill multiplexer session: %w", killErr)
@@ -1069,19 +1190,133 @@ func (m *Manager) AttachSession(ctx context.Context, instanceID, sessionName str
 	}
 
 	// Attach to the multiplexer session (blocks until user exits or detaches)
-	attachErr := m.mux.AttachSession(ctx, session.MuxSessionID)
+	mux := m.sessionMux(entry)
+	attachErr := mux.AttachSession(ctx, session.MuxSessionID)
 
 	// After attach returns, check if session still exists in multiplexer.
 	// If not, the user exited (not detached) so we clean up the catalog.
-	m.cleanupExitedSession(ctx, instanceID, sessionName, session.MuxSessionID)
+	m.cleanupExitedSession(ctx, mux, instanceID, sessionName, session.MuxSessionID)
+
+	return attachErr
+}
+
+// SendKeys types text into a session without attaching to it, updating the
+// last accessed timestamp. If enter is true, Enter is pressed after the text.
+func (m *Manager) SendKeys(ctx context.Context, instanceID, sessionName, text string, enter bool) error {
+	log := slogger.L(ctx)
+	log.Debug("sending keys to session", slog.String("instance", instanceID), slog.String("session", sessionName))
+
+	entry, err := m.catalog.Get(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get catalog entry: %w", err)
+	}
+
+	// Find the session
+	var sessionIndex = -1
+	for i, s := range entry.Sessions {
+		if s.Name == sessionName {
+			sessionIndex = i
+			break
+		}
+	}
+	if sessionIndex == -1 {
+		return ErrSessionNotFound
+	}
+
+	if err := m.sessionMux(entry).SendKeys(ctx, entry.Sessions[sessionIndex].MuxSessionID, text, enter); err != nil {
+		return fmt.Errorf("send keys: %w", err)
+	}
+
+	// Update last accessed timestamp
+	entry.Sessions[sessionIndex].LastAccessed = time.Now()
+	if updateErr := m.catalog.Update(ctx, entry); updateErr != nil {
+		return fmt.Errorf("update catalog entry: %w", updateErr)
+	}
+
+	return nil
+}
+
+// CapturePane returns the rendered screen of a session without attaching.
+// Unlike AttachSession and SendKeys, it does not update the last accessed
+// timestamp.
+func (m *Manager) CapturePane(ctx context.Context, instanceID, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+	entry, err := m.catalog.Get(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("get catalog entry: %w", err)
+	}
+
+	for _, s := range entry.Sessions {
+		if s.Name == sessionName {
+			screen, err := m.sessionMux(entry).CapturePane(ctx, s.MuxSessionID, opts)
+			if err != nil {
+				return "", fmt.Errorf("capture pane: %w", err)
+			}
+			return screen, nil
+		}
+	}
+
+	return "", ErrSessionNotFound
+}
+
+// AttachGrid attaches to several sessions at once, shown as tiled panes of a
+// transient host tmux session. Detaching from the grid leaves the sessions
+// running. This is a blocking operation that takes over the terminal.
+func (m *Manager) AttachGrid(ctx context.Context, targets []GridTarget) error {
+	if len(targets) == 0 {
+		return ErrNoSessionsAvailable
+	}
+
+	type attached struct {
+		mux sessionMultiplexer
+		GridTarget
+		muxSessionID string
+	}
+	sessions := make([]attached, 0, len(targets))
+	panes := make([]multiplexer.GridPane, 0, len(targets))
+
+	for _, target := range targets {
+		entry, err := m.catalog.Get(ctx, target.InstanceID)
+		if err != nil {
+			if errors.Is(err, catalog.ErrNotFound) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("get catalog entry: %w", err)
+		}
+
+		idx := slices.IndexFunc(entry.Sessions, func(s catalog.Session) bool { return s.Name == target.SessionName })
+		if idx == -1 {
+			return ErrSessionNotFound
+		}
+		session := entry.Sessions[idx]
+
+		mux := m.sessionMux(entry)
+		sessions = append(sessions, attached{mux: mux, GridTarget: target, muxSessionID: session.MuxSessionID})
+		panes = append(panes, multiplexer.GridPane{
+			Title:   entry.Branch + "/" + session.Name,
+			Command: mux.AttachCommand(session.MuxSessionID),
+		})
+	}
+
+	attachErr := multiplexer.AttachGrid(ctx, m.executor, panes)
+
+	// Sessions exited from inside the grid are removed, as with AttachSession.
+	for _, s := range sessions {
+		m.cleanupExitedSession(ctx, s.mux, s.InstanceID, s.SessionName, s.muxSessionID)
+	}
 
 	return attachErr
 }
 
 // cleanupExitedSession removes a session from the catalog if it no longer exists in the multiplexer.
 // This handles the case where a user exits a session (vs detaching).
-func (m *Manager) cleanupExitedSession(ctx context.Context, instanceID, sessionName, muxSessionID string) {
-	sessions, err := m.mux.ListSessions(ctx)
+func (m *Manager) cleanupExitedSession(ctx context.Context, mux sessionMultiplexer, instanceID, sessionName, muxSessionID string) {
+	sessions, err := mux.ListSessions(ctx)
 	if err != nil {
 		return // Best effort - don't fail if we can't list sessions
 	}
@@ -1141,6 +1376,7 @@ func (m *Manager) GetMRUSession(ctx context.Context, instanceID string) (*Sessio
 		MuxSessionID: mru.MuxSessionID,
 		CreatedAt:    mru.CreatedAt,
 		LastAccessed: mru.LastAccessed,
+		Profile:      mru.Profile,
 	}, nil
 }
 
@@ -1177,6 +1413,7 @@ func (m *Manager) GetGlobalMRUSession(ctx context.Context) (*GlobalMRUSession, e
 						MuxSessionID: s.MuxSessionID,
 						CreatedAt:    s.CreatedAt,
 						LastAccessed: s.LastAccessed,
+						Profile:      s.Profile,
 					},
 				}
 			}
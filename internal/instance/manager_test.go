@@ -14,10 +14,13 @@ import (
 	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
 	"github.com/jmgilman/headjack/internal/container"
 	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+	"github.com/jmgilman/headjack/internal/exec"
+	execmocks "github.com/jmgilman/headjack/internal/exec/mocks"
 	"github.com/jmgilman/headjack/internal/git"
 	gitmocks "github.com/jmgilman/headjack/internal/git/mocks"
 	"github.com/jmgilman/headjack/internal/multiplexer"
 	muxmocks "github.com/jmgilman/headjack/internal/multiplexer/mocks"
+	"github.com/jmgilman/headjack/internal/version"
 )
 
 // Test constants for repeated values.
@@ -45,6 +48,12 @@ func TestNewManager(t *testing.T) {
 
 		assert.Equal(t, RuntimePodman, mgr.runtimeType)
 	})
+
+	t.Run("defaults MuxMode to host when not specified", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{})
+
+		assert.Equal(t, catalog.MuxModeHost, mgr.muxMode)
+	})
 }
 
 func TestManager_Create(t *testing.T) {
@@ -107,6 +116,13 @@ func TestManager_Create(t *testing.T) {
 		assert.Equal(t, "myimage:latest", runCfg.Image)
 		require.Len(t, runCfg.Mounts, 1)
 		assert.Equal(t, "/workspace", runCfg.Mounts[0].Target)
+		assert.Equal(t, map[string]string{
+			container.LabelInstanceID: inst.ID,
+			container.LabelRepoID:     testRepoID,
+			container.LabelBranch:     "feature/auth",
+			container.LabelVersion:    version.Version,
+			container.LabelCreatedBy:  "headjack",
+		}, runCfg.Labels)
 	})
 
 	t.Run("returns ErrAlreadyExists for duplicate branch", func(t *testing.T) {
@@ -649,11 +665,12 @@ func TestManager_CreateSession(t *testing.T) {
 				assert.Equal(t, worktreeDir, opts.Cwd)
 				assert.NotEmpty(t, opts.LogPath, "LogPath should be set for output capture")
 				assert.Contains(t, opts.LogPath, logsDir)
+				assert.Equal(t, []string{"hjk", "log-sink"}, opts.LogSink)
 				return &multiplexer.Session{Name: opts.Name}, nil
 			},
 		}
 
-		mgr := NewManager(store, runtime, nil, mux, &ManagerConfig{LogsDir: logsDir})
+		mgr := NewManager(store, runtime, nil, mux, &ManagerConfig{LogsDir: logsDir, LogSink: []string{"hjk", "log-sink"}})
 
 		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{})
 
@@ -707,6 +724,49 @@ func TestManager_CreateSession(t *testing.T) {
 		assert.Equal(t, "my-session", session.Name)
 	})
 
+	t.Run("records credential profile", func(t *testing.T) {
+		logsDir := t.TempDir()
+
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:          "abc12345",
+					ContainerID: "container-123",
+					Sessions:    []catalog.Session{},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				require.Len(t, entry.Sessions, 1)
+				assert.Equal(t, "work", entry.Sessions[0].Profile)
+				return nil
+			},
+		}
+		runtime := &containermocks.RuntimeMock{
+			GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+				return &container.Container{ID: "container-123", Status: container.StatusRunning}, nil
+			},
+			ExecFunc: func(ctx context.Context, id string, cfg *container.ExecConfig) error {
+				return nil
+			},
+			ExecCommandFunc: func() []string {
+				return []string{"container", "exec"}
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
+				return &multiplexer.Session{Name: opts.Name}, nil
+			},
+		}
+
+		mgr := NewManager(store, runtime, nil, mux, &ManagerConfig{LogsDir: logsDir})
+
+		session, err := mgr.CreateSession(ctx, "abc12345", &CreateSessionConfig{Type: "claude", Profile: "work"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "work", session.Profile)
+		require.Len(t, store.UpdateCalls(), 1)
+	})
+
 	t.Run("returns ErrSessionExists for duplicate name", func(t *testing.T) {
 		store := &catalogmocks.StoreMock{
 			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
@@ -991,6 +1051,197 @@ func TestManager_KillSession(t *testing.T) {
 	})
 }
 
+func TestManager_SendKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends keys and updates last accessed", func(t *testing.T) {
+		oldTime := time.Now().Add(-1 * time.Hour)
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID: "abc12345",
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1", LastAccessed: oldTime},
+					},
+				}, nil
+			},
+			UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+				require.Len(t, entry.Sessions, 1)
+				assert.True(t, entry.Sessions[0].LastAccessed.After(oldTime))
+				return nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			SendKeysFunc: func(ctx context.Context, sessionName, text string, enter bool) error {
+				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+				assert.Equal(t, "run the tests", text)
+				assert.True(t, enter)
+				return nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, &ManagerConfig{})
+
+		err := mgr.SendKeys(ctx, "abc12345", "my-session", "run the tests", true)
+
+		require.NoError(t, err)
+		require.Len(t, mux.SendKeysCalls(), 1)
+		require.Len(t, store.UpdateCalls(), 1)
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345"}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, &ManagerConfig{})
+
+		err := mgr.SendKeys(ctx, "abc12345", "nonexistent", "hello", true)
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("does not update catalog when send fails", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:       "abc12345",
+					Sessions: []catalog.Session{{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1"}},
+				}, nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			SendKeysFunc: func(ctx context.Context, sessionName, text string, enter bool) error {
+				return multiplexer.ErrSessionNotFound
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, &ManagerConfig{})
+
+		err := mgr.SendKeys(ctx, "abc12345", "my-session", "hello", true)
+
+		require.ErrorIs(t, err, multiplexer.ErrSessionNotFound)
+		assert.Empty(t, store.UpdateCalls())
+	})
+}
+
+func TestManager_CapturePane(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("captures the multiplexer session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:       "abc12345",
+					Sessions: []catalog.Session{{ID: "sess1", Name: "my-session", MuxSessionID: "hjk-abc12345-sess1"}},
+				}, nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			CapturePaneFunc: func(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+				assert.Equal(t, "hjk-abc12345-sess1", sessionName)
+				assert.Equal(t, 50, opts.Scrollback)
+				return "$ ls\nREADME.md\n", nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, &ManagerConfig{})
+
+		screen, err := mgr.CapturePane(ctx, "abc12345", "my-session", &multiplexer.CaptureOpts{Scrollback: 50})
+
+		require.NoError(t, err)
+		assert.Equal(t, "$ ls\nREADME.md\n", screen)
+		assert.Empty(t, store.UpdateCalls(), "capture should not change last accessed")
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: "abc12345"}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, &ManagerConfig{})
+
+		_, err := mgr.CapturePane(ctx, "abc12345", "nonexistent", nil)
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestManager_AttachGrid(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("attaches to a grid of session panes", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{
+					ID:     id,
+					Branch: "feat/" + id,
+					Sessions: []catalog.Session{
+						{ID: "sess1", Name: "claude", MuxSessionID: "hjk-" + id + "-sess1"},
+						{ID: "sess2", Name: "shell", MuxSessionID: "hjk-" + id + "-sess2"},
+					},
+				}, nil
+			},
+		}
+		mux := &muxmocks.MultiplexerMock{
+			AttachCommandFunc: func(sessionName string) []string {
+				return []string{"tmux", "attach-session", "-t", sessionName}
+			},
+			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
+				return []multiplexer.Session{{Name: "hjk-a-sess1"}, {Name: "hjk-b-sess2"}}, nil
+			},
+		}
+		var tmuxCalls [][]string
+		executor := &execmocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				tmuxCalls = append(tmuxCalls, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, mux, &ManagerConfig{Executor: executor})
+
+		err := mgr.AttachGrid(ctx, []GridTarget{
+			{InstanceID: "a", SessionName: "claude"},
+			{InstanceID: "b", SessionName: "shell"},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, mux.AttachCommandCalls(), 2)
+		assert.Equal(t, "hjk-a-sess1", mux.AttachCommandCalls()[0].SessionName)
+		assert.Equal(t, "hjk-b-sess2", mux.AttachCommandCalls()[1].SessionName)
+		assert.Contains(t, tmuxCalls, []string{"select-pane", "-t", tmuxCalls[0][3], "-T", "feat/b/shell"})
+		assert.Empty(t, mux.AttachSessionCalls(), "sessions are attached from grid panes")
+		assert.Empty(t, store.UpdateCalls(), "running sessions stay in the catalog")
+	})
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				return &catalog.Entry{ID: id}, nil
+			},
+		}
+
+		mgr := NewManager(store, nil, nil, nil, &ManagerConfig{})
+
+		err := mgr.AttachGrid(ctx, []GridTarget{{InstanceID: "a", SessionName: "missing"}})
+
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("returns ErrNoSessionsAvailable without targets", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{})
+
+		err := mgr.AttachGrid(ctx, nil)
+
+		assert.ErrorIs(t, err, ErrNoSessionsAvailable)
+	})
+}
+
 func TestManager_AttachSession(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,107 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"syscall"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	"github.com/jmgilman/headjack/internal/container"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+// errForeignFile stops the walk of hasForeignFiles at the first match.
+var errForeignFile = errors.New("foreign-owned file")
+
+// unscannedDirs are directories whose contents hasForeignFiles skips: git
+// metadata, and dependencies that are large and usually installed by the
+// container into a directory it creates, so that the directory itself is
+// foreign-owned.
+var unscannedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+	"target":       true,
+}
+
+// hostUser returns the user running headjack as a container user mapping.
+func hostUser() *container.UserMapping {
+	name := "headjack"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	return &container.UserMapping{UID: os.Getuid(), GID: os.Getgid(), Name: name}
+}
+
+// hasForeignFiles reports whether anything under root is owned by a user
+// other than uid, stopping at the first such file. Symlinks are not
+// followed, and of unscannedDirs only the directory itself is checked.
+func hasForeignFiles(root string, uid int) (bool, error) {
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are typically owned by someone else
+			if errors.Is(err, fs.ErrPermission) {
+				return errForeignFile
+			}
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != uid {
+			return errForeignFile
+		}
+		if d.IsDir() && p != root && unscannedDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if errors.Is(err, errForeignFile) {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// repairOwnership returns files in the worktree of a stopped instance that a
+// container created as another user to the host user. The stop has already
+// succeeded, so a failed check or chown is only logged as a warning, and the
+// files keep their owner until the next stop.
+func (m *Manager) repairOwnership(ctx context.Context, entry *catalog.Entry) {
+	log := slogger.L(ctx)
+
+	foreign, err := hasForeignFiles(entry.Worktree, os.Getuid())
+	if err != nil {
+		log.Warn("failed to check worktree ownership", slog.String("path", entry.Worktree), slog.String("error", err.Error()))
+		return
+	}
+	if !foreign {
+		return
+	}
+
+	repairer, ok := m.runtime.(container.OwnershipRepairer)
+	if !ok || entry.ContainerID == "" {
+		log.Warn("worktree has files owned by another user", slog.String("path", entry.Worktree))
+		return
+	}
+	// The helper container uses the instance image, which is known to exist
+	c, err := m.runtime.Get(ctx, entry.ContainerID)
+	if err != nil {
+		log.Warn("failed to repair worktree ownership", slog.String("path", entry.Worktree), slog.String("error", err.Error()))
+		return
+	}
+
+	log.Debug("repairing worktree ownership", slog.String("path", entry.Worktree))
+	if err := repairer.RepairOwnership(ctx, entry.Worktree, hostUser(), c.Image); err != nil {
+		log.Warn("failed to repair worktree ownership", slog.String("path", entry.Worktree), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,145 @@
+package instance
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+)
+
+// repairingRuntime is a runtime mock that also repairs ownership.
+type repairingRuntime struct {
+	*containermocks.RuntimeMock
+	repaired []string
+}
+
+func (r *repairingRuntime) RepairOwnership(_ context.Context, dir string, user *container.UserMapping, image string) error {
+	r.repaired = append(r.repaired, dir+" "+image)
+	return os.Chown(filepath.Join(dir, "file"), user.UID, user.GID)
+}
+
+func TestHostUser(t *testing.T) {
+	u := hostUser()
+
+	assert.Equal(t, os.Getuid(), u.UID)
+	assert.Equal(t, os.Getgid(), u.GID)
+	assert.NotEmpty(t, u.Name)
+}
+
+func TestManager_buildRunConfig_MapUser(t *testing.T) {
+	t.Run("maps the host user in vanilla mode", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{MapUser: true})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{Image: "ubuntu"}, "hjk-test", "/worktree")
+
+		assert.Equal(t, hostUser(), runCfg.User)
+	})
+
+	t.Run("leaves devcontainers to their own user settings", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{MapUser: true})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{WorkspaceFolder: "/repo"}, "hjk-test", "/worktree")
+
+		assert.Nil(t, runCfg.User)
+	})
+
+	t.Run("keeps the image user by default", func(t *testing.T) {
+		mgr := NewManager(nil, nil, nil, nil, &ManagerConfig{})
+
+		runCfg := mgr.buildRunConfig(&CreateConfig{Image: "ubuntu"}, "hjk-test", "/worktree")
+
+		assert.Nil(t, runCfg.User)
+	})
+}
+
+func TestHasForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "file"), []byte("x"), 0o600))
+
+	t.Run("false when all files belong to the user", func(t *testing.T) {
+		foreign, err := hasForeignFiles(dir, os.Getuid())
+		require.NoError(t, err)
+		assert.False(t, foreign)
+	})
+
+	t.Run("true when files belong to another user", func(t *testing.T) {
+		foreign, err := hasForeignFiles(dir, os.Getuid()+1)
+		require.NoError(t, err)
+		assert.True(t, foreign)
+	})
+
+	t.Run("false when the directory does not exist", func(t *testing.T) {
+		foreign, err := hasForeignFiles(filepath.Join(dir, "missing"), os.Getuid())
+		require.NoError(t, err)
+		assert.False(t, foreign)
+	})
+}
+
+func TestHasForeignFiles_UnscannedDirs(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating files owned by another user requires root")
+	}
+	dir := t.TempDir()
+	modules := filepath.Join(dir, "node_modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(modules, "pkg"), 0o750))
+	require.NoError(t, os.Chown(filepath.Join(modules, "pkg"), 4242, 4242))
+
+	t.Run("skips the contents of dependency directories", func(t *testing.T) {
+		foreign, err := hasForeignFiles(dir, os.Getuid())
+		require.NoError(t, err)
+		assert.False(t, foreign)
+	})
+
+	t.Run("checks the owner of dependency directories", func(t *testing.T) {
+		require.NoError(t, os.Chown(modules, 4242, 4242))
+
+		foreign, err := hasForeignFiles(dir, os.Getuid())
+		require.NoError(t, err)
+		assert.True(t, foreign)
+	})
+}
+
+func TestManager_Stop_RepairsOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("creating files owned by another user requires root")
+	}
+	ctx := context.Background()
+	worktree := t.TempDir()
+	file := filepath.Join(worktree, "file")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o600))
+	require.NoError(t, os.Chown(file, 4242, 4242))
+
+	store := &catalogmocks.StoreMock{
+		GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+			return &catalog.Entry{ID: id, ContainerID: "container-123", Worktree: worktree}, nil
+		},
+		UpdateFunc: func(ctx context.Context, entry *catalog.Entry) error {
+			return nil
+		},
+	}
+	runtime := &repairingRuntime{RuntimeMock: &containermocks.RuntimeMock{
+		StopFunc: func(ctx context.Context, id string) error {
+			return nil
+		},
+		GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+			return &container.Container{ID: id, Image: "myimage:latest"}, nil
+		},
+	}}
+
+	mgr := NewManager(store, runtime, nil, nil, &ManagerConfig{})
+	require.NoError(t, mgr.Stop(ctx, "abc123"))
+
+	assert.Equal(t, []string{worktree + " myimage:latest"}, runtime.repaired)
+	foreign, err := hasForeignFiles(worktree, os.Getuid())
+	require.NoError(t, err)
+	assert.False(t, foreign)
+}
@@ -0,0 +1,195 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/portfwd"
+	"github.com/jmgilman/headjack/internal/slogger"
+)
+
+// procNetTCPCommand prints the IPv4 and IPv6 TCP socket tables. IPv6 may be
+// disabled in the container, so its table is optional.
+const procNetTCPCommand = "cat /proc/net/tcp; cat /proc/net/tcp6 2>/dev/null; true"
+
+// PortForward describes a host port forwarded to a port inside an instance.
+type PortForward struct {
+	ContainerPort int  // Port inside the container
+	HostPort      int  // Port on the host loopback interface
+	Active        bool // True if a proxy is forwarding the port
+}
+
+// URL returns the address of the forwarded port for a browser.
+func (p *PortForward) URL() string {
+	return "http://localhost:" + strconv.Itoa(p.HostPort)
+}
+
+// pidfile returns the pidfile of the proxy forwarding port of an instance.
+func (m *Manager) pidfile(instanceID string, port int) string {
+	return filepath.Join(m.portsDir, instanceID+"-"+strconv.Itoa(port)+".pid")
+}
+
+// forwardActive reports whether the proxy forwarding port of an instance is
+// running.
+func (m *Manager) forwardActive(instanceID string, port int) bool {
+	_, ok := portfwd.Running(m.pidfile(instanceID, port))
+	return ok
+}
+
+// portForwards converts the port forwards of a catalog entry, checking
+// which proxies are still alive.
+func (m *Manager) portForwards(entry *catalog.Entry) []PortForward {
+	ports := make([]PortForward, 0, len(entry.Ports))
+	for _, p := range entry.Ports {
+		ports = append(ports, PortForward{
+			ContainerPort: p.ContainerPort,
+			HostPort:      p.HostPort,
+			Active:        m.forwardActive(entry.ID, p.ContainerPort),
+		})
+	}
+	return ports
+}
+
+// ListeningPorts returns the TCP ports that processes in an instance's
+// container listen on.
+func (m *Manager) ListeningPorts(ctx context.Context, instanceID string) ([]int, error) {
+	entry, err := m.getRunningInstance(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ce := &containerExecutor{
+		exec:        m.executor,
+		execCmd:     m.runtime.ExecCommand(),
+		containerID: entry.ContainerID,
+	}
+	result, err := ce.Run(ctx, &exec.RunOptions{
+		Name: "sh",
+		Args: []string{"-c", procNetTCPCommand},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read listening ports: %w", err)
+	}
+	return portfwd.ListeningPorts(result.Stdout)
+}
+
+// ForwardPort forwards a host port to a port inside an instance's container
+// through a background proxy, and returns the forward. A port forwarded
+// before keeps its host port if it is still free. Otherwise the host port is
+// the container port if it is free, or any free port, avoiding ports
+// assigned to other instances.
+func (m *Manager) ForwardPort(ctx context.Context, instanceID string, port int) (*PortForward, error) {
+	if len(m.proxyCommand) == 0 || m.portsDir == "" {
+		return nil, errors.New("port forwarding is not configured")
+	}
+
+	entry, err := m.getRunningInstance(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(entry.Ports, func(p catalog.PortForward) bool { return p.ContainerPort == port })
+	preferred := port
+	if i >= 0 {
+		if m.forwardActive(entry.ID, port) {
+			return &PortForward{ContainerPort: port, HostPort: entry.Ports[i].HostPort, Active: true}, nil
+		}
+		preferred = entry.Ports[i].HostPort
+	}
+
+	taken, err := m.assignedHostPorts(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := portfwd.Listen(preferred, taken)
+	if err != nil {
+		return nil, err
+	}
+	defer ln.Close()
+
+	args := append([]string{}, m.proxyCommand[1:]...)
+	args = append(args, entry.ContainerID, strconv.Itoa(port), "--")
+	args = append(args, m.runtime.ExecCommand()...)
+	pidfile := m.pidfile(entry.ID, port)
+	pid, err := portfwd.Start(m.proxyCommand[0], args, ln, pidfile)
+	if err != nil {
+		return nil, err
+	}
+
+	forward := catalog.PortForward{ContainerPort: port, HostPort: portfwd.Port(ln)}
+	if i >= 0 {
+		entry.Ports[i] = forward
+	} else {
+		entry.Ports = append(entry.Ports, forward)
+	}
+	if err := m.catalog.Update(ctx, entry); err != nil {
+		_ = portfwd.Stop(pidfile) //nolint:errcheck // best-effort cleanup
+		return nil, fmt.Errorf("update catalog entry: %w", err)
+	}
+
+	slogger.L(ctx).Debug("forwarding port", slog.Int("container_port", port), slog.Int("host_port", forward.HostPort), slog.Int("pid", pid))
+	return &PortForward{ContainerPort: port, HostPort: forward.HostPort, Active: true}, nil
+}
+
+// StopForward stops forwarding a port of an instance and releases its host
+// port. Returns ErrPortNotForwarded if the port was never forwarded.
+func (m *Manager) StopForward(ctx context.Context, instanceID string, port int) error {
+	entry, err := m.catalog.Get(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get catalog entry: %w", err)
+	}
+
+	i := slices.IndexFunc(entry.Ports, func(p catalog.PortForward) bool { return p.ContainerPort == port })
+	if i < 0 {
+		return fmt.Errorf("%w: %d", ErrPortNotForwarded, port)
+	}
+	if err := portfwd.Stop(m.pidfile(entry.ID, port)); err != nil {
+		return err
+	}
+
+	entry.Ports = slices.Delete(entry.Ports, i, i+1)
+	if err := m.catalog.Update(ctx, entry); err != nil {
+		return fmt.Errorf("update catalog entry: %w", err)
+	}
+	return nil
+}
+
+// assignedHostPorts returns the host ports assigned to instances other
+// than instanceID.
+func (m *Manager) assignedHostPorts(ctx context.Context, instanceID string) (map[int]bool, error) {
+	entries, err := m.catalog.List(ctx, catalog.ListFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("list catalog entries: %w", err)
+	}
+
+	taken := make(map[int]bool)
+	for i := range entries {
+		if entries[i].ID == instanceID {
+			continue
+		}
+		for _, p := range entries[i].Ports {
+			taken[p.HostPort] = true
+		}
+	}
+	return taken, nil
+}
+
+// stopForwards stops the port proxies of an instance whose container is
+// going away. Host ports stay assigned.
+func (m *Manager) stopForwards(ctx context.Context, entry *catalog.Entry) {
+	for _, p := range entry.Ports {
+		if err := portfwd.Stop(m.pidfile(entry.ID, p.ContainerPort)); err != nil {
+			slogger.L(ctx).Warn("failed to stop port forward", slog.Int("port", p.ContainerPort), slog.String("error", err.Error()))
+		}
+	}
+}
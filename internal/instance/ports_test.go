@@ -0,0 +1,193 @@
+package instance
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/catalog"
+	catalogmocks "github.com/jmgilman/headjack/internal/catalog/mocks"
+	"github.com/jmgilman/headjack/internal/container"
+	containermocks "github.com/jmgilman/headjack/internal/container/mocks"
+	"github.com/jmgilman/headjack/internal/exec"
+	execmocks "github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/portfwd"
+)
+
+// runningRuntime returns a runtime mock with a running container.
+func runningRuntime() *containermocks.RuntimeMock {
+	return &containermocks.RuntimeMock{
+		GetFunc: func(ctx context.Context, id string) (*container.Container, error) {
+			return &container.Container{ID: id, Status: container.StatusRunning}, nil
+		},
+		ExecCommandFunc: func() []string { return []string{"docker", "exec"} },
+	}
+}
+
+// freePort returns a host port that is free right now.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+func TestManager_ListeningPorts(t *testing.T) {
+	store := &catalogmocks.StoreMock{
+		GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+			return &catalog.Entry{ID: id, ContainerID: "container-123"}, nil
+		},
+	}
+	mockExec := &execmocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			assert.Equal(t, "docker", opts.Name)
+			assert.Equal(t, []string{"exec", "container-123", "sh", "-c", procNetTCPCommand}, opts.Args)
+			return &exec.Result{Stdout: []byte(
+				"  sl  local_address rem_address   st\n" +
+					"   0: 0100007F:0BB8 00000000:0000 0A\n",
+			)}, nil
+		},
+	}
+	mgr := NewManager(store, runningRuntime(), nil, nil, &ManagerConfig{Executor: mockExec})
+
+	ports, err := mgr.ListeningPorts(context.Background(), "inst-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{3000}, ports)
+}
+
+func TestManager_ForwardPort(t *testing.T) {
+	ctx := context.Background()
+
+	// newStore returns a store holding a single running instance, and
+	// another instance with a forwarded port.
+	newStore := func(entry *catalog.Entry, otherHostPort int) *catalogmocks.StoreMock {
+		return &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) {
+				e := *entry
+				return &e, nil
+			},
+			ListFunc: func(ctx context.Context, filter catalog.ListFilter) ([]catalog.Entry, error) {
+				return []catalog.Entry{*entry, {
+					ID:    "other",
+					Ports: []catalog.PortForward{{ContainerPort: 3000, HostPort: otherHostPort}},
+				}}, nil
+			},
+			UpdateFunc: func(ctx context.Context, e *catalog.Entry) error {
+				*entry = *e
+				return nil
+			},
+		}
+	}
+	// The proxy is a process that waits to be stopped
+	proxy := []string{"sh", "-c", "exec sleep 30"}
+	newManager := func(store *catalogmocks.StoreMock) *Manager {
+		return NewManager(store, runningRuntime(), nil, nil, &ManagerConfig{ProxyCommand: proxy, PortsDir: t.TempDir()})
+	}
+
+	t.Run("starts a proxy on the container port and records it", func(t *testing.T) {
+		port := freePort(t)
+		entry := &catalog.Entry{ID: "inst-1", ContainerID: "container-123"}
+		store := newStore(entry, 0)
+		mgr := newManager(store)
+
+		fwd, err := mgr.ForwardPort(ctx, "inst-1", port)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mgr.StopForward(ctx, "inst-1", port) })
+
+		assert.Equal(t, &PortForward{ContainerPort: port, HostPort: port, Active: true}, fwd)
+		require.Len(t, entry.Ports, 1)
+		pid, running := portfwd.Running(mgr.pidfile("inst-1", port))
+		require.True(t, running)
+		assert.Positive(t, pid)
+
+		// Forwarding again reuses the running proxy
+		again, err := mgr.ForwardPort(ctx, "inst-1", port)
+		require.NoError(t, err)
+		assert.Equal(t, fwd, again)
+		assert.Len(t, store.UpdateCalls(), 1)
+	})
+
+	t.Run("avoids host ports assigned to other instances", func(t *testing.T) {
+		port := freePort(t)
+		entry := &catalog.Entry{ID: "inst-1", ContainerID: "container-123"}
+		mgr := newManager(newStore(entry, port))
+
+		fwd, err := mgr.ForwardPort(ctx, "inst-1", port)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mgr.StopForward(ctx, "inst-1", port) })
+
+		assert.NotEqual(t, port, fwd.HostPort)
+	})
+
+	t.Run("does not trust a pidfile without a running proxy", func(t *testing.T) {
+		port := freePort(t)
+		entry := &catalog.Entry{ID: "inst-1", ContainerID: "container-123", Ports: []catalog.PortForward{{ContainerPort: port, HostPort: port}}}
+		mgr := newManager(newStore(entry, 0))
+		// A stale pidfile naming a live process that is not a proxy
+		require.NoError(t, os.MkdirAll(mgr.portsDir, 0o700))
+		require.NoError(t, os.WriteFile(mgr.pidfile("inst-1", port), []byte(strconv.Itoa(os.Getpid())), 0o600))
+
+		assert.False(t, mgr.portForwards(entry)[0].Active)
+
+		fwd, err := mgr.ForwardPort(ctx, "inst-1", port)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mgr.StopForward(ctx, "inst-1", port) })
+
+		pid, running := portfwd.Running(mgr.pidfile("inst-1", port))
+		require.True(t, running)
+		assert.NotEqual(t, os.Getpid(), pid)
+		assert.True(t, fwd.Active)
+	})
+
+	t.Run("keeps the host port of a stopped forward", func(t *testing.T) {
+		hostPort := freePort(t)
+		entry := &catalog.Entry{
+			ID:          "inst-1",
+			ContainerID: "container-123",
+			Ports:       []catalog.PortForward{{ContainerPort: 8080, HostPort: hostPort}},
+		}
+		mgr := newManager(newStore(entry, 0))
+
+		fwd, err := mgr.ForwardPort(ctx, "inst-1", 8080)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = mgr.StopForward(ctx, "inst-1", 8080) })
+
+		assert.Equal(t, hostPort, fwd.HostPort)
+		assert.Len(t, entry.Ports, 1)
+	})
+}
+
+func TestManager_StopForward(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("releases the host port", func(t *testing.T) {
+		entry := &catalog.Entry{ID: "inst-1", Ports: []catalog.PortForward{{ContainerPort: 3000, HostPort: 3000}}}
+		store := &catalogmocks.StoreMock{
+			GetFunc:    func(ctx context.Context, id string) (*catalog.Entry, error) { return entry, nil },
+			UpdateFunc: func(ctx context.Context, e *catalog.Entry) error { return nil },
+		}
+		mgr := NewManager(store, nil, nil, nil, &ManagerConfig{})
+
+		require.NoError(t, mgr.StopForward(ctx, "inst-1", 3000))
+
+		require.Len(t, store.UpdateCalls(), 1)
+		assert.Empty(t, store.UpdateCalls()[0].Entry.Ports)
+	})
+
+	t.Run("returns ErrPortNotForwarded for unknown ports", func(t *testing.T) {
+		store := &catalogmocks.StoreMock{
+			GetFunc: func(ctx context.Context, id string) (*catalog.Entry, error) { return &catalog.Entry{ID: id}, nil },
+		}
+		mgr := NewManager(store, nil, nil, nil, &ManagerConfig{})
+
+		assert.ErrorIs(t, mgr.StopForward(ctx, "inst-1", 3000), ErrPortNotForwarded)
+	})
+}
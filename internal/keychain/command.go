@@ -0,0 +1,116 @@
+package keychain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// EnvKeychainAccount is the environment variable that passes the account name to external commands.
+const EnvKeychainAccount = "HEADJACK_KEYCHAIN_ACCOUNT"
+
+// NotFoundExitCode is the exit status a command uses to report that a
+// credential does not exist.
+const NotFoundExitCode = 44
+
+// Exit statuses of sh when the command itself can't be found or run.
+const (
+	exitNotExecutable = 126
+	exitNotFound      = 127
+)
+
+// ErrReadOnly is returned when the command backend has no command for a write operation.
+var ErrReadOnly = errors.New("keychain command backend is read-only: no command configured")
+
+// commandStore implements Keychain by running user-configured shell commands.
+type commandStore struct {
+	cfg  CommandConfig
+	exec exec.Executor
+}
+
+func newCommandStore(cfg CommandConfig, executor exec.Executor) (*commandStore, error) {
+	if cfg.Get == "" {
+		return nil, errors.New("command backend requires a get command")
+	}
+	return &commandStore{cfg: cfg, exec: executor}, nil
+}
+
+func (c *commandStore) Set(account, secret string) error {
+	if c.cfg.Set == "" {
+		return fmt.Errorf("set: %w", ErrReadOnly)
+	}
+	_, err := c.run(c.cfg.Set, account, secret)
+	return err
+}
+
+func (c *commandStore) Get(account string) (string, error) {
+	out, err := c.run(c.cfg.Get, account, "")
+	if err != nil {
+		return "", err
+	}
+	// Most CLIs terminate their output with a newline that is not part of the secret.
+	return strings.TrimRight(out, "\r\n"), nil
+}
+
+func (c *commandStore) Delete(account string) error {
+	if c.cfg.Delete == "" {
+		return fmt.Errorf("delete: %w", ErrReadOnly)
+	}
+	_, err := c.run(c.cfg.Delete, account, "")
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// run executes a configured command through the shell and returns its stdout.
+// Failures reporting a missing item are mapped to ErrNotFound; a command
+// that can't be run is an error.
+func (c *commandStore) run(command, account, stdin string) (string, error) {
+	opts := &exec.RunOptions{
+		Name: "sh",
+		Args: []string{"-c", command},
+		Env:  []string{EnvKeychainAccount + "=" + account},
+	}
+	if stdin != "" {
+		opts.Stdin = strings.NewReader(stdin)
+	}
+
+	result, err := c.exec.Run(context.Background(), opts)
+	if err != nil {
+		stderr, code := "", 0
+		if result != nil {
+			stderr = strings.TrimSpace(string(result.Stderr))
+			code = result.ExitCode
+		}
+		switch {
+		case code == exitNotFound || code == exitNotExecutable:
+			// A missing CLI must not look like a missing credential
+			return "", fmt.Errorf("keychain command could not be run: %s: %w", stderr, err)
+		case code == NotFoundExitCode || isItemNotFound(stderr):
+			return "", ErrNotFound
+		case stderr != "":
+			return "", fmt.Errorf("keychain command failed: %s: %w", stderr, err)
+		}
+		return "", fmt.Errorf("keychain command failed: %w", err)
+	}
+	return string(result.Stdout), nil
+}
+
+// isItemNotFound reports whether command stderr is the message pass,
+// 1Password (op) or Bitwarden (bw) print for a missing item.
+func isItemNotFound(stderr string) bool {
+	if strings.Contains(stderr, "is not in the password store") || // pass
+		strings.Contains(stderr, "isn't an item") { // op
+		return true
+	}
+	for line := range strings.SplitSeq(stderr, "\n") {
+		if strings.TrimSpace(line) == "Not found." { // bw
+			return true
+		}
+	}
+	return false
+}
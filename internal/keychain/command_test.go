@@ -0,0 +1,240 @@
+package keychain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// fakePassScript emulates the subset of pass used by the keyring pass backend,
+// storing entries as plain files named <name>.gpg under PASSWORD_STORE_DIR.
+const fakePassScript = `#!/bin/sh
+store="$PASSWORD_STORE_DIR"
+case "$1" in
+show) cat "$store/$2.gpg" ;;
+insert) mkdir -p "$(dirname "$store/$4.gpg")" && cat > "$store/$4.gpg" ;;
+rm) rm -f "$store/$3.gpg" ;;
+*) echo "unsupported: $1" >&2; exit 1 ;;
+esac
+`
+
+// testKeychainContract exercises the behavior every Keychain backend must provide.
+func testKeychainContract(t *testing.T, store Keychain) {
+	t.Helper()
+
+	t.Run("set and get", func(t *testing.T) {
+		if err := store.Set("test-account", "test-secret"); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+		secret, err := store.Get("test-account")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if secret != "test-secret" {
+			t.Errorf("Get() = %q, want %q", secret, "test-secret")
+		}
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		if _, err := store.Get("nonexistent"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		if err := store.Set("overwrite-test", "initial"); err != nil {
+			t.Fatalf("Set() initial failed: %v", err)
+		}
+		if err := store.Set("overwrite-test", "updated"); err != nil {
+			t.Fatalf("Set() overwrite failed: %v", err)
+		}
+		secret, err := store.Get("overwrite-test")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if secret != "updated" {
+			t.Errorf("Get() = %q, want %q", secret, "updated")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := store.Set("delete-test", "secret"); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+		if err := store.Delete("delete-test"); err != nil {
+			t.Fatalf("Delete() failed: %v", err)
+		}
+		if _, err := store.Get("delete-test"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("delete idempotent", func(t *testing.T) {
+		if err := store.Delete("nonexistent"); err != nil {
+			t.Errorf("Delete() of nonexistent key failed: %v", err)
+		}
+	})
+
+	t.Run("account with separators", func(t *testing.T) {
+		if err := store.Set("secret:repo-abc1234:npm", "token"); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+		secret, err := store.Get("secret:repo-abc1234:npm")
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if secret != "token" {
+			t.Errorf("Get() = %q, want %q", secret, "token")
+		}
+	})
+}
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+}
+
+func TestFileBackend_Contract(t *testing.T) {
+	store, err := NewWithConfig(Config{
+		Backend:      BackendFile,
+		FileDir:      t.TempDir(),
+		PasswordFunc: testPasswordFunc,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() failed: %v", err)
+	}
+	testKeychainContract(t, store)
+}
+
+func TestPassBackend_Contract(t *testing.T) {
+	skipWithoutShell(t)
+
+	passCmd := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(passCmd, []byte(fakePassScript), 0o700); err != nil { //nolint:gosec // Test script must be executable
+		t.Fatalf("write fake pass: %v", err)
+	}
+
+	store, err := NewWithConfig(Config{
+		Backend: BackendPass,
+		PassCmd: passCmd,
+		PassDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() failed: %v", err)
+	}
+	testKeychainContract(t, store)
+}
+
+func TestCommandBackend_Contract(t *testing.T) {
+	skipWithoutShell(t)
+
+	dir := t.TempDir()
+	t.Setenv("FAKE_STORE", dir)
+
+	store, err := NewWithConfig(Config{
+		Backend: BackendCommand,
+		Command: CommandConfig{
+			Get:    `cat "$FAKE_STORE/$HEADJACK_KEYCHAIN_ACCOUNT" 2>/dev/null || exit 44`,
+			Set:    `cat > "$FAKE_STORE/$HEADJACK_KEYCHAIN_ACCOUNT"`,
+			Delete: `[ -f "$FAKE_STORE/$HEADJACK_KEYCHAIN_ACCOUNT" ] || { echo "Not found." >&2; exit 1; }; rm "$FAKE_STORE/$HEADJACK_KEYCHAIN_ACCOUNT"`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() failed: %v", err)
+	}
+	testKeychainContract(t, store)
+}
+
+func TestEnvBackendOverride_Command(t *testing.T) {
+	t.Setenv(EnvKeyringBackend, string(BackendCommand))
+
+	_, err := NewWithConfig(Config{Backend: BackendFile})
+	if err == nil {
+		t.Fatal("NewWithConfig() succeeded without a get command, want error")
+	}
+}
+
+func TestCommandBackend_ReadOnly(t *testing.T) {
+	skipWithoutShell(t)
+
+	store, err := newCommandStore(CommandConfig{Get: "echo value"}, exec.New())
+	if err != nil {
+		t.Fatalf("newCommandStore() failed: %v", err)
+	}
+
+	secret, err := store.Get("any")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if secret != "value" {
+		t.Errorf("Get() = %q, want %q", secret, "value")
+	}
+	if err := store.Set("any", "x"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Set() error = %v, want %v", err, ErrReadOnly)
+	}
+	if err := store.Delete("any"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrReadOnly)
+	}
+}
+
+func TestCommandBackend_Failure(t *testing.T) {
+	skipWithoutShell(t)
+
+	store, err := newCommandStore(CommandConfig{Get: `echo "session expired" >&2; exit 1`}, exec.New())
+	if err != nil {
+		t.Fatalf("newCommandStore() failed: %v", err)
+	}
+
+	_, err = store.Get("any")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want command failure", err)
+	}
+	if got := err.Error(); !strings.Contains(got, "session expired") {
+		t.Errorf("Get() error = %q, want stderr in message", got)
+	}
+}
+
+func TestCommandBackend_MissingCommand(t *testing.T) {
+	skipWithoutShell(t)
+
+	store, err := newCommandStore(CommandConfig{
+		Get:    "headjack-missing-cli get",
+		Delete: "headjack-missing-cli delete",
+	}, exec.New())
+	if err != nil {
+		t.Fatalf("newCommandStore() failed: %v", err)
+	}
+
+	if _, err := store.Get("any"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want command failure", err)
+	}
+	if err := store.Delete("any"); err == nil {
+		t.Error("Delete() succeeded with a missing command, want error")
+	}
+}
+
+func TestIsItemNotFound(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{"Error: headjack/x is not in the password store.", true},
+		{`[ERROR] 2024/01/02 15:04:05 "x" isn't an item in the "Headjack" vault`, true},
+		{"Not found.", true},
+		{"sh: 1: op: not found", false},
+		{"open /home/u/.config: No such file or directory", false},
+		{"could not find session", false},
+	}
+	for _, tt := range tests {
+		if got := isItemNotFound(tt.stderr); got != tt.want {
+			t.Errorf("isItemNotFound(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,11 @@
 // to the Linux kernel keyring (keyctl), and finally to an encrypted file. On Windows,
 // credentials are stored in Windows Credential Manager.
 //
+// Two opt-in backends cover headless machines without a system keyring: "pass"
+// stores credentials in a pass (or gopass) GPG password store, and "command"
+// delegates to user-supplied shell commands (e.g., 1Password's "op read" or
+// Bitwarden's "bw get").
+//
 // The backend can be overridden using the HEADJACK_KEYRING_BACKEND environment variable.
 // For the encrypted file backend, the password can be provided via HEADJACK_KEYRING_PASSWORD.
 package keychain
@@ -39,6 +44,12 @@ const (
 
 	// BackendFile uses an encrypted file (universal fallback).
 	BackendFile Backend = "file"
+
+	// BackendPass uses a pass-compatible GPG password store (pass, gopass).
+	BackendPass Backend = "pass"
+
+	// BackendCommand delegates to external commands configured in CommandConfig.
+	BackendCommand Backend = "command"
 )
 
 // Config holds configuration for the keyring.
@@ -53,6 +64,35 @@ type Config struct {
 	// PasswordFunc provides a password for the encrypted file backend.
 	// If nil, HEADJACK_KEYRING_PASSWORD env var is checked, then interactive prompt.
 	PasswordFunc func(string) (string, error)
+
+	// PassCmd is the pass-compatible binary for the pass backend.
+	// Defaults to "pass"; set to "gopass" to use gopass.
+	PassCmd string
+
+	// PassDir is the password store directory for the pass backend.
+	// Defaults to $PASSWORD_STORE_DIR, then ~/.password-store.
+	PassDir string
+
+	// PassPrefix is the folder within the password store holding Headjack credentials.
+	// Defaults to "headjack".
+	PassPrefix string
+
+	// Command configures the external command backend.
+	Command CommandConfig
+}
+
+// CommandConfig holds the shell commands used by the external command backend.
+//
+// Each command is run with "sh -c" and receives the credential account name in
+// the HEADJACK_KEYCHAIN_ACCOUNT environment variable. Set receives the secret on
+// stdin; Get must print the secret to stdout. A command reports a missing
+// credential by exiting with NotFoundExitCode, or with the not-found message
+// of pass, op or bw. Failing to run the command (exit status 126 or 127) is
+// an error, not a missing credential.
+type CommandConfig struct {
+	Get    string // Command that prints a credential (required)
+	Set    string // Command that stores a credential read from stdin (optional: read-only without it)
+	Delete string // Command that removes a credential (optional: read-only without it)
 }
 
 // Keychain provides secure credential storage.
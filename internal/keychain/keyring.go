@@ -9,10 +9,15 @@ import (
 
 	"github.com/99designs/keyring"
 	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/exec"
 )
 
 const serviceName = "com.headjack.cli"
 
+// defaultPassPrefix is the password store folder used when none is configured.
+const defaultPassPrefix = "headjack"
+
 // Environment variable names for keyring configuration.
 const (
 	EnvKeyringBackend  = "HEADJACK_KEYRING_BACKEND"
@@ -41,6 +46,14 @@ func NewWithConfig(cfg Config) (Keychain, error) {
 		backend = Backend(envBackend)
 	}
 
+	if backend == BackendCommand {
+		store, err := newCommandStore(cfg.Command, exec.New())
+		if err != nil {
+			return nil, fmt.Errorf("open keyring (%s): %w", backend, err)
+		}
+		return store, nil
+	}
+
 	ring, err := openKeyring(backend, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("open keyring (%s): %w", backend, err)
@@ -105,6 +118,11 @@ func openKeyring(backend Backend, cfg Config) (keyring.Keyring, error) {
 		fileDir = filepath.Join(home, ".config", "headjack")
 	}
 
+	passPrefix := cfg.PassPrefix
+	if passPrefix == "" {
+		passPrefix = defaultPassPrefix
+	}
+
 	passwordFunc := cfg.PasswordFunc
 	if passwordFunc == nil {
 		passwordFunc = defaultPasswordFunc
@@ -123,6 +141,11 @@ func openKeyring(backend Backend, cfg Config) (keyring.Keyring, error) {
 		FileDir:          fileDir,
 		FilePasswordFunc: passwordFunc,
 
+		// pass backend options
+		PassCmd:    cfg.PassCmd,
+		PassDir:    cfg.PassDir,
+		PassPrefix: passPrefix,
+
 		// Restrict to specified backend
 		AllowedBackends: []keyring.BackendType{keyring.BackendType(backend)},
 	}
@@ -146,6 +169,17 @@ func defaultPasswordFunc(prompt string) (string, error) {
 	return "", ErrNoPassword
 }
 
+// EnvPasswordFunc provides a password for the encrypted file backend from
+// HEADJACK_KEYRING_PASSWORD only. Unlike the default, it never prompts, so it
+// is suitable for non-interactive use (CI, piped stdin). Returns ErrNoPassword
+// if the environment variable is not set.
+func EnvPasswordFunc(string) (string, error) {
+	if pw := os.Getenv(EnvKeyringPassword); pw != "" {
+		return pw, nil
+	}
+	return "", ErrNoPassword
+}
+
 // terminalPrompt reads a password from the terminal without echoing.
 func terminalPrompt(prompt string) (string, error) {
 	fmt.Fprint(os.Stderr, prompt)
@@ -1,6 +1,7 @@
 package keychain
 
 import (
+	"errors"
 	"os"
 	"runtime"
 	"testing"
@@ -234,3 +235,38 @@ func TestDefaultPasswordFunc_NoPassword(t *testing.T) {
 		t.Errorf("defaultPasswordFunc() error = %v, want %v", err, ErrNoPassword)
 	}
 }
+
+func TestEnvPasswordFunc(t *testing.T) {
+	t.Setenv(EnvKeyringPassword, "env-password")
+
+	password, err := EnvPasswordFunc("Enter password: ")
+	if err != nil {
+		t.Fatalf("EnvPasswordFunc() failed: %v", err)
+	}
+	if password != "env-password" {
+		t.Errorf("EnvPasswordFunc() = %q, want %q", password, "env-password")
+	}
+
+	t.Setenv(EnvKeyringPassword, "")
+	if _, err := EnvPasswordFunc("Enter password: "); err != ErrNoPassword {
+		t.Errorf("EnvPasswordFunc() error = %v, want %v", err, ErrNoPassword)
+	}
+}
+
+func TestKeyringStore_SetWithoutPassword(t *testing.T) {
+	t.Setenv(EnvKeyringPassword, "")
+
+	store, err := NewWithConfig(Config{
+		Backend:      BackendFile,
+		FileDir:      t.TempDir(),
+		PasswordFunc: EnvPasswordFunc,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() failed: %v", err)
+	}
+
+	err = store.Set("test-account", "test-secret")
+	if !errors.Is(err, ErrNoPassword) {
+		t.Errorf("Set() error = %v, want %v", err, ErrNoPassword)
+	}
+}
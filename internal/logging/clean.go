@@ -0,0 +1,58 @@
+package logging
+
+import "strings"
+
+// CleanLine makes a line of raw terminal output printable as plain text.
+// Escape sequences are removed, tabs are expanded, and only the text after
+// the last carriage return is kept since it overwrote what came before on the
+// terminal.
+func CleanLine(line string) string {
+	if i := strings.LastIndexByte(strings.TrimRight(line, "\r"), '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\x1b':
+			i = skipEscape(line, i)
+		case c == '\t':
+			b.WriteString("    ")
+		case c < ' ' || c == 0x7f:
+			// Drop other control characters
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// skipEscape returns the index of the last byte of the escape sequence that
+// starts at line[i].
+func skipEscape(line string, i int) int {
+	if i+1 >= len(line) {
+		return i
+	}
+	switch line[i+1] {
+	case '[': // CSI: parameters end with a byte in 0x40-0x7e
+		for j := i + 2; j < len(line); j++ {
+			if line[j] >= 0x40 && line[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(line) - 1
+	case ']', 'P', '_', '^': // OSC, DCS, APC, PM: end with BEL or ST
+		for j := i + 2; j < len(line); j++ {
+			if line[j] == '\a' {
+				return j
+			}
+			if line[j] == '\x1b' && j+1 < len(line) && line[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(line) - 1
+	default: // Two-byte sequence such as ESC =
+		return i + 1
+	}
+}
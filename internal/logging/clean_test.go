@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "plain text", line: "hello world", want: "hello world"},
+		{name: "color codes", line: "\x1b[1;31merror\x1b[0m: failed", want: "error: failed"},
+		{name: "cursor movement", line: "\x1b[2K\x1b[1Gdone", want: "done"},
+		{name: "window title", line: "\x1b]0;title\x07prompt$ ", want: "prompt$ "},
+		{name: "carriage return overwrite", line: "50%\r100%\r", want: "100%"},
+		{name: "tabs and controls", line: "a\tb\x08c", want: "a    bc"},
+		{name: "truncated escape", line: "text\x1b[", want: "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CleanLine(tt.line))
+		})
+	}
+}
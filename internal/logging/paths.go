@@ -35,6 +35,12 @@ func (p *PathManager) SessionLogPath(instanceID, sessionID string) string {
 	return filepath.Join(p.baseDir, instanceID, sessionID+".log")
 }
 
+// SessionTranscriptPath returns the full path for a session's transcript.
+// Path format: <baseDir>/<instanceID>/<sessionID>.txt
+func (p *PathManager) SessionTranscriptPath(instanceID, sessionID string) string {
+	return TranscriptPath(p.SessionLogPath(instanceID, sessionID))
+}
+
 // EnsureInstanceDir creates the instance log directory if it doesn't exist.
 // Returns the instance directory path.
 func (p *PathManager) EnsureInstanceDir(instanceID string) (string, error) {
@@ -61,11 +67,23 @@ func (p *PathManager) LogExists(instanceID, sessionID string) bool {
 	return err == nil
 }
 
-// RemoveSessionLog removes a session's log file if it exists.
+// TranscriptExists checks if a transcript exists for the given session.
+func (p *PathManager) TranscriptExists(instanceID, sessionID string) bool {
+	_, err := os.Stat(p.SessionTranscriptPath(instanceID, sessionID))
+	return err == nil
+}
+
+// RemoveSessionLog removes a session's log file and transcript, with their
+// rotated segments, if they exist.
 func (p *PathManager) RemoveSessionLog(instanceID, sessionID string) error {
 	path := p.SessionLogPath(instanceID, sessionID)
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove session log: %w", err)
+	for _, file := range []string{path, TranscriptPath(path)} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove session log: %w", err)
+		}
+		if err := removeSegments(file); err != nil {
+			return fmt.Errorf("remove session log: %w", err)
+		}
 	}
 	return nil
 }
@@ -79,6 +97,25 @@ func (p *PathManager) RemoveInstanceLogs(instanceID string) error {
 	return nil
 }
 
+// ListInstances returns the IDs of the instances that have a log directory.
+func (p *PathManager) ListInstances() ([]string, error) {
+	entries, err := os.ReadDir(p.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read log directory: %w", err)
+	}
+
+	var instances []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			instances = append(instances, entry.Name())
+		}
+	}
+	return instances, nil
+}
+
 // ListSessionLogs returns a list of session IDs that have log files for the given instance.
 func (p *PathManager) ListSessionLogs(instanceID string) ([]string, error) {
 	dir := p.InstanceDir(instanceID)
@@ -152,3 +152,22 @@ func TestPathManager_ListSessionLogs(t *testing.T) {
 	require.NoError(t, err)
 	assert.ElementsMatch(t, []string{"alpha", "beta", "gamma"}, sessions)
 }
+
+func TestPathManager_ListInstances(t *testing.T) {
+	baseDir := t.TempDir()
+	pm := NewPathManager(baseDir)
+
+	_, err := pm.EnsureInstanceDir("inst1")
+	require.NoError(t, err)
+	_, err = pm.EnsureInstanceDir("inst2")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "stray.log"), nil, 0o600))
+
+	instances, err := pm.ListInstances()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inst1", "inst2"}, instances)
+
+	instances, err = NewPathManager(filepath.Join(baseDir, "missing")).ListInstances()
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+}
@@ -2,10 +2,14 @@ package logging
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -14,7 +18,10 @@ const DefaultTailLines = 100
 
 // Reader provides functionality to read session log files.
 type Reader struct {
-	pathMgr *PathManager
+	pathMgr    *PathManager
+	transcript bool
+	since      time.Time
+	until      time.Time
 }
 
 // NewReader creates a new Reader with the given PathManager.
@@ -22,37 +29,75 @@ func NewReader(pathMgr *PathManager) *Reader {
 	return &Reader{pathMgr: pathMgr}
 }
 
-// ReadAll reads the entire log file for a session.
+// NewTranscriptReader creates a Reader for session transcripts (see
+// TranscriptWriter) instead of raw logs.
+func NewTranscriptReader(pathMgr *PathManager) *Reader {
+	return &Reader{pathMgr: pathMgr, transcript: true}
+}
+
+// WithTimeRange returns a copy of the reader that only returns lines
+// timestamped between since and until, inclusive. A zero time leaves that
+// end of the range open. Only transcript lines have timestamps, so no raw log
+// line is in a range.
+func (r *Reader) WithTimeRange(since, until time.Time) *Reader {
+	c := *r
+	c.since, c.until = since, until
+	return &c
+}
+
+// ReadAll reads the entire log of a session, including rotated segments.
 func (r *Reader) ReadAll(instanceID, sessionID string) ([]string, error) {
-	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
-	return readAllLines(path)
+	lines, err := readAllLines(r.path(instanceID, sessionID))
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(lines), nil
 }
 
-// ReadLastN reads the last n lines from a session's log file.
-// If n <= 0, uses DefaultTailLines.
+// ReadLastN reads the last n lines from a session's log, reading rotated
+// segments as needed. If n <= 0, uses DefaultTailLines.
 func (r *Reader) ReadLastN(instanceID, sessionID string, n int) ([]string, error) {
 	if n <= 0 {
 		n = DefaultTailLines
 	}
 
-	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
-	return readLastNLines(path, n)
+	path := r.path(instanceID, sessionID)
+	if !r.hasRange() {
+		return readLastNLines(path, n)
+	}
+
+	// The range can exclude any number of the last lines, so the whole log
+	// is read. Transcripts are compact enough for this.
+	lines, err := r.ReadAll(instanceID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
 }
 
 // Follow streams new log lines to the provided writer as they are appended.
-// This is similar to `tail -f`. It blocks until the context is canceled.
+// This is similar to `tail -F`: when the log is rotated, following continues
+// in the new log. It blocks until the context is canceled, or with a time
+// range, until a line after the end of the range is written or that time
+// has passed.
 // The pollInterval determines how frequently to check for new content.
 //
-//nolint:gocognit // Follow requires nested loops for polling and reading; complexity is inherent to tail -f semantics
+//nolint:gocognit // Follow requires nested loops for polling and reading; complexity is inherent to tail -F semantics
 func (r *Reader) Follow(ctx context.Context, instanceID, sessionID string, out io.Writer, pollInterval time.Duration) error {
-	path := r.pathMgr.SessionLogPath(instanceID, sessionID)
+	path := r.path(instanceID, sessionID)
+	if r.hasRange() {
+		out = &rangeWriter{r: r, out: out}
+	}
 
 	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
 	file, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("open log file: %w", err)
 	}
-	defer file.Close()
+	defer func() { _ = file.Close() }()
 
 	// Seek to end of file
 	if _, err := file.Seek(0, io.SeekEnd); err != nil {
@@ -68,28 +113,39 @@ func (r *Reader) Follow(ctx context.Context, instanceID, sessionID string, out i
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			for {
-				line, err := reader.ReadBytes('\n')
-				// Always write any data we received, even with EOF
-				if len(line) > 0 {
-					if _, werr := out.Write(line); werr != nil {
-						return fmt.Errorf("write output: %w", werr)
-					}
-				}
-				if err != nil {
-					if err == io.EOF {
-						// No more data, wait for next poll
-						break
-					}
-					return fmt.Errorf("read line: %w", err)
-				}
+		}
+
+		if err := copyAvailable(reader, out); err != nil {
+			if errors.Is(err, errPastRange) {
+				return nil
 			}
+			return err
 		}
+		if !r.until.IsZero() && time.Now().After(r.until) {
+			return nil
+		}
+
+		next, rotated, err := reopenIfRotated(path, file)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			continue
+		}
+
+		// Output written before the rotation is still in the old file
+		if err := copyAvailable(reader, out); err != nil {
+			_ = next.Close()
+			return err
+		}
+		_ = file.Close()
+		file = next
+		reader = bufio.NewReader(file)
 	}
 }
 
 // FollowWithHistory reads the last n lines and then follows new output.
-// This is similar to `tail -n N -f`.
+// This is similar to `tail -n N -F`.
 func (r *Reader) FollowWithHistory(ctx context.Context, instanceID, sessionID string, out io.Writer, n int, pollInterval time.Duration) error {
 	// First, output the last N lines
 	lines, err := r.ReadLastN(instanceID, sessionID, n)
@@ -107,44 +163,233 @@ func (r *Reader) FollowWithHistory(ctx context.Context, instanceID, sessionID st
 	return r.Follow(ctx, instanceID, sessionID, out, pollInterval)
 }
 
-// readAllLines reads all lines from a file.
-func readAllLines(path string) ([]string, error) {
+// path returns the path of the log the reader reads for a session.
+func (r *Reader) path(instanceID, sessionID string) string {
+	if r.transcript {
+		return r.pathMgr.SessionTranscriptPath(instanceID, sessionID)
+	}
+	return r.pathMgr.SessionLogPath(instanceID, sessionID)
+}
+
+// hasRange reports whether the reader filters lines by time.
+func (r *Reader) hasRange() bool {
+	return !r.since.IsZero() || !r.until.IsZero()
+}
+
+// filter returns the lines in the reader's time range.
+func (r *Reader) filter(lines []string) []string {
+	if !r.hasRange() {
+		return lines
+	}
+
+	var filtered []string
+	for _, line := range lines {
+		if r.inRange(line) == 0 {
+			filtered = append(filtered, line)
+		}
+	}
+	return filtered
+}
+
+// inRange compares the timestamp of a transcript line with the reader's time
+// range. It returns -1 for lines before the range or without a timestamp,
+// 0 for lines in the range, and 1 for lines after it.
+func (r *Reader) inRange(line string) int {
+	stamp, _, _ := strings.Cut(line, " ")
+	t, err := time.Parse(TranscriptTimeFormat, stamp)
+	switch {
+	case err != nil, !r.since.IsZero() && t.Before(r.since):
+		return -1
+	case !r.until.IsZero() && t.After(r.until):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// errPastRange stops following once a line after the time range is written.
+var errPastRange = errors.New("line after time range")
+
+// rangeWriter writes only the lines in a reader's time range.
+type rangeWriter struct {
+	r       *Reader
+	out     io.Writer
+	partial []byte
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := w.partial[:i+1]
+		w.partial = w.partial[i+1:]
+
+		switch w.r.inRange(string(line[:i])) {
+		case 1:
+			return 0, errPastRange
+		case 0:
+			if _, err := w.out.Write(line); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// copyAvailable writes everything that can currently be read from reader,
+// including a trailing partial line, to out.
+func copyAvailable(reader *bufio.Reader, out io.Writer) error {
+	for {
+		line, err := reader.ReadBytes('\n')
+		// Always write any data we received, even with EOF
+		if len(line) > 0 {
+			if _, werr := out.Write(line); werr != nil {
+				return fmt.Errorf("write output: %w", werr)
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				// No more data, wait for next poll
+				return nil
+			}
+			return fmt.Errorf("read line: %w", err)
+		}
+	}
+}
+
+// reopenIfRotated opens the log at path if it is no longer the file being
+// followed. It reports false while the log has not been rotated, or while a
+// rotation is in progress and the new log does not exist yet.
+func reopenIfRotated(path string, file *os.File) (*os.File, bool, error) {
+	current, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("stat log file: %w", err)
+	}
+	followed, err := file.Stat()
+	if err != nil {
+		return nil, false, fmt.Errorf("stat log file: %w", err)
+	}
+	if os.SameFile(current, followed) {
+		return nil, false, nil
+	}
+
 	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
-	file, err := os.Open(path)
+	next, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("open log file: %w", err)
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("open log file: %w", err)
+	}
+	return next, true, nil
+}
+
+// readAllLines reads all lines of a session log, oldest segment first.
+func readAllLines(path string) ([]string, error) {
+	segs, err := rotatedSegments(path)
+	if err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
 	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	var partial string // Unterminated last line of the previous segment
+	for _, seg := range segs {
+		segLines, complete, err := readSegment(seg, 0)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // Deleted by the size cap since it was listed
+			}
+			return nil, err
+		}
+		segLines = joinPartial(partial, segLines)
+		partial = ""
+		if !complete && len(segLines) > 0 {
+			partial = segLines[len(segLines)-1]
+			segLines = segLines[:len(segLines)-1]
+		}
+		lines = append(lines, segLines...)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan log file: %w", err)
+	activeLines, _, err := readSegment(segment{path: path}, 0)
+	if err != nil {
+		return nil, err
+	}
+	activeLines = joinPartial(partial, activeLines)
+	return append(lines, activeLines...), nil
+}
+
+// readLastNLines reads the last n lines of a session log. Rotated segments
+// are read from newest to oldest only until n lines have been found.
+func readLastNLines(path string, n int) ([]string, error) {
+	// One extra line is kept per segment in case the first line continues
+	// the unterminated last line of the previous segment.
+	lines, _, err := readSegment(segment{path: path}, n+1)
+	if err != nil {
+		return nil, err
 	}
 
+	segs, err := rotatedSegments(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(segs) - 1; i >= 0 && len(lines) <= n; i-- {
+		older, complete, err := readSegment(segs[i], n+1)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				break // Deleted by the size cap; older segments are gone too
+			}
+			return nil, err
+		}
+		if !complete && len(older) > 0 {
+			lines = joinPartial(older[len(older)-1], lines)
+			older = older[:len(older)-1]
+		}
+		lines = append(older, lines...)
+	}
+
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
 	return lines, nil
 }
 
-// readLastNLines reads the last n lines from a file.
+// readSegment reads the lines of a log segment, keeping only the last n
+// if n > 0. It also reports whether the segment ends with a newline.
 // Uses a ring buffer approach for efficiency with large files.
-func readLastNLines(path string, n int) ([]string, error) {
-	//nolint:gosec // G304: path is constructed from trusted PathManager, not arbitrary user input
-	file, err := os.Open(path)
+func readSegment(seg segment, n int) ([]string, bool, error) {
+	rc, err := seg.open()
 	if err != nil {
-		return nil, fmt.Errorf("open log file: %w", err)
+		return nil, false, err
+	}
+	defer rc.Close()
+
+	tracker := &lastByteReader{r: rc}
+	scanner := newLineScanner(tracker)
+
+	if n <= 0 {
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, false, fmt.Errorf("scan log file: %w", err)
+		}
+		return lines, tracker.endsWithNewline(), nil
 	}
-	defer file.Close()
 
 	// Use a ring buffer to keep track of last n lines
 	ring := make([]string, n)
 	idx := 0
 	count := 0
 
-	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		ring[idx] = scanner.Text()
 		idx = (idx + 1) % n
@@ -152,17 +397,13 @@ func readLastNLines(path string, n int) ([]string, error) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan log file: %w", err)
+		return nil, false, fmt.Errorf("scan log file: %w", err)
 	}
 
 	// Build result in correct order
-	if count == 0 {
-		return nil, nil
-	}
-
 	if count < n {
 		// Haven't filled the buffer yet
-		return ring[:count], nil
+		return ring[:count], tracker.endsWithNewline(), nil
 	}
 
 	// Buffer is full, need to reorder
@@ -170,5 +411,71 @@ func readLastNLines(path string, n int) ([]string, error) {
 	for i := range n {
 		result[i] = ring[(idx+i)%n]
 	}
-	return result, nil
+	return result, tracker.endsWithNewline(), nil
+}
+
+// joinPartial prepends the unterminated last line of the previous segment to
+// the first of lines.
+func joinPartial(partial string, lines []string) []string {
+	if partial == "" {
+		return lines
+	}
+	if len(lines) == 0 {
+		return []string{partial}
+	}
+	lines[0] = partial + lines[0]
+	return lines
+}
+
+// maxLineLength is the length at which log lines are truncated when read
+// line by line. Full-screen programs can redraw for a long time without a
+// newline.
+const maxLineLength = 1 << 20
+
+// newLineScanner returns a scanner of the lines of r. Lines longer than
+// maxLineLength are truncated to it rather than ending the scan with
+// bufio.ErrTooLong.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineLength)
+	skipping := false // Within the rest of a truncated line
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if skipping {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				return len(data), nil, nil
+			}
+			skipping = false
+			return i + 1, nil, nil
+		}
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance == 0 && token == nil && len(data) >= maxLineLength {
+			skipping = true
+			return len(data), data[:maxLineLength], nil
+		}
+		return advance, token, err
+	})
+	return scanner
+}
+
+// lastByteReader records the last byte read through it.
+type lastByteReader struct {
+	r    io.Reader
+	last byte
+	read bool
+}
+
+func (l *lastByteReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if n > 0 {
+		l.last = p[n-1]
+		l.read = true
+	}
+	return n, err
+}
+
+// endsWithNewline reports whether the data read ended with a newline. Empty
+// input counts as ending with a newline.
+func (l *lastByteReader) endsWithNewline() bool {
+	return !l.read || l.last == '\n'
 }
@@ -0,0 +1,358 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Defaults for RotateConfig.
+const (
+	DefaultMaxSize  = 10 << 20  // 10 MiB
+	DefaultMaxTotal = 200 << 20 // 200 MiB
+)
+
+// compressedExt is the file extension of compressed rotated segments.
+const compressedExt = ".gz"
+
+// RotateConfig configures size-based rotation of session logs.
+//
+// The active log of a session is <sessionID>.log. When it reaches MaxSize it
+// is renamed to a rotated segment, <sessionID>.log.<N>, where N increases with
+// each rotation, and compressed to <sessionID>.log.<N>.gz if Compress is set.
+type RotateConfig struct {
+	MaxSize  int64 // Size at which the active log is rotated (0 = never rotate)
+	MaxTotal int64 // Total size of an instance's logs above which the oldest rotated segments are deleted (0 = unlimited)
+	Compress bool  // Gzip rotated segments
+}
+
+// DefaultRotateConfig returns the default rotation settings.
+func DefaultRotateConfig() RotateConfig {
+	return RotateConfig{
+		MaxSize:  DefaultMaxSize,
+		MaxTotal: DefaultMaxTotal,
+		Compress: true,
+	}
+}
+
+// RotatingWriter appends to a session log, rotating it by size. It is not
+// safe for concurrent use.
+type RotatingWriter struct {
+	path string
+	cfg  RotateConfig
+	file *os.File
+	size int64
+}
+
+// NewRotatingWriter opens the session log at path for appending.
+func NewRotatingWriter(path string, cfg RotateConfig) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, cfg: cfg}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the active log, first rotating it if p would take it
+// past the maximum size. A single write is never split across segments.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	if w.cfg.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.cfg.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write log file: %w", err)
+	}
+	return n, nil
+}
+
+// Close closes the active log.
+func (w *RotatingWriter) Close() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	return nil
+}
+
+// open opens the active log and records its current size.
+func (w *RotatingWriter) open() error {
+	//nolint:gosec // G302/G304: path is from trusted PathManager; 0644 matches the other session log writers
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate moves the active log to the next segment, compresses it if
+// configured, enforces the instance size cap and opens a new active log.
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	segs, err := rotatedSegments(w.path)
+	if err != nil {
+		return err
+	}
+	seq := 1
+	if len(segs) > 0 {
+		seq = segs[len(segs)-1].seq + 1
+	}
+
+	rotated := w.path + "." + strconv.Itoa(seq)
+	if err := os.Rename(w.path, rotated); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	if w.cfg.Compress {
+		if err := compressFile(rotated); err != nil {
+			return err
+		}
+	}
+	if w.cfg.MaxTotal > 0 {
+		if err := enforceTotal(filepath.Dir(w.path), w.cfg.MaxTotal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile gzips path to path.gz and removes path. The compressed file
+// is written under a temporary name so readers never see a partial segment.
+func compressFile(path string) (err error) {
+	//nolint:gosec // G304: path is a rotated segment of a trusted log path
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open rotated log: %w", err)
+	}
+	defer src.Close()
+
+	tmp := path + compressedExt + ".tmp"
+	//nolint:gosec // G302/G304: tmp is derived from a trusted log path; 0644 matches the other session logs
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("create compressed log: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return fmt.Errorf("compress log: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress log: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("close compressed log: %w", err)
+	}
+	if err := os.Rename(tmp, path+compressedExt); err != nil {
+		return fmt.Errorf("rename compressed log: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remove rotated log: %w", err)
+	}
+	return nil
+}
+
+// enforceTotal deletes the oldest rotated segments in dir until the total
+// size of the files in dir is at most maxTotal. Active logs are never deleted.
+func enforceTotal(dir string, maxTotal int64) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read instance log directory: %w", err)
+	}
+
+	type rotatedFile struct {
+		path  string
+		size  int64
+		mtime int64
+		seq   int
+	}
+	var total int64
+	var rotated []rotatedFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed concurrently
+		}
+		total += info.Size()
+		if _, seq, ok := parseSegment(entry.Name()); ok {
+			rotated = append(rotated, rotatedFile{
+				path:  filepath.Join(dir, entry.Name()),
+				size:  info.Size(),
+				mtime: info.ModTime().UnixNano(),
+				seq:   seq,
+			})
+		}
+	}
+
+	// Modification times can be equal for segments rotated in quick
+	// succession, so ties are broken by sequence number.
+	sort.Slice(rotated, func(i, j int) bool {
+		if rotated[i].mtime != rotated[j].mtime {
+			return rotated[i].mtime < rotated[j].mtime
+		}
+		return rotated[i].seq < rotated[j].seq
+	})
+	for _, f := range rotated {
+		if total <= maxTotal {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove rotated log: %w", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// segment is one file of a session log: a rotated segment or the active log.
+type segment struct {
+	path       string
+	seq        int
+	compressed bool
+}
+
+// open opens the segment for reading, decompressing it if needed.
+func (s segment) open() (io.ReadCloser, error) {
+	//nolint:gosec // G304: path is a rotated segment of a trusted log path
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	if !s.compressed {
+		return file, nil
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("open compressed log %s: %w", s.path, err)
+	}
+	return &gzipFile{Reader: zr, file: file}, nil
+}
+
+// gzipFile closes both the decompressor and the underlying file.
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	zerr := g.Reader.Close()
+	if err := g.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	return zerr
+}
+
+// rotatedSegments returns the rotated segments of the session log at path,
+// oldest first. When a segment exists both compressed and uncompressed
+// (compression was interrupted), the compressed copy is used.
+func rotatedSegments(path string) ([]segment, error) {
+	dir, base := filepath.Split(path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read instance log directory: %w", err)
+	}
+
+	bySeq := make(map[int]segment)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		active, seq, ok := parseSegment(name)
+		if !ok || active != base {
+			continue
+		}
+		seg := segment{
+			path:       filepath.Join(dir, name),
+			seq:        seq,
+			compressed: strings.HasSuffix(name, compressedExt),
+		}
+		if prev, exists := bySeq[seq]; !exists || !prev.compressed {
+			bySeq[seq] = seg
+		}
+	}
+
+	segs := make([]segment, 0, len(bySeq))
+	for _, seg := range bySeq {
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].seq < segs[j].seq })
+	return segs, nil
+}
+
+// parseSegment parses a rotated segment file name of the form
+// <sessionID>.log.<N>[.gz] or <sessionID>.txt.<N>[.gz], returning the active
+// log name and N.
+func parseSegment(name string) (active string, seq int, ok bool) {
+	name = strings.TrimSuffix(name, compressedExt)
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		return "", 0, false
+	}
+	seq, err := strconv.Atoi(name[i+1:])
+	if err != nil || seq < 1 {
+		return "", 0, false
+	}
+	active = name[:i]
+	if ext := filepath.Ext(active); ext != ".log" && ext != ".txt" {
+		return "", 0, false
+	}
+	return active, seq, true
+}
+
+// removeSegments deletes the rotated segments of the session log at path.
+func removeSegments(path string) error {
+	dir, base := filepath.Split(path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read instance log directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmp")
+		if active, _, ok := parseSegment(name); ok && active == base {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove rotated log: %w", err)
+			}
+		}
+	}
+	return nil
+}
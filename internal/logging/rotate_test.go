@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRotating writes each chunk to a rotating writer for inst1/sess1.
+func writeRotating(t *testing.T, dir string, cfg RotateConfig, chunks ...string) string {
+	t.Helper()
+	path, err := NewPathManager(dir).EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+
+	w, err := NewRotatingWriter(path, cfg)
+	require.NoError(t, err)
+	for _, chunk := range chunks {
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return path
+}
+
+// logFiles returns the names of the files in the inst1 log directory.
+func logFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(dir, "inst1"))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestRotatingWriter_Rotates(t *testing.T) {
+	t.Run("compresses rotated segments", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRotating(t, dir, RotateConfig{MaxSize: 12, Compress: true},
+			"line1\n", "line2\n", "line3\n", "line4\n", "line5\n")
+
+		assert.ElementsMatch(t, []string{"sess1.log", "sess1.log.1.gz", "sess1.log.2.gz"}, logFiles(t, dir))
+
+		lines, err := NewReader(NewPathManager(dir)).ReadAll("inst1", "sess1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"line1", "line2", "line3", "line4", "line5"}, lines)
+	})
+
+	t.Run("keeps rotated segments uncompressed", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRotating(t, dir, RotateConfig{MaxSize: 12}, "line1\n", "line2\n", "line3\n")
+
+		assert.ElementsMatch(t, []string{"sess1.log", "sess1.log.1"}, logFiles(t, dir))
+	})
+
+	t.Run("never rotates without a maximum size", func(t *testing.T) {
+		dir := t.TempDir()
+		writeRotating(t, dir, RotateConfig{Compress: true}, strings.Repeat("x", 1<<16))
+
+		assert.Equal(t, []string{"sess1.log"}, logFiles(t, dir))
+	})
+
+	t.Run("continues numbering after reopening", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := RotateConfig{MaxSize: 12}
+		writeRotating(t, dir, cfg, "line1\n", "line2\n", "line3\n")
+		writeRotating(t, dir, cfg, "line4\n", "line5\n")
+
+		assert.ElementsMatch(t, []string{"sess1.log", "sess1.log.1", "sess1.log.2"}, logFiles(t, dir))
+
+		lines, err := NewReader(NewPathManager(dir)).ReadAll("inst1", "sess1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"line1", "line2", "line3", "line4", "line5"}, lines)
+	})
+}
+
+func TestRotatingWriter_MaxTotal(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+
+	// Another session's active log counts toward the instance total
+	other, err := pm.EnsureSessionLog("inst1", "sess2")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(other, []byte("0123456789\n"), 0o600))
+
+	writeRotating(t, dir, RotateConfig{MaxSize: 6, MaxTotal: 30},
+		"line1\n", "line2\n", "line3\n", "line4\n", "line5\n", "line6\n")
+
+	assert.ElementsMatch(t, []string{"sess1.log", "sess1.log.3", "sess1.log.4", "sess1.log.5", "sess2.log"}, logFiles(t, dir))
+
+	lines, err := NewReader(pm).ReadAll("inst1", "sess1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line3", "line4", "line5", "line6"}, lines)
+}
+
+func TestReader_ReadLastN_AcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	// The second line is split across the first rotation
+	writeRotating(t, dir, RotateConfig{MaxSize: 10, Compress: true},
+		"one\ntw", "o\nthree\n", "four\n", "five\n")
+	reader := NewReader(NewPathManager(dir))
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{n: 1, want: []string{"five"}},
+		{n: 3, want: []string{"three", "four", "five"}},
+		{n: 4, want: []string{"two", "three", "four", "five"}},
+		{n: 10, want: []string{"one", "two", "three", "four", "five"}},
+	}
+	for _, tt := range tests {
+		lines, err := reader.ReadLastN("inst1", "sess1", tt.n)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, lines, "n=%d", tt.n)
+	}
+
+	all, err := reader.ReadAll("inst1", "sess1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "three", "four", "five"}, all)
+}
+
+func TestReader_ReadAll_InterruptedCompression(t *testing.T) {
+	dir := t.TempDir()
+	path := writeRotating(t, dir, RotateConfig{MaxSize: 6, Compress: true}, "line1\n", "line2\n")
+
+	// Compression finished but the uncompressed segment was not removed
+	require.NoError(t, os.WriteFile(path+".1", []byte("line1\n"), 0o600))
+
+	lines, err := NewReader(NewPathManager(dir)).ReadAll("inst1", "sess1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line1", "line2"}, lines)
+}
+
+func TestReader_Follow_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeRotating(t, dir, RotateConfig{})
+
+	w, err := NewRotatingWriter(path, RotateConfig{MaxSize: 12, Compress: true})
+	require.NoError(t, err)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- NewReader(NewPathManager(dir)).Follow(ctx, "inst1", "sess1", &out, 10*time.Millisecond)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n", "line5\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		time.Sleep(30 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, "line1\nline2\nline3\nline4\nline5\n", out.String())
+	assert.Contains(t, logFiles(t, dir), "sess1.log.2.gz")
+}
+
+func TestPathManager_RemoveSessionLog_Segments(t *testing.T) {
+	dir := t.TempDir()
+	pm := NewPathManager(dir)
+	path := writeRotating(t, dir, RotateConfig{MaxSize: 6, Compress: true}, "line1\n", "line2\n", "line3\n")
+	w, err := OpenSessionLog(path, RotateConfig{MaxSize: 6}, true)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line4\nline5\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Contains(t, logFiles(t, dir), "sess1.txt")
+
+	other, err := pm.EnsureSessionLog("inst1", "sess2")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(other, nil, 0o600))
+
+	require.NoError(t, pm.RemoveSessionLog("inst1", "sess1"))
+
+	assert.Equal(t, []string{"sess2.log"}, logFiles(t, dir))
+}
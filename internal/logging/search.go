@@ -0,0 +1,262 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SearchOptions configures a log search.
+type SearchOptions struct {
+	Context int // Lines of context to include before and after each match
+	Workers int // Number of session logs scanned concurrently (<= 0 = number of CPUs)
+
+	// Include reports whether a session's log is searched (nil = all).
+	Include func(instanceID, sessionID string) bool
+}
+
+// SearchMatch is a line of a session log that matched a search.
+type SearchMatch struct {
+	InstanceID string
+	SessionID  string
+	Line       int      // Line number within the session log, starting at 1
+	Text       string   // Matched line, cleaned with CleanLine
+	Before     []string // Cleaned context lines before the match, oldest first
+	After      []string // Cleaned context lines after the match
+}
+
+// Searcher searches the session logs of all instances.
+type Searcher struct {
+	pathMgr *PathManager
+}
+
+// NewSearcher creates a new Searcher with the given PathManager.
+func NewSearcher(pathMgr *PathManager) *Searcher {
+	return &Searcher{pathMgr: pathMgr}
+}
+
+// searchJob is one session log to search.
+type searchJob struct {
+	instanceID string
+	sessionID  string
+}
+
+// Search scans every session log under the base directory, including
+// rotated segments, for lines matching re. Lines are cleaned with CleanLine
+// before matching, so patterns never see terminal escape sequences.
+// Matches are ordered by instance ID, session ID and line number.
+func (s *Searcher) Search(ctx context.Context, re *regexp.Regexp, opts SearchOptions) ([]SearchMatch, error) {
+	jobs, err := s.jobs(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(jobs))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first error cancels the remaining scans, which then fail with
+	// context errors that are not reported.
+	var (
+		mu       sync.Mutex
+		matches  []SearchMatch
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	queue := make(chan searchJob)
+	for range workers {
+		wg.Go(func() {
+			for job := range queue {
+				found, err := s.searchSession(ctx, job, re, opts.Context)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				matches = append(matches, found...)
+				mu.Unlock()
+			}
+		})
+	}
+
+send:
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.InstanceID != b.InstanceID {
+			return a.InstanceID < b.InstanceID
+		}
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		return a.Line < b.Line
+	})
+	return matches, nil
+}
+
+// jobs lists the session logs to search.
+func (s *Searcher) jobs(include func(instanceID, sessionID string) bool) ([]searchJob, error) {
+	instances, err := s.pathMgr.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []searchJob
+	for _, instanceID := range instances {
+		sessions, err := s.pathMgr.ListSessionLogs(instanceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sessionID := range sessions {
+			if include == nil || include(instanceID, sessionID) {
+				jobs = append(jobs, searchJob{instanceID: instanceID, sessionID: sessionID})
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// searchSession scans one session log. A log removed while it is being
+// searched has no matches.
+func (s *Searcher) searchSession(ctx context.Context, job searchJob, re *regexp.Regexp, contextLines int) ([]SearchMatch, error) {
+	var matches []SearchMatch
+	var before []string // Last context lines, oldest first
+	var open []int      // Indexes of matches still collecting context after them
+	lineNo := 0
+
+	err := scanLines(s.pathMgr.SessionLogPath(job.instanceID, job.sessionID), func(raw string) error {
+		lineNo++
+		if lineNo%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		line := CleanLine(raw)
+
+		still := open[:0]
+		for _, i := range open {
+			matches[i].After = append(matches[i].After, line)
+			if len(matches[i].After) < contextLines {
+				still = append(still, i)
+			}
+		}
+		open = still
+
+		if re.MatchString(line) {
+			matches = append(matches, SearchMatch{
+				InstanceID: job.instanceID,
+				SessionID:  job.sessionID,
+				Line:       lineNo,
+				Text:       line,
+				Before:     append([]string(nil), before...),
+			})
+			if contextLines > 0 {
+				open = append(open, len(matches)-1)
+			}
+		}
+
+		if contextLines > 0 {
+			if len(before) == contextLines {
+				before = before[1:]
+			}
+			before = append(before, line)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("search log of session %s: %w", job.sessionID, err)
+	}
+	return matches, nil
+}
+
+// scanLines calls fn for each line of a session log, oldest segment first,
+// without reading whole segments into memory. A line split across a
+// rotation is passed to fn once, joined.
+func scanLines(path string, fn func(line string) error) error {
+	segs, err := rotatedSegments(path)
+	if err != nil {
+		return err
+	}
+	segs = append(segs, segment{path: path})
+
+	var partial string // Unterminated last line of the previous segment
+	for i, seg := range segs {
+		last, complete, err := scanSegment(seg, partial, fn)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && i < len(segs)-1 {
+				continue // Deleted by the size cap since it was listed
+			}
+			return err
+		}
+		partial = ""
+		if !complete && i < len(segs)-1 {
+			partial = last
+		} else if last != "" || complete {
+			if err := fn(last); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanSegment calls fn for every line of seg except the last, which it
+// returns along with whether the segment ends with a newline. partial is
+// prepended to the first line. If the segment is empty, partial is returned
+// as the last line.
+func scanSegment(seg segment, partial string, fn func(line string) error) (string, bool, error) {
+	rc, err := seg.open()
+	if err != nil {
+		return "", false, err
+	}
+	defer rc.Close()
+
+	tracker := &lastByteReader{r: rc}
+	scanner := newLineScanner(tracker)
+	last := partial
+	first := true
+	for scanner.Scan() {
+		if first {
+			last += scanner.Text()
+			first = false
+			continue
+		}
+		if err := fn(last); err != nil {
+			return "", false, err
+		}
+		last = scanner.Text()
+	}
+	if err := scanner.Err(); err != nil {
+		return "", false, fmt.Errorf("scan log file: %w", err)
+	}
+	return last, !first && tracker.endsWithNewline(), nil
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLog writes content as the active log of a session.
+func writeLog(t *testing.T, dir, instanceID, sessionID, content string) {
+	t.Helper()
+	path, err := NewPathManager(dir).EnsureSessionLog(instanceID, sessionID)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestSearcher_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeLog(t, dir, "inst1", "sess1", "one\n\x1b[31mmigrations/001.sql\x1b[0m\nthree\nfour\n")
+	writeLog(t, dir, "inst1", "sess2", "nothing here\n")
+	writeLog(t, dir, "inst2", "sess1", "edit migrations/002.sql")
+	searcher := NewSearcher(NewPathManager(dir))
+	re := regexp.MustCompile(`migrations/\d+\.sql`)
+
+	t.Run("finds cleaned matches in all instances", func(t *testing.T) {
+		matches, err := searcher.Search(context.Background(), re, SearchOptions{Workers: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, []SearchMatch{
+			{InstanceID: "inst1", SessionID: "sess1", Line: 2, Text: "migrations/001.sql"},
+			{InstanceID: "inst2", SessionID: "sess1", Line: 1, Text: "edit migrations/002.sql"},
+		}, matches)
+	})
+
+	t.Run("includes context lines", func(t *testing.T) {
+		matches, err := searcher.Search(context.Background(), re, SearchOptions{Context: 1})
+		require.NoError(t, err)
+
+		require.Len(t, matches, 2)
+		assert.Equal(t, []string{"one"}, matches[0].Before)
+		assert.Equal(t, []string{"three"}, matches[0].After)
+		assert.Empty(t, matches[1].Before)
+		assert.Empty(t, matches[1].After)
+	})
+
+	t.Run("searches only included sessions", func(t *testing.T) {
+		matches, err := searcher.Search(context.Background(), re, SearchOptions{
+			Include: func(instanceID, _ string) bool { return instanceID == "inst2" },
+		})
+		require.NoError(t, err)
+
+		require.Len(t, matches, 1)
+		assert.Equal(t, "inst2", matches[0].InstanceID)
+	})
+
+	t.Run("stops when canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := searcher.Search(ctx, re, SearchOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSearcher_Search_AcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	// The match is split across the first rotation
+	writeRotating(t, dir, RotateConfig{MaxSize: 10, Compress: true},
+		"one\nneed", "le\nthree\n", "four\n")
+
+	matches, err := NewSearcher(NewPathManager(dir)).Search(context.Background(), regexp.MustCompile("needle|four"), SearchOptions{Context: 2})
+	require.NoError(t, err)
+
+	require.Len(t, matches, 2)
+	assert.Equal(t, SearchMatch{
+		InstanceID: "inst1",
+		SessionID:  "sess1",
+		Line:       2,
+		Text:       "needle",
+		Before:     []string{"one"},
+		After:      []string{"three", "four"},
+	}, matches[0])
+	assert.Equal(t, 4, matches[1].Line)
+	assert.Equal(t, []string{"needle", "three"}, matches[1].Before)
+}
+
+func TestSearcher_Search_NoLogs(t *testing.T) {
+	matches, err := NewSearcher(NewPathManager(t.TempDir()+"/missing")).Search(context.Background(), regexp.MustCompile("x"), SearchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestScanLines(t *testing.T) {
+	dir := t.TempDir()
+	path := writeRotating(t, dir, RotateConfig{MaxSize: 6}, "a\nb", "c\n\n", "d")
+
+	var lines []string
+	require.NoError(t, scanLines(path, func(line string) error {
+		lines = append(lines, line)
+		return nil
+	}))
+
+	all, err := readAllLines(path)
+	require.NoError(t, err)
+	assert.Equal(t, all, lines)
+	assert.Equal(t, "a|bc||d", strings.Join(lines, "|"))
+}
+
+func TestSearcher_Search_LongLines(t *testing.T) {
+	dir := t.TempDir()
+	long := strings.Repeat("x", 2*maxLineLength)
+	writeLog(t, dir, "inst1", "sess1", "before\n"+strings.Repeat("y", 100_000)+"\n"+long+"\nneedle\n")
+
+	matches, err := NewSearcher(NewPathManager(dir)).Search(context.Background(), regexp.MustCompile("needle"), SearchOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, matches, 1)
+	assert.Equal(t, 4, matches[0].Line)
+}
+
+func TestNewLineScanner_TruncatesLongLines(t *testing.T) {
+	long := strings.Repeat("x", maxLineLength+10)
+	scanner := newLineScanner(strings.NewReader("a\n" + long + "\nb\n" + long))
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, lines, 4)
+	assert.Equal(t, "a", lines[0])
+	assert.Len(t, lines[1], maxLineLength)
+	assert.Equal(t, "b", lines[2])
+	assert.Len(t, lines[3], maxLineLength)
+}
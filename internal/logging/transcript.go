@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// TranscriptTimeFormat is the format of the timestamp that starts each
+// transcript line.
+const TranscriptTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// maxTranscriptLine bounds the buffered partial line. Full-screen programs
+// can redraw for a long time without a newline; their output is written out
+// in pieces of this size.
+const maxTranscriptLine = 64 << 10
+
+// TranscriptPath returns the transcript path for the session log at logPath.
+// Path format: <dir>/<sessionID>.txt
+func TranscriptPath(logPath string) string {
+	return strings.TrimSuffix(logPath, ".log") + ".txt"
+}
+
+// TranscriptWriter converts raw terminal output into a plain-text
+// transcript. Each line is cleaned with CleanLine and prefixed with the time
+// it was completed. Lines that are blank after cleaning are dropped.
+type TranscriptWriter struct {
+	out     io.Writer
+	now     func() time.Time
+	partial []byte
+}
+
+// NewTranscriptWriter creates a TranscriptWriter that writes to out.
+func NewTranscriptWriter(out io.Writer) *TranscriptWriter {
+	return &TranscriptWriter{out: out, now: time.Now}
+}
+
+// Write buffers p and writes out every completed line.
+func (t *TranscriptWriter) Write(p []byte) (int, error) {
+	t.partial = append(t.partial, p...)
+
+	start := 0
+	for {
+		i := bytes.IndexByte(t.partial[start:], '\n')
+		if i < 0 {
+			break
+		}
+		if err := t.writeLine(t.partial[start : start+i]); err != nil {
+			return 0, err
+		}
+		start += i + 1
+	}
+	t.partial = t.partial[:copy(t.partial, t.partial[start:])]
+
+	if len(t.partial) > maxTranscriptLine {
+		if err := t.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes out a buffered partial line.
+func (t *TranscriptWriter) Flush() error {
+	if len(t.partial) == 0 {
+		return nil
+	}
+	err := t.writeLine(t.partial)
+	t.partial = t.partial[:0]
+	return err
+}
+
+// writeLine writes one cleaned, timestamped line.
+func (t *TranscriptWriter) writeLine(raw []byte) error {
+	line := strings.TrimRight(CleanLine(string(raw)), " ")
+	if line == "" {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(t.out, "%s %s\n", t.now().Format(TranscriptTimeFormat), line); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return nil
+}
+
+// sessionLog records session output to the raw log and a transcript.
+type sessionLog struct {
+	raw            *RotatingWriter
+	transcript     *TranscriptWriter
+	transcriptFile *RotatingWriter
+}
+
+// OpenSessionLog opens the session log at path for appending, rotated as
+// configured. If transcript is set, a transcript is also written to the
+// path returned by TranscriptPath, with the same rotation.
+func OpenSessionLog(path string, rotation RotateConfig, transcript bool) (io.WriteCloser, error) {
+	raw, err := NewRotatingWriter(path, rotation)
+	if err != nil {
+		return nil, err
+	}
+	if !transcript {
+		return raw, nil
+	}
+
+	file, err := NewRotatingWriter(TranscriptPath(path), rotation)
+	if err != nil {
+		_ = raw.Close()
+		return nil, err
+	}
+	return &sessionLog{raw: raw, transcript: NewTranscriptWriter(file), transcriptFile: file}, nil
+}
+
+// Write writes p to the raw log and the transcript.
+func (s *sessionLog) Write(p []byte) (int, error) {
+	n, err := s.raw.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if _, err := s.transcript.Write(p); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// Close flushes the transcript and closes both logs.
+func (s *sessionLog) Close() error {
+	return errors.Join(s.transcript.Flush(), s.transcriptFile.Close(), s.raw.Close())
+}
@@ -0,0 +1,190 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedClock returns a clock that starts at start and advances a second per
+// call.
+func fixedClock(start time.Time) func() time.Time {
+	next := start
+	return func() time.Time {
+		t := next
+		next = next.Add(time.Second)
+		return t
+	}
+}
+
+func TestTranscriptPath(t *testing.T) {
+	assert.Equal(t, "/logs/inst1/sess1.txt", TranscriptPath("/logs/inst1/sess1.log"))
+}
+
+func TestTranscriptWriter(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	t.Run("writes cleaned timestamped lines", func(t *testing.T) {
+		var out bytes.Buffer
+		w := NewTranscriptWriter(&out)
+		w.now = fixedClock(start)
+
+		_, err := w.Write([]byte("\x1b[32m$\x1b[0m make\r\nbuil"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("ding 10%\rbuilding 100%\r\n\x1b[2K\r\n   \nok"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "2024-01-02T15:04:05.000Z $ make\n2024-01-02T15:04:06.000Z building 100%\n", out.String())
+
+		require.NoError(t, w.Flush())
+		assert.True(t, strings.HasSuffix(out.String(), "2024-01-02T15:04:07.000Z ok\n"))
+	})
+
+	t.Run("writes out long partial lines", func(t *testing.T) {
+		var out bytes.Buffer
+		w := NewTranscriptWriter(&out)
+
+		_, err := w.Write(bytes.Repeat([]byte("x"), maxTranscriptLine+1))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+		assert.Empty(t, w.partial)
+	})
+}
+
+func TestOpenSessionLog(t *testing.T) {
+	t.Run("writes raw log and transcript", func(t *testing.T) {
+		dir := t.TempDir()
+		pm := NewPathManager(dir)
+		path, err := pm.EnsureSessionLog("inst1", "sess1")
+		require.NoError(t, err)
+
+		w, err := OpenSessionLog(path, RotateConfig{}, true)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("\x1b[1mhello\x1b[0m\r\nbye"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[1mhello\x1b[0m\r\nbye", string(raw))
+
+		lines, err := NewTranscriptReader(pm).ReadAll("inst1", "sess1")
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasSuffix(lines[0], " hello"))
+		assert.True(t, strings.HasSuffix(lines[1], " bye"))
+	})
+
+	t.Run("writes only the raw log without transcript", func(t *testing.T) {
+		dir := t.TempDir()
+		path, err := NewPathManager(dir).EnsureSessionLog("inst1", "sess1")
+		require.NoError(t, err)
+
+		w, err := OpenSessionLog(path, RotateConfig{}, false)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, []string{"sess1.log"}, logFiles(t, dir))
+	})
+}
+
+// createTestTranscript writes a transcript for inst1/sess1 with one line per
+// minute starting at start.
+func createTestTranscript(t *testing.T, dir string, start time.Time, lines ...string) {
+	t.Helper()
+	path, err := NewPathManager(dir).EnsureSessionLog("inst1", "sess1")
+	require.NoError(t, err)
+
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(start.Add(time.Duration(i)*time.Minute).Format(TranscriptTimeFormat) + " " + line + "\n")
+	}
+	require.NoError(t, os.WriteFile(TranscriptPath(path), []byte(b.String()), 0o600))
+}
+
+func TestReader_WithTimeRange(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	createTestTranscript(t, dir, start, "one", "two", "three", "four")
+	reader := NewTranscriptReader(NewPathManager(dir))
+
+	text := func(lines []string) []string {
+		var out []string
+		for _, line := range lines {
+			_, text, _ := strings.Cut(line, " ")
+			out = append(out, text)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		since time.Time
+		until time.Time
+		want  []string
+	}{
+		{name: "no range", want: []string{"one", "two", "three", "four"}},
+		{name: "since", since: start.Add(2 * time.Minute), want: []string{"three", "four"}},
+		{name: "until", until: start.Add(90 * time.Second), want: []string{"one", "two"}},
+		{name: "since and until", since: start.Add(time.Minute), until: start.Add(2 * time.Minute), want: []string{"two", "three"}},
+		{name: "empty range", since: start.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := reader.WithTimeRange(tt.since, tt.until).ReadAll("inst1", "sess1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, text(lines))
+		})
+	}
+
+	t.Run("last n lines in range", func(t *testing.T) {
+		lines, err := reader.WithTimeRange(time.Time{}, start.Add(2*time.Minute)).ReadLastN("inst1", "sess1", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"two", "three"}, text(lines))
+	})
+
+	t.Run("raw log lines are never in range", func(t *testing.T) {
+		createTestLog(t, dir, "inst1", "sess1", []string{"raw"})
+
+		lines, err := NewReader(NewPathManager(dir)).WithTimeRange(start, time.Time{}).ReadAll("inst1", "sess1")
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+}
+
+func TestReader_Follow_Until(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	createTestTranscript(t, dir, now.Add(-time.Hour), "old")
+	path := NewPathManager(dir).SessionTranscriptPath("inst1", "sess1")
+	reader := NewTranscriptReader(NewPathManager(dir)).WithTimeRange(time.Time{}, now.Add(time.Minute))
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- reader.Follow(context.Background(), "inst1", "sess1", &out, 10*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(now.Format(TranscriptTimeFormat) + " in range\n" +
+		now.Add(time.Hour).Format(TranscriptTimeFormat) + " after range\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Follow did not stop after the end of the range")
+	}
+	assert.Equal(t, now.Format(TranscriptTimeFormat)+" in range\n", out.String())
+}
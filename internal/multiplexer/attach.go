@@ -0,0 +1,101 @@
+package multiplexer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// runAttached runs a multiplexer attach command with the caller's terminal
+// connected. When stdin is a terminal it is placed in raw mode for the
+// duration of the command. Returns the captured stderr alongside any error so
+// that backends can map it to sentinel errors.
+func runAttached(ctx context.Context, e exec.Executor, name string, args []string) (string, error) {
+	stdinFd := int(os.Stdin.Fd())
+
+	// Capture stderr while also streaming to os.Stderr for user visibility
+	var stderrBuf bytes.Buffer
+	stderrWriter := io.MultiWriter(os.Stderr, &stderrBuf)
+
+	opts := &exec.RunOptions{
+		Name:   name,
+		Args:   args,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: stderrWriter,
+	}
+
+	// Check if stdin is a terminal
+	if !term.IsTerminal(stdinFd) {
+		// Fall back to non-interactive mode
+		_, err := e.Run(ctx, opts)
+		return stderrBuf.String(), err
+	}
+
+	// Put terminal in raw mode for proper TTY handling
+	oldState, err := term.MakeRaw(stdinFd)
+	if err != nil {
+		return "", fmt.Errorf("set terminal raw mode: %w", err)
+	}
+	defer func() {
+		// Drain stdin with timeout BEFORE restoring terminal mode.
+		// This catches in-flight terminal responses (escape sequences) that
+		// arrive asynchronously after the multiplexer exits for short-lived sessions.
+		// We do this while still in raw mode so responses are consumed properly.
+		drainStdinWithTimeout(stdinFd, 100*time.Millisecond)
+		_ = term.Restore(stdinFd, oldState)
+	}()
+
+	// Handle window resize signals
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	defer signal.Stop(sigCh)
+
+	_, err = e.Run(ctx, opts)
+	return stderrBuf.String(), err
+}
+
+// drainStdinWithTimeout reads and discards input from stdin for the specified duration.
+// This is used to consume stray terminal escape sequence responses that arrive
+// asynchronously after the multiplexer exits. The timeout allows in-flight responses to arrive.
+func drainStdinWithTimeout(fd int, timeout time.Duration) {
+	// Set stdin to non-blocking temporarily
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		return
+	}
+	//nolint:errcheck // best-effort cleanup
+	defer func() { _ = syscall.SetNonblock(fd, false) }()
+
+	deadline := time.Now().Add(timeout)
+	buf := make([]byte, 1024)
+
+	for time.Now().Before(deadline) {
+		//nolint:errcheck // best-effort drain, errors expected when no data
+		n, _ := syscall.Read(fd, buf)
+		if n <= 0 {
+			// No data available, wait briefly and try again
+			time.Sleep(10 * time.Millisecond)
+		}
+		// If we read data, continue immediately to drain more
+	}
+}
+
+// shellEscape escapes a string for safe use in a shell command.
+// It wraps the string in single quotes and escapes any embedded single quotes.
+func shellEscape(s string) string {
+	// Single quotes prevent all shell interpretation except for single quotes themselves.
+	// To include a single quote, we end the quoted string, add an escaped single quote,
+	// and start a new quoted string: 'foo'\''bar' -> foo'bar
+	escaped := strings.ReplaceAll(s, "'", `'\''`)
+	return "'" + escaped + "'"
+}
@@ -0,0 +1,413 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+// backendFixture describes how a multiplexer backend talks to its CLI so the
+// conformance suite can fake its responses with the mock executor.
+type backendFixture struct {
+	name   string
+	binary string
+	new    func(exec.Executor) Multiplexer
+
+	// isList reports whether opts is the backend's list-sessions call.
+	isList func(opts *exec.RunOptions) bool
+	// listOutput renders list-sessions stdout for the given session names.
+	listOutput func(names ...string) string
+
+	noSessionsStderr string // stderr when no sessions (or no server) exist; empty if listing succeeds
+	notFoundStderr   string // stderr when a named session does not exist
+	duplicateStderr  string // stderr when creation races with an existing session
+}
+
+var backendFixtures = []backendFixture{
+	{
+		name:   "tmux",
+		binary: "tmux",
+		new:    NewTmux,
+		isList: func(opts *exec.RunOptions) bool {
+			return opts.Args[0] == tmuxCmdListSessions
+		},
+		listOutput: func(names ...string) string {
+			return strings.Join(names, "\n") + "\n"
+		},
+		noSessionsStderr: "no server running on /tmp/tmux-1000/default",
+		notFoundStderr:   "can't find session: missing",
+		duplicateStderr:  "duplicate session: test-session",
+	},
+	{
+		name:   "zellij",
+		binary: "zellij",
+		new:    NewZellij,
+		isList: func(opts *exec.RunOptions) bool {
+			return opts.Args[0] == zellijCmdListSessions
+		},
+		listOutput: func(names ...string) string {
+			lines := make([]string, len(names))
+			for i, n := range names {
+				lines[i] = n + " [Created 5m ago]"
+			}
+			return strings.Join(lines, "\n") + "\n"
+		},
+		noSessionsStderr: "No active zellij sessions found.",
+		notFoundStderr:   "No session named \"missing\" found.",
+		duplicateStderr:  "Session with name \"test-session\" already exists.",
+	},
+	{
+		name:   "native",
+		binary: "hjk",
+		new: func(e exec.Executor) Multiplexer {
+			return NewNative(e, NativeConfig{Binary: "hjk"})
+		},
+		isList: func(opts *exec.RunOptions) bool {
+			return len(opts.Args) == 2 && opts.Args[0] == "mux" && opts.Args[1] == "ls"
+		},
+		listOutput: func(names ...string) string {
+			return strings.Join(names, "\n") + "\n"
+		},
+		notFoundStderr:  "Error: session not found",
+		duplicateStderr: "Error: session already exists",
+	},
+}
+
+// noSessions returns the backend's list-sessions response when no sessions exist.
+func (fx backendFixture) noSessions() (*exec.Result, error) {
+	if fx.noSessionsStderr == "" {
+		return &exec.Result{}, nil
+	}
+	return exitErr(fx.noSessionsStderr)
+}
+
+// exitErr returns a failed command result with the given stderr.
+func exitErr(stderr string) (*exec.Result, error) {
+	return &exec.Result{Stderr: []byte(stderr), ExitCode: 1}, errors.New("exit code 1")
+}
+
+func TestMultiplexerConformance(t *testing.T) {
+	for _, fx := range backendFixtures {
+		t.Run(fx.name, func(t *testing.T) {
+			testCreateSessionConformance(t, fx)
+			testListSessionsConformance(t, fx)
+			testKillSessionConformance(t, fx)
+			testAttachSessionConformance(t, fx)
+			testSendKeysConformance(t, fx)
+		})
+	}
+}
+
+func testCreateSessionConformance(t *testing.T, fx backendFixture) {
+	ctx := context.Background()
+
+	t.Run("CreateSession", func(t *testing.T) {
+		t.Run("creates session with name only", func(t *testing.T) {
+			var created bool
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, fx.binary, opts.Name)
+					if fx.isList(opts) {
+						return fx.noSessions()
+					}
+					created = true
+					assert.Contains(t, opts.Args, "test-session")
+					return &exec.Result{ExitCode: 0}, nil
+				},
+			}
+
+			session, err := fx.new(mockExec).CreateSession(ctx, &CreateSessionOpts{Name: "test-session"})
+
+			require.NoError(t, err)
+			require.NotNil(t, session)
+			assert.True(t, created)
+			assert.Equal(t, "test-session", session.Name)
+			assert.Equal(t, "test-session", session.ID)
+		})
+
+		t.Run("returns ErrSessionExists when session exists", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					if fx.isList(opts) {
+						return &exec.Result{Stdout: []byte(fx.listOutput("existing-session"))}, nil
+					}
+					t.Fatal("session should not be created")
+					return nil, nil
+				},
+			}
+
+			_, err := fx.new(mockExec).CreateSession(ctx, &CreateSessionOpts{Name: "existing-session"})
+
+			require.ErrorIs(t, err, ErrSessionExists)
+		})
+
+		t.Run("returns ErrSessionExists on duplicate session error", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					if fx.isList(opts) {
+						return fx.noSessions()
+					}
+					return exitErr(fx.duplicateStderr)
+				},
+			}
+
+			_, err := fx.new(mockExec).CreateSession(ctx, &CreateSessionOpts{Name: "test-session"})
+
+			require.ErrorIs(t, err, ErrSessionExists)
+		})
+
+		t.Run("returns ErrCreateFailed on command error", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					if fx.isList(opts) {
+						return fx.noSessions()
+					}
+					return exitErr("unexpected error")
+				},
+			}
+
+			_, err := fx.new(mockExec).CreateSession(ctx, &CreateSessionOpts{Name: "test-session"})
+
+			require.ErrorIs(t, err, ErrCreateFailed)
+		})
+
+		t.Run("returns ErrCreateFailed when name is empty", func(t *testing.T) {
+			_, err := fx.new(&mocks.ExecutorMock{}).CreateSession(ctx, &CreateSessionOpts{})
+
+			require.ErrorIs(t, err, ErrCreateFailed)
+		})
+
+		t.Run("returns ErrCreateFailed when opts is nil", func(t *testing.T) {
+			_, err := fx.new(&mocks.ExecutorMock{}).CreateSession(ctx, nil)
+
+			require.ErrorIs(t, err, ErrCreateFailed)
+		})
+	})
+}
+
+func testListSessionsConformance(t *testing.T, fx backendFixture) {
+	ctx := context.Background()
+
+	t.Run("ListSessions", func(t *testing.T) {
+		t.Run("returns empty list when no sessions", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.True(t, fx.isList(opts))
+					return fx.noSessions()
+				},
+			}
+
+			sessions, err := fx.new(mockExec).ListSessions(ctx)
+
+			require.NoError(t, err)
+			assert.Empty(t, sessions)
+		})
+
+		t.Run("returns empty list on empty output", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return &exec.Result{}, nil
+				},
+			}
+
+			sessions, err := fx.new(mockExec).ListSessions(ctx)
+
+			require.NoError(t, err)
+			assert.Empty(t, sessions)
+		})
+
+		t.Run("parses single session", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return &exec.Result{Stdout: []byte(fx.listOutput("my-session"))}, nil
+				},
+			}
+
+			sessions, err := fx.new(mockExec).ListSessions(ctx)
+
+			require.NoError(t, err)
+			require.Len(t, sessions, 1)
+			assert.Equal(t, "my-session", sessions[0].ID)
+			assert.Equal(t, "my-session", sessions[0].Name)
+		})
+
+		t.Run("parses multiple sessions", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return &exec.Result{Stdout: []byte(fx.listOutput("session-1", "session-2", "session-3"))}, nil
+				},
+			}
+
+			sessions, err := fx.new(mockExec).ListSessions(ctx)
+
+			require.NoError(t, err)
+			require.Len(t, sessions, 3)
+			assert.Equal(t, "session-1", sessions[0].Name)
+			assert.Equal(t, "session-2", sessions[1].Name)
+			assert.Equal(t, "session-3", sessions[2].Name)
+		})
+
+		t.Run("returns error with unexpected stderr", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return exitErr("permission denied")
+				},
+			}
+
+			_, err := fx.new(mockExec).ListSessions(ctx)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "list sessions")
+		})
+	})
+}
+
+func testKillSessionConformance(t *testing.T, fx backendFixture) {
+	ctx := context.Background()
+
+	t.Run("KillSession", func(t *testing.T) {
+		t.Run("kills session successfully", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, fx.binary, opts.Name)
+					assert.Contains(t, opts.Args, "my-session")
+					return &exec.Result{}, nil
+				},
+			}
+
+			err := fx.new(mockExec).KillSession(ctx, "my-session")
+
+			require.NoError(t, err)
+		})
+
+		t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return exitErr(fx.notFoundStderr)
+				},
+			}
+
+			err := fx.new(mockExec).KillSession(ctx, "missing")
+
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+
+		t.Run("returns generic error for other failures", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return exitErr("unexpected error")
+				},
+			}
+
+			err := fx.new(mockExec).KillSession(ctx, "my-session")
+
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrSessionNotFound)
+			assert.Contains(t, err.Error(), "kill session")
+		})
+	})
+}
+
+func testAttachSessionConformance(t *testing.T, fx backendFixture) {
+	ctx := context.Background()
+
+	// failWith emulates the CLI writing to the stderr writer that
+	// AttachSession provides (streamed output is not captured in Result).
+	failWith := func(stderr string) func(context.Context, *exec.RunOptions) (*exec.Result, error) {
+		return func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+			if opts.Stderr != nil {
+				_, _ = opts.Stderr.Write([]byte(stderr))
+			}
+			return &exec.Result{ExitCode: 1}, errors.New("exit code 1")
+		}
+	}
+
+	t.Run("AttachSession", func(t *testing.T) {
+		t.Run("attaches to session", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, fx.binary, opts.Name)
+					assert.Contains(t, opts.Args, "my-session")
+					return &exec.Result{}, nil
+				},
+			}
+
+			// Note: This test won't fully exercise TTY handling since we're not in a terminal
+			err := fx.new(mockExec).AttachSession(ctx, "my-session")
+
+			require.NoError(t, err)
+		})
+
+		t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{RunFunc: failWith(fx.notFoundStderr)}
+
+			err := fx.new(mockExec).AttachSession(ctx, "missing")
+
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+
+		t.Run("returns ErrAttachFailed on command error", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{RunFunc: failWith("attach failed")}
+
+			err := fx.new(mockExec).AttachSession(ctx, "my-session")
+
+			assert.ErrorIs(t, err, ErrAttachFailed)
+		})
+	})
+}
+
+func testSendKeysConformance(t *testing.T, fx backendFixture) {
+	ctx := context.Background()
+
+	t.Run("SendKeys", func(t *testing.T) {
+		t.Run("sends text and enter to session", func(t *testing.T) {
+			var sent []string
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, fx.binary, opts.Name)
+					assert.Contains(t, opts.Args, "my-session")
+					sent = append(sent, opts.Args...)
+					return &exec.Result{}, nil
+				},
+			}
+
+			err := fx.new(mockExec).SendKeys(ctx, "my-session", "--help me", true)
+
+			require.NoError(t, err)
+			assert.Contains(t, sent, "--help me")
+		})
+
+		t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return exitErr(fx.notFoundStderr)
+				},
+			}
+
+			err := fx.new(mockExec).SendKeys(ctx, "missing", "hello", true)
+
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+
+		t.Run("returns generic error for other failures", func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					return exitErr("unexpected error")
+				},
+			}
+
+			err := fx.new(mockExec).SendKeys(ctx, "my-session", "hello", true)
+
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrSessionNotFound)
+			assert.Contains(t, err.Error(), "send keys")
+		})
+	})
+}
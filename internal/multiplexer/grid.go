@@ -0,0 +1,116 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// ErrNoPanes is returned by AttachGrid when there is nothing to show.
+var ErrNoPanes = errors.New("grid has no panes")
+
+// GridPane is one pane of a grid session.
+type GridPane struct {
+	Title   string   // Shown in the pane border (e.g., "feat/auth/claude-main")
+	Command []string // Command run in the pane, usually a session's AttachCommand
+}
+
+// commandLiner is implemented by executors that wrap the commands they run,
+// such as an executor that runs commands inside a container. CommandLine
+// returns the full command line that runs name with args interactively.
+type commandLiner interface {
+	CommandLine(name string, args []string) []string
+}
+
+// commandLine returns the command line that runs name with args through e.
+func commandLine(e exec.Executor, name string, args []string) []string {
+	if cl, ok := e.(commandLiner); ok {
+		return cl.CommandLine(name, args)
+	}
+	return append([]string{name}, args...)
+}
+
+// AttachGrid shows several sessions side by side and attaches to them.
+//
+// The grid is a transient tmux session on the host, whatever the configured
+// multiplexer, with one tiled pane per GridPane. Each pane runs a client
+// attached to the underlying session, so detaching from the grid or closing
+// a pane only detaches that client and leaves the sessions running. The grid
+// session is destroyed when AttachGrid returns.
+func AttachGrid(ctx context.Context, e exec.Executor, panes []GridPane) error {
+	if len(panes) == 0 {
+		return ErrNoPanes
+	}
+
+	name := fmt.Sprintf("%s-grid-%d", SessionPrefix, os.Getpid())
+	if err := createGrid(ctx, e, name, panes); err != nil {
+		killGrid(ctx, e, name)
+		return fmt.Errorf("%w: create grid: %v", ErrAttachFailed, err)
+	}
+	defer killGrid(ctx, e, name)
+
+	// tmux attach-session -t <grid-name> \; set-option -t <grid-name> destroy-unattached on
+	// The option is set once the client is attached (it would destroy the
+	// grid immediately before), so detaching destroys the grid even if this
+	// process is gone.
+	attachArgs := []string{"attach-session", "-t", name, ";", "set-option", "-t", name, "destroy-unattached", "on"}
+	if _, err := runAttached(ctx, e, "tmux", attachArgs); err != nil {
+		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
+	}
+	return nil
+}
+
+// createGrid creates the detached grid session and lays out its panes.
+func createGrid(ctx context.Context, e exec.Executor, name string, panes []GridPane) error {
+	newArgs := []string{"new-session", "-d", "-s", name}
+	// Size the detached session like the terminal so panes are not
+	// squeezed into the 80x24 default before the client attaches.
+	if cols, rows, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		newArgs = append(newArgs, "-x", strconv.Itoa(cols), "-y", strconv.Itoa(rows))
+	}
+
+	calls := [][]string{
+		append(newArgs, gridPaneCommand(panes[0])...),
+		{"select-pane", "-t", name, "-T", panes[0].Title},
+		{"set-option", "-t", name, "mouse", "on"},
+		{"set-option", "-t", name, "pane-border-status", "top"},
+		{"set-option", "-t", name, "pane-border-format", " #{pane_title} "},
+	}
+	for _, pane := range panes[1:] {
+		calls = append(calls,
+			append([]string{"split-window", "-t", name}, gridPaneCommand(pane)...),
+			// Re-tile after every split so later splits have room.
+			[]string{"select-layout", "-t", name, "tiled"},
+			[]string{"select-pane", "-t", name, "-T", pane.Title},
+		)
+	}
+
+	for _, args := range calls {
+		if _, err := e.Run(ctx, &exec.RunOptions{Name: "tmux", Args: args}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gridPaneCommand returns the command for a pane. TMUX is removed from the
+// environment so that tmux clients in the pane do not refuse to nest.
+func gridPaneCommand(pane GridPane) []string {
+	return append([]string{"env", "-u", "TMUX"}, pane.Command...)
+}
+
+// killGrid destroys the grid session. The grid normally destroys itself when
+// the client detaches, so errors are ignored.
+func killGrid(ctx context.Context, e exec.Executor, name string) {
+	//nolint:errcheck // best-effort cleanup
+	_, _ = e.Run(ctx, &exec.RunOptions{
+		Name: "tmux",
+		Args: []string{"kill-session", "-t", name},
+	})
+}
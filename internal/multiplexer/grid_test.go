@@ -0,0 +1,122 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+// wrappingExecutor is an executor that runs commands through a wrapper.
+type wrappingExecutor struct {
+	mocks.ExecutorMock
+}
+
+func (w *wrappingExecutor) CommandLine(name string, args []string) []string {
+	return append([]string{"docker", "exec", "-i", "-t", "c1", name}, args...)
+}
+
+func TestAttachGrid(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates tiled panes, attaches, then kills the grid", func(t *testing.T) {
+		var calls [][]string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "tmux", opts.Name)
+				calls = append(calls, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := AttachGrid(ctx, mockExec, []GridPane{
+			{Title: "main/claude", Command: []string{"tmux", "attach-session", "-t", "hjk-a-1"}},
+			{Title: "main/shell", Command: []string{"tmux", "attach-session", "-t", "hjk-a-2"}},
+		})
+
+		require.NoError(t, err)
+		require.NotEmpty(t, calls)
+		grid := calls[0][3]
+		assert.Contains(t, grid, "hjk-grid-")
+
+		assert.Equal(t, []string{"env", "-u", "TMUX", "tmux", "attach-session", "-t", "hjk-a-1"}, calls[0][len(calls[0])-7:])
+		assert.Contains(t, calls, []string{"split-window", "-t", grid, "env", "-u", "TMUX", "tmux", "attach-session", "-t", "hjk-a-2"})
+		assert.Contains(t, calls, []string{"select-layout", "-t", grid, "tiled"})
+		assert.Contains(t, calls, []string{"select-pane", "-t", grid, "-T", "main/shell"})
+		assert.Equal(t, []string{"attach-session", "-t", grid, ";", "set-option", "-t", grid, "destroy-unattached", "on"}, calls[len(calls)-2])
+		assert.Equal(t, []string{"kill-session", "-t", grid}, calls[len(calls)-1])
+	})
+
+	t.Run("returns ErrNoPanes without panes", func(t *testing.T) {
+		err := AttachGrid(ctx, &mocks.ExecutorMock{}, nil)
+
+		assert.ErrorIs(t, err, ErrNoPanes)
+	})
+
+	t.Run("kills the grid when setup fails", func(t *testing.T) {
+		var calls [][]string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				calls = append(calls, opts.Args)
+				if opts.Args[0] == "split-window" {
+					return &exec.Result{ExitCode: 1}, errors.New("exit code 1")
+				}
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := AttachGrid(ctx, mockExec, []GridPane{
+			{Title: "a", Command: []string{"true"}},
+			{Title: "b", Command: []string{"true"}},
+		})
+
+		require.ErrorIs(t, err, ErrAttachFailed)
+		assert.Equal(t, "kill-session", calls[len(calls)-1][0])
+		for _, call := range calls {
+			assert.NotEqual(t, "attach-session", call[0])
+		}
+	})
+}
+
+func TestAttachCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		mux  func(exec.Executor) Multiplexer
+		want []string
+	}{
+		{
+			name: "tmux",
+			mux:  NewTmux,
+			want: []string{"tmux", "attach-session", "-t", "my-session"},
+		},
+		{
+			name: "zellij",
+			mux:  NewZellij,
+			want: []string{"zellij", "attach", "my-session"},
+		},
+		{
+			name: "native",
+			mux: func(e exec.Executor) Multiplexer {
+				return NewNative(e, NativeConfig{Binary: "/usr/local/bin/hjk", DetachKeys: "ctrl-a,d"})
+			},
+			want: []string{"/usr/local/bin/hjk", "mux", "attach", "--detach-keys", "ctrl-a,d", "my-session"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.mux(&mocks.ExecutorMock{}).AttachCommand("my-session"))
+		})
+	}
+
+	t.Run("wraps command for wrapping executors", func(t *testing.T) {
+		got := NewTmux(&wrappingExecutor{}).AttachCommand("my-session")
+
+		assert.Equal(t, []string{"docker", "exec", "-i", "-t", "c1", "tmux", "attach-session", "-t", "my-session"}, got)
+	})
+}
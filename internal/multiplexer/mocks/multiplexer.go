@@ -20,9 +20,15 @@ var _ multiplexer.Multiplexer = &MultiplexerMock{}
 //
 //		// make and configure a mocked multiplexer.Multiplexer
 //		mockedMultiplexer := &MultiplexerMock{
+//			AttachCommandFunc: func(sessionName string) []string {
+//				panic("mock out the AttachCommand method")
+//			},
 //			AttachSessionFunc: func(ctx context.Context, sessionName string) error {
 //				panic("mock out the AttachSession method")
 //			},
+//			CapturePaneFunc: func(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+//				panic("mock out the CapturePane method")
+//			},
 //			CreateSessionFunc: func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
 //				panic("mock out the CreateSession method")
 //			},
@@ -32,6 +38,9 @@ var _ multiplexer.Multiplexer = &MultiplexerMock{}
 //			ListSessionsFunc: func(ctx context.Context) ([]multiplexer.Session, error) {
 //				panic("mock out the ListSessions method")
 //			},
+//			SendKeysFunc: func(ctx context.Context, sessionName string, text string, enter bool) error {
+//				panic("mock out the SendKeys method")
+//			},
 //		}
 //
 //		// use mockedMultiplexer in code that requires multiplexer.Multiplexer
@@ -39,9 +48,15 @@ var _ multiplexer.Multiplexer = &MultiplexerMock{}
 //
 //	}
 type MultiplexerMock struct {
+	// AttachCommandFunc mocks the AttachCommand method.
+	AttachCommandFunc func(sessionName string) []string
+
 	// AttachSessionFunc mocks the AttachSession method.
 	AttachSessionFunc func(ctx context.Context, sessionName string) error
 
+	// CapturePaneFunc mocks the CapturePane method.
+	CapturePaneFunc func(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error)
+
 	// CreateSessionFunc mocks the CreateSession method.
 	CreateSessionFunc func(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error)
 
@@ -51,8 +66,16 @@ type MultiplexerMock struct {
 	// ListSessionsFunc mocks the ListSessions method.
 	ListSessionsFunc func(ctx context.Context) ([]multiplexer.Session, error)
 
+	// SendKeysFunc mocks the SendKeys method.
+	SendKeysFunc func(ctx context.Context, sessionName string, text string, enter bool) error
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// AttachCommand holds details about calls to the AttachCommand method.
+		AttachCommand []struct {
+			// SessionName is the sessionName argument value.
+			SessionName string
+		}
 		// AttachSession holds details about calls to the AttachSession method.
 		AttachSession []struct {
 			// Ctx is the ctx argument value.
@@ -60,6 +83,15 @@ type MultiplexerMock struct {
 			// SessionName is the sessionName argument value.
 			SessionName string
 		}
+		// CapturePane holds details about calls to the CapturePane method.
+		CapturePane []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SessionName is the sessionName argument value.
+			SessionName string
+			// Opts is the opts argument value.
+			Opts *multiplexer.CaptureOpts
+		}
 		// CreateSession holds details about calls to the CreateSession method.
 		CreateSession []struct {
 			// Ctx is the ctx argument value.
@@ -79,11 +111,57 @@ type MultiplexerMock struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
+		// SendKeys holds details about calls to the SendKeys method.
+		SendKeys []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SessionName is the sessionName argument value.
+			SessionName string
+			// Text is the text argument value.
+			Text string
+			// Enter is the enter argument value.
+			Enter bool
+		}
 	}
+	lockAttachCommand sync.RWMutex
 	lockAttachSession sync.RWMutex
+	lockCapturePane   sync.RWMutex
 	lockCreateSession sync.RWMutex
 	lockKillSession   sync.RWMutex
 	lockListSessions  sync.RWMutex
+	lockSendKeys      sync.RWMutex
+}
+
+// AttachCommand calls AttachCommandFunc.
+func (mock *MultiplexerMock) AttachCommand(sessionName string) []string {
+	if mock.AttachCommandFunc == nil {
+		panic("MultiplexerMock.AttachCommandFunc: method is nil but Multiplexer.AttachCommand was just called")
+	}
+	callInfo := struct {
+		SessionName string
+	}{
+		SessionName: sessionName,
+	}
+	mock.lockAttachCommand.Lock()
+	mock.calls.AttachCommand = append(mock.calls.AttachCommand, callInfo)
+	mock.lockAttachCommand.Unlock()
+	return mock.AttachCommandFunc(sessionName)
+}
+
+// AttachCommandCalls gets all the calls that were made to AttachCommand.
+// Check the length with:
+//
+//	len(mockedMultiplexer.AttachCommandCalls())
+func (mock *MultiplexerMock) AttachCommandCalls() []struct {
+	SessionName string
+} {
+	var calls []struct {
+		SessionName string
+	}
+	mock.lockAttachCommand.RLock()
+	calls = mock.calls.AttachCommand
+	mock.lockAttachCommand.RUnlock()
+	return calls
 }
 
 // AttachSession calls AttachSessionFunc.
@@ -122,6 +200,46 @@ func (mock *MultiplexerMock) AttachSessionCalls() []struct {
 	return calls
 }
 
+// CapturePane calls CapturePaneFunc.
+func (mock *MultiplexerMock) CapturePane(ctx context.Context, sessionName string, opts *multiplexer.CaptureOpts) (string, error) {
+	if mock.CapturePaneFunc == nil {
+		panic("MultiplexerMock.CapturePaneFunc: method is nil but Multiplexer.CapturePane was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		SessionName string
+		Opts        *multiplexer.CaptureOpts
+	}{
+		Ctx:         ctx,
+		SessionName: sessionName,
+		Opts:        opts,
+	}
+	mock.lockCapturePane.Lock()
+	mock.calls.CapturePane = append(mock.calls.CapturePane, callInfo)
+	mock.lockCapturePane.Unlock()
+	return mock.CapturePaneFunc(ctx, sessionName, opts)
+}
+
+// CapturePaneCalls gets all the calls that were made to CapturePane.
+// Check the length with:
+//
+//	len(mockedMultiplexer.CapturePaneCalls())
+func (mock *MultiplexerMock) CapturePaneCalls() []struct {
+	Ctx         context.Context
+	SessionName string
+	Opts        *multiplexer.CaptureOpts
+} {
+	var calls []struct {
+		Ctx         context.Context
+		SessionName string
+		Opts        *multiplexer.CaptureOpts
+	}
+	mock.lockCapturePane.RLock()
+	calls = mock.calls.CapturePane
+	mock.lockCapturePane.RUnlock()
+	return calls
+}
+
 // CreateSession calls CreateSessionFunc.
 func (mock *MultiplexerMock) CreateSession(ctx context.Context, opts *multiplexer.CreateSessionOpts) (*multiplexer.Session, error) {
 	if mock.CreateSessionFunc == nil {
@@ -225,3 +343,47 @@ func (mock *MultiplexerMock) ListSessionsCalls() []struct {
 	mock.lockListSessions.RUnlock()
 	return calls
 }
+
+// SendKeys calls SendKeysFunc.
+func (mock *MultiplexerMock) SendKeys(ctx context.Context, sessionName string, text string, enter bool) error {
+	if mock.SendKeysFunc == nil {
+		panic("MultiplexerMock.SendKeysFunc: method is nil but Multiplexer.SendKeys was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		SessionName string
+		Text        string
+		Enter       bool
+	}{
+		Ctx:         ctx,
+		SessionName: sessionName,
+		Text:        text,
+		Enter:       enter,
+	}
+	mock.lockSendKeys.Lock()
+	mock.calls.SendKeys = append(mock.calls.SendKeys, callInfo)
+	mock.lockSendKeys.Unlock()
+	return mock.SendKeysFunc(ctx, sessionName, text, enter)
+}
+
+// SendKeysCalls gets all the calls that were made to SendKeys.
+// Check the length with:
+//
+//	len(mockedMultiplexer.SendKeysCalls())
+func (mock *MultiplexerMock) SendKeysCalls() []struct {
+	Ctx         context.Context
+	SessionName string
+	Text        string
+	Enter       bool
+} {
+	var calls []struct {
+		Ctx         context.Context
+		SessionName string
+		Text        string
+		Enter       bool
+	}
+	mock.lockSendKeys.RLock()
+	calls = mock.calls.SendKeys
+	mock.lockSendKeys.RUnlock()
+	return calls
+}
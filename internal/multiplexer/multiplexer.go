@@ -22,6 +22,7 @@ var (
 	ErrCreateFailed             = errors.New("failed to create session")
 	ErrInvalidInstanceID        = errors.New("instance ID cannot contain hyphens")
 	ErrDetachedModeNotSupported = errors.New("detached session creation not supported by this backend")
+	ErrCaptureNotSupported      = errors.New("screen capture not supported by this backend")
 )
 
 // Session represents a multiplexer session.
@@ -40,6 +41,23 @@ type CreateSessionOpts struct {
 	Cwd     string   // Working directory (optional)
 	Env     []string // Environment variables (KEY=VALUE format)
 	LogPath string   // Path to log file for capturing session output (optional)
+
+	// LogSink is a command that receives session output on standard input
+	// and records it, with LogPath appended as its last argument. The tmux
+	// and zellij backends use it; when empty, output is appended to LogPath
+	// as is.
+	LogSink []string
+}
+
+// CaptureOpts configures pane capture.
+type CaptureOpts struct {
+	// Scrollback is the number of history lines above the visible screen to
+	// include. Zero captures only the visible screen; negative captures the
+	// entire history.
+	Scrollback int
+	// Escapes keeps text and color attributes as ANSI escape sequences.
+	// When false, plain text is returned.
+	Escapes bool
 }
 
 // Multiplexer provides terminal multiplexer operations.
@@ -58,12 +76,29 @@ type Multiplexer interface {
 	// Returns ErrAttachFailed if attachment fails.
 	AttachSession(ctx context.Context, sessionName string) error
 
+	// AttachCommand returns the command line that attaches to a session, for
+	// running in a terminal other than the caller's (e.g., a grid pane).
+	AttachCommand(sessionName string) []string
+
 	// ListSessions returns all active sessions.
 	ListSessions(ctx context.Context) ([]Session, error)
 
 	// KillSession terminates a session.
 	// Returns ErrSessionNotFound if session doesn't exist.
 	KillSession(ctx context.Context, sessionName string) error
+
+	// SendKeys types text into a session as if entered at its terminal.
+	// The text is sent literally; key names such as "Enter" are not
+	// interpreted. If enter is true, a carriage return is sent afterwards.
+	// Returns ErrSessionNotFound if session doesn't exist.
+	SendKeys(ctx context.Context, sessionName, text string, enter bool) error
+
+	// CapturePane returns the rendered contents of a session's screen as
+	// lines of text, without attaching. A nil opts captures the visible
+	// screen as plain text.
+	// Returns ErrSessionNotFound if session doesn't exist.
+	// Returns ErrCaptureNotSupported if the backend cannot render the screen.
+	CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error)
 }
 
 // FormatSessionName creates a namespaced session name using the format:
@@ -107,3 +142,19 @@ func ParseSessionName(name string) (instanceID, sessionID string) {
 
 	return "", ""
 }
+
+// trimCapture removes trailing spaces from each line of a captured screen
+// and drops the blank lines that pad the screen below the last output.
+func trimCapture(screen string) string {
+	lines := strings.Split(screen, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -134,3 +134,21 @@ func TestFormatAndParseRoundTrip(t *testing.T) {
 func TestSessionPrefix(t *testing.T) {
 	assert.Equal(t, "hjk", SessionPrefix)
 }
+
+func TestTrimCapture(t *testing.T) {
+	tests := []struct {
+		name   string
+		screen string
+		want   string
+	}{
+		{name: "empty screen", screen: "\n\n\n", want: ""},
+		{name: "trailing spaces and padding", screen: "a  \nb\n   \n\n", want: "a\nb\n"},
+		{name: "keeps blank lines between output", screen: "a\n\nb", want: "a\n\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, trimCapture(tt.screen))
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package multiplexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// NativeConfig configures the headjack-native multiplexer.
+type NativeConfig struct {
+	// Binary is the headjack executable that serves sessions (required).
+	Binary string
+	// DetachKeys is the key sequence for detaching, in the Docker CLI format
+	// (e.g., "ctrl-p,ctrl-q"). Empty uses the session server default.
+	DetachKeys string
+	// LogRotation configures rotation of session logs. The zero value never
+	// rotates.
+	LogRotation logging.RotateConfig
+	// LogTranscript also writes a plain-text transcript of each session.
+	LogTranscript bool
+}
+
+// native implements Multiplexer using headjack's own session server, so no
+// terminal multiplexer needs to be installed on the host. Each session is a
+// background headjack process that owns a pseudo-terminal and records its
+// output; see the ptyserver package.
+type native struct {
+	exec exec.Executor
+	cfg  NativeConfig
+}
+
+// NewNative creates a Multiplexer using the headjack-native session server,
+// driven through the hidden "mux" commands of the headjack CLI.
+func NewNative(e exec.Executor, cfg NativeConfig) Multiplexer {
+	return &native{exec: e, cfg: cfg}
+}
+
+func (n *native) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Session, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, fmt.Errorf("%w: session name is required", ErrCreateFailed)
+	}
+
+	// Check if session already exists
+	sessions, err := n.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check existing sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Name == opts.Name {
+			return nil, ErrSessionExists
+		}
+	}
+
+	// hjk mux new [--cwd dir] [--env KEY=VALUE]... [--log path [rotation flags]] <name> -- [command...]
+	args := []string{"mux", "new"}
+	if opts.Cwd != "" {
+		args = append(args, "--cwd", opts.Cwd)
+	}
+	for _, env := range opts.Env {
+		args = append(args, "--env", env)
+	}
+	if opts.LogPath != "" {
+		args = append(args, "--log", opts.LogPath)
+		rotation := n.cfg.LogRotation
+		if rotation.MaxSize > 0 {
+			args = append(args, "--log-max-size", strconv.FormatInt(rotation.MaxSize, 10))
+		}
+		if rotation.MaxTotal > 0 {
+			args = append(args, "--log-max-total", strconv.FormatInt(rotation.MaxTotal, 10))
+		}
+		if rotation.Compress {
+			args = append(args, "--log-compress")
+		}
+		if n.cfg.LogTranscript {
+			args = append(args, "--log-transcript")
+		}
+	}
+	args = append(args, opts.Name, "--")
+	args = append(args, opts.Command...)
+
+	result, err := n.exec.Run(ctx, &exec.RunOptions{
+		Name: n.cfg.Binary,
+		Args: args,
+	})
+	if err != nil {
+		if strings.Contains(string(result.Stderr), "session already exists") {
+			return nil, ErrSessionExists
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+
+	return &Session{
+		ID:   opts.Name,
+		Name: opts.Name,
+	}, nil
+}
+
+func (n *native) AttachSession(ctx context.Context, sessionName string) error {
+	stderr, err := runAttached(ctx, n.exec, n.cfg.Binary, n.attachArgs(sessionName))
+	if err != nil {
+		if strings.Contains(stderr, "session not found") {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
+	}
+
+	return nil
+}
+
+func (n *native) AttachCommand(sessionName string) []string {
+	return commandLine(n.exec, n.cfg.Binary, n.attachArgs(sessionName))
+}
+
+// attachArgs returns the arguments that attach to a session.
+func (n *native) attachArgs(sessionName string) []string {
+	// hjk mux attach [--detach-keys keys] <session-name>
+	args := []string{"mux", "attach"}
+	if n.cfg.DetachKeys != "" {
+		args = append(args, "--detach-keys", n.cfg.DetachKeys)
+	}
+	return append(args, sessionName)
+}
+
+func (n *native) ListSessions(ctx context.Context) ([]Session, error) {
+	// hjk mux ls
+	result, err := n.exec.Run(ctx, &exec.RunOptions{
+		Name: n.cfg.Binary,
+		Args: []string{"mux", "ls"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	// Parse output - each line is a session name
+	output := strings.TrimSpace(string(result.Stdout))
+	if output == "" {
+		return []Session{}, nil
+	}
+
+	lines := strings.Split(output, "\n")
+	sessions := make([]Session, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		sessions = append(sessions, Session{
+			ID:   line,
+			Name: line,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (n *native) KillSession(ctx context.Context, sessionName string) error {
+	// hjk mux kill <session-name>
+	result, err := n.exec.Run(ctx, &exec.RunOptions{
+		Name: n.cfg.Binary,
+		Args: []string{"mux", "kill", sessionName},
+	})
+	if err != nil {
+		if strings.Contains(string(result.Stderr), "session not found") {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("kill session: %w", err)
+	}
+
+	return nil
+}
+
+func (n *native) SendKeys(ctx context.Context, sessionName, text string, enter bool) error {
+	// hjk mux send [--enter] <session-name> -- <text>
+	args := []string{"mux", "send"}
+	if enter {
+		args = append(args, "--enter")
+	}
+	args = append(args, sessionName, "--", text)
+
+	result, err := n.exec.Run(ctx, &exec.RunOptions{
+		Name: n.cfg.Binary,
+		Args: args,
+	})
+	if err != nil {
+		if strings.Contains(string(result.Stderr), "session not found") {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("send keys: %w", err)
+	}
+
+	return nil
+}
+
+// CapturePane is not supported: the session server relays the raw terminal
+// stream and keeps no screen state to render.
+func (n *native) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	return "", ErrCaptureNotSupported
+}
@@ -0,0 +1,118 @@
+package multiplexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+func TestNewNative(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+	n := NewNative(mockExec, NativeConfig{Binary: "/usr/local/bin/hjk"})
+
+	require.NotNil(t, n)
+}
+
+func TestNative_CreateSession(t *testing.T) {
+	t.Run("passes all options to mux new", func(t *testing.T) {
+		var createArgs []string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "/usr/local/bin/hjk", opts.Name)
+				if opts.Args[1] == "ls" {
+					return &exec.Result{}, nil
+				}
+				createArgs = opts.Args
+				return &exec.Result{}, nil
+			},
+		}
+
+		_, err := NewNative(mockExec, NativeConfig{Binary: "/usr/local/bin/hjk"}).CreateSession(context.Background(), &CreateSessionOpts{
+			Name:    "hjk-abc-main",
+			Command: []string{"docker", "exec", "-it", "c1", "--flag"},
+			Cwd:     "/workspace",
+			Env:     []string{"FOO=bar"},
+			LogPath: "/logs/main.log",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"mux", "new",
+			"--cwd", "/workspace",
+			"--env", "FOO=bar",
+			"--log", "/logs/main.log",
+			"hjk-abc-main", "--",
+			"docker", "exec", "-it", "c1", "--flag",
+		}, createArgs)
+	})
+
+	t.Run("passes log rotation", func(t *testing.T) {
+		var createArgs []string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[1] == "new" {
+					createArgs = opts.Args
+				}
+				return &exec.Result{}, nil
+			},
+		}
+
+		_, err := NewNative(mockExec, NativeConfig{
+			Binary:        "hjk",
+			LogRotation:   logging.RotateConfig{MaxSize: 1024, Compress: true},
+			LogTranscript: true,
+		}).CreateSession(context.Background(), &CreateSessionOpts{
+			Name:    "hjk-abc-main",
+			LogPath: "/logs/main.log",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"mux", "new",
+			"--log", "/logs/main.log", "--log-max-size", "1024", "--log-compress", "--log-transcript",
+			"hjk-abc-main", "--",
+		}, createArgs)
+	})
+}
+
+func TestNative_AttachSession(t *testing.T) {
+	t.Run("passes detach keys", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"mux", "attach", "--detach-keys", "ctrl-a,d", "my-session"}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewNative(mockExec, NativeConfig{Binary: "hjk", DetachKeys: "ctrl-a,d"}).AttachSession(context.Background(), "my-session")
+
+		require.NoError(t, err)
+	})
+}
+
+func TestNative_SendKeys(t *testing.T) {
+	t.Run("passes text after separator", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"mux", "send", "--enter", "my-session", "--", "-v"}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewNative(mockExec, NativeConfig{Binary: "hjk"}).SendKeys(context.Background(), "my-session", "-v", true)
+
+		require.NoError(t, err)
+	})
+}
+
+func TestNative_CapturePane(t *testing.T) {
+	_, err := NewNative(&mocks.ExecutorMock{}, NativeConfig{Binary: "hjk"}).CapturePane(context.Background(), "my-session", nil)
+
+	assert.ErrorIs(t, err, ErrCaptureNotSupported)
+}
@@ -1,17 +1,10 @@
 package multiplexer
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"os"
-	"os/signal"
+	"strconv"
 	"strings"
-	"syscall"
-	"time"
-
-	"golang.org/x/term"
 
 	"github.com/jmgilman/headjack/internal/exec"
 )
@@ -78,8 +71,15 @@ func (t *tmux) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Ses
 	// Set up log capture via pipe-pane if LogPath is specified
 	if opts.LogPath != "" {
 		// Shell-escape the path to handle spaces and special characters safely
-		escapedPath := shellEscape(opts.LogPath)
-		pipeArgs := []string{"pipe-pane", "-t", opts.Name, "cat >> " + escapedPath}
+		pipeCmd := "cat >> " + shellEscape(opts.LogPath)
+		if len(opts.LogSink) > 0 {
+			escaped := make([]string, 0, len(opts.LogSink)+1)
+			for _, arg := range opts.LogSink {
+				escaped = append(escaped, shellEscape(arg))
+			}
+			pipeCmd = strings.Join(append(escaped, shellEscape(opts.LogPath)), " ")
+		}
+		pipeArgs := []string{"pipe-pane", "-t", opts.Name, pipeCmd}
 		// Log capture failure is non-fatal, session was still created
 		//nolint:errcheck // best-effort log capture
 		_, _ = t.exec.Run(ctx, &exec.RunOptions{
@@ -96,63 +96,8 @@ func (t *tmux) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Ses
 
 func (t *tmux) AttachSession(ctx context.Context, sessionName string) error {
 	// tmux attach-session -t <session-name>
-	args := []string{"attach-session", "-t", sessionName}
-
-	stdinFd := int(os.Stdin.Fd())
-
-	// Capture stderr while also streaming to os.Stderr for user visibility
-	var stderrBuf bytes.Buffer
-	stderrWriter := io.MultiWriter(os.Stderr, &stderrBuf)
-
-	// Check if stdin is a terminal
-	if !term.IsTerminal(stdinFd) {
-		// Fall back to non-interactive mode
-		_, err := t.exec.Run(ctx, &exec.RunOptions{
-			Name:   "tmux",
-			Args:   args,
-			Stdin:  os.Stdin,
-			Stdout: os.Stdout,
-			Stderr: stderrWriter,
-		})
-		if err != nil {
-			stderr := stderrBuf.String()
-			if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") {
-				return ErrSessionNotFound
-			}
-			return fmt.Errorf("%w: %v", ErrAttachFailed, err)
-		}
-		return nil
-	}
-
-	// Put terminal in raw mode for proper TTY handling
-	oldState, err := term.MakeRaw(stdinFd)
-	if err != nil {
-		return fmt.Errorf("set terminal raw mode: %w", err)
-	}
-	defer func() {
-		// Drain stdin with timeout BEFORE restoring terminal mode.
-		// This catches in-flight terminal responses (escape sequences) that
-		// arrive asynchronously after tmux exits for short-lived sessions.
-		// We do this while still in raw mode so responses are consumed properly.
-		drainStdinWithTimeout(stdinFd, 100*time.Millisecond)
-		_ = term.Restore(stdinFd, oldState)
-	}()
-
-	// Handle window resize signals
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGWINCH)
-	defer signal.Stop(sigCh)
-
-	// Run tmux with stdio attached
-	_, err = t.exec.Run(ctx, &exec.RunOptions{
-		Name:   "tmux",
-		Args:   args,
-		Stdin:  os.Stdin,
-		Stdout: os.Stdout,
-		Stderr: stderrWriter,
-	})
+	stderr, err := runAttached(ctx, t.exec, "tmux", []string{"attach-session", "-t", sessionName})
 	if err != nil {
-		stderr := stderrBuf.String()
 		if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") {
 			return ErrSessionNotFound
 		}
@@ -162,6 +107,10 @@ func (t *tmux) AttachSession(ctx context.Context, sessionName string) error {
 	return nil
 }
 
+func (t *tmux) AttachCommand(sessionName string) []string {
+	return commandLine(t.exec, "tmux", []string{"attach-session", "-t", sessionName})
+}
+
 func (t *tmux) ListSessions(ctx context.Context) ([]Session, error) {
 	// tmux list-sessions -F "#{session_name}"
 	result, err := t.exec.Run(ctx, &exec.RunOptions{
@@ -221,37 +170,67 @@ func (t *tmux) KillSession(ctx context.Context, sessionName string) error {
 	return nil
 }
 
-// shellEscape escapes a string for safe use in a shell command.
-// It wraps the string in single quotes and escapes any embedded single quotes.
-func shellEscape(s string) string {
-	// Single quotes prevent all shell interpretation except for single quotes themselves.
-	// To include a single quote, we end the quoted string, add an escaped single quote,
-	// and start a new quoted string: 'foo'\''bar' -> foo'bar
-	escaped := strings.ReplaceAll(s, "'", `'\''`)
-	return "'" + escaped + "'"
+func (t *tmux) SendKeys(ctx context.Context, sessionName, text string, enter bool) error {
+	// tmux send-keys -t <session-name> -l -- <text>
+	// -l sends the text literally instead of looking up key names, and "--"
+	// keeps text starting with a dash from being parsed as a flag.
+	var calls [][]string
+	if text != "" {
+		calls = append(calls, []string{"send-keys", "-t", sessionName, "-l", "--", text})
+	}
+	if enter {
+		calls = append(calls, []string{"send-keys", "-t", sessionName, "Enter"})
+	}
+
+	for _, args := range calls {
+		result, err := t.exec.Run(ctx, &exec.RunOptions{
+			Name: "tmux",
+			Args: args,
+		})
+		if err != nil {
+			stderr := string(result.Stderr)
+			if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") ||
+				strings.Contains(stderr, "no server running") {
+				return ErrSessionNotFound
+			}
+			return fmt.Errorf("send keys: %w", err)
+		}
+	}
+
+	return nil
 }
 
-// drainStdinWithTimeout reads and discards input from stdin for the specified duration.
-// This is used to consume stray terminal escape sequence responses that arrive
-// asynchronously after tmux exits. The timeout allows in-flight responses to arrive.
-func drainStdinWithTimeout(fd int, timeout time.Duration) {
-	// Set stdin to non-blocking temporarily
-	if err := syscall.SetNonblock(fd, true); err != nil {
-		return
+func (t *tmux) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
 	}
-	//nolint:errcheck // best-effort cleanup
-	defer func() { _ = syscall.SetNonblock(fd, false) }()
 
-	deadline := time.Now().Add(timeout)
-	buf := make([]byte, 1024)
+	// tmux capture-pane -p -J -t <session-name> [-e] [-S <start>]
+	// -p prints to stdout and -J joins lines that were wrapped to fit the
+	// pane, so long lines are not split at the pane width.
+	args := []string{"capture-pane", "-p", "-J", "-t", sessionName}
+	if opts.Escapes {
+		args = append(args, "-e")
+	}
+	switch {
+	case opts.Scrollback < 0:
+		args = append(args, "-S", "-")
+	case opts.Scrollback > 0:
+		args = append(args, "-S", strconv.Itoa(-opts.Scrollback))
+	}
 
-	for time.Now().Before(deadline) {
-		//nolint:errcheck // best-effort drain, errors expected when no data
-		n, _ := syscall.Read(fd, buf)
-		if n <= 0 {
-			// No data available, wait briefly and try again
-			time.Sleep(10 * time.Millisecond)
+	result, err := t.exec.Run(ctx, &exec.RunOptions{
+		Name: "tmux",
+		Args: args,
+	})
+	if err != nil {
+		stderr := string(result.Stderr)
+		if strings.Contains(stderr, "no session") || strings.Contains(stderr, "can't find session") ||
+			strings.Contains(stderr, "no server running") {
+			return "", ErrSessionNotFound
 		}
-		// If we read data, continue immediately to drain more
+		return "", fmt.Errorf("capture pane: %w", err)
 	}
+
+	return trimCapture(string(result.Stdout)), nil
 }
@@ -128,14 +128,12 @@ func TestTmux_CreateSession(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("returns ErrSessionExists when session exists", func(t *testing.T) {
+	t.Run("pipes output to log sink", func(t *testing.T) {
+		var pipeArgs []string
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				if opts.Args[0] == tmuxCmdListSessions {
-					return &exec.Result{
-						Stdout:   []byte("existing-session\n"),
-						ExitCode: 0,
-					}, nil
+				if opts.Args[0] == "pipe-pane" {
+					pipeArgs = opts.Args
 				}
 				return &exec.Result{ExitCode: 0}, nil
 			},
@@ -143,52 +141,16 @@ func TestTmux_CreateSession(t *testing.T) {
 
 		tm := NewTmux(mockExec)
 		_, err := tm.CreateSession(ctx, &CreateSessionOpts{
-			Name: "existing-session",
-		})
-
-		require.ErrorIs(t, err, ErrSessionExists)
-	})
-
-	t.Run("returns ErrCreateFailed when name is empty", func(t *testing.T) {
-		mockExec := &mocks.ExecutorMock{}
-
-		tm := NewTmux(mockExec)
-		_, err := tm.CreateSession(ctx, &CreateSessionOpts{})
-
-		require.ErrorIs(t, err, ErrCreateFailed)
-	})
-
-	t.Run("returns ErrCreateFailed when opts is nil", func(t *testing.T) {
-		mockExec := &mocks.ExecutorMock{}
-
-		tm := NewTmux(mockExec)
-		_, err := tm.CreateSession(ctx, nil)
-
-		require.ErrorIs(t, err, ErrCreateFailed)
-	})
-
-	t.Run("returns ErrSessionExists on duplicate session error", func(t *testing.T) {
-		mockExec := &mocks.ExecutorMock{
-			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				if opts.Args[0] == tmuxCmdListSessions {
-					return &exec.Result{
-						Stderr:   []byte("no server running"),
-						ExitCode: 1,
-					}, errors.New("exit code 1")
-				}
-				return &exec.Result{
-					Stderr:   []byte("duplicate session: test-session"),
-					ExitCode: 1,
-				}, errors.New("exit code 1")
-			},
-		}
-
-		tm := NewTmux(mockExec)
-		_, err := tm.CreateSession(ctx, &CreateSessionOpts{
-			Name: "test-session",
+			Name:    "test-session",
+			LogPath: "/var/log/my session.log",
+			LogSink: []string{"/usr/bin/hjk", "log-sink", "--compress"},
 		})
 
-		require.ErrorIs(t, err, ErrSessionExists)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"pipe-pane", "-t", "test-session",
+			"'/usr/bin/hjk' 'log-sink' '--compress' '/var/log/my session.log'",
+		}, pipeArgs)
 	})
 }
 
@@ -215,45 +177,6 @@ func TestTmux_ListSessions(t *testing.T) {
 		assert.Empty(t, sessions)
 	})
 
-	t.Run("parses single session", func(t *testing.T) {
-		mockExec := &mocks.ExecutorMock{
-			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				return &exec.Result{
-					Stdout:   []byte("my-session\n"),
-					ExitCode: 0,
-				}, nil
-			},
-		}
-
-		tm := NewTmux(mockExec)
-		sessions, err := tm.ListSessions(ctx)
-
-		require.NoError(t, err)
-		require.Len(t, sessions, 1)
-		assert.Equal(t, "my-session", sessions[0].ID)
-		assert.Equal(t, "my-session", sessions[0].Name)
-	})
-
-	t.Run("parses multiple sessions", func(t *testing.T) {
-		mockExec := &mocks.ExecutorMock{
-			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				return &exec.Result{
-					Stdout:   []byte("session-1\nsession-2\nsession-3\n"),
-					ExitCode: 0,
-				}, nil
-			},
-		}
-
-		tm := NewTmux(mockExec)
-		sessions, err := tm.ListSessions(ctx)
-
-		require.NoError(t, err)
-		require.Len(t, sessions, 3)
-		assert.Equal(t, "session-1", sessions[0].Name)
-		assert.Equal(t, "session-2", sessions[1].Name)
-		assert.Equal(t, "session-3", sessions[2].Name)
-	})
-
 	t.Run("handles no sessions message gracefully", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
@@ -290,26 +213,6 @@ func TestTmux_ListSessions(t *testing.T) {
 		assert.Empty(t, sessions)
 	})
 
-	t.Run("returns error on exit code 1 with unexpected stderr", func(t *testing.T) {
-		// Exit code 1 should only be treated as "no sessions" if stderr contains
-		// known messages like "no server running", "no sessions", or "error connecting to".
-		// Other exit code 1 errors should be surfaced.
-		mockExec := &mocks.ExecutorMock{
-			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				return &exec.Result{
-					Stderr:   []byte("permission denied: /tmp/tmux-1000/default"),
-					ExitCode: 1,
-				}, errors.New("exit code 1")
-			},
-		}
-
-		tm := NewTmux(mockExec)
-		_, err := tm.ListSessions(ctx)
-
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "list sessions")
-	})
-
 	t.Run("returns error on unexpected command failure", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
@@ -349,131 +252,169 @@ func TestTmux_KillSession(t *testing.T) {
 		require.NoError(t, err)
 	})
 
-	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+	t.Run("returns ErrSessionNotFound for no session error", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
 				return &exec.Result{
-					Stderr:   []byte("can't find session: missing"),
+					Stderr:   []byte("no session: nonexistent"),
 					ExitCode: 1,
 				}, errors.New("exit code 1")
 			},
 		}
 
 		tm := NewTmux(mockExec)
-		err := tm.KillSession(ctx, "missing")
+		err := tm.KillSession(ctx, "nonexistent")
 
 		assert.ErrorIs(t, err, ErrSessionNotFound)
 	})
+}
 
-	t.Run("returns ErrSessionNotFound for no session error", func(t *testing.T) {
+func TestTmux_AttachSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("attaches to session with correct args", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, "tmux", opts.Name)
+				assert.Equal(t, []string{"attach-session", "-t", "my-session"}, opts.Args)
+
 				return &exec.Result{
-					Stderr:   []byte("no session: nonexistent"),
-					ExitCode: 1,
-				}, errors.New("exit code 1")
+					ExitCode: 0,
+				}, nil
 			},
 		}
 
 		tm := NewTmux(mockExec)
-		err := tm.KillSession(ctx, "nonexistent")
+		// Note: This test won't fully exercise TTY handling since we're not in a terminal
+		err := tm.AttachSession(ctx, "my-session")
 
-		assert.ErrorIs(t, err, ErrSessionNotFound)
+		require.NoError(t, err)
 	})
 
-	t.Run("returns generic error for other failures", func(t *testing.T) {
+	t.Run("returns ErrSessionNotFound for no session error", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Stderr != nil {
+					_, _ = opts.Stderr.Write([]byte("no session: nonexistent"))
+				}
 				return &exec.Result{
-					Stderr:   []byte("unexpected error"),
 					ExitCode: 1,
 				}, errors.New("exit code 1")
 			},
 		}
 
 		tm := NewTmux(mockExec)
-		err := tm.KillSession(ctx, "my-session")
+		err := tm.AttachSession(ctx, "nonexistent")
 
-		require.Error(t, err)
-		require.NotErrorIs(t, err, ErrSessionNotFound)
-		assert.Contains(t, err.Error(), "kill session")
+		assert.ErrorIs(t, err, ErrSessionNotFound)
 	})
 }
 
-func TestTmux_AttachSession(t *testing.T) {
+func TestTmux_SendKeys(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("attaches to session with correct args", func(t *testing.T) {
+	t.Run("sends text literally then presses enter", func(t *testing.T) {
+		var calls [][]string
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
 				assert.Equal(t, "tmux", opts.Name)
-				assert.Equal(t, []string{"attach-session", "-t", "my-session"}, opts.Args)
-
-				return &exec.Result{
-					ExitCode: 0,
-				}, nil
+				calls = append(calls, opts.Args)
+				return &exec.Result{}, nil
 			},
 		}
 
-		tm := NewTmux(mockExec)
-		// Note: This test won't fully exercise TTY handling since we're not in a terminal
-		err := tm.AttachSession(ctx, "my-session")
+		err := NewTmux(mockExec).SendKeys(ctx, "my-session", "-n Enter", true)
 
 		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"send-keys", "-t", "my-session", "-l", "--", "-n Enter"},
+			{"send-keys", "-t", "my-session", "Enter"},
+		}, calls)
 	})
 
-	t.Run("returns ErrSessionNotFound when session missing", func(t *testing.T) {
+	t.Run("skips enter when not requested", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				// Write to the stderr writer (io.MultiWriter) that AttachSession provides
-				if opts.Stderr != nil {
-					_, _ = opts.Stderr.Write([]byte("can't find session: missing"))
-				}
-				return &exec.Result{
-					ExitCode: 1,
-				}, errors.New("exit code 1")
+				return &exec.Result{}, nil
 			},
 		}
 
-		tm := NewTmux(mockExec)
-		err := tm.AttachSession(ctx, "missing")
+		err := NewTmux(mockExec).SendKeys(ctx, "my-session", "ls", false)
 
-		assert.ErrorIs(t, err, ErrSessionNotFound)
+		require.NoError(t, err)
+		require.Len(t, mockExec.RunCalls(), 1)
 	})
 
-	t.Run("returns ErrSessionNotFound for no session error", func(t *testing.T) {
+	t.Run("returns ErrSessionNotFound when no server is running", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				if opts.Stderr != nil {
-					_, _ = opts.Stderr.Write([]byte("no session: nonexistent"))
-				}
 				return &exec.Result{
+					Stderr:   []byte("no server running on /tmp/tmux-1000/default"),
 					ExitCode: 1,
 				}, errors.New("exit code 1")
 			},
 		}
 
-		tm := NewTmux(mockExec)
-		err := tm.AttachSession(ctx, "nonexistent")
+		err := NewTmux(mockExec).SendKeys(ctx, "my-session", "ls", true)
 
 		assert.ErrorIs(t, err, ErrSessionNotFound)
 	})
+}
+
+func TestTmux_CapturePane(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		opts     *CaptureOpts
+		wantArgs []string
+	}{
+		{
+			name:     "visible screen as plain text",
+			opts:     nil,
+			wantArgs: []string{"capture-pane", "-p", "-J", "-t", "my-session"},
+		},
+		{
+			name:     "with escapes and scrollback",
+			opts:     &CaptureOpts{Scrollback: 200, Escapes: true},
+			wantArgs: []string{"capture-pane", "-p", "-J", "-t", "my-session", "-e", "-S", "-200"},
+		},
+		{
+			name:     "entire history",
+			opts:     &CaptureOpts{Scrollback: -1},
+			wantArgs: []string{"capture-pane", "-p", "-J", "-t", "my-session", "-S", "-"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mocks.ExecutorMock{
+				RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+					assert.Equal(t, "tmux", opts.Name)
+					assert.Equal(t, tt.wantArgs, opts.Args)
+					return &exec.Result{Stdout: []byte("$ ls   \nREADME.md\n\n\n")}, nil
+				},
+			}
 
-	t.Run("returns ErrAttachFailed on command error", func(t *testing.T) {
+			screen, err := NewTmux(mockExec).CapturePane(ctx, "my-session", tt.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, "$ ls\nREADME.md\n", screen)
+		})
+	}
+
+	t.Run("returns ErrSessionNotFound for missing session", func(t *testing.T) {
 		mockExec := &mocks.ExecutorMock{
 			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
-				if opts.Stderr != nil {
-					_, _ = opts.Stderr.Write([]byte("attach failed"))
-				}
 				return &exec.Result{
+					Stderr:   []byte("can't find session: missing"),
 					ExitCode: 1,
 				}, errors.New("exit code 1")
 			},
 		}
 
-		tm := NewTmux(mockExec)
-		err := tm.AttachSession(ctx, "my-session")
+		_, err := NewTmux(mockExec).CapturePane(ctx, "missing", nil)
 
-		assert.ErrorIs(t, err, ErrAttachFailed)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
 	})
 }
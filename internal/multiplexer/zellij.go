@@ -0,0 +1,312 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/exec"
+)
+
+// zellij implements Multiplexer using the Zellij terminal workspace.
+//
+// Zellij has no equivalent of tmux's pipe-pane, so when a log path is given
+// the session command is wrapped with script(1), which records the pane
+// output while keeping the command attached to a TTY. With a log sink, the
+// recording is piped to the sink instead of appended to the log.
+type zellij struct {
+	exec exec.Executor
+}
+
+// NewZellij creates a Multiplexer using the zellij CLI.
+// Requires zellij 0.40 or later for background session creation from a layout.
+func NewZellij(e exec.Executor) Multiplexer {
+	return &zellij{exec: e}
+}
+
+func (z *zellij) CreateSession(ctx context.Context, opts *CreateSessionOpts) (*Session, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, fmt.Errorf("%w: session name is required", ErrCreateFailed)
+	}
+
+	// Check if session already exists
+	sessions, err := z.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("check existing sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Name == opts.Name {
+			return nil, ErrSessionExists
+		}
+	}
+
+	// Zellij reads the initial pane command and cwd from a layout file.
+	layout, err := os.CreateTemp("", "hjk-zellij-*.kdl")
+	if err != nil {
+		return nil, fmt.Errorf("%w: create layout: %v", ErrCreateFailed, err)
+	}
+	defer os.Remove(layout.Name()) //nolint:errcheck // best-effort cleanup of temp layout
+
+	_, writeErr := layout.WriteString(zellijLayout(zellijCommand(opts), opts.Cwd))
+	closeErr := layout.Close()
+	if writeErr != nil || closeErr != nil {
+		return nil, fmt.Errorf("%w: write layout: %v", ErrCreateFailed, errors.Join(writeErr, closeErr))
+	}
+
+	// zellij --layout <file> attach --create-background <name>
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"--layout", layout.Name(), "attach", "--create-background", opts.Name},
+	})
+	if err != nil {
+		stderr := string(result.Stderr)
+		if strings.Contains(stderr, "already exists") {
+			return nil, ErrSessionExists
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCreateFailed, err)
+	}
+
+	return &Session{
+		ID:   opts.Name,
+		Name: opts.Name,
+	}, nil
+}
+
+func (z *zellij) AttachSession(ctx context.Context, sessionName string) error {
+	// zellij attach <session-name>
+	stderr, err := runAttached(ctx, z.exec, "zellij", []string{"attach", sessionName})
+	if err != nil {
+		if isZellijNotFound(stderr) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrAttachFailed, err)
+	}
+
+	return nil
+}
+
+func (z *zellij) AttachCommand(sessionName string) []string {
+	return commandLine(z.exec, "zellij", []string{"attach", sessionName})
+}
+
+func (z *zellij) ListSessions(ctx context.Context) ([]Session, error) {
+	// zellij list-sessions --no-formatting
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"list-sessions", "--no-formatting"},
+	})
+	if err != nil {
+		// Zellij exits non-zero when there are no sessions at all.
+		stderr := strings.ToLower(string(result.Stderr))
+		if strings.Contains(stderr, "no active zellij sessions") {
+			return []Session{}, nil
+		}
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	output := strings.TrimSpace(string(result.Stdout))
+	if output == "" {
+		return []Session{}, nil
+	}
+
+	lines := strings.Split(output, "\n")
+	sessions := make([]Session, 0, len(lines))
+
+	for _, line := range lines {
+		// Each line is "<name> [Created ...]", with an "(EXITED ...)" suffix
+		// for sessions that are only kept for resurrection. Only the status
+		// after the name is checked, as names may contain "EXITED" too.
+		fields := strings.Fields(line)
+		if len(fields) == 0 || slices.ContainsFunc(fields[1:], isExitedStatus) {
+			continue
+		}
+
+		sessions = append(sessions, Session{
+			ID:   fields[0],
+			Name: fields[0],
+		})
+	}
+
+	return sessions, nil
+}
+
+// isExitedStatus reports whether field starts the "(EXITED ...)" status of
+// a list-sessions line.
+func isExitedStatus(field string) bool {
+	return strings.HasPrefix(field, "(EXITED")
+}
+
+func (z *zellij) KillSession(ctx context.Context, sessionName string) error {
+	// zellij delete-session --force <session-name>
+	// Unlike kill-session, this also removes the resurrection data so the
+	// name can be reused.
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: []string{"delete-session", "--force", sessionName},
+	})
+	if err != nil {
+		if isZellijNotFound(string(result.Stderr)) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("kill session: %w", err)
+	}
+
+	return nil
+}
+
+func (z *zellij) SendKeys(ctx context.Context, sessionName, text string, enter bool) error {
+	// zellij --session <session-name> action write-chars <text>
+	// zellij --session <session-name> action write 13
+	var calls [][]string
+	if text != "" {
+		calls = append(calls, []string{"--session", sessionName, "action", "write-chars", text})
+	}
+	if enter {
+		calls = append(calls, []string{"--session", sessionName, "action", "write", "13"})
+	}
+
+	for _, args := range calls {
+		result, err := z.exec.Run(ctx, &exec.RunOptions{
+			Name: "zellij",
+			Args: args,
+		})
+		if err != nil {
+			if isZellijNotFound(string(result.Stderr)) {
+				return ErrSessionNotFound
+			}
+			return fmt.Errorf("send keys: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CapturePane dumps the focused pane of the session. Zellij renders the dump
+// as plain text, so opts.Escapes is ignored, and any non-zero
+// opts.Scrollback includes the entire history.
+func (z *zellij) CapturePane(ctx context.Context, sessionName string, opts *CaptureOpts) (string, error) {
+	if opts == nil {
+		opts = &CaptureOpts{}
+	}
+
+	dump, err := os.CreateTemp("", "hjk-zellij-*.dump")
+	if err != nil {
+		return "", fmt.Errorf("capture pane: create dump file: %w", err)
+	}
+	_ = dump.Close()
+	defer os.Remove(dump.Name()) //nolint:errcheck // best-effort cleanup of temp dump
+
+	// zellij --session <session-name> action dump-screen [--full] <path>
+	args := []string{"--session", sessionName, "action", "dump-screen"}
+	if opts.Scrollback != 0 {
+		args = append(args, "--full")
+	}
+	args = append(args, dump.Name())
+
+	result, err := z.exec.Run(ctx, &exec.RunOptions{
+		Name: "zellij",
+		Args: args,
+	})
+	if err != nil {
+		if isZellijNotFound(string(result.Stderr)) {
+			return "", ErrSessionNotFound
+		}
+		return "", fmt.Errorf("capture pane: %w", err)
+	}
+
+	data, err := os.ReadFile(dump.Name())
+	if err != nil {
+		return "", fmt.Errorf("capture pane: read dump file: %w", err)
+	}
+	return trimCapture(string(data)), nil
+}
+
+// zellijCommand builds the pane command, applying environment variables and
+// log capture. Defaults to the user's shell.
+func zellijCommand(opts *CreateSessionOpts) []string {
+	command := opts.Command
+	if len(command) == 0 {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		command = []string{shell}
+	}
+
+	// Zellij has no per-session environment, so pass it through env(1).
+	if len(opts.Env) > 0 {
+		command = append(append([]string{"env"}, opts.Env...), command...)
+	}
+
+	if opts.LogPath == "" {
+		return command
+	}
+	if len(opts.LogSink) > 0 {
+		return zellijSinkCommand(command, opts)
+	}
+
+	// script(1) differs between BSD (macOS) and util-linux.
+	if runtime.GOOS == "darwin" {
+		return append([]string{"script", "-q", "-a", "-F", opts.LogPath}, command...)
+	}
+	return []string{"script", "-q", "-f", "-a", "-c", shellJoin(command), opts.LogPath}
+}
+
+// zellijSinkCommand wraps command with script(1) recording to descriptor 3,
+// which is piped to the log sink, while the pane keeps the original stdout.
+func zellijSinkCommand(command []string, opts *CreateSessionOpts) []string {
+	record := "script -q -f -c " + shellEscape(shellJoin(command)) + " /dev/fd/3"
+	if runtime.GOOS == "darwin" {
+		record = "script -q -F /dev/fd/3 " + shellJoin(command)
+	}
+	sink := shellJoin(append(append([]string{}, opts.LogSink...), opts.LogPath))
+	return []string{"sh", "-c", "{ " + record + " 3>&1 1>&4 4>&- | " + sink + "; } 4>&1"}
+}
+
+// shellJoin joins args into a shell command line, escaping each of them.
+func shellJoin(args []string) string {
+	escaped := make([]string, len(args))
+	for i, arg := range args {
+		escaped[i] = shellEscape(arg)
+	}
+	return strings.Join(escaped, " ")
+}
+
+// zellijLayout renders a single-pane KDL layout running command in cwd.
+// The pane closes when the command exits, ending the session as tmux does.
+func zellijLayout(command []string, cwd string) string {
+	var b strings.Builder
+	b.WriteString("layout {\n")
+	b.WriteString("    pane command=" + kdlString(command[0]) + " close_on_exit=true")
+	if cwd != "" {
+		b.WriteString(" cwd=" + kdlString(cwd))
+	}
+	b.WriteString(" {\n")
+	if len(command) > 1 {
+		args := make([]string, len(command)-1)
+		for i, arg := range command[1:] {
+			args[i] = kdlString(arg)
+		}
+		b.WriteString("        args " + strings.Join(args, " ") + "\n")
+	}
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// kdlString quotes s as a KDL string literal.
+func kdlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// isZellijNotFound reports whether zellij stderr indicates a missing session.
+func isZellijNotFound(stderr string) bool {
+	normalized := strings.ToLower(stderr)
+	return strings.Contains(normalized, "not found") ||
+		strings.Contains(normalized, "no session")
+}
@@ -0,0 +1,230 @@
+package multiplexer
+
+import (
+	"context"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/exec"
+	"github.com/jmgilman/headjack/internal/exec/mocks"
+)
+
+const zellijCmdListSessions = "list-sessions"
+
+func TestNewZellij(t *testing.T) {
+	mockExec := &mocks.ExecutorMock{}
+	z := NewZellij(mockExec)
+
+	require.NotNil(t, z)
+}
+
+func TestZellij_CreateSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates background session from layout", func(t *testing.T) {
+		var layout string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == zellijCmdListSessions {
+					return &exec.Result{
+						Stderr:   []byte("No active zellij sessions found."),
+						ExitCode: 1,
+					}, errors.New("exit code 1")
+				}
+
+				assert.Equal(t, "zellij", opts.Name)
+				require.Len(t, opts.Args, 5)
+				assert.Equal(t, "--layout", opts.Args[0])
+				assert.Equal(t, []string{"attach", "--create-background", "my-session"}, opts.Args[2:])
+
+				data, err := os.ReadFile(opts.Args[1])
+				require.NoError(t, err)
+				layout = string(data)
+				return &exec.Result{ExitCode: 0}, nil
+			},
+		}
+
+		z := NewZellij(mockExec)
+		_, err := z.CreateSession(ctx, &CreateSessionOpts{
+			Name:    "my-session",
+			Command: []string{"bash", "-c", `echo "hi"`},
+			Cwd:     "/workspace",
+			Env:     []string{"FOO=bar"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, `layout {
+    pane command="env" close_on_exit=true cwd="/workspace" {
+        args "FOO=bar" "bash" "-c" "echo \"hi\""
+    }
+}
+`, layout)
+	})
+
+	t.Run("removes layout file after creation", func(t *testing.T) {
+		var layoutPath string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				if opts.Args[0] == zellijCmdListSessions {
+					return &exec.Result{}, nil
+				}
+				layoutPath = opts.Args[1]
+				return &exec.Result{}, nil
+			},
+		}
+
+		_, err := NewZellij(mockExec).CreateSession(ctx, &CreateSessionOpts{Name: "s"})
+
+		require.NoError(t, err)
+		_, statErr := os.Stat(layoutPath)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
+
+func TestZellijCommand(t *testing.T) {
+	t.Run("defaults to shell", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/zsh")
+
+		assert.Equal(t, []string{"/bin/zsh"}, zellijCommand(&CreateSessionOpts{Name: "s"}))
+	})
+
+	t.Run("wraps command with script for log capture", func(t *testing.T) {
+		cmd := zellijCommand(&CreateSessionOpts{
+			Name:    "s",
+			Command: []string{"echo", "it's"},
+			LogPath: "/var/log/my session.log",
+		})
+
+		if runtime.GOOS == "darwin" {
+			assert.Equal(t, []string{"script", "-q", "-a", "-F", "/var/log/my session.log", "echo", "it's"}, cmd)
+			return
+		}
+		assert.Equal(t, []string{"script", "-q", "-f", "-a", "-c", `'echo' 'it'\''s'`, "/var/log/my session.log"}, cmd)
+	})
+
+	t.Run("pipes the recording to the log sink", func(t *testing.T) {
+		if runtime.GOOS == "darwin" {
+			t.Skip("script(1) of util-linux")
+		}
+		if _, err := osexec.LookPath("script"); err != nil {
+			t.Skip("script(1) not installed")
+		}
+		dir := t.TempDir()
+		logPath := filepath.Join(dir, "session.log")
+		sink := filepath.Join(dir, "sink")
+		require.NoError(t, os.WriteFile(sink, []byte("#!/bin/sh\necho \"$1\" > \"$1.args\"\ncat >> \"$1\"\n"), 0o700)) //nolint:gosec // test script
+
+		cmd := zellijCommand(&CreateSessionOpts{
+			Name:    "s",
+			Command: []string{"echo", "hello from pane"},
+			LogPath: logPath,
+			LogSink: []string{sink},
+		})
+		require.Equal(t, "sh", cmd[0])
+		out, err := osexec.Command(cmd[0], cmd[1:]...).Output()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(out), "hello from pane", "the pane still shows output")
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "hello from pane")
+		args, err := os.ReadFile(logPath + ".args")
+		require.NoError(t, err)
+		assert.Equal(t, logPath+"\n", string(args))
+	})
+}
+
+func TestZellij_ListSessions(t *testing.T) {
+	t.Run("skips exited sessions", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"list-sessions", "--no-formatting"}, opts.Args)
+				return &exec.Result{
+					Stdout: []byte("hjk-a-one [Created 2m ago]\nhjk-a-two [Created 1h ago] (EXITED - attach to resurrect)\n"),
+				}, nil
+			},
+		}
+
+		sessions, err := NewZellij(mockExec).ListSessions(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "hjk-a-one", sessions[0].Name)
+	})
+
+	t.Run("keeps live sessions named with EXITED", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				return &exec.Result{
+					Stdout: []byte("hjk-fix-EXITED-state [Created 2m ago]\nhjk-EXITED [Created 1h ago] (EXITED - attach to resurrect)\n"),
+				}, nil
+			},
+		}
+
+		sessions, err := NewZellij(mockExec).ListSessions(context.Background())
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "hjk-fix-EXITED-state", sessions[0].Name)
+	})
+}
+
+func TestZellij_KillSession(t *testing.T) {
+	t.Run("force deletes session", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				assert.Equal(t, []string{"delete-session", "--force", "my-session"}, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewZellij(mockExec).KillSession(context.Background(), "my-session")
+
+		require.NoError(t, err)
+	})
+}
+
+func TestZellij_SendKeys(t *testing.T) {
+	t.Run("writes chars then carriage return", func(t *testing.T) {
+		var calls [][]string
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				calls = append(calls, opts.Args)
+				return &exec.Result{}, nil
+			},
+		}
+
+		err := NewZellij(mockExec).SendKeys(context.Background(), "my-session", "hello", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"--session", "my-session", "action", "write-chars", "hello"},
+			{"--session", "my-session", "action", "write", "13"},
+		}, calls)
+	})
+}
+
+func TestZellij_CapturePane(t *testing.T) {
+	t.Run("dumps full screen to a file and reads it", func(t *testing.T) {
+		mockExec := &mocks.ExecutorMock{
+			RunFunc: func(ctx context.Context, opts *exec.RunOptions) (*exec.Result, error) {
+				require.Len(t, opts.Args, 6)
+				assert.Equal(t, []string{"--session", "my-session", "action", "dump-screen", "--full"}, opts.Args[:5])
+				require.NoError(t, os.WriteFile(opts.Args[5], []byte("hello  \n\n"), 0o600))
+				return &exec.Result{}, nil
+			},
+		}
+
+		screen, err := NewZellij(mockExec).CapturePane(context.Background(), "my-session", &CaptureOpts{Scrollback: -1})
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", screen)
+	})
+}
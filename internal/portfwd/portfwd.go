@@ -0,0 +1,306 @@
+// Package portfwd forwards TCP ports on the host to ports inside containers.
+//
+// Forwarding needs no published ports or network access to the container:
+// a proxy process on the host accepts connections and relays each one
+// through the container runtime's exec command to a small relay inside the
+// container, using socat, nc or bash, whichever the image has.
+package portfwd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// ErrRunning is returned by Start when a proxy already holds the pidfile.
+var ErrRunning = errors.New("port proxy already running")
+
+// listenState is the st value of listening sockets in /proc/net/tcp.
+const listenState = "0A"
+
+// File descriptors of the listener and the locked pidfile in a proxy
+// process started by Start (the entries of ExtraFiles).
+const (
+	listenerFD = 3
+	pidfileFD  = 4
+)
+
+// listenerEnv marks a proxy process started by Start.
+const listenerEnv = "HEADJACK_PORTFWD_LISTENER"
+
+// maxListenAttempts bounds the search for a free host port outside those
+// already assigned.
+const maxListenAttempts = 20
+
+// relayScript connects its standard input and output to TCP port $1 on the
+// container's loopback interface.
+const relayScript = `if command -v socat >/dev/null 2>&1; then
+	exec socat - "TCP:localhost:$1"
+elif command -v nc >/dev/null 2>&1; then
+	exec nc localhost "$1"
+elif command -v bash >/dev/null 2>&1; then
+	exec bash -c 'exec 3<>"/dev/tcp/127.0.0.1/$1" && { cat <&3 & cat >&3; }' bash "$1"
+fi
+echo "no socat, nc or bash to relay connections" >&2
+exit 127`
+
+// ListeningPorts returns the TCP ports in the listening state, sorted and
+// without duplicates, from the contents of /proc/net/tcp and /proc/net/tcp6.
+func ListeningPorts(procNetTCP []byte) ([]int, error) {
+	var ports []int
+	scanner := bufio.NewScanner(bytes.NewReader(procNetTCP))
+	for scanner.Scan() {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "sl" || fields[3] != listenState {
+			continue
+		}
+		i := strings.LastIndexByte(fields[1], ':')
+		if i < 0 {
+			return nil, fmt.Errorf("parse socket address %q", fields[1])
+		}
+		port, err := strconv.ParseUint(fields[1][i+1:], 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("parse socket port %q: %w", fields[1], err)
+		}
+		ports = append(ports, int(port))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read socket table: %w", err)
+	}
+
+	slices.Sort(ports)
+	return slices.Compact(ports), nil
+}
+
+// Listen listens on a port of the host's loopback interface: preferred if
+// it is free and not taken, otherwise any free port that is not taken.
+// Taken ports are assigned elsewhere but may not be in use right now.
+func Listen(preferred int, taken map[int]bool) (*net.TCPListener, error) {
+	if preferred > 0 && !taken[preferred] {
+		if ln, err := listenTCP(preferred); err == nil {
+			return ln, nil
+		}
+	}
+
+	for range maxListenAttempts {
+		ln, err := listenTCP(0)
+		if err != nil {
+			return nil, err
+		}
+		if !taken[ln.Addr().(*net.TCPAddr).Port] {
+			return ln, nil
+		}
+		_ = ln.Close()
+	}
+	return nil, errors.New("no free host port")
+}
+
+// listenTCP listens on port of the loopback interface (0 = any free port).
+func listenTCP(port int) (*net.TCPListener, error) {
+	ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port})
+	if err != nil {
+		return nil, fmt.Errorf("listen on host port %d: %w", port, err)
+	}
+	return ln, nil
+}
+
+// Port returns the port a listener is bound to.
+func Port(ln *net.TCPListener) int {
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// Start runs binary with args as a detached background proxy process that
+// serves ln, and returns its process ID. The process takes the listener
+// with InheritedListener; the caller should close its copy.
+//
+// The process holds an exclusive lock on pidfile, which records its PID,
+// for as long as it runs. Running and Stop only trust a PID while its lock
+// is held, so a PID reused by an unrelated process after the proxy exited,
+// or after a reboot, is never reported or signaled.
+func Start(binary string, args []string, ln *net.TCPListener, pidfile string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(pidfile), 0o700); err != nil {
+		return 0, fmt.Errorf("create pidfile directory: %w", err)
+	}
+	//nolint:gosec // G304: pidfile is in headjack's data directory
+	lock, err := os.OpenFile(pidfile, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("open pidfile: %w", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return 0, ErrRunning
+		}
+		return 0, fmt.Errorf("lock pidfile: %w", err)
+	}
+
+	f, err := ln.File()
+	if err != nil {
+		return 0, fmt.Errorf("pass listener: %w", err)
+	}
+	defer f.Close()
+
+	// The child shares the locked file description, so the lock stays held
+	// after this process closes its copy, until the child exits
+	cmd := osexec.Command(binary, args...) //nolint:gosec // binary is the headjack executable
+	cmd.Env = append(os.Environ(), listenerEnv+"=1")
+	cmd.ExtraFiles = []*os.File{f, lock}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("start port proxy: %w", err)
+	}
+
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return 0, fmt.Errorf("release port proxy: %w", err)
+	}
+	if err := writePID(lock, pid); err != nil {
+		_ = syscall.Kill(pid, syscall.SIGTERM) //nolint:errcheck // best-effort cleanup
+		return 0, err
+	}
+	return pid, nil
+}
+
+// writePID replaces the contents of a pidfile with pid.
+func writePID(f *os.File, pid int) error {
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("write pidfile: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(pid)+"\n"), 0); err != nil {
+		return fmt.Errorf("write pidfile: %w", err)
+	}
+	return nil
+}
+
+// InheritedListener returns the listener passed to a proxy process by Start.
+// The locked pidfile stays open for the life of the process, but is not
+// passed on to the relay processes it runs.
+func InheritedListener() (net.Listener, error) {
+	if os.Getenv(listenerEnv) == "" {
+		return nil, errors.New("no listener passed to the port proxy")
+	}
+	_ = os.Unsetenv(listenerEnv)
+	syscall.CloseOnExec(pidfileFD)
+
+	f := os.NewFile(listenerFD, "listener")
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("open inherited listener: %w", err)
+	}
+	return ln, nil
+}
+
+// Running returns the PID of the proxy process of pidfile, and whether it
+// is running: whether a process still holds the lock on pidfile.
+func Running(pidfile string) (int, bool) {
+	//nolint:gosec // G304: pidfile is in headjack's data directory
+	f, err := os.Open(pidfile)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if err == nil {
+		// Nothing holds the lock, so the proxy exited
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:errcheck // released on close anyway
+		return 0, false
+	}
+	if !errors.Is(err, syscall.EWOULDBLOCK) {
+		return 0, false
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// Stop terminates the proxy process of pidfile, if it is running, and
+// removes pidfile.
+func Stop(pidfile string) error {
+	if pid, ok := Running(pidfile); ok {
+		if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return fmt.Errorf("stop port proxy %d: %w", pid, err)
+		}
+	}
+	if err := os.Remove(pidfile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove pidfile: %w", err)
+	}
+	return nil
+}
+
+// Proxy relays connections to a port inside a container.
+type Proxy struct {
+	ExecCommand []string // Runtime exec command prefix (e.g., ["docker", "exec"])
+	ContainerID string
+	Port        int // Port inside the container
+}
+
+// Serve accepts connections on ln and relays each one until ln is closed.
+func (p *Proxy) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("accept connection: %w", err)
+		}
+		go p.relay(conn)
+	}
+}
+
+// Command returns the command line that relays standard input and output
+// to the port inside the container.
+func (p *Proxy) Command() []string {
+	argv := append([]string{}, p.ExecCommand...)
+	return append(argv, "-i", p.ContainerID, "sh", "-c", relayScript, "sh", strconv.Itoa(p.Port))
+}
+
+// relay copies data between conn and a relay process in the container
+// until either side closes.
+func (p *Proxy) relay(conn net.Conn) {
+	defer conn.Close()
+
+	argv := p.Command()
+	cmd := osexec.Command(argv[0], argv[1:]...) //nolint:gosec // argv is the runtime exec command
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return
+	}
+	cmd.Stdout = conn
+	if err := cmd.Start(); err != nil {
+		return
+	}
+
+	var once sync.Once
+	closeStdin := func() { once.Do(func() { _ = stdin.Close() }) }
+	go func() {
+		_, _ = io.Copy(stdin, conn)
+		closeStdin()
+	}()
+
+	// The relay exits when the service closes the connection, or after the
+	// client closes its side
+	_ = cmd.Wait()
+	closeStdin()
+}
@@ -0,0 +1,161 @@
+package portfwd
+
+import (
+	"io"
+	"net"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1 1 0000000000000000 100 0 0 10 0
+   1: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 2 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:0BB8 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000  1000        0 3 1 0000000000000000 20 4 30 10 -1
+  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0BB8 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4 1 0000000000000000 100 0 0 10 0
+`
+
+func TestListeningPorts(t *testing.T) {
+	ports, err := ListeningPorts([]byte(procNetTCP))
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{3000, 8080}, ports)
+
+	_, err = ListeningPorts([]byte("   0: 0100007F:ZZZZ 00000000:0000 0A\n"))
+	assert.Error(t, err)
+}
+
+func TestListen(t *testing.T) {
+	t.Run("uses the preferred port when free", func(t *testing.T) {
+		free, err := listenTCP(0)
+		require.NoError(t, err)
+		port := Port(free)
+		require.NoError(t, free.Close())
+
+		ln, err := Listen(port, nil)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		assert.Equal(t, port, Port(ln))
+	})
+
+	t.Run("falls back when the preferred port is in use", func(t *testing.T) {
+		busy, err := listenTCP(0)
+		require.NoError(t, err)
+		defer busy.Close()
+
+		ln, err := Listen(Port(busy), nil)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		assert.NotEqual(t, Port(busy), Port(ln))
+	})
+
+	t.Run("skips taken ports", func(t *testing.T) {
+		free, err := listenTCP(0)
+		require.NoError(t, err)
+		port := Port(free)
+		require.NoError(t, free.Close())
+
+		ln, err := Listen(port, map[int]bool{port: true})
+		require.NoError(t, err)
+		defer ln.Close()
+
+		assert.NotEqual(t, port, Port(ln))
+	})
+}
+
+func TestProxy_Serve(t *testing.T) {
+	if _, err := osexec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+
+	// The service echoes a line back
+	service, err := listenTCP(0)
+	require.NoError(t, err)
+	defer service.Close()
+	go func() {
+		conn, err := service.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	// Run the relay on the host instead of in a container by dropping the
+	// exec options and container ID
+	proxy := &Proxy{
+		ExecCommand: []string{"sh", "-c", `shift 2; exec "$@"`, "exec"},
+		ContainerID: "container",
+		Port:        Port(service),
+	}
+	ln, err := listenTCP(0)
+	require.NoError(t, err)
+	go func() { _ = proxy.Serve(ln) }()
+	defer ln.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(buf))
+}
+
+func TestStart(t *testing.T) {
+	pidfile := filepath.Join(t.TempDir(), "ports", "inst-3000.pid")
+	ln, err := listenTCP(0)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	_, running := Running(pidfile)
+	assert.False(t, running)
+
+	pid, err := Start("sleep", []string{"30"}, ln, pidfile)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = Stop(pidfile) })
+
+	got, running := Running(pidfile)
+	require.True(t, running)
+	assert.Equal(t, pid, got)
+
+	// A second proxy for the same pidfile is refused
+	_, err = Start("sleep", []string{"30"}, ln, pidfile)
+	require.ErrorIs(t, err, ErrRunning)
+
+	// The lock is released when the process exits
+	f, err := os.Open(pidfile)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, Stop(pidfile))
+	assert.NoFileExists(t, pidfile)
+	assert.Eventually(t, func() bool {
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRunning_StalePidfile(t *testing.T) {
+	// The pidfile of a proxy that exited names a live, unrelated process
+	pidfile := filepath.Join(t.TempDir(), "inst-3000.pid")
+	require.NoError(t, os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())), 0o600))
+
+	_, running := Running(pidfile)
+	assert.False(t, running)
+
+	// Stop must not signal the unrelated process
+	require.NoError(t, Stop(pidfile))
+	assert.NoFileExists(t, pidfile)
+}
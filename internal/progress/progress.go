@@ -0,0 +1,294 @@
+// Package progress turns the output of container image pulls, image builds
+// and the devcontainer CLI into structured progress events.
+//
+// Output is parsed line by line. Docker and Podman pull output, BuildKit and
+// legacy build output, and devcontainer CLI JSON log lines (--log-format json)
+// are recognized; other lines become events of the current phase that only
+// carry a message.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// Phase is a stage of container creation.
+type Phase string
+
+// Phase constants in the order they usually occur.
+const (
+	PhasePull   Phase = "pull"   // Pulling an image
+	PhaseBuild  Phase = "build"  // Building an image
+	PhaseCreate Phase = "create" // Creating and starting the container
+	PhaseSetup  Phase = "setup"  // Running devcontainer lifecycle commands
+)
+
+// Event is a progress update parsed from a line of output.
+type Event struct {
+	Phase   Phase   `json:"phase"`
+	Layer   string  `json:"layer,omitempty"`   // Image layer ID or build step (e.g., "#5")
+	Status  string  `json:"status,omitempty"`  // e.g., "Downloading", "Pull complete", "DONE"
+	Current int64   `json:"current,omitempty"` // Bytes transferred
+	Total   int64   `json:"total,omitempty"`   // Bytes to transfer (0 = unknown)
+	Percent float64 `json:"percent,omitempty"` // Progress of the layer or build (0-100)
+	Message string  `json:"message"`           // The line without escape sequences
+}
+
+// Done reports whether the event completes its layer.
+func (e *Event) Done() bool {
+	switch strings.ToLower(e.Status) {
+	case "pull complete", "already exists", "done", "cached", "download complete":
+		return true
+	}
+	return e.Total > 0 && e.Current >= e.Total
+}
+
+var (
+	// dockerLayerRe matches Docker pull lines, with a progress bar and sizes
+	// when Docker writes to a terminal or the API reports progress:
+	// "a1b2c3d4e5f6: Downloading [==>   ]  12.3MB/30.1MB"
+	dockerLayerRe = regexp.MustCompile(`^([0-9a-f]{12}): ([A-Za-z][A-Za-z ]*[A-Za-z])\s*(?:\[[=> -]*\])?\s*(.*)$`)
+	// podmanBlobRe matches Podman pull lines: "Copying blob 6e3729cf69e0 [==>--] 12.3MiB / 30.1MiB"
+	podmanBlobRe = regexp.MustCompile(`^Copying (?:blob|config) (?:sha256:)?([0-9a-f]{12})[0-9a-f]*\s*(.*)$`)
+	// sizesRe matches transferred and total sizes: "12.3MB/30.1MB", "12.3MiB / 30.1MiB"
+	sizesRe = regexp.MustCompile(`([\d.]+\s?[kKMGT]?i?B)\s*/\s*([\d.]+\s?[kKMGT]?i?B)`)
+	// buildkitRe matches BuildKit plain progress: "#5 [2/4] RUN make", "#5 DONE 3.2s"
+	buildkitRe = regexp.MustCompile(`^#(\d+) (.*)$`)
+	// buildkitStepRe matches the step counter of a BuildKit vertex: "[2/4] ", "[stage 1/3] "
+	buildkitStepRe = regexp.MustCompile(`^\[(?:[^\]]*\s)?(\d+)/(\d+)\] `)
+	// buildkitBlobRe matches BuildKit layer downloads: "sha256:abc... 12.58MB / 30.43MB 0.7s"
+	buildkitBlobRe = regexp.MustCompile(`^sha256:([0-9a-f]{12})[0-9a-f]*\s`)
+	// legacyStepRe matches the legacy builder: "Step 2/4 : RUN make"
+	legacyStepRe = regexp.MustCompile(`^Step (\d+)/(\d+) : `)
+)
+
+// pullPrefixes start lines that report on an image pull as a whole.
+var pullPrefixes = []string{
+	"Unable to find image",
+	"Trying to pull",
+	"Getting image source signatures",
+	"Writing manifest",
+	"Storing signatures",
+	"Digest: ",
+	"Status: ",
+	"Pulling from ",
+}
+
+// devcontainerLog is a line of devcontainer CLI output in --log-format json.
+type devcontainerLog struct {
+	Type       string `json:"type"` // text, raw, start, stop, progress
+	Text       string `json:"text"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	StepDetail string `json:"stepDetail"`
+}
+
+// Parser converts lines of output into events. It remembers the current
+// phase, which lines that are not recognized are attributed to.
+type Parser struct {
+	phase Phase
+}
+
+// NewParser creates a Parser that starts in the create phase.
+func NewParser() *Parser {
+	return &Parser{phase: PhaseCreate}
+}
+
+// Parse converts a line of output into events. It returns no events for
+// empty lines, and several for devcontainer log lines with multiple lines
+// of text.
+func (p *Parser) Parse(line string) []Event {
+	if strings.HasPrefix(line, "{") {
+		var log devcontainerLog
+		if err := json.Unmarshal([]byte(line), &log); err == nil && log.Type != "" {
+			return p.parseDevcontainer(&log)
+		}
+	}
+
+	if ev, ok := p.parseText(line); ok {
+		return []Event{ev}
+	}
+	return nil
+}
+
+// parseDevcontainer converts a devcontainer CLI log line into events.
+func (p *Parser) parseDevcontainer(log *devcontainerLog) []Event {
+	switch log.Type {
+	case "text", "raw":
+		var events []Event
+		for _, line := range strings.Split(log.Text, "\n") {
+			if ev, ok := p.parseText(line); ok {
+				events = append(events, ev)
+			}
+		}
+		return events
+	case "start":
+		// Commands run by the CLI, e.g. "Run: docker buildx build ..."
+		text := logging.CleanLine(log.Text)
+		switch {
+		case strings.Contains(text, " build "):
+			p.phase = PhaseBuild
+		case strings.Contains(text, " pull "):
+			p.phase = PhasePull
+		case strings.Contains(text, " run "):
+			p.phase = PhaseCreate
+		}
+		return []Event{{Phase: p.phase, Message: text}}
+	case "progress":
+		// Lifecycle commands, e.g. "Running postCreateCommand..."
+		p.phase = PhaseSetup
+		msg := log.Name
+		if log.StepDetail != "" {
+			msg += " " + log.StepDetail
+		}
+		return []Event{{Phase: p.phase, Status: log.Status, Message: msg}}
+	default:
+		return nil
+	}
+}
+
+// parseText converts a line of plain output into an event.
+func (p *Parser) parseText(line string) (Event, bool) {
+	line = strings.TrimSpace(logging.CleanLine(line))
+	if line == "" {
+		return Event{}, false
+	}
+
+	ev := Event{Phase: p.phase, Message: line}
+	if !p.parsePull(line, &ev) {
+		p.parseBuild(line, &ev)
+	}
+	if ev.Total > 0 {
+		ev.Percent = percent(ev.Current, ev.Total)
+	}
+	return ev, true
+}
+
+// parsePull fills in a pull event if line reports on a pull.
+func (p *Parser) parsePull(line string, ev *Event) bool {
+	if m := dockerLayerRe.FindStringSubmatch(line); m != nil {
+		ev.Layer, ev.Status = m[1], m[2]
+		ev.Current, ev.Total = parseSizes(m[3])
+	} else if m := podmanBlobRe.FindStringSubmatch(line); m != nil {
+		ev.Layer, ev.Status = m[1], "Copying"
+		if rest := strings.TrimSpace(m[2]); strings.HasPrefix(rest, "done") || strings.HasPrefix(rest, "skipped") {
+			ev.Status = "done"
+		}
+		ev.Current, ev.Total = parseSizes(m[2])
+	} else if !hasAnyPrefix(line, pullPrefixes) && !strings.Contains(line, ": Pulling from ") {
+		return false
+	}
+	p.phase = PhasePull
+	ev.Phase = PhasePull
+	return true
+}
+
+// parseBuild fills in a build event if line is build output.
+func (p *Parser) parseBuild(line string, ev *Event) {
+	if m := legacyStepRe.FindStringSubmatch(line); m != nil {
+		n, total := atoi(m[1]), atoi(m[2])
+		ev.Layer = "step " + m[1]
+		ev.Percent = percent(int64(n-1), int64(total))
+	} else if m := buildkitRe.FindStringSubmatch(line); m != nil {
+		ev.Layer = "#" + m[1]
+		rest := m[2]
+		switch {
+		case strings.HasPrefix(rest, "DONE"), strings.HasPrefix(rest, "CACHED"):
+			ev.Status, _, _ = strings.Cut(rest, " ")
+		case strings.HasPrefix(rest, "ERROR"):
+			ev.Status = "ERROR"
+		}
+		if s := buildkitStepRe.FindStringSubmatch(rest); s != nil {
+			ev.Percent = percent(int64(atoi(s[1])-1), int64(atoi(s[2])))
+		}
+		if s := buildkitBlobRe.FindStringSubmatch(rest); s != nil {
+			ev.Layer = s[1]
+			ev.Current, ev.Total = parseSizes(rest)
+		}
+	} else {
+		return
+	}
+	p.phase = PhaseBuild
+	ev.Phase = PhaseBuild
+}
+
+// parseSizes returns the transferred and total bytes in s, or zeros.
+func parseSizes(s string) (current, total int64) {
+	m := sizesRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0
+	}
+	return ParseSize(m[1]), ParseSize(m[2])
+}
+
+// sizeUnits maps size suffixes to bytes. Docker uses decimal units, Podman
+// binary units.
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"kB":  1e3,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// ParseSize parses a size such as "12.3MB" or "4.5 MiB" into bytes. It
+// returns 0 for sizes it doesn't understand.
+func ParseSize(s string) int64 {
+	s = strings.ReplaceAll(s, " ", "")
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i <= 0 {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0
+	}
+	return int64(math.Round(v * sizeUnits[s[i:]]))
+}
+
+// FormatSize formats a size in bytes with decimal units, as Docker does.
+func FormatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "kMGT"[exp])
+}
+
+// percent returns current as a percentage of total with one decimal.
+func percent(current, total int64) float64 {
+	if total <= 0 || current <= 0 {
+		return 0
+	}
+	return math.Round(float64(current)/float64(total)*1000) / 10
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s) //nolint:errcheck // only called on digits matched by a regexp
+	return n
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package progress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Parse_Pull(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Event
+	}{
+		{
+			name: "docker pull header",
+			line: "24.04: Pulling from library/ubuntu",
+			want: Event{Phase: PhasePull, Message: "24.04: Pulling from library/ubuntu"},
+		},
+		{
+			name: "docker layer status",
+			line: "a1b2c3d4e5f6: Pulling fs layer",
+			want: Event{Phase: PhasePull, Layer: "a1b2c3d4e5f6", Status: "Pulling fs layer", Message: "a1b2c3d4e5f6: Pulling fs layer"},
+		},
+		{
+			name: "docker layer progress",
+			line: "a1b2c3d4e5f6: Downloading [=====>      ]  12.5MB/25MB",
+			want: Event{
+				Phase: PhasePull, Layer: "a1b2c3d4e5f6", Status: "Downloading",
+				Current: 12_500_000, Total: 25_000_000, Percent: 50,
+				Message: "a1b2c3d4e5f6: Downloading [=====>      ]  12.5MB/25MB",
+			},
+		},
+		{
+			name: "podman blob progress",
+			line: "Copying blob 6e3729cf69e0aaaa [==>-----] 1.0MiB / 4.0MiB",
+			want: Event{
+				Phase: PhasePull, Layer: "6e3729cf69e0", Status: "Copying",
+				Current: 1 << 20, Total: 4 << 20, Percent: 25,
+				Message: "Copying blob 6e3729cf69e0aaaa [==>-----] 1.0MiB / 4.0MiB",
+			},
+		},
+		{
+			name: "podman blob done",
+			line: "Copying blob sha256:6e3729cf69e0aaaa done   |",
+			want: Event{Phase: PhasePull, Layer: "6e3729cf69e0", Status: "done", Message: "Copying blob sha256:6e3729cf69e0aaaa done   |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := NewParser().Parse(tt.line)
+
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.want, events[0])
+		})
+	}
+}
+
+func TestParser_Parse_Build(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Event
+	}{
+		{
+			name: "buildkit step",
+			line: "#5 [2/4] RUN apt-get update",
+			want: Event{Phase: PhaseBuild, Layer: "#5", Percent: 25, Message: "#5 [2/4] RUN apt-get update"},
+		},
+		{
+			name: "buildkit done",
+			line: "#5 DONE 3.2s",
+			want: Event{Phase: PhaseBuild, Layer: "#5", Status: "DONE", Message: "#5 DONE 3.2s"},
+		},
+		{
+			name: "buildkit layer download",
+			line: "#6 sha256:0123456789abcdef 10MB / 40MB 0.7s",
+			want: Event{
+				Phase: PhaseBuild, Layer: "0123456789ab", Current: 10_000_000, Total: 40_000_000, Percent: 25,
+				Message: "#6 sha256:0123456789abcdef 10MB / 40MB 0.7s",
+			},
+		},
+		{
+			name: "legacy builder step",
+			line: "Step 3/4 : RUN make",
+			want: Event{Phase: PhaseBuild, Layer: "step 3", Percent: 50, Message: "Step 3/4 : RUN make"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := NewParser().Parse(tt.line)
+
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.want, events[0])
+		})
+	}
+}
+
+func TestParser_Parse_Devcontainer(t *testing.T) {
+	p := NewParser()
+
+	events := p.Parse(`{"type":"start","level":2,"timestamp":1,"text":"Run: docker buildx build --load -f Dockerfile ."}`)
+	require.Len(t, events, 1)
+	assert.Equal(t, PhaseBuild, events[0].Phase)
+
+	// Raw output can hold several lines
+	events = p.Parse(`{"type":"raw","level":3,"timestamp":2,"text":"#5 [1/2] FROM ubuntu\n#5 DONE 0.1s\n"}`)
+	require.Len(t, events, 2)
+	assert.Equal(t, "#5", events[0].Layer)
+	assert.Equal(t, "DONE", events[1].Status)
+
+	events = p.Parse(`{"type":"progress","name":"Running postCreateCommand...","status":"running","stepDetail":"npm install"}`)
+	require.Len(t, events, 1)
+	assert.Equal(t, Event{Phase: PhaseSetup, Status: "running", Message: "Running postCreateCommand... npm install"}, events[0])
+
+	assert.Empty(t, p.Parse(`{"type":"stop","level":2,"timestamp":3,"text":"Run: docker run"}`))
+}
+
+func TestParser_Parse_KeepsPhase(t *testing.T) {
+	p := NewParser()
+
+	events := p.Parse("\x1b[1mcreating container\x1b[0m")
+	require.Len(t, events, 1)
+	assert.Equal(t, Event{Phase: PhaseCreate, Message: "creating container"}, events[0])
+
+	p.Parse("Trying to pull docker.io/library/ubuntu:24.04...")
+	events = p.Parse("some other output")
+	require.Len(t, events, 1)
+	assert.Equal(t, PhasePull, events[0].Phase)
+
+	assert.Empty(t, p.Parse("   "))
+}
+
+func TestEvent_Done(t *testing.T) {
+	assert.True(t, (&Event{Status: "Pull complete"}).Done())
+	assert.True(t, (&Event{Status: "DONE"}).Done())
+	assert.True(t, (&Event{Current: 10, Total: 10}).Done())
+	assert.False(t, (&Event{Status: "Downloading", Current: 5, Total: 10}).Done())
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512B":    512,
+		"1.5kB":   1500,
+		"12.3MB":  12_300_000,
+		"2GB":     2_000_000_000,
+		"1.0 MiB": 1 << 20,
+		"1GiB":    1 << 30,
+		"0B":      0,
+		"MB":      0,
+		"N/A":     0,
+		"":        0,
+	}
+
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, want, ParseSize(in))
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		0:             "0B",
+		999:           "999B",
+		1500:          "1.5kB",
+		77_900_000:    "77.9MB",
+		1_200_000_000: "1.2GB",
+	}
+
+	for in, want := range tests {
+		assert.Equal(t, want, FormatSize(in))
+	}
+}
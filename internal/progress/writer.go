@@ -0,0 +1,78 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Writer is an io.WriteCloser that parses the output written to it into
+// events. It can be used as RunConfig.Stderr.
+type Writer struct {
+	mu      sync.Mutex
+	parser  *Parser
+	handle  func(Event)
+	partial []byte // Incomplete last line
+}
+
+// NewWriter creates a Writer that calls handle for each event. handle is
+// called from the goroutine writing output and must not block for long.
+func NewWriter(handle func(Event)) *Writer {
+	return &Writer{parser: NewParser(), handle: handle}
+}
+
+// Write parses complete lines in p. An incomplete last line is kept until
+// more output or Close completes it.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(string(data[:i]))
+		data = data[i+1:]
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Close parses the incomplete last line, if any.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) > 0 {
+		w.emit(string(w.partial))
+		w.partial = nil
+	}
+	return nil
+}
+
+func (w *Writer) emit(line string) {
+	for _, ev := range w.parser.Parse(line) {
+		w.handle(ev)
+	}
+}
+
+// PlainHandler returns a handler that writes each event to out as a line of
+// text prefixed with its phase, e.g. "[pull] a1b2c3d4e5f6: Pull complete".
+func PlainHandler(out io.Writer) func(Event) {
+	return func(ev Event) {
+		_, _ = fmt.Fprintf(out, "[%s] %s\n", ev.Phase, ev.Message) //nolint:errcheck // progress output is best-effort
+	}
+}
+
+// JSONHandler returns a handler that writes each event to out as a line of
+// JSON.
+func JSONHandler(out io.Writer) func(Event) {
+	enc := json.NewEncoder(out)
+	return func(ev Event) {
+		_ = enc.Encode(ev) //nolint:errcheck // progress output is best-effort
+	}
+}
@@ -0,0 +1,53 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var events []Event
+	w := NewWriter(func(ev Event) { events = append(events, ev) })
+
+	_, err := w.Write([]byte("a1b2c3d4e5f6: Pull"))
+	require.NoError(t, err)
+	assert.Empty(t, events, "incomplete lines are kept")
+
+	_, err = w.Write([]byte(" complete\nStep 1/2 : FROM ubuntu\n\nlast"))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Pull complete", events[0].Status)
+	assert.Equal(t, PhaseBuild, events[1].Phase)
+
+	require.NoError(t, w.Close())
+	require.Len(t, events, 3)
+	assert.Equal(t, "last", events[2].Message)
+}
+
+func TestPlainHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handle := PlainHandler(&buf)
+
+	handle(Event{Phase: PhasePull, Layer: "a1b2c3d4e5f6", Message: "a1b2c3d4e5f6: Pull complete"})
+
+	assert.Equal(t, "[pull] a1b2c3d4e5f6: Pull complete\n", buf.String())
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handle := JSONHandler(&buf)
+
+	handle(Event{Phase: PhasePull, Layer: "a1b2c3d4e5f6", Current: 5, Total: 10, Percent: 50, Message: "m"})
+	handle(Event{Phase: PhaseCreate, Message: "n"})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var ev Event
+	require.NoError(t, json.Unmarshal(lines[0], &ev))
+	assert.Equal(t, Event{Phase: PhasePull, Layer: "a1b2c3d4e5f6", Current: 5, Total: 10, Percent: 50, Message: "m"}, ev)
+	assert.JSONEq(t, `{"phase":"create","message":"n"}`, string(lines[1]))
+}
@@ -0,0 +1,194 @@
+package ptyserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultDetachKeys is the default detach key sequence, matching the
+// Docker CLI.
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// ErrInvalidDetachKeys is returned when a detach key sequence cannot be parsed.
+var ErrInvalidDetachKeys = errors.New("invalid detach keys")
+
+// AttachOptions configures an attached client.
+type AttachOptions struct {
+	Stdin      io.Reader // Terminal input (required)
+	Stdout     io.Writer // Terminal output (required)
+	DetachKeys []byte    // Input sequence that detaches the client (optional)
+
+	// Size reports the client terminal size. If nil, the session keeps its
+	// current size.
+	Size func() (rows, cols uint16, ok bool)
+	// Resize receives a value whenever the client terminal is resized.
+	Resize <-chan os.Signal
+}
+
+// AttachResult describes how an attached client finished.
+type AttachResult struct {
+	Detached bool // The client detached; the session is still running
+	ExitCode int  // Exit code of the session command if it ended
+}
+
+// Attach connects to the named session and relays terminal input and output
+// until the client detaches or the session ends. The caller is responsible
+// for putting the terminal into raw mode.
+func Attach(dir, name string, opts AttachOptions) (*AttachResult, error) {
+	conn, err := dial(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	send := func(typ byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeFrame(conn, typ, payload)
+	}
+	sendSize := func() {
+		if opts.Size == nil {
+			return
+		}
+		if rows, cols, ok := opts.Size(); ok {
+			_ = send(frameResize, resizePayload(rows, cols))
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	sendSize()
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-opts.Resize:
+				sendSize()
+			}
+		}
+	}()
+
+	var detachOnce sync.Once
+	detached := make(chan struct{})
+	go func() {
+		filter := &detachFilter{keys: opts.DetachKeys}
+		buf := make([]byte, 4096)
+		for {
+			n, err := opts.Stdin.Read(buf)
+			if n > 0 {
+				out, hit := filter.feed(buf[:n])
+				if len(out) > 0 {
+					if sendErr := send(frameData, out); sendErr != nil {
+						return
+					}
+				}
+				if hit {
+					detachOnce.Do(func() { close(detached) })
+					_ = conn.Close()
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		typ, payload, err := readFrame(conn)
+		if err != nil {
+			select {
+			case <-detached:
+				return &AttachResult{Detached: true}, nil
+			default:
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return &AttachResult{ExitCode: -1}, nil
+			}
+			return nil, fmt.Errorf("read from session: %w", err)
+		}
+
+		switch typ {
+		case frameData:
+			if _, err := opts.Stdout.Write(payload); err != nil {
+				return nil, fmt.Errorf("write output: %w", err)
+			}
+		case frameExit:
+			return &AttachResult{ExitCode: parseExit(payload)}, nil
+		}
+	}
+}
+
+// ParseDetachKeys parses a comma-separated detach key sequence in the Docker
+// CLI format, where each key is a single character or ctrl-<key> (for
+// example "ctrl-p,ctrl-q").
+func ParseDetachKeys(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, ",")
+	keys := make([]byte, 0, len(parts))
+	for _, part := range parts {
+		if len(part) == 1 {
+			keys = append(keys, part[0])
+			continue
+		}
+
+		key, ok := strings.CutPrefix(strings.ToLower(part), "ctrl-")
+		if !ok || len(key) != 1 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDetachKeys, part)
+		}
+		switch c := key[0]; {
+		case c >= 'a' && c <= 'z':
+			keys = append(keys, c-'a'+1)
+		case c == '@', c >= '[' && c <= '_':
+			keys = append(keys, c-'@')
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDetachKeys, part)
+		}
+	}
+	return keys, nil
+}
+
+// detachFilter scans terminal input for the detach key sequence. Input that
+// partially matches the sequence is held back until the match either
+// completes or fails.
+type detachFilter struct {
+	keys    []byte
+	matched int
+}
+
+// feed returns the input to forward to the session and whether the detach
+// sequence was completed.
+func (f *detachFilter) feed(in []byte) ([]byte, bool) {
+	if len(f.keys) == 0 {
+		return in, false
+	}
+
+	out := make([]byte, 0, len(in)+f.matched)
+	for _, b := range in {
+		if f.matched > 0 && b != f.keys[f.matched] {
+			out = append(out, f.keys[:f.matched]...)
+			f.matched = 0
+		}
+		if b == f.keys[f.matched] {
+			f.matched++
+			if f.matched == len(f.keys) {
+				f.matched = 0
+				return out, true
+			}
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, false
+}
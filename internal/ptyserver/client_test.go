@@ -0,0 +1,88 @@
+package ptyserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDetachKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []byte
+		wantErr bool
+	}{
+		{"default", DefaultDetachKeys, []byte{0x10, 0x11}, false},
+		{"empty disables detach", "", nil, false},
+		{"single characters", "a,b", []byte{'a', 'b'}, false},
+		{"uppercase ctrl", "CTRL-A", []byte{0x01}, false},
+		{"ctrl punctuation", "ctrl-@,ctrl-\\,ctrl-_", []byte{0x00, 0x1c, 0x1f}, false},
+		{"unknown ctrl key", "ctrl-1", nil, true},
+		{"multi-character key", "esc", nil, true},
+		{"empty element", "ctrl-p,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDetachKeys(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidDetachKeys)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetachFilter(t *testing.T) {
+	t.Run("detects sequence within input", func(t *testing.T) {
+		f := &detachFilter{keys: []byte{0x10, 0x11}}
+
+		out, hit := f.feed([]byte{'a', 0x10, 0x11, 'b'})
+
+		assert.True(t, hit)
+		assert.Equal(t, []byte{'a'}, out)
+	})
+
+	t.Run("detects sequence split across reads", func(t *testing.T) {
+		f := &detachFilter{keys: []byte{0x10, 0x11}}
+
+		out, hit := f.feed([]byte{'a', 0x10})
+		assert.False(t, hit)
+		assert.Equal(t, []byte{'a'}, out)
+
+		out, hit = f.feed([]byte{0x11})
+		assert.True(t, hit)
+		assert.Empty(t, out)
+	})
+
+	t.Run("releases held input on mismatch", func(t *testing.T) {
+		f := &detachFilter{keys: []byte{0x10, 0x11}}
+
+		out, hit := f.feed([]byte{0x10, 'x'})
+
+		assert.False(t, hit)
+		assert.Equal(t, []byte{0x10, 'x'}, out)
+	})
+
+	t.Run("restarts match on repeated first key", func(t *testing.T) {
+		f := &detachFilter{keys: []byte{0x10, 0x11}}
+
+		out, hit := f.feed([]byte{0x10, 0x10, 0x11})
+
+		assert.True(t, hit)
+		assert.Equal(t, []byte{0x10}, out)
+	})
+
+	t.Run("passes input through without keys", func(t *testing.T) {
+		f := &detachFilter{}
+
+		out, hit := f.feed([]byte{0x10, 0x11})
+
+		assert.False(t, hit)
+		assert.Equal(t, []byte{0x10, 0x11}, out)
+	})
+}
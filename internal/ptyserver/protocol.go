@@ -0,0 +1,75 @@
+package ptyserver
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Frame types exchanged over a session socket. Each frame is a one-byte type,
+// a big-endian uint32 payload length, and the payload.
+const (
+	frameData   byte = 'd' // Terminal bytes, in either direction
+	frameResize byte = 'r' // Client to server: rows and columns as two uint16
+	frameKill   byte = 'k' // Client to server: terminate the session
+	frameExit   byte = 'x' // Server to client: session ended, exit code as int32
+)
+
+// maxFrameSize bounds frame payloads so a misbehaving peer cannot force
+// large allocations.
+const maxFrameSize = 1 << 20
+
+var errFrameTooLarge = errors.New("frame too large")
+
+func writeFrame(w io.Writer, typ byte, payload []byte) error {
+	buf := make([]byte, 5+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload))) //nolint:gosec // payloads are far below 4GiB
+	copy(buf[5:], payload)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxFrameSize {
+		return 0, nil, fmt.Errorf("%w: %d bytes", errFrameTooLarge, n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return hdr[0], payload, nil
+}
+
+func resizePayload(rows, cols uint16) []byte {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint16(p[0:2], rows)
+	binary.BigEndian.PutUint16(p[2:4], cols)
+	return p
+}
+
+func parseResize(p []byte) (rows, cols uint16, ok bool) {
+	if len(p) != 4 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint16(p[0:2]), binary.BigEndian.Uint16(p[2:4]), true
+}
+
+func exitPayload(code int) []byte {
+	p := make([]byte, 4)
+	binary.BigEndian.PutUint32(p, uint32(int32(code))) //nolint:gosec // exit codes fit in int32
+	return p
+}
+
+func parseExit(p []byte) int {
+	if len(p) != 4 {
+		return -1
+	}
+	return int(int32(binary.BigEndian.Uint32(p))) //nolint:gosec // round-trips exitPayload
+}
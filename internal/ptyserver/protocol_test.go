@@ -0,0 +1,51 @@
+package ptyserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, frameData, []byte("hello")))
+	require.NoError(t, writeFrame(&buf, frameResize, resizePayload(24, 80)))
+	require.NoError(t, writeFrame(&buf, frameExit, exitPayload(-1)))
+
+	typ, payload, err := readFrame(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, frameData, typ)
+	assert.Equal(t, []byte("hello"), payload)
+
+	typ, payload, err = readFrame(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, frameResize, typ)
+	rows, cols, ok := parseResize(payload)
+	require.True(t, ok)
+	assert.Equal(t, uint16(24), rows)
+	assert.Equal(t, uint16(80), cols)
+
+	typ, payload, err = readFrame(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, frameExit, typ)
+	assert.Equal(t, -1, parseExit(payload))
+}
+
+func TestReadFrame_TooLarge(t *testing.T) {
+	hdr := make([]byte, 5)
+	hdr[0] = frameData
+	binary.BigEndian.PutUint32(hdr[1:], maxFrameSize+1)
+
+	_, _, err := readFrame(bytes.NewReader(hdr))
+
+	require.ErrorIs(t, err, errFrameTooLarge)
+}
+
+func TestParseResize_Invalid(t *testing.T) {
+	_, _, ok := parseResize([]byte{1, 2})
+
+	assert.False(t, ok)
+}
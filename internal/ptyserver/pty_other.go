@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package ptyserver
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY is not implemented on this platform.
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,176 @@
+// Package ptyserver implements a minimal terminal session server.
+//
+// Each session is a background process that owns a pseudo-terminal running a
+// single command, records the terminal output to a log file, and accepts
+// clients over a Unix socket. Clients attach to send input and receive
+// output, and detach with a key sequence while the session keeps running.
+package ptyserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors for session operations.
+var (
+	ErrSessionExists   = errors.New("session already exists")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidName     = errors.New("invalid session name")
+	ErrUnsafeDir       = errors.New("unsafe socket directory")
+)
+
+// socketExt is the file extension of session sockets.
+const socketExt = ".sock"
+
+// dialTimeout bounds how long a client waits to connect to a session.
+const dialTimeout = 2 * time.Second
+
+// killTimeout is how long Kill waits for a session to exit.
+const killTimeout = 5 * time.Second
+
+// DefaultDir returns the directory holding session sockets:
+// $XDG_RUNTIME_DIR/headjack if set, otherwise headjack-<uid> in the
+// system temp directory.
+func DefaultDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "headjack")
+	}
+	return filepath.Join(os.TempDir(), "headjack-"+strconv.Itoa(os.Getuid()))
+}
+
+// EnsureDir creates the socket directory if needed and verifies that it is a
+// private directory owned by the current user, so other users cannot plant
+// or read session sockets.
+func EnsureDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create socket directory: %w", err)
+	}
+
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("stat socket directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrUnsafeDir, dir)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%w: %s is not owned by the current user", ErrUnsafeDir, dir)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%w: %s is accessible by other users", ErrUnsafeDir, dir)
+	}
+	return nil
+}
+
+// ValidateName checks that name can be used as a session socket name.
+func ValidateName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// SocketPath returns the socket path for the named session.
+func SocketPath(dir, name string) string {
+	return filepath.Join(dir, name+socketExt)
+}
+
+// List returns the names of running sessions in dir, sorted by name.
+// Sockets left behind by sessions that exited uncleanly are removed.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("read socket directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), socketExt)
+		if !ok || entry.Type()&os.ModeSocket == 0 {
+			continue
+		}
+		conn, err := dial(dir, name)
+		if err != nil {
+			continue
+		}
+		_ = conn.Close()
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Kill terminates the named session and waits for it to exit.
+func Kill(dir, name string) error {
+	conn, err := dial(dir, name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := writeFrame(conn, frameKill, nil); err != nil {
+		return fmt.Errorf("send kill: %w", err)
+	}
+
+	// The server closes the connection once the session has exited.
+	_ = conn.SetReadDeadline(time.Now().Add(killTimeout))
+	for {
+		typ, _, err := readFrame(conn)
+		if err != nil || typ == frameExit {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return fmt.Errorf("session %s did not exit within %s", name, killTimeout)
+			}
+			return nil
+		}
+	}
+}
+
+// Send writes data to the terminal of the named session as if it were typed
+// by an attached client.
+func Send(dir, name string, data []byte) error {
+	conn, err := dial(dir, name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := writeFrame(conn, frameData, data); err != nil {
+		return fmt.Errorf("send input: %w", err)
+	}
+	return nil
+}
+
+// dial connects to the named session. A missing or stale socket is reported
+// as ErrSessionNotFound, and stale sockets are removed.
+func dial(dir, name string) (net.Conn, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	path := SocketPath(dir, name)
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			_ = os.Remove(path)
+			return nil, ErrSessionNotFound
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("connect to session: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,84 @@
+package ptyserver
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultDir(t *testing.T) {
+	t.Run("uses XDG_RUNTIME_DIR", func(t *testing.T) {
+		t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+		assert.Equal(t, "/run/user/1000/headjack", DefaultDir())
+	})
+
+	t.Run("falls back to temp dir", func(t *testing.T) {
+		t.Setenv("XDG_RUNTIME_DIR", "")
+
+		assert.Contains(t, DefaultDir(), "headjack-")
+	})
+}
+
+func TestEnsureDir(t *testing.T) {
+	t.Run("creates private directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "sockets")
+
+		require.NoError(t, EnsureDir(dir))
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	})
+
+	t.Run("rejects directory accessible by others", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "shared")
+		require.NoError(t, os.Mkdir(dir, 0o700))
+		require.NoError(t, os.Chmod(dir, 0o755)) //nolint:gosec // testing an insecure directory
+
+		err := EnsureDir(dir)
+
+		require.ErrorIs(t, err, ErrUnsafeDir)
+	})
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("hjk-abc1234-main"))
+	assert.ErrorIs(t, ValidateName(""), ErrInvalidName)
+	assert.ErrorIs(t, ValidateName("../escape"), ErrInvalidName)
+	assert.ErrorIs(t, ValidateName(".hidden"), ErrInvalidName)
+}
+
+func TestList_RemovesStaleSockets(t *testing.T) {
+	dir := t.TempDir()
+	path := SocketPath(dir, "stale")
+
+	// Leave a socket file behind without a listener.
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+
+	names, err := List(dir)
+
+	require.NoError(t, err)
+	assert.Empty(t, names)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestKill_SessionNotFound(t *testing.T) {
+	err := Kill(t.TempDir(), "missing")
+
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSend_SessionNotFound(t *testing.T) {
+	err := Send(t.TempDir(), "missing", []byte("hello"))
+
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
@@ -0,0 +1,285 @@
+package ptyserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	osexec "os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// clientWriteTimeout bounds how long a slow client may stall session output
+// before it is disconnected.
+const clientWriteTimeout = 5 * time.Second
+
+// hangupGrace is how long a killed session has to exit after SIGHUP before
+// it is sent SIGKILL.
+const hangupGrace = 3 * time.Second
+
+// outputDrainTimeout bounds how long Run waits for remaining terminal output
+// after the command exits. Background processes holding the terminal open
+// would otherwise keep the session alive.
+const outputDrainTimeout = time.Second
+
+// Config describes a session to serve.
+type Config struct {
+	Dir     string   // Socket directory (required, see DefaultDir)
+	Name    string   // Session name (required)
+	Command []string // Command to run (optional, defaults to $SHELL)
+	Cwd     string   // Working directory (optional)
+	Env     []string // Additional environment variables (KEY=VALUE format)
+	LogPath string   // File that terminal output is appended to (optional)
+
+	// LogRotation configures size-based rotation of the log (optional,
+	// the zero value never rotates).
+	LogRotation logging.RotateConfig
+	// LogTranscript also writes a plain-text transcript next to the log.
+	LogTranscript bool
+}
+
+// Server owns the terminal of a single running session.
+type Server struct {
+	ln     net.Listener
+	master *os.File
+	cmd    *osexec.Cmd
+	log    io.WriteCloser
+
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+
+	killOnce sync.Once
+	exited   chan struct{}
+}
+
+// Listen starts the session command on a new pseudo-terminal and begins
+// listening on the session socket. Returns ErrSessionExists if a session with
+// the same name is already running. Call Run to serve clients.
+func Listen(cfg Config) (_ *Server, err error) {
+	if err := ValidateName(cfg.Name); err != nil {
+		return nil, err
+	}
+	if err := EnsureDir(cfg.Dir); err != nil {
+		return nil, err
+	}
+
+	if conn, err := dial(cfg.Dir, cfg.Name); err == nil {
+		_ = conn.Close()
+		return nil, ErrSessionExists
+	}
+
+	path := SocketPath(cfg.Dir, cfg.Name)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	s := &Server{ln: ln, clients: make(map[net.Conn]struct{}), exited: make(chan struct{})}
+	defer func() {
+		if err != nil {
+			s.close()
+		}
+	}()
+
+	if cfg.LogPath != "" {
+		s.log, err = logging.OpenSessionLog(cfg.LogPath, cfg.LogRotation, cfg.LogTranscript)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	s.master = master
+	defer slave.Close()
+
+	command := cfg.Command
+	if len(command) == 0 {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		command = []string{shell}
+	}
+
+	s.cmd = osexec.Command(command[0], command[1:]...) //nolint:gosec // running the session command is the purpose of the server
+	s.cmd.Dir = cfg.Cwd
+	s.cmd.Env = append(os.Environ(), cfg.Env...)
+	s.cmd.Stdin = slave
+	s.cmd.Stdout = slave
+	s.cmd.Stderr = slave
+	s.cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err := s.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start command: %w", err)
+	}
+
+	return s, nil
+}
+
+// Run serves clients until the session command exits and returns its exit
+// code. The socket is removed before Run returns.
+func (s *Server) Run() int {
+	outputDone := make(chan struct{})
+	go func() {
+		s.pumpOutput()
+		close(outputDone)
+	}()
+	go s.acceptLoop()
+
+	code := exitCode(s.cmd.Wait())
+	close(s.exited)
+
+	select {
+	case <-outputDone:
+	case <-time.After(outputDrainTimeout):
+	}
+
+	_ = s.ln.Close()
+	s.broadcast(frameExit, exitPayload(code))
+	s.close()
+	return code
+}
+
+// pumpOutput copies terminal output to the log and attached clients until
+// the terminal is closed.
+func (s *Server) pumpOutput() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.master.Read(buf)
+		if n > 0 {
+			if s.log != nil {
+				_, _ = s.log.Write(buf[:n])
+			}
+			s.broadcast(frameData, buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads frames from a client until it disconnects.
+func (s *Server) handle(conn net.Conn) {
+	s.mu.Lock()
+	s.clients[conn] = struct{}{}
+	s.mu.Unlock()
+	defer s.drop(conn)
+
+	for {
+		typ, payload, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+		switch typ {
+		case frameData:
+			_, _ = s.master.Write(payload)
+		case frameResize:
+			if rows, cols, ok := parseResize(payload); ok {
+				s.resize(rows, cols)
+			}
+		case frameKill:
+			s.terminate()
+		}
+	}
+}
+
+// resize sets the terminal size and signals the foreground process group.
+// The signal is sent even when the size is unchanged so that a newly
+// attached client gets a redraw.
+func (s *Server) resize(rows, cols uint16) {
+	fd := int(s.master.Fd())
+	_ = unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	if err != nil || pgrp <= 0 {
+		pgrp = s.cmd.Process.Pid
+	}
+	_ = syscall.Kill(-pgrp, syscall.SIGWINCH)
+}
+
+// terminate hangs up the session command, escalating to SIGKILL if it has
+// not exited after hangupGrace.
+func (s *Server) terminate() {
+	s.killOnce.Do(func() {
+		pgid := s.cmd.Process.Pid
+		_ = syscall.Kill(-pgid, syscall.SIGHUP)
+		go func() {
+			select {
+			case <-s.exited:
+			case <-time.After(hangupGrace):
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			}
+		}()
+	})
+}
+
+// broadcast sends a frame to every attached client, disconnecting clients
+// that cannot keep up.
+func (s *Server) broadcast(typ byte, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.clients {
+		_ = conn.SetWriteDeadline(time.Now().Add(clientWriteTimeout))
+		if err := writeFrame(conn, typ, payload); err != nil {
+			_ = conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+}
+
+func (s *Server) drop(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.clients, conn)
+	s.mu.Unlock()
+	_ = conn.Close()
+}
+
+// close releases the listener, clients, terminal and log file.
+func (s *Server) close() {
+	_ = s.ln.Close()
+
+	s.mu.Lock()
+	for conn := range s.clients {
+		_ = conn.Close()
+	}
+	s.clients = map[net.Conn]struct{}{}
+	s.mu.Unlock()
+
+	if s.master != nil {
+		_ = s.master.Close()
+	}
+	if s.log != nil {
+		_ = s.log.Close()
+	}
+}
+
+// exitCode extracts the exit code from a command wait error.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *osexec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,172 @@
+package ptyserver
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// socketDir returns a fresh private socket directory.
+func socketDir(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "sockets")
+}
+
+// startServer starts a session and serves it in the background. The returned
+// channel receives the command's exit code.
+func startServer(t *testing.T, cfg Config) <-chan int {
+	t.Helper()
+
+	s, err := Listen(cfg)
+	require.NoError(t, err)
+
+	done := make(chan int, 1)
+	go func() { done <- s.Run() }()
+	return done
+}
+
+func TestServer_AttachRelaysIO(t *testing.T) {
+	dir := socketDir(t)
+	logPath := filepath.Join(t.TempDir(), "session.log")
+	done := startServer(t, Config{
+		Dir:     dir,
+		Name:    "io",
+		Command: []string{"/bin/sh", "-c", "read line; echo got:$line; exit 3"},
+		LogPath: logPath,
+	})
+
+	stdinR, stdinW := io.Pipe()
+	defer stdinW.Close()
+	var stdout syncBuffer
+
+	resultCh := make(chan *AttachResult, 1)
+	go func() {
+		result, err := Attach(dir, "io", AttachOptions{
+			Stdin:  stdinR,
+			Stdout: &stdout,
+			Size:   func() (uint16, uint16, bool) { return 24, 80, true },
+		})
+		assert.NoError(t, err)
+		resultCh <- result
+	}()
+
+	_, err := stdinW.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	select {
+	case result := <-resultCh:
+		require.NotNil(t, result)
+		assert.False(t, result.Detached)
+		assert.Equal(t, 3, result.ExitCode)
+	case <-time.After(10 * time.Second):
+		t.Fatal("attach did not return after session exit")
+	}
+	assert.Equal(t, 3, <-done)
+	assert.Contains(t, stdout.String(), "got:hello")
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "got:hello")
+
+	_, err = os.Stat(SocketPath(dir, "io"))
+	assert.True(t, os.IsNotExist(err), "socket should be removed on exit")
+}
+
+func TestServer_DetachListKill(t *testing.T) {
+	dir := socketDir(t)
+	done := startServer(t, Config{Dir: dir, Name: "long", Command: []string{"cat"}})
+
+	_, err := Listen(Config{Dir: dir, Name: "long", Command: []string{"cat"}})
+	require.ErrorIs(t, err, ErrSessionExists)
+
+	keys, err := ParseDetachKeys(DefaultDetachKeys)
+	require.NoError(t, err)
+	result, err := Attach(dir, "long", AttachOptions{
+		Stdin:      bytes.NewReader(append([]byte("abc"), keys...)),
+		Stdout:     io.Discard,
+		DetachKeys: keys,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Detached)
+
+	names, err := List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long"}, names)
+
+	require.NoError(t, Kill(dir, "long"))
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not exit after kill")
+	}
+
+	names, err = List(dir)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestSend_WritesInput(t *testing.T) {
+	dir := socketDir(t)
+	logPath := filepath.Join(t.TempDir(), "session.log")
+	done := startServer(t, Config{
+		Dir:     dir,
+		Name:    "input",
+		Command: []string{"/bin/sh", "-c", "read line; echo got:$line"},
+		LogPath: logPath,
+	})
+
+	require.NoError(t, Send(dir, "input", []byte("hello\r")))
+
+	select {
+	case code := <-done:
+		assert.Equal(t, 0, code)
+	case <-time.After(10 * time.Second):
+		t.Fatal("session did not exit after input")
+	}
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "got:hello")
+}
+
+func TestListen_StartFailure(t *testing.T) {
+	dir := socketDir(t)
+
+	_, err := Listen(Config{Dir: dir, Name: "bad", Command: []string{"true"}, Cwd: "/nonexistent"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start command")
+	_, statErr := os.Stat(SocketPath(dir, "bad"))
+	assert.True(t, os.IsNotExist(statErr), "socket should be removed on failure")
+}
+
+func TestAttach_SessionNotFound(t *testing.T) {
+	_, err := Attach(t.TempDir(), "missing", AttachOptions{Stdin: bytes.NewReader(nil), Stdout: io.Discard})
+
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
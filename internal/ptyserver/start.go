@@ -0,0 +1,88 @@
+package ptyserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"syscall"
+)
+
+// notifyEnv marks a server process started by Start. The server reports
+// startup status on notifyFD.
+const notifyEnv = "HEADJACK_PTYSERVER_NOTIFY"
+
+// notifyFD is the file descriptor of the startup status pipe in a server
+// process started by Start (the first entry of ExtraFiles).
+const notifyFD = 3
+
+// readyMessage is written to the status pipe once the server is listening.
+const readyMessage = "ready"
+
+// Start runs binary with args as a detached background server and waits for
+// it to report that it is listening. The server must call TakeNotifier and
+// Notify to report its startup status. Startup errors reported by the server
+// are returned with their original message.
+func Start(binary string, args []string) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("create status pipe: %w", err)
+	}
+	defer r.Close()
+
+	cmd := osexec.Command(binary, args...) //nolint:gosec // binary is the headjack executable
+	cmd.Env = append(os.Environ(), notifyEnv+"=1")
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("start session server: %w", err)
+	}
+	_ = w.Close()
+
+	status, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read session server status: %w", err)
+	}
+
+	switch msg := strings.TrimSpace(string(status)); msg {
+	case readyMessage:
+		return cmd.Process.Release()
+	case "":
+		_ = cmd.Wait()
+		return errors.New("session server exited during startup")
+	default:
+		_ = cmd.Wait()
+		return errors.New(msg)
+	}
+}
+
+// TakeNotifier returns the startup status pipe if the current process was
+// started by Start, or nil otherwise. It removes the marker from the
+// environment so the session command does not inherit it.
+func TakeNotifier() *os.File {
+	if os.Getenv(notifyEnv) == "" {
+		return nil
+	}
+	_ = os.Unsetenv(notifyEnv)
+	// Keep the pipe out of the session command, or Start would wait for
+	// the command to exit.
+	syscall.CloseOnExec(notifyFD)
+	return os.NewFile(notifyFD, "notify")
+}
+
+// Notify reports the startup status on f and closes it. A nil err reports
+// that the server is ready. Notify is a no-op if f is nil.
+func Notify(f *os.File, err error) {
+	if f == nil {
+		return
+	}
+	msg := readyMessage
+	if err != nil {
+		msg = err.Error()
+	}
+	_, _ = f.WriteString(msg)
+	_ = f.Close()
+}
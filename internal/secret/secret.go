@@ -0,0 +1,195 @@
+// Package secret provides per-repository storage for project secrets
+// (e.g., NPM_TOKEN, database URLs) that are injected into sessions as
+// environment variables.
+//
+// Secrets are stored in the system keychain, namespaced by repository
+// identifier. Configuration refers to them by name using the "secret:<name>"
+// syntax, which is resolved when a session is created.
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+)
+
+// RefPrefix marks an environment value as a reference to a stored secret.
+const RefPrefix = "secret:"
+
+// Sentinel errors for secret operations.
+var (
+	ErrNotFound    = errors.New("secret not found")
+	ErrInvalidName = errors.New("invalid secret name")
+)
+
+// namePattern restricts secret names to characters that are safe in keychain account names.
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Store provides secret storage scoped to a single repository.
+type Store struct {
+	kc    keychain.Keychain
+	scope string
+}
+
+// NewStore creates a Store for the given scope (typically a repository identifier).
+func NewStore(kc keychain.Keychain, scope string) *Store {
+	return &Store{kc: kc, scope: scope}
+}
+
+// ValidateName checks that a secret name is usable as part of a keychain account.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q (use letters, digits, '.', '-' or '_')", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Set stores a secret, replacing any existing value.
+func (s *Store) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := s.kc.Set(s.account(name), value); err != nil {
+		return fmt.Errorf("store secret: %w", err)
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == name {
+			return nil
+		}
+	}
+	return s.writeIndex(append(names, name))
+}
+
+// Get retrieves a secret value.
+// Returns ErrNotFound if the secret does not exist.
+func (s *Store) Get(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	value, err := s.kc.Get(s.account(name))
+	if errors.Is(err, keychain.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get secret: %w", err)
+	}
+	return value, nil
+}
+
+// Delete removes a secret.
+// Returns ErrNotFound if the secret does not exist.
+func (s *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	names, err := s.List()
+	if err != nil {
+		return err
+	}
+	remaining := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			remaining = append(remaining, n)
+		}
+	}
+	if len(remaining) == len(names) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	if err := s.kc.Delete(s.account(name)); err != nil {
+		return fmt.Errorf("delete secret: %w", err)
+	}
+	return s.writeIndex(remaining)
+}
+
+// List returns the names of all secrets in this scope, sorted alphabetically.
+func (s *Store) List() ([]string, error) {
+	data, err := s.kc.Get(s.indexAccount())
+	if errors.Is(err, keychain.ErrNotFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secret index: %w", err)
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(data), &names); err != nil {
+		return nil, fmt.Errorf("parse secret index: %w", err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Resolve returns a copy of env with all "secret:<name>" values replaced by
+// the stored secret. Values without the prefix are returned unchanged.
+func (s *Store) Resolve(env map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(env))
+	for k, v := range env {
+		name, ok := ParseRef(v)
+		if !ok {
+			resolved[k] = v
+			continue
+		}
+		value, err := s.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", k, err)
+		}
+		resolved[k] = value
+	}
+	return resolved, nil
+}
+
+// ParseRef extracts the secret name from a "secret:<name>" reference.
+// Returns false if value is not a secret reference.
+func ParseRef(value string) (string, bool) {
+	if !strings.HasPrefix(value, RefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, RefPrefix), true
+}
+
+// HasRefs reports whether any value in env is a secret reference.
+func HasRefs(env map[string]string) bool {
+	for _, v := range env {
+		if _, ok := ParseRef(v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// account returns the keychain account for a secret.
+// Format: secret:<scope>:<name>
+func (s *Store) account(name string) string {
+	return fmt.Sprintf("secret:%s:%s", s.scope, name)
+}
+
+// indexAccount returns the keychain account holding the list of secret names.
+// The keychain cannot enumerate entries, so the names are tracked separately.
+func (s *Store) indexAccount() string {
+	return "secrets:" + s.scope
+}
+
+// writeIndex persists the list of secret names.
+func (s *Store) writeIndex(names []string) error {
+	sort.Strings(names)
+	data, err := json.Marshal(names)
+	if err != nil {
+		return fmt.Errorf("marshal secret index: %w", err)
+	}
+	if err := s.kc.Set(s.indexAccount(), string(data)); err != nil {
+		return fmt.Errorf("write secret index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/keychain"
+	"github.com/jmgilman/headjack/internal/keychain/mocks"
+)
+
+// newMemoryKeychain returns a KeychainMock backed by an in-memory map.
+func newMemoryKeychain() (*mocks.KeychainMock, map[string]string) {
+	data := make(map[string]string)
+	return &mocks.KeychainMock{
+		SetFunc: func(account, secret string) error {
+			data[account] = secret
+			return nil
+		},
+		GetFunc: func(account string) (string, error) {
+			v, ok := data[account]
+			if !ok {
+				return "", keychain.ErrNotFound
+			}
+			return v, nil
+		},
+		DeleteFunc: func(account string) error {
+			delete(data, account)
+			return nil
+		},
+	}, data
+}
+
+func TestStore_SetGet(t *testing.T) {
+	kc, data := newMemoryKeychain()
+	store := NewStore(kc, "myrepo-abc1234")
+
+	require.NoError(t, store.Set("npm", "token-123"))
+
+	value, err := store.Get("npm")
+	require.NoError(t, err)
+	assert.Equal(t, "token-123", value)
+	assert.Equal(t, "token-123", data["secret:myrepo-abc1234:npm"])
+}
+
+func TestStore_ScopedPerRepo(t *testing.T) {
+	kc, _ := newMemoryKeychain()
+	a := NewStore(kc, "repo-a")
+	b := NewStore(kc, "repo-b")
+
+	require.NoError(t, a.Set("npm", "token-a"))
+
+	_, err := b.Get("npm")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	names, err := b.List()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestStore_List(t *testing.T) {
+	kc, _ := newMemoryKeychain()
+	store := NewStore(kc, "repo")
+
+	require.NoError(t, store.Set("npm", "a"))
+	require.NoError(t, store.Set("db-url", "b"))
+	require.NoError(t, store.Set("npm", "c")) // overwrite does not duplicate
+
+	names, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-url", "npm"}, names)
+}
+
+func TestStore_Delete(t *testing.T) {
+	t.Run("removes secret and index entry", func(t *testing.T) {
+		kc, _ := newMemoryKeychain()
+		store := NewStore(kc, "repo")
+		require.NoError(t, store.Set("npm", "a"))
+		require.NoError(t, store.Set("db-url", "b"))
+
+		require.NoError(t, store.Delete("npm"))
+
+		_, err := store.Get("npm")
+		require.ErrorIs(t, err, ErrNotFound)
+		names, err := store.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db-url"}, names)
+	})
+
+	t.Run("returns ErrNotFound for unknown secret", func(t *testing.T) {
+		kc, _ := newMemoryKeychain()
+		store := NewStore(kc, "repo")
+
+		err := store.Delete("missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestStore_InvalidName(t *testing.T) {
+	kc, _ := newMemoryKeychain()
+	store := NewStore(kc, "repo")
+
+	assert.ErrorIs(t, store.Set("bad:name", "x"), ErrInvalidName)
+	_, err := store.Get("")
+	assert.ErrorIs(t, err, ErrInvalidName)
+}
+
+func TestStore_Resolve(t *testing.T) {
+	kc, _ := newMemoryKeychain()
+	store := NewStore(kc, "repo")
+	require.NoError(t, store.Set("npm", "token-123"))
+
+	t.Run("replaces references and keeps literals", func(t *testing.T) {
+		resolved, err := store.Resolve(map[string]string{
+			"NPM_TOKEN": "secret:npm",
+			"DEBUG":     "1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"NPM_TOKEN": "token-123", "DEBUG": "1"}, resolved)
+	})
+
+	t.Run("errors on missing secret", func(t *testing.T) {
+		_, err := store.Resolve(map[string]string{"DB_URL": "secret:db"})
+		require.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorContains(t, err, "DB_URL")
+	})
+
+	t.Run("propagates keychain errors", func(t *testing.T) {
+		failing := NewStore(&mocks.KeychainMock{
+			GetFunc: func(string) (string, error) { return "", errors.New("locked") },
+		}, "repo")
+		_, err := failing.Resolve(map[string]string{"X": "secret:npm"})
+		assert.ErrorContains(t, err, "locked")
+	})
+}
+
+func TestHasRefs(t *testing.T) {
+	assert.True(t, HasRefs(map[string]string{"A": "1", "B": "secret:b"}))
+	assert.False(t, HasRefs(map[string]string{"A": "1"}))
+	assert.False(t, HasRefs(nil))
+}
@@ -0,0 +1,248 @@
+package spinner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"github.com/jmgilman/headjack/internal/progress"
+)
+
+// maxLayers is the number of layers shown at once. Older layers scroll off.
+const maxLayers = 6
+
+// barWidth is the width of layer progress bars in cells.
+const barWidth = 20
+
+// phaseTitles are the headings shown for each phase.
+var phaseTitles = map[progress.Phase]string{
+	progress.PhasePull:   "Pulling image",
+	progress.PhaseBuild:  "Building image",
+	progress.PhaseCreate: "Creating container",
+	progress.PhaseSetup:  "Running lifecycle commands",
+}
+
+// Progress displays structured progress events on multiple lines: a spinner
+// with the current phase and latest message, followed by a line for each
+// image layer or build step in progress, with a bar when its size is known.
+type Progress struct {
+	program *tea.Program
+	events  chan progress.Event
+	done    chan struct{}
+	stop    sync.Once
+	output  io.Writer
+}
+
+// NewProgress creates a Progress that writes to the given output (typically
+// os.Stderr). If output is nil, os.Stderr is used.
+func NewProgress(output io.Writer) *Progress {
+	if output == nil {
+		output = os.Stderr
+	}
+	return &Progress{
+		events: make(chan progress.Event, 100), // Buffer to avoid blocking the output writer
+		done:   make(chan struct{}),
+		output: output,
+	}
+}
+
+// Handle queues an event for display. It can be passed to progress.NewWriter
+// and is safe to call from any goroutine, including after Stop.
+func (p *Progress) Handle(ev progress.Event) {
+	select {
+	case p.events <- ev:
+	case <-p.done:
+	}
+}
+
+// Start begins the display. This blocks until Stop() is called.
+func (p *Progress) Start() error {
+	width := 80 // default
+	if fd := int(os.Stderr.Fd()); term.IsTerminal(fd) {
+		if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+			width = w
+		}
+	}
+
+	p.program = tea.NewProgram(newProgressModel(p.events, p.done, width),
+		tea.WithOutput(p.output),
+		tea.WithoutSignalHandler(), // Let parent handle signals
+	)
+	_, err := p.program.Run()
+	return err
+}
+
+// Stop stops the display and clears it from the terminal.
+func (p *Progress) Stop() {
+	p.stop.Do(func() {
+		close(p.done)
+		if p.program != nil {
+			p.program.Quit()
+		}
+	})
+}
+
+// layerState is the latest event of a layer or build step.
+type layerState struct {
+	id    string
+	event progress.Event
+}
+
+// progressModel is the bubbletea model for Progress.
+type progressModel struct {
+	spinner  spinner.Model
+	phase    progress.Phase
+	message  string
+	layers   []layerState // In order of first appearance
+	width    int
+	events   <-chan progress.Event
+	done     <-chan struct{}
+	quitting bool
+}
+
+// eventMsg is sent when a progress event is received.
+type eventMsg progress.Event
+
+func newProgressModel(events <-chan progress.Event, done <-chan struct{}, width int) progressModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	return progressModel{
+		spinner: s,
+		phase:   progress.PhaseCreate,
+		width:   width,
+		events:  events,
+		done:    done,
+	}
+}
+
+// Init implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m progressModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, waitForEvent(m.events, m.done))
+}
+
+// Update implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+
+	case eventMsg:
+		m.apply(progress.Event(msg))
+		return m, waitForEvent(m.events, m.done)
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.QuitMsg:
+		m.quitting = true
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// apply records an event. A new phase starts with no layers; completed
+// layers are dropped.
+func (m *progressModel) apply(ev progress.Event) {
+	if ev.Phase != m.phase {
+		m.phase = ev.Phase
+		m.layers = nil
+	}
+	m.message = ev.Message
+	if ev.Layer == "" {
+		return
+	}
+
+	i := 0
+	for i < len(m.layers) && m.layers[i].id != ev.Layer {
+		i++
+	}
+	switch {
+	case ev.Done():
+		if i < len(m.layers) {
+			m.layers = append(m.layers[:i], m.layers[i+1:]...)
+		}
+	case i < len(m.layers):
+		m.layers[i].event = ev
+	default:
+		m.layers = append(m.layers, layerState{id: ev.Layer, event: ev})
+		if len(m.layers) > maxLayers {
+			m.layers = m.layers[1:]
+		}
+	}
+}
+
+// View implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m progressModel) View() string {
+	if m.quitting {
+		return "" // Clear the display on exit
+	}
+
+	title := phaseTitles[m.phase]
+	if title == "" {
+		title = string(m.phase)
+	}
+	header := m.spinner.View() + " " + title
+	if m.message != "" {
+		header += ": " + m.message
+	}
+
+	lines := []string{truncate(header, m.width)}
+	for _, l := range m.layers {
+		lines = append(lines, truncate("  "+formatLayer(&l.event), m.width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatLayer formats a layer line: its ID and status, and a bar with sizes
+// when its size is known.
+func formatLayer(ev *progress.Event) string {
+	if ev.Total <= 0 {
+		// Build steps carry their command in the message
+		if ev.Status == "" {
+			return ev.Message
+		}
+		return ev.Layer + " " + ev.Status
+	}
+
+	filled := int(ev.Percent / 100 * barWidth)
+	filled = max(0, min(filled, barWidth))
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	return fmt.Sprintf("%s %-12s [%s] %5.1f%% %s/%s",
+		ev.Layer, ev.Status, bar, ev.Percent, progress.FormatSize(ev.Current), progress.FormatSize(ev.Total))
+}
+
+// waitForEvent returns a command that waits for the next progress event.
+func waitForEvent(events <-chan progress.Event, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case ev := <-events:
+			return eventMsg(ev)
+		case <-done:
+			return tea.Quit()
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package tui
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/logging"
+)
+
+// Default terminal size used until the first WindowSizeMsg.
+const (
+	defaultWidth  = 100
+	defaultHeight = 30
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	paneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+)
+
+// helpText lists the key bindings.
+const helpText = "↑/↓ move • enter attach • n new shell • x kill session • s stop • d remove • r refresh • q quit"
+
+// row is a line of the instance list: an instance, or one of its sessions.
+type row struct {
+	inst    instance.Instance
+	session *instance.Session // nil for instance rows
+}
+
+// key identifies the row across refreshes.
+func (r row) key() string {
+	if r.session == nil {
+		return r.inst.ID
+	}
+	return r.inst.ID + "/" + r.session.Name
+}
+
+// confirmation is a destructive action awaiting a yes/no answer.
+type confirmation struct {
+	prompt string
+	run    tea.Cmd
+}
+
+type (
+	// tickMsg triggers a background refresh.
+	tickMsg struct{}
+	// loadedMsg carries freshly loaded rows.
+	loadedMsg struct {
+		rows []row
+		err  error
+	}
+	// actionDoneMsg reports the result of a lifecycle action.
+	actionDoneMsg struct {
+		status string
+		err    error
+	}
+	// sessionCreatedMsg reports a new session to attach to.
+	sessionCreatedMsg struct {
+		instanceID string
+		name       string
+	}
+	// attachDoneMsg reports that the user left an attached session.
+	attachDoneMsg struct {
+		err error
+	}
+)
+
+// execFunc runs a function as a tea.ExecCommand. The function uses the
+// process terminal directly, so the standard streams are ignored.
+type execFunc func() error
+
+func (f execFunc) Run() error          { return f() }
+func (f execFunc) SetStdin(io.Reader)  {}
+func (f execFunc) SetStdout(io.Writer) {}
+func (f execFunc) SetStderr(io.Writer) {}
+
+// model is the bubbletea model for the dashboard.
+type model struct {
+	ctx  context.Context
+	mgr  Manager
+	logs LogFollower
+	cfg  Config
+
+	// exec releases the terminal to run a command (tea.Exec, replaced in tests).
+	exec func(tea.ExecCommand, tea.ExecCallback) tea.Cmd
+
+	rows    []row
+	cursor  int
+	loaded  bool
+	confirm *confirmation
+	status  string
+	err     error
+
+	preview      *preview
+	previewLines []string
+	previewErr   error
+
+	width  int
+	height int
+}
+
+// newModel creates a dashboard model.
+func newModel(ctx context.Context, mgr Manager, logs LogFollower, cfg Config) model {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = DefaultRefreshInterval
+	}
+	if cfg.PreviewLines <= 0 {
+		cfg.PreviewLines = DefaultPreviewLines
+	}
+
+	return model{
+		ctx:    ctx,
+		mgr:    mgr,
+		logs:   logs,
+		cfg:    cfg,
+		exec:   tea.Exec,
+		width:  defaultWidth,
+		height: defaultHeight,
+	}
+}
+
+// Init implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+// Update implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case tickMsg:
+		return m, tea.Batch(m.load(), m.tick())
+
+	case loadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.setRows(msg.rows)
+		return m.syncPreview()
+
+	case previewLineMsg:
+		if m.preview == nil || msg.key != m.preview.key {
+			return m, nil
+		}
+		m.previewLines = append(m.previewLines, logging.CleanLine(msg.line))
+		if extra := len(m.previewLines) - m.cfg.PreviewLines; extra > 0 {
+			m.previewLines = m.previewLines[extra:]
+		}
+		return m, m.preview.wait()
+
+	case previewEndMsg:
+		if m.preview != nil && msg.key == m.preview.key && !errors.Is(msg.err, context.Canceled) {
+			m.previewErr = msg.err
+		}
+
+	case actionDoneMsg:
+		m.status, m.err = msg.status, msg.err
+		return m, m.load()
+
+	case sessionCreatedMsg:
+		return m, m.attach(msg.instanceID, msg.name)
+
+	case attachDoneMsg:
+		m.err = msg.err
+		return m, m.load()
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+// handleKey handles a key press.
+//
+//nolint:gocritic // hugeParam: called from Update with the value model
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	if m.confirm != nil {
+		c := m.confirm
+		m.confirm = nil
+		if key == "y" || key == "Y" {
+			return m, c.run
+		}
+		m.status = "Canceled"
+		return m, nil
+	}
+
+	m.status, m.err = "", nil
+
+	switch key {
+	case "ctrl+c", "q":
+		m.stopPreview()
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m.syncPreview()
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+		return m.syncPreview()
+	case "r":
+		return m, m.load()
+	}
+
+	sel, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+
+	switch key {
+	case "enter", "a":
+		session := sel.session
+		if session == nil {
+			session = m.recentSession(sel.inst.ID)
+		}
+		if session == nil {
+			m.status = fmt.Sprintf("No sessions in %s (press n to start a shell)", sel.inst.Branch)
+			return m, nil
+		}
+		return m, m.attach(sel.inst.ID, session.Name)
+
+	case "n":
+		return m, m.createShell(sel.inst)
+
+	case "x":
+		if sel.session == nil {
+			m.status = "Select a session to kill"
+			return m, nil
+		}
+		inst, name := sel.inst, sel.session.Name
+		m.confirm = &confirmation{
+			prompt: fmt.Sprintf("Kill session %s in %s?", name, inst.Branch),
+			run: m.action(fmt.Sprintf("Killed session %s", name), func(ctx context.Context) error {
+				return m.mgr.KillSession(ctx, inst.ID, name)
+			}),
+		}
+
+	case "s":
+		inst := sel.inst
+		m.confirm = &confirmation{
+			prompt: fmt.Sprintf("Stop instance %s?", inst.Branch),
+			run: m.action(fmt.Sprintf("Stopped %s", inst.Branch), func(ctx context.Context) error {
+				return m.mgr.Stop(ctx, inst.ID)
+			}),
+		}
+
+	case "d":
+		inst := sel.inst
+		m.confirm = &confirmation{
+			prompt: fmt.Sprintf("Remove instance %s and its worktree?", inst.Branch),
+			run: m.action(fmt.Sprintf("Removed %s", inst.Branch), func(ctx context.Context) error {
+				return m.mgr.Remove(ctx, inst.ID)
+			}),
+		}
+	}
+
+	return m, nil
+}
+
+// View implements tea.Model.
+//
+//nolint:gocritic // hugeParam: tea.Model interface requires value receiver
+func (m model) View() string {
+	// Header, status and help lines, plus the pane borders.
+	bodyHeight := max(m.height-5, 3)
+	listWidth := max(m.width*2/5, 20)
+	previewWidth := max(m.width-listWidth-4, 20)
+
+	list := paneStyle.Width(listWidth).Height(bodyHeight).Render(m.viewList(listWidth, bodyHeight))
+	preview := paneStyle.Width(previewWidth).Height(bodyHeight).Render(m.viewPreview(previewWidth, bodyHeight))
+
+	var status string
+	switch {
+	case m.confirm != nil:
+		status = m.confirm.prompt + " (y/n)"
+	case m.err != nil:
+		status = errorStyle.Render("Error: " + m.err.Error())
+	default:
+		status = m.status
+	}
+
+	return strings.Join([]string{
+		titleStyle.Render("Headjack"),
+		lipgloss.JoinHorizontal(lipgloss.Top, list, preview),
+		lipgloss.NewStyle().MaxWidth(m.width).Render(status),
+		dimStyle.MaxWidth(m.width).Render(helpText),
+	}, "\n")
+}
+
+// viewList renders the instance list, scrolled to keep the cursor visible.
+//
+//nolint:gocritic // hugeParam: called from View with the value model
+func (m model) viewList(width, height int) string {
+	if !m.loaded {
+		return "Loading..."
+	}
+	if len(m.rows) == 0 {
+		return dimStyle.Render("No instances (use 'hjk run' to create one)")
+	}
+
+	start := max(m.cursor-height+1, 0)
+	end := min(start+height, len(m.rows))
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		r := m.rows[i]
+		var line string
+		if r.session == nil {
+			line = fmt.Sprintf("%s %s", r.inst.Branch, dimStyle.Render(fmt.Sprintf("[%s] %s", r.inst.Status, filepath.Base(r.inst.Repo))))
+		} else {
+			line = fmt.Sprintf("  %s %s", r.session.Name, dimStyle.Render(r.session.Type))
+		}
+		line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewPreview renders the log preview for a session, or a summary for an
+// instance.
+//
+//nolint:gocritic // hugeParam: called from View with the value model
+func (m model) viewPreview(width, height int) string {
+	sel, ok := m.selected()
+	if !ok {
+		return ""
+	}
+
+	clip := lipgloss.NewStyle().MaxWidth(width)
+	if sel.session == nil {
+		sessions := 0
+		for _, r := range m.rows {
+			if r.session != nil && r.inst.ID == sel.inst.ID {
+				sessions++
+			}
+		}
+		return clip.Render(strings.Join([]string{
+			titleStyle.Render(sel.inst.Branch),
+			"Status:   " + string(sel.inst.Status),
+			"Repo:     " + sel.inst.Repo,
+			"Worktree: " + sel.inst.Worktree,
+			fmt.Sprintf("Sessions: %d", sessions),
+		}, "\n"))
+	}
+
+	header := titleStyle.Render(sel.session.Name) + dimStyle.Render(" log")
+	lines := m.previewLines
+	if n := height - 1; len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	body := strings.Join(lines, "\n")
+	switch {
+	case m.previewErr != nil:
+		body = errorStyle.Render("Log unavailable: " + m.previewErr.Error())
+	case len(lines) == 0:
+		body = dimStyle.Render("No output yet")
+	}
+	return clip.Render(header + "\n" + body)
+}
+
+// selected returns the row under the cursor.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) selected() (row, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return row{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+// recentSession returns the most recently accessed session of an instance.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) recentSession(instanceID string) *instance.Session {
+	var recent *instance.Session
+	for _, r := range m.rows {
+		if r.session != nil && r.inst.ID == instanceID &&
+			(recent == nil || r.session.LastAccessed.After(recent.LastAccessed)) {
+			recent = r.session
+		}
+	}
+	return recent
+}
+
+// setRows replaces the rows, keeping the cursor on the same row if it still
+// exists.
+func (m *model) setRows(rows []row) {
+	var selectedKey string
+	if sel, ok := m.selected(); ok {
+		selectedKey = sel.key()
+	}
+
+	m.rows = rows
+	m.loaded = true
+
+	if i := slices.IndexFunc(rows, func(r row) bool { return r.key() == selectedKey }); i >= 0 {
+		m.cursor = i
+	}
+	m.cursor = max(min(m.cursor, len(rows)-1), 0)
+}
+
+// syncPreview follows the log of the selected session, replacing any
+// previous preview.
+//
+//nolint:gocritic // hugeParam: returns the updated value model
+func (m model) syncPreview() (tea.Model, tea.Cmd) {
+	var key string
+	sel, ok := m.selected()
+	if ok && sel.session != nil {
+		key = previewKey(sel.inst.ID, sel.session.ID)
+	}
+	if m.preview != nil && m.preview.key == key {
+		return m, nil
+	}
+
+	m.stopPreview()
+	m.preview, m.previewLines, m.previewErr = nil, nil, nil
+	if key == "" {
+		return m, nil
+	}
+
+	m.preview = startPreview(m.ctx, m.logs, sel.inst.ID, sel.session.ID, m.cfg.PreviewLines)
+	return m, m.preview.wait()
+}
+
+// stopPreview stops following the previewed log.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) stopPreview() {
+	if m.preview != nil {
+		m.preview.cancel()
+	}
+}
+
+// tick schedules the next background refresh.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) tick() tea.Cmd {
+	return tea.Tick(m.cfg.RefreshInterval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// load reads all instances and their sessions.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) load() tea.Cmd {
+	ctx, mgr := m.ctx, m.mgr
+	return func() tea.Msg {
+		instances, err := mgr.List(ctx, instance.ListFilter{})
+		if err != nil {
+			return loadedMsg{err: fmt.Errorf("list instances: %w", err)}
+		}
+		slices.SortFunc(instances, func(a, b instance.Instance) int {
+			return cmp.Or(cmp.Compare(a.Repo, b.Repo), cmp.Compare(a.Branch, b.Branch))
+		})
+
+		var rows []row
+		for _, inst := range instances {
+			rows = append(rows, row{inst: inst})
+
+			sessions, err := mgr.ListSessions(ctx, inst.ID)
+			if err != nil {
+				return loadedMsg{err: fmt.Errorf("list sessions: %w", err)}
+			}
+			for i := range sessions {
+				rows = append(rows, row{inst: inst, session: &sessions[i]})
+			}
+		}
+		return loadedMsg{rows: rows}
+	}
+}
+
+// action runs fn in the background and reports status on success.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) action(status string, fn func(context.Context) error) tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		if err := fn(ctx); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{status: status}
+	}
+}
+
+// createShell starts a shell session in an instance, to be attached to.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) createShell(inst instance.Instance) tea.Cmd {
+	ctx, mgr := m.ctx, m.mgr
+	return func() tea.Msg {
+		session, err := mgr.CreateSession(ctx, inst.ID, &instance.CreateSessionConfig{Type: "shell"})
+		if err != nil {
+			return actionDoneMsg{err: fmt.Errorf("create session: %w", err)}
+		}
+		return sessionCreatedMsg{instanceID: inst.ID, name: session.Name}
+	}
+}
+
+// attach hands the terminal to a session until the user detaches.
+//
+//nolint:gocritic // hugeParam: small helper on the value model
+func (m model) attach(instanceID, sessionName string) tea.Cmd {
+	ctx, mgr := m.ctx, m.mgr
+	return m.exec(execFunc(func() error {
+		return mgr.AttachSession(ctx, instanceID, sessionName)
+	}), func(err error) tea.Msg {
+		return attachDoneMsg{err: err}
+	})
+}
@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jmgilman/headjack/internal/instance"
+	"github.com/jmgilman/headjack/internal/tui/mocks"
+)
+
+// newTestManager returns a manager with two instances: feat/b with sessions
+// claude and shell, and feat/a with no sessions.
+func newTestManager() *mocks.ManagerMock {
+	now := time.Now()
+	return &mocks.ManagerMock{
+		ListFunc: func(ctx context.Context, filter instance.ListFilter) ([]instance.Instance, error) {
+			return []instance.Instance{
+				{ID: "inst2", Repo: "/src/app", Branch: "feat/b", Status: instance.StatusRunning},
+				{ID: "inst1", Repo: "/src/app", Branch: "feat/a", Status: instance.StatusStopped},
+			}, nil
+		},
+		ListSessionsFunc: func(ctx context.Context, instanceID string) ([]instance.Session, error) {
+			if instanceID != "inst2" {
+				return nil, nil
+			}
+			return []instance.Session{
+				{ID: "s1", Name: "claude", Type: "claude", LastAccessed: now.Add(-time.Hour)},
+				{ID: "s2", Name: "shell", Type: "shell", LastAccessed: now},
+			}, nil
+		},
+	}
+}
+
+// blockingLogs returns a log follower that writes lines and then follows
+// until canceled.
+func blockingLogs(lines ...string) *mocks.LogFollowerMock {
+	return &mocks.LogFollowerMock{
+		FollowWithHistoryFunc: func(ctx context.Context, instanceID, sessionID string, out io.Writer, n int, pollInterval time.Duration) error {
+			for _, line := range lines {
+				if _, err := fmt.Fprintln(out, line); err != nil {
+					return err
+				}
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+}
+
+// loadedModel returns a model that has loaded the rows of mgr.
+func loadedModel(t *testing.T, mgr Manager, logs LogFollower) model {
+	t.Helper()
+
+	m := newModel(context.Background(), mgr, logs, Config{})
+	updated, _ := m.Update(m.load()())
+	t.Cleanup(func() { updated.(model).stopPreview() })
+	return updated.(model)
+}
+
+// press sends a key to the model.
+func press(t *testing.T, m model, key string) (model, tea.Cmd) {
+	t.Helper()
+
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	updated, cmd := m.Update(msg)
+	t.Cleanup(func() { updated.(model).stopPreview() })
+	return updated.(model), cmd
+}
+
+func TestModel_Load(t *testing.T) {
+	t.Run("lists instances sorted by branch with their sessions", func(t *testing.T) {
+		m := loadedModel(t, newTestManager(), blockingLogs())
+
+		require.Len(t, m.rows, 4)
+		assert.Equal(t, "inst1", m.rows[0].key())
+		assert.Equal(t, "inst2", m.rows[1].key())
+		assert.Equal(t, "inst2/claude", m.rows[2].key())
+		assert.Equal(t, "inst2/shell", m.rows[3].key())
+		assert.Contains(t, m.View(), "feat/a")
+		assert.Contains(t, m.View(), "claude")
+	})
+
+	t.Run("shows load errors", func(t *testing.T) {
+		mgr := &mocks.ManagerMock{
+			ListFunc: func(ctx context.Context, filter instance.ListFilter) ([]instance.Instance, error) {
+				return nil, errors.New("catalog locked")
+			},
+		}
+
+		m := loadedModel(t, mgr, blockingLogs())
+
+		require.Error(t, m.err)
+		assert.Contains(t, m.View(), "catalog locked")
+	})
+
+	t.Run("keeps the selected row across refreshes", func(t *testing.T) {
+		mgr := newTestManager()
+		m := loadedModel(t, mgr, blockingLogs())
+		m, _ = press(t, m, "down")
+		m, _ = press(t, m, "down")
+
+		// A new instance sorts before the selected one
+		list := mgr.ListFunc
+		mgr.ListFunc = func(ctx context.Context, filter instance.ListFilter) ([]instance.Instance, error) {
+			insts, err := list(ctx, filter)
+			return append(insts, instance.Instance{ID: "inst0", Repo: "/src/app", Branch: "feat/0"}), err
+		}
+		updated, _ := m.Update(m.load()())
+		m = updated.(model)
+
+		assert.Equal(t, 3, m.cursor)
+		assert.Equal(t, "inst2/claude", m.rows[m.cursor].key())
+	})
+}
+
+func TestModel_Preview(t *testing.T) {
+	t.Run("follows the log of the selected session", func(t *testing.T) {
+		logs := blockingLogs("\x1b[31mhello\x1b[0m", "progress 10%\rprogress 100%")
+		m := loadedModel(t, newTestManager(), logs)
+
+		m, cmd := press(t, m, "down")
+		assert.Nil(t, cmd, "instance rows have no log preview")
+		m, cmd = press(t, m, "down")
+		require.NotNil(t, cmd)
+
+		for range 2 {
+			updated, next := m.Update(cmd())
+			m, cmd = updated.(model), next
+		}
+
+		assert.Equal(t, []string{"hello", "progress 100%"}, m.previewLines)
+		require.Len(t, logs.FollowWithHistoryCalls(), 1)
+		assert.Equal(t, "inst2", logs.FollowWithHistoryCalls()[0].InstanceID)
+		assert.Equal(t, "s1", logs.FollowWithHistoryCalls()[0].SessionID)
+		assert.Contains(t, m.View(), "progress 100%")
+	})
+
+	t.Run("ignores lines from a previous selection", func(t *testing.T) {
+		m := loadedModel(t, newTestManager(), blockingLogs())
+		m, _ = press(t, m, "down")
+		m, _ = press(t, m, "down")
+
+		updated, _ := m.Update(previewLineMsg{key: "other/s9", line: "stale"})
+
+		assert.Empty(t, updated.(model).previewLines)
+	})
+}
+
+func TestModel_Actions(t *testing.T) {
+	t.Run("kills a session after confirmation", func(t *testing.T) {
+		mgr := newTestManager()
+		mgr.KillSessionFunc = func(ctx context.Context, instanceID, sessionName string) error {
+			return nil
+		}
+		m := loadedModel(t, mgr, blockingLogs())
+		m.cursor = 3
+
+		m, cmd := press(t, m, "x")
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "Kill session shell in feat/b? (y/n)")
+
+		m, cmd = press(t, m, "y")
+		require.NotNil(t, cmd)
+		updated, _ := m.Update(cmd())
+
+		require.Len(t, mgr.KillSessionCalls(), 1)
+		assert.Equal(t, "inst2", mgr.KillSessionCalls()[0].InstanceID)
+		assert.Equal(t, "shell", mgr.KillSessionCalls()[0].SessionName)
+		assert.Equal(t, "Killed session shell", updated.(model).status)
+	})
+
+	t.Run("cancels on any other key", func(t *testing.T) {
+		mgr := newTestManager()
+		m := loadedModel(t, mgr, blockingLogs())
+
+		m, _ = press(t, m, "d")
+		m, cmd := press(t, m, "n")
+
+		assert.Nil(t, cmd)
+		assert.Nil(t, m.confirm)
+		assert.Equal(t, "Canceled", m.status)
+		assert.Empty(t, mgr.RemoveCalls())
+		assert.Empty(t, mgr.CreateSessionCalls())
+	})
+
+	t.Run("stops and removes the selected instance", func(t *testing.T) {
+		mgr := newTestManager()
+		mgr.StopFunc = func(ctx context.Context, id string) error { return nil }
+		mgr.RemoveFunc = func(ctx context.Context, id string) error { return errors.New("worktree dirty") }
+		m := loadedModel(t, mgr, blockingLogs())
+		m.cursor = 2 // A session row acts on its instance
+
+		m, _ = press(t, m, "s")
+		m, cmd := press(t, m, "y")
+		updated, _ := m.Update(cmd())
+		m = updated.(model)
+		assert.Equal(t, "Stopped feat/b", m.status)
+
+		m, _ = press(t, m, "d")
+		m, cmd = press(t, m, "y")
+		updated, _ = m.Update(cmd())
+		m = updated.(model)
+
+		require.Len(t, mgr.StopCalls(), 1)
+		require.Len(t, mgr.RemoveCalls(), 1)
+		assert.Equal(t, "inst2", mgr.RemoveCalls()[0].ID)
+		assert.EqualError(t, m.err, "worktree dirty")
+	})
+
+	t.Run("kill requires a session row", func(t *testing.T) {
+		m := loadedModel(t, newTestManager(), blockingLogs())
+
+		m, cmd := press(t, m, "x")
+
+		assert.Nil(t, cmd)
+		assert.Nil(t, m.confirm)
+		assert.Equal(t, "Select a session to kill", m.status)
+	})
+}
+
+func TestModel_Attach(t *testing.T) {
+	// captureExec replaces tea.Exec, running the command immediately.
+	captureExec := func(m *model) {
+		m.exec = func(c tea.ExecCommand, fn tea.ExecCallback) tea.Cmd {
+			return func() tea.Msg { return fn(c.Run()) }
+		}
+	}
+
+	t.Run("attaches to the most recent session of an instance", func(t *testing.T) {
+		mgr := newTestManager()
+		mgr.AttachSessionFunc = func(ctx context.Context, instanceID, sessionName string) error {
+			return nil
+		}
+		m := loadedModel(t, mgr, blockingLogs())
+		captureExec(&m)
+		m.cursor = 1
+
+		_, cmd := press(t, m, "enter")
+		require.NotNil(t, cmd)
+		assert.Equal(t, attachDoneMsg{}, cmd())
+
+		require.Len(t, mgr.AttachSessionCalls(), 1)
+		assert.Equal(t, "shell", mgr.AttachSessionCalls()[0].SessionName)
+	})
+
+	t.Run("reports instances without sessions", func(t *testing.T) {
+		m := loadedModel(t, newTestManager(), blockingLogs())
+
+		m, cmd := press(t, m, "enter")
+
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.status, "No sessions in feat/a")
+	})
+
+	t.Run("creates a shell session and attaches to it", func(t *testing.T) {
+		mgr := newTestManager()
+		mgr.CreateSessionFunc = func(ctx context.Context, instanceID string, cfg *instance.CreateSessionConfig) (*instance.Session, error) {
+			assert.Equal(t, "shell", cfg.Type)
+			return &instance.Session{Name: "happy-panda"}, nil
+		}
+		mgr.AttachSessionFunc = func(ctx context.Context, instanceID, sessionName string) error {
+			return nil
+		}
+		m := loadedModel(t, mgr, blockingLogs())
+		captureExec(&m)
+		m.cursor = 1
+
+		m, cmd := press(t, m, "n")
+		require.NotNil(t, cmd)
+		updated, cmd := m.Update(cmd())
+		require.NotNil(t, cmd)
+		_, _ = updated.Update(cmd())
+
+		require.Len(t, mgr.CreateSessionCalls(), 1)
+		assert.Equal(t, "inst2", mgr.CreateSessionCalls()[0].InstanceID)
+		require.Len(t, mgr.AttachSessionCalls(), 1)
+		assert.Equal(t, "happy-panda", mgr.AttachSessionCalls()[0].SessionName)
+	})
+}
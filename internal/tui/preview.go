@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewLineMsg carries a log line for the preview identified by key.
+type previewLineMsg struct {
+	key  string
+	line string
+}
+
+// previewEndMsg reports that the preview identified by key stopped following.
+type previewEndMsg struct {
+	key string
+	err error
+}
+
+// preview follows the log of one session.
+type preview struct {
+	key    string // instanceID/sessionID of the followed session
+	cancel context.CancelFunc
+	lines  <-chan string
+	errc   <-chan error
+}
+
+// startPreview starts following a session log in the background. Lines are
+// delivered as previewLineMsg by waitPreview.
+func startPreview(ctx context.Context, logs LogFollower, instanceID, sessionID string, n int) *preview {
+	ctx, cancel := context.WithCancel(ctx)
+	lines := make(chan string, 256)
+	errc := make(chan error, 1)
+
+	go func() {
+		w := &lineWriter{ctx: ctx, lines: lines}
+		errc <- logs.FollowWithHistory(ctx, instanceID, sessionID, w, n, defaultLogPollInterval)
+		close(lines)
+	}()
+
+	return &preview{
+		key:    previewKey(instanceID, sessionID),
+		cancel: cancel,
+		lines:  lines,
+		errc:   errc,
+	}
+}
+
+// wait returns a command that delivers the next preview line.
+func (p *preview) wait() tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-p.lines
+		if !ok {
+			return previewEndMsg{key: p.key, err: <-p.errc}
+		}
+		return previewLineMsg{key: p.key, line: line}
+	}
+}
+
+func previewKey(instanceID, sessionID string) string {
+	return instanceID + "/" + sessionID
+}
+
+// lineWriter splits written data into lines and sends them on a channel
+// until its context is canceled.
+type lineWriter struct {
+	ctx     context.Context
+	lines   chan<- string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(w.partial[:i])
+		w.partial = w.partial[i+1:]
+
+		select {
+		case w.lines <- line:
+		case <-w.ctx.Done():
+			return 0, w.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	lines := make(chan string, 10)
+	w := &lineWriter{ctx: context.Background(), lines: lines}
+
+	n, err := w.Write([]byte("one\ntw"))
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	_, err = w.Write([]byte("o\nthree"))
+	require.NoError(t, err)
+
+	close(lines)
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	assert.Equal(t, []string{"one", "two"}, got)
+}
+
+func TestLineWriter_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := &lineWriter{ctx: ctx, lines: make(chan string)}
+
+	_, err := w.Write([]byte("blocked\n"))
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,63 @@
+// Package tui provides an interactive terminal dashboard for instances and
+// sessions. It shows a live list of instances with their sessions, previews
+// the selected session's log, and binds keys for the common lifecycle
+// commands (attach, new session, kill, stop, remove).
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/jmgilman/headjack/internal/instance"
+)
+
+// Defaults for Config.
+const (
+	DefaultRefreshInterval = 2 * time.Second
+	DefaultPreviewLines    = 200
+	defaultLogPollInterval = 100 * time.Millisecond
+)
+
+// Manager is the subset of instance.Manager used by the dashboard.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -skip-ensure -out mocks/manager.go . Manager
+type Manager interface {
+	List(ctx context.Context, filter instance.ListFilter) ([]instance.Instance, error)
+	ListSessions(ctx context.Context, instanceID string) ([]instance.Session, error)
+	CreateSession(ctx context.Context, instanceID string, cfg *instance.CreateSessionConfig) (*instance.Session, error)
+	AttachSession(ctx context.Context, instanceID, sessionName string) error
+	KillSession(ctx context.Context, instanceID, sessionName string) error
+	Stop(ctx context.Context, id string) error
+	Remove(ctx context.Context, id string) error
+}
+
+// LogFollower streams session logs for the preview pane. It is implemented
+// by logging.Reader.
+//
+//go:generate go run github.com/matryer/moq@latest -pkg mocks -skip-ensure -out mocks/log_follower.go . LogFollower
+type LogFollower interface {
+	FollowWithHistory(ctx context.Context, instanceID, sessionID string, out io.Writer, n int, pollInterval time.Duration) error
+}
+
+// Config configures the dashboard.
+type Config struct {
+	RefreshInterval time.Duration // How often instances are reloaded (default: DefaultRefreshInterval)
+	PreviewLines    int           // Log lines kept in the preview (default: DefaultPreviewLines)
+}
+
+// Run shows the dashboard until the user quits or ctx is canceled.
+func Run(ctx context.Context, mgr Manager, logs LogFollower, cfg Config) error {
+	m := newModel(ctx, mgr, logs, cfg)
+	final, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if fm, ok := final.(model); ok {
+		fm.stopPreview()
+	}
+	if err != nil {
+		return fmt.Errorf("run dashboard: %w", err)
+	}
+	return nil
+}